@@ -16,7 +16,20 @@ function run(cmd, args, options = {}) {
 // Ensure GOOS/GOARCH
 const env = { ...process.env, GOOS: 'js', GOARCH: 'wasm' };
 
+// Stamp the build so GetVersion can identify it
+const pkg = require(path.join(projectRoot, 'package.json'));
+const git = spawnSync('git', ['rev-parse', 'HEAD'], { cwd: projectRoot, encoding: 'utf8' });
+const commit = git.status === 0 ? git.stdout.trim() : '';
+const ldflags = [
+  `-X main.version=${pkg.version}`,
+  `-X main.commit=${commit}`,
+  `-X main.buildTime=${new Date().toISOString()}`,
+].join(' ');
+
+// LIGHTER_STRICT=1 builds the strict flavor, which refuses the demo and diagnostic paths from startup
+const tags = process.env.LIGHTER_STRICT === '1' ? ['-tags', 'strict'] : [];
+
 // Build
-run(process.platform === 'win32' ? 'go.exe' : 'go', ['build', '-o', outWasm, './wasm'], { cwd: goDir, env });
+run(process.platform === 'win32' ? 'go.exe' : 'go', ['build', ...tags, '-ldflags', ldflags, '-o', outWasm, './wasm'], { cwd: goDir, env });
 
 console.log('Built wasm ->', outWasm);
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ClockSkew is the offset of the exchange clock from the local one, as last measured by SyncClock.
+type ClockSkew struct {
+	// Offset is added to the local time to get the exchange time.
+	Offset time.Duration
+	// RoundTrip is the duration of the request the offset was measured with, which bounds its error.
+	RoundTrip time.Duration
+	// SyncedAt is the local time of the measurement, zero until the clock was synced.
+	SyncedAt time.Time
+}
+
+var clock struct {
+	mu     sync.RWMutex
+	skew   ClockSkew
+	frozen time.Time
+}
+
+// Now returns the current time on the exchange clock, i.e. the local time corrected by the offset measured by
+// SyncClock. Deadlines and expiries sent to the exchange are computed from it.
+func Now() time.Time {
+	clock.mu.RLock()
+	defer clock.mu.RUnlock()
+	if !clock.frozen.IsZero() {
+		return clock.frozen
+	}
+	return time.Now().Add(clock.skew.Offset)
+}
+
+// FreezeClock makes Now return t, e.g. to compute the same deadlines as a recorded call, until it is called with
+// the zero time.
+func FreezeClock(t time.Time) {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	clock.frozen = t
+}
+
+// GetClockSkew returns the offset applied by Now.
+func GetClockSkew() ClockSkew {
+	clock.mu.RLock()
+	defer clock.mu.RUnlock()
+	return clock.skew
+}
+
+// SetClockSkew replaces the offset applied by Now, e.g. with one measured by the host. The zero value stops
+// correcting the local time.
+func SetClockSkew(skew ClockSkew) {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	clock.skew = skew
+}
+
+// serverTime returns the time reported by the exchange status endpoint, truncated to resolution.
+func (c *HTTPClient) serverTime() (t time.Time, resolution time.Duration, err error) {
+	endpoint := c.endpoints.current()
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	u.Path = "/"
+	resp, err := c.get(u.String())
+	c.endpoints.report(endpoint, resp, err)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, 0, errors.New(string(body))
+	}
+
+	var status struct {
+		Timestamp int64 `json:"timestamp"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return time.Time{}, 0, err
+	}
+	if status.Timestamp <= 0 {
+		return time.Time{}, 0, fmt.Errorf("exchange status carries no timestamp")
+	}
+	// The status endpoint reports seconds; accept milliseconds should it switch
+	if status.Timestamp > 1e12 {
+		return time.UnixMilli(status.Timestamp), time.Millisecond, nil
+	}
+	return time.Unix(status.Timestamp, 0), time.Second, nil
+}
+
+// SyncClock measures the offset of the exchange clock from the local one and applies it to Now. The exchange
+// time is taken to be that of the middle of the request. Offsets within the precision of the measurement, the
+// resolution of the status endpoint plus half the round trip, are dropped.
+func (c *HTTPClient) SyncClock() (ClockSkew, error) {
+	start := time.Now()
+	serverTime, resolution, err := c.serverTime()
+	if err != nil {
+		return ClockSkew{}, fmt.Errorf("failed to fetch the exchange time: %w", err)
+	}
+	end := time.Now()
+
+	rtt := end.Sub(start)
+	// The reported time is truncated, so it is half the resolution early on average
+	offset := serverTime.Add(resolution / 2).Sub(start.Add(rtt / 2))
+	if offset.Abs() <= resolution+rtt/2 {
+		offset = 0
+	}
+	skew := ClockSkew{Offset: offset, RoundTrip: rtt, SyncedAt: end}
+	SetClockSkew(skew)
+	return skew, nil
+}
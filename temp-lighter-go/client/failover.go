@@ -0,0 +1,261 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// FailoverThreshold is the number of consecutive failures after which an endpoint is skipped.
+	FailoverThreshold = 3
+	// RecoveryDelay is how long a skipped endpoint is left alone. It is then tried again, and a single failure
+	// skips it for another RecoveryDelay.
+	RecoveryDelay = 30 * time.Second
+)
+
+// FailoverEvent reports that an HTTPClient switched the endpoint it sends requests to.
+type FailoverEvent struct {
+	From   string
+	To     string
+	Reason string
+}
+
+// endpoint is one API base URL with its health.
+type endpoint struct {
+	url       string
+	failures  int
+	downUntil time.Time
+	// probation is set once downUntil passed, until the endpoint answers again.
+	probation bool
+}
+
+// endpoints holds the base URLs of an HTTPClient by priority. Requests go to the first healthy one.
+type endpoints struct {
+	mu         sync.Mutex
+	list       []*endpoint
+	active     string
+	onFailover func(FailoverEvent)
+}
+
+func newEndpoints(baseUrls []string) *endpoints {
+	e := &endpoints{}
+	for _, u := range baseUrls {
+		e.list = append(e.list, &endpoint{url: u})
+	}
+	e.active = baseUrls[0]
+	return e
+}
+
+// current returns the base URL the next request should use.
+func (e *endpoints) current() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	pick := e.pickLocked(time.Now())
+	if pick != e.active {
+		reason := "all endpoints failing"
+		if e.priority(pick) < e.priority(e.active) {
+			reason = "recovered"
+		}
+		e.switchTo(pick, reason)
+	}
+	return pick
+}
+
+// pickLocked returns the first endpoint that is not skipped, or the one recovering the soonest when all are.
+// Endpoints whose RecoveryDelay passed are put on probation. e.mu must be held.
+func (e *endpoints) pickLocked(now time.Time) string {
+	var pick *endpoint
+	for _, ep := range e.list {
+		if !now.Before(ep.downUntil) {
+			if !ep.downUntil.IsZero() {
+				ep.downUntil, ep.probation = time.Time{}, true
+			}
+			return ep.url
+		}
+		if pick == nil || ep.downUntil.Before(pick.downUntil) {
+			pick = ep
+		}
+	}
+	return pick.url
+}
+
+func (e *endpoints) priority(url string) int {
+	for i, ep := range e.list {
+		if ep.url == url {
+			return i
+		}
+	}
+	return len(e.list)
+}
+
+// switchTo makes url the active endpoint, reporting the change. e.mu must be held.
+func (e *endpoints) switchTo(url, reason string) {
+	if url == e.active {
+		return
+	}
+	event := FailoverEvent{From: e.active, To: url, Reason: reason}
+	e.active = url
+	if e.onFailover != nil {
+		e.onFailover(event)
+	}
+}
+
+// report records the outcome of a request sent to url. Transport errors and 5xx statuses count as failures.
+func (e *endpoints) report(url string, resp *http.Response, err error) {
+	failed := err != nil || (resp != nil && resp.StatusCode >= http.StatusInternalServerError)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, ep := range e.list {
+		if ep.url != url {
+			continue
+		}
+		if !failed {
+			ep.failures, ep.probation, ep.downUntil = 0, false, time.Time{}
+			return
+		}
+		ep.failures++
+		if ep.failures < FailoverThreshold && !ep.probation {
+			return
+		}
+
+		reason := fmt.Sprintf("%d consecutive failures", ep.failures)
+		if err != nil {
+			reason += ": " + err.Error()
+		} else {
+			reason += fmt.Sprintf(": status %d", resp.StatusCode)
+		}
+		now := time.Now()
+		ep.failures, ep.probation, ep.downUntil = 0, false, now.Add(RecoveryDelay)
+		if url == e.active {
+			e.switchTo(e.pickLocked(now), reason)
+		}
+		return
+	}
+}
+
+// EndpointStatus describes one base URL of an HTTPClient.
+type EndpointStatus struct {
+	URL       string
+	Active    bool
+	Healthy   bool
+	Failures  int
+	DownUntil time.Time
+}
+
+// Endpoints returns the status of the base URLs of c, by priority.
+func (c *HTTPClient) Endpoints() []EndpointStatus {
+	c.endpoints.mu.Lock()
+	defer c.endpoints.mu.Unlock()
+
+	now := time.Now()
+	res := make([]EndpointStatus, 0, len(c.endpoints.list))
+	for _, ep := range c.endpoints.list {
+		res = append(res, EndpointStatus{
+			URL:       ep.url,
+			Active:    ep.url == c.endpoints.active,
+			Healthy:   !now.Before(ep.downUntil),
+			Failures:  ep.failures,
+			DownUntil: ep.downUntil,
+		})
+	}
+	return res
+}
+
+// SetFailoverHandler installs fn, called whenever c switches endpoints. It is called with c's endpoints locked
+// and must not send requests through c.
+func (c *HTTPClient) SetFailoverHandler(fn func(FailoverEvent)) {
+	c.endpoints.mu.Lock()
+	defer c.endpoints.mu.Unlock()
+	c.endpoints.onFailover = fn
+}
+
+// CheckEndpoints probes the root of every base URL of c and records the outcome as for any request, so that
+// failing endpoints are skipped and recovered ones used again without waiting for traffic.
+func (c *HTTPClient) CheckEndpoints() []EndpointStatus {
+	c.endpoints.mu.Lock()
+	urls := make([]string, 0, len(c.endpoints.list))
+	for _, ep := range c.endpoints.list {
+		urls = append(urls, ep.url)
+	}
+	c.endpoints.mu.Unlock()
+
+	for _, u := range urls {
+		resp, err := c.get(u + "/")
+		if resp != nil {
+			resp.Body.Close()
+		}
+		c.endpoints.report(u, resp, err)
+	}
+	c.endpoints.current()
+	return c.Endpoints()
+}
+
+// NetworkError is returned when a tx could not reach the exchange, as opposed to being rejected by it. The
+// tx was not processed and may be sent again.
+type NetworkError struct {
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return "network error: " + e.Err.Error()
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// RateLimitError is returned when the exchange throttled a tx submission. The tx was not processed and may be
+// sent again after RetryAfter, which is 0 when the exchange did not say.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter == 0 {
+		return "rate limited"
+	}
+	return fmt.Sprintf("rate limited, retry after %s", e.RetryAfter)
+}
+
+// IsNonceError reports whether err is the exchange refusing a tx for its nonce, which it only tells in the
+// message of the rejection. Network failures and throttling never are.
+func IsNonceError(err error) bool {
+	var netErr *NetworkError
+	var rateErr *RateLimitError
+	if err == nil || errors.As(err, &netErr) || errors.As(err, &rateErr) {
+		return false
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "nonce")
+}
+
+// IsHaltError reports whether err is the exchange refusing a tx because trading is halted, e.g. for maintenance,
+// which it only tells in the message of the rejection or of the page it serves instead. Throttling never is.
+func IsHaltError(err error) bool {
+	var rateErr *RateLimitError
+	if err == nil || errors.As(err, &rateErr) {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "maintenance") || strings.Contains(msg, "halted") || strings.Contains(msg, "trading is paused")
+}
+
+// retryAfter parses a Retry-After header given in seconds; dates and malformed values give 0.
+func retryAfter(header string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(header))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// isUnavailable reports whether status means the request never reached the exchange.
+func isUnavailable(status int) bool {
+	return status == http.StatusBadGateway || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
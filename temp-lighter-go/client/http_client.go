@@ -4,6 +4,7 @@ import (
 	"crypto/tls"
 	"net"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -27,23 +28,109 @@ var (
 )
 
 type HTTPClient struct {
-	endpoint            string
+	endpoints           *endpoints
 	channelName         string
 	fatFingerProtection bool
+	onNonce             func(NonceEvent)
+
+	// transport holds the headers and round tripper set for c; requests default to the shared client.
+	transport struct {
+		mu      sync.RWMutex
+		headers http.Header
+		client  *http.Client
+	}
+}
+
+// NonceEvent reports the next nonce of an (account, api key) pair as fetched from the exchange.
+type NonceEvent struct {
+	AccountIndex int64
+	ApiKeyIndex  uint8
+	Nonce        int64
 }
 
 func NewHTTPClient(baseUrl string) *HTTPClient {
 	if baseUrl == "" {
 		return nil
 	}
+	return NewHTTPClientWithFallbacks([]string{baseUrl})
+}
+
+// NewHTTPClientWithFallbacks returns a client sending its requests to the first of baseUrls, by priority, that
+// is healthy: after FailoverThreshold consecutive failures an endpoint is skipped for RecoveryDelay. Requests
+// are not retried; the ones following a failover use the next endpoint.
+func NewHTTPClientWithFallbacks(baseUrls []string) *HTTPClient {
+	if len(baseUrls) == 0 {
+		return nil
+	}
 
 	return &HTTPClient{
-		endpoint:            baseUrl,
+		endpoints:           newEndpoints(baseUrls),
 		channelName:         "",
 		fatFingerProtection: true,
 	}
 }
 
+// SetTransport replaces the transport shared by all HTTP clients, e.g. with one backed by the host's fetch
+// when running as wasm.
+func SetTransport(rt http.RoundTripper) {
+	httpClient.Transport = rt
+}
+
+// SetHeaders replaces the headers sent with every request of c, e.g. the credentials of a gateway in front of
+// the exchange. They do not override the ones a request sets itself.
+func (c *HTTPClient) SetHeaders(headers map[string]string) {
+	h := http.Header{}
+	for k, v := range headers {
+		h.Set(k, v)
+	}
+	c.transport.mu.Lock()
+	c.transport.headers = h
+	c.transport.mu.Unlock()
+}
+
+// SetRoundTripper routes the requests of c through rt instead of the transport shared by all HTTP clients, e.g.
+// to go through a proxy. A nil rt restores the shared transport.
+func (c *HTTPClient) SetRoundTripper(rt http.RoundTripper) {
+	var client *http.Client
+	if rt != nil {
+		client = &http.Client{Timeout: httpClient.Timeout, Transport: rt}
+	}
+	c.transport.mu.Lock()
+	c.transport.client = client
+	c.transport.mu.Unlock()
+}
+
+// do sends req with the headers and through the round tripper set for c.
+func (c *HTTPClient) do(req *http.Request) (*http.Response, error) {
+	c.transport.mu.RLock()
+	headers, client := c.transport.headers, c.transport.client
+	c.transport.mu.RUnlock()
+	if client == nil {
+		client = httpClient
+	}
+	for k, v := range headers {
+		if req.Header.Get(k) == "" {
+			req.Header[k] = v
+		}
+	}
+	return client.Do(req)
+}
+
+// get sends a GET request to u, see do.
+func (c *HTTPClient) get(u string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(req)
+}
+
 func (c *HTTPClient) SetFatFingerProtection(enabled bool) {
 	c.fatFingerProtection = enabled
 }
+
+// SetNonceHandler installs fn, called whenever c fetches the next nonce of an (account, api key) pair from the
+// exchange. It must be set before c is shared.
+func (c *HTTPClient) SetNonceHandler(fn func(NonceEvent)) {
+	c.onNonce = fn
+}
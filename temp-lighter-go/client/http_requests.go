@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 
+	"github.com/elliottech/lighter-go/types"
 	"github.com/elliottech/lighter-go/types/txtypes"
 )
 
@@ -25,7 +27,8 @@ func (c *HTTPClient) parseResultStatus(respBody []byte) error {
 }
 
 func (c *HTTPClient) getAndParseL2HTTPResponse(path string, params map[string]any, result interface{}) error {
-	u, err := url.Parse(c.endpoint)
+	endpoint := c.endpoints.current()
+	u, err := url.Parse(endpoint)
 	if err != nil {
 		return err
 	}
@@ -36,7 +39,8 @@ func (c *HTTPClient) getAndParseL2HTTPResponse(path string, params map[string]an
 		q.Set(k, fmt.Sprintf("%v", v))
 	}
 	u.RawQuery = q.Encode()
-	resp, err := httpClient.Get(u.String())
+	resp, err := c.get(u.String())
+	c.endpoints.report(endpoint, resp, err)
 	if err != nil {
 		return err
 	}
@@ -63,9 +67,42 @@ func (c *HTTPClient) GetNextNonce(accountIndex int64, apiKeyIndex uint8) (int64,
 	if err != nil {
 		return -1, err
 	}
+	if c.onNonce != nil {
+		c.onNonce(NonceEvent{AccountIndex: accountIndex, ApiKeyIndex: apiKeyIndex, Nonce: result.Nonce})
+	}
 	return result.Nonce, nil
 }
 
+// GetExchangeStatus fetches the status of the exchange. A maintenance page, served as 503, is reported as a
+// status rather than an error; transport failures and other unavailable gateways are a NetworkError.
+func (c *HTTPClient) GetExchangeStatus() (*ExchangeStatus, error) {
+	endpoint := c.endpoints.current()
+	resp, err := c.get(endpoint + "/")
+	c.endpoints.report(endpoint, resp, err)
+	if err != nil {
+		return nil, &NetworkError{Err: err}
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &NetworkError{Err: err}
+	}
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		return &ExchangeStatus{Status: resp.StatusCode, Message: string(body)}, nil
+	}
+	if isUnavailable(resp.StatusCode) {
+		return nil, &NetworkError{Err: fmt.Errorf("status %d: %s", resp.StatusCode, body)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(string(body))
+	}
+	result := &ExchangeStatus{}
+	if err := json.Unmarshal(body, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (c *HTTPClient) GetApiKey(accountIndex int64, apiKeyIndex uint8) (*AccountApiKeys, error) {
 	result := &AccountApiKeys{}
 	err := c.getAndParseL2HTTPResponse("api/v1/apikeys", map[string]any{"account_index": accountIndex, "api_key_index": apiKeyIndex}, result)
@@ -88,30 +125,87 @@ func (c *HTTPClient) SendRawTx(tx txtypes.TxInfo) (string, error) {
 		data.Add("price_protection", "false")
 	}
 
-	req, _ := http.NewRequest("POST", c.endpoint+"/api/v1/sendTx", strings.NewReader(data.Encode()))
+	res := &TxHash{}
+	if err := c.postTxForm("/api/v1/sendTx", data, res); err != nil {
+		return "", err
+	}
+	return res.TxHash, nil
+}
+
+// SendTxBatch submits txs, already in submission order, in one sendTxBatch call and returns their hashes.
+func (c *HTTPClient) SendTxBatch(txs []txtypes.TxInfo) ([]string, error) {
+	if len(txs) > types.MaxBatchTxs {
+		return nil, fmt.Errorf("batch holds %d txs, at most %d are allowed", len(txs), types.MaxBatchTxs)
+	}
+	data, err := TxBatchForm(txs)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &TxHashes{}
+	if err := c.postTxForm("/api/v1/sendTxBatch", data, res); err != nil {
+		return nil, err
+	}
+	return res.TxHash, nil
+}
+
+// postTxForm posts the form data of a tx submission to path and decodes the response into result. Transport
+// errors and unavailable gateways are returned as a NetworkError, throttled calls as a RateLimitError.
+func (c *HTTPClient) postTxForm(path string, data url.Values, result interface{}) error {
+	endpoint := c.endpoints.current()
+	req, err := http.NewRequest("POST", endpoint+path, strings.NewReader(data.Encode()))
+	if err != nil {
+		return err
+	}
 	req.Header.Set("Channel-Name", c.channelName)
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	resp, err := httpClient.Do(req)
+	resp, err := c.do(req)
+	c.endpoints.report(endpoint, resp, err)
 	if err != nil {
-		return "", err
+		return &NetworkError{Err: err}
 	}
 	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return &NetworkError{Err: err}
+	}
+	if isUnavailable(resp.StatusCode) {
+		return &NetworkError{Err: fmt.Errorf("status %d: %s", resp.StatusCode, body)}
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &RateLimitError{RetryAfter: retryAfter(resp.Header.Get("Retry-After"))}
 	}
 	if resp.StatusCode != http.StatusOK {
-		return "", errors.New(string(body))
+		return errors.New(string(body))
 	}
 	if err = c.parseResultStatus(body); err != nil {
-		return "", err
+		return err
 	}
-	res := &TxHash{}
-	if err := json.Unmarshal(body, res); err != nil {
-		return "", err
+	return json.Unmarshal(body, result)
+}
+
+// TxBatchForm encodes txs, already in submission order, as the form body of a sendTxBatch call.
+func TxBatchForm(txs []txtypes.TxInfo) (url.Values, error) {
+	txTypes := make([]int, 0, len(txs))
+	txInfos := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		txInfo, err := tx.GetTxInfo()
+		if err != nil {
+			return nil, err
+		}
+		txTypes = append(txTypes, int(tx.GetTxType()))
+		txInfos = append(txInfos, txInfo)
 	}
 
-	return res.TxHash, nil
+	typesJSON, err := json.Marshal(txTypes)
+	if err != nil {
+		return nil, err
+	}
+	infosJSON, err := json.Marshal(txInfos)
+	if err != nil {
+		return nil, err
+	}
+	return url.Values{"tx_types": {string(typesJSON)}, "tx_infos": {string(infosJSON)}}, nil
 }
 
 func (c *HTTPClient) GetTransferFeeInfo(accountIndex, toAccountIndex int64, auth string) (*TransferFeeInfo, error) {
@@ -126,3 +220,207 @@ func (c *HTTPClient) GetTransferFeeInfo(accountIndex, toAccountIndex int64, auth
 	}
 	return result, nil
 }
+
+func (c *HTTPClient) GetOrderBookDetails(marketIndex uint8) (*OrderBookDetails, error) {
+	result := &OrderBookDetails{}
+	err := c.getAndParseL2HTTPResponse("api/v1/orderBookDetails", map[string]any{"market_id": marketIndex}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *HTTPClient) GetOrderBookOrders(marketIndex uint8, limit int) (*OrderBookOrders, error) {
+	result := &OrderBookOrders{}
+	err := c.getAndParseL2HTTPResponse("api/v1/orderBookOrders", map[string]any{"market_id": marketIndex, "limit": limit}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTopOfBook returns the best bid and ask of a market in price ticks, 0 for an empty side.
+func (c *HTTPClient) GetTopOfBook(marketIndex uint8) (bestBid, bestAsk uint32, err error) {
+	market, err := c.marketDetail(marketIndex)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	orders, err := c.GetOrderBookOrders(marketIndex, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(orders.Bids) > 0 {
+		if bestBid, err = types.ParsePriceTicks(orders.Bids[0].Price, market.PriceDecimals); err != nil {
+			return 0, 0, fmt.Errorf("best bid: %w", err)
+		}
+	}
+	if len(orders.Asks) > 0 {
+		if bestAsk, err = types.ParsePriceTicks(orders.Asks[0].Price, market.PriceDecimals); err != nil {
+			return 0, 0, fmt.Errorf("best ask: %w", err)
+		}
+	}
+	return bestBid, bestAsk, nil
+}
+
+func (c *HTTPClient) GetAccount(accountIndex int64) (*DetailedAccounts, error) {
+	result := &DetailedAccounts{}
+	err := c.getAndParseL2HTTPResponse("api/v1/account", map[string]any{"by": "index", "value": accountIndex}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPositions returns the signed base amount held by accountIndex in each market with an open position,
+// negative for shorts.
+func (c *HTTPClient) GetPositions(accountIndex int64) (map[uint8]int64, error) {
+	accounts, err := c.GetAccount(accountIndex)
+	if err != nil {
+		return nil, err
+	}
+	var account *DetailedAccount
+	for _, a := range accounts.Accounts {
+		if a.Index == accountIndex {
+			account = a
+		}
+	}
+	if account == nil {
+		return nil, fmt.Errorf("account %d not found", accountIndex)
+	}
+
+	positions := map[uint8]int64{}
+	for _, p := range account.Positions {
+		if p.Sign == 0 {
+			continue
+		}
+		market, err := c.marketDetail(p.MarketId)
+		if err != nil {
+			return nil, err
+		}
+		size, err := types.ParseDecimalUnits(p.Position, market.SizeDecimals, txtypes.MaxOrderBaseAmount)
+		if err != nil {
+			return nil, fmt.Errorf("position in market %d: %w", p.MarketId, err)
+		}
+		if size < 0 {
+			size = -size
+		}
+		if size != 0 {
+			positions[p.MarketId] = int64(p.Sign) * size
+		}
+	}
+	return positions, nil
+}
+
+func (c *HTTPClient) GetActiveOrders(accountIndex int64, marketIndex uint8, auth string) (*Orders, error) {
+	result := &Orders{}
+	err := c.getAndParseL2HTTPResponse("api/v1/accountActiveOrders", map[string]any{
+		"account_index": accountIndex,
+		"market_id":     marketIndex,
+		"auth":          auth,
+	}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetOpenOrders returns the resting orders of accountIndex on marketIndex in protocol units, with BaseAmount
+// holding the remaining base amount.
+func (c *HTTPClient) GetOpenOrders(accountIndex int64, marketIndex uint8, auth string) ([]*OpenOrder, error) {
+	market, err := c.marketDetail(marketIndex)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := c.GetActiveOrders(accountIndex, marketIndex, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*OpenOrder, 0, len(orders.Orders))
+	for _, o := range orders.Orders {
+		price, err := types.ParsePriceTicks(o.Price, market.PriceDecimals)
+		if err != nil {
+			return nil, fmt.Errorf("order %d: %w", o.OrderIndex, err)
+		}
+		remaining, err := types.ParseDecimalUnits(o.RemainingBaseAmount, market.SizeDecimals, txtypes.MaxOrderBaseAmount)
+		if err != nil {
+			return nil, fmt.Errorf("order %d: %w", o.OrderIndex, err)
+		}
+		var isAsk uint8
+		if o.IsAsk {
+			isAsk = 1
+		}
+		res = append(res, &OpenOrder{
+			OrderIndex:       o.OrderIndex,
+			ClientOrderIndex: o.ClientOrderIndex,
+			MarketIndex:      marketIndex,
+			IsAsk:            isAsk,
+			Price:            price,
+			BaseAmount:       remaining,
+			OrderExpiry:      o.OrderExpiry,
+		})
+	}
+	return res, nil
+}
+
+// GetMarketRules returns the order constraints of marketIndex in protocol units.
+func (c *HTTPClient) GetMarketRules(marketIndex uint8) (*types.MarketRules, error) {
+	market, err := c.marketDetail(marketIndex)
+	if err != nil {
+		return nil, err
+	}
+	// A market quoting fewer digits than its units hold only accepts multiples of the dropped digits.
+	step := func(supported *uint8, decimals uint8) (int64, error) {
+		if supported == nil {
+			return 1, nil
+		}
+		if *supported > decimals {
+			return 0, fmt.Errorf("market %d supports %d decimals out of %d", marketIndex, *supported, decimals)
+		}
+		s := int64(1)
+		for i := *supported; i < decimals; i++ {
+			s *= 10
+		}
+		return s, nil
+	}
+
+	rules := &types.MarketRules{
+		MarketIndex:   marketIndex,
+		SizeDecimals:  market.SizeDecimals,
+		PriceDecimals: market.PriceDecimals,
+		HasDecimals:   true,
+	}
+	if rules.BaseStep, err = step(market.SupportedSizeDecimals, market.SizeDecimals); err != nil {
+		return nil, err
+	}
+	priceStep, err := step(market.SupportedPriceDecimals, market.PriceDecimals)
+	if err != nil {
+		return nil, err
+	}
+	rules.PriceStep = uint32(priceStep)
+	if market.MinBaseAmount != "" {
+		if rules.MinBaseAmount, err = types.ParseDecimalUnits(market.MinBaseAmount, market.SizeDecimals, txtypes.MaxOrderBaseAmount); err != nil {
+			return nil, fmt.Errorf("min base amount: %w", err)
+		}
+	}
+	if market.MinQuoteAmount != "" {
+		if rules.MinQuoteAmount, err = types.ParseDecimalUnits(market.MinQuoteAmount, market.SizeDecimals+market.PriceDecimals, math.MaxInt64); err != nil {
+			return nil, fmt.Errorf("min quote amount: %w", err)
+		}
+	}
+	return rules, nil
+}
+
+func (c *HTTPClient) marketDetail(marketIndex uint8) (*OrderBookDetail, error) {
+	details, err := c.GetOrderBookDetails(marketIndex)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range details.OrderBookDetails {
+		if d.MarketId == marketIndex {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("market %d not found", marketIndex)
+}
@@ -14,6 +14,14 @@ type NextNonce struct {
 	Nonce int64 `json:"nonce,example=722"`
 }
 
+// ExchangeStatus is what the exchange reports on its root endpoint. Status is 200 while it accepts txs.
+type ExchangeStatus struct {
+	Status    int    `json:"status"`
+	NetworkId int    `json:"network_id"`
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message,omitempty"`
+}
+
 type ApiKey struct {
 	AccountIndex int64  `json:"account_index,example=3"`
 	ApiKeyIndex  uint8  `json:"api_key_index,example=0"`
@@ -31,7 +39,83 @@ type TxHash struct {
 	TxHash string `json:"tx_hash,example=0x70997970C51812dc3A010C7d01b50e0d17dc79C8"`
 }
 
+type TxHashes struct {
+	ResultCode
+	TxHash []string `json:"tx_hash"`
+}
+
 type TransferFeeInfo struct {
 	ResultCode
 	TransferFee int64 `json:"transfer_fee_usdc"`
 }
+
+type OrderBookDetail struct {
+	MarketId      uint8 `json:"market_id,example=0"`
+	PriceDecimals uint8 `json:"price_decimals,example=2"`
+	SizeDecimals  uint8 `json:"size_decimals,example=4"`
+	// SupportedPriceDecimals and SupportedSizeDecimals are the digits orders may use, at most PriceDecimals and
+	// SizeDecimals. All digits are supported when they are missing.
+	SupportedPriceDecimals *uint8 `json:"supported_price_decimals,example=2"`
+	SupportedSizeDecimals  *uint8 `json:"supported_size_decimals,example=4"`
+	MinBaseAmount          string `json:"min_base_amount,example=0.0050"`
+	MinQuoteAmount         string `json:"min_quote_amount,example=10.000000"`
+}
+
+type OrderBookDetails struct {
+	ResultCode
+	OrderBookDetails []*OrderBookDetail `json:"order_book_details"`
+}
+
+type OrderBookOrder struct {
+	Price               string `json:"price,example=3024.66"`
+	RemainingBaseAmount string `json:"remaining_base_amount,example=1.5"`
+}
+
+type OrderBookOrders struct {
+	ResultCode
+	Asks []*OrderBookOrder `json:"asks"`
+	Bids []*OrderBookOrder `json:"bids"`
+}
+
+type AccountPosition struct {
+	MarketId uint8 `json:"market_id,example=1"`
+	// Sign is 1 for a long position, -1 for a short one.
+	Sign     int8   `json:"sign,example=1"`
+	Position string `json:"position,example=3.6956"`
+}
+
+type DetailedAccount struct {
+	Index     int64              `json:"index,example=1"`
+	Positions []*AccountPosition `json:"positions"`
+}
+
+type DetailedAccounts struct {
+	ResultCode
+	Accounts []*DetailedAccount `json:"accounts"`
+}
+
+type Order struct {
+	OrderIndex          int64  `json:"order_index,example=281476929510616"`
+	ClientOrderIndex    int64  `json:"client_order_index,example=1"`
+	MarketIndex         uint8  `json:"market_index,example=1"`
+	IsAsk               bool   `json:"is_ask"`
+	Price               string `json:"price,example=3024.66"`
+	RemainingBaseAmount string `json:"remaining_base_amount,example=1.5"`
+	OrderExpiry         int64  `json:"order_expiry,example=1700000000000"`
+}
+
+type Orders struct {
+	ResultCode
+	Orders []*Order `json:"orders"`
+}
+
+// OpenOrder is a resting Order converted to protocol units.
+type OpenOrder struct {
+	OrderIndex       int64
+	ClientOrderIndex int64
+	MarketIndex      uint8
+	IsAsk            uint8
+	Price            uint32
+	BaseAmount       int64
+	OrderExpiry      int64
+}
@@ -1,53 +1,85 @@
 package client
 
 import (
-	"encoding/hex"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/elliottech/lighter-go/signer"
 	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
 )
 
 const (
-	defaultExpireTime = time.Minute*10 - time.Second // we need to give a second margin, to eliminate millisecond differences
+	MaxDefaultExpireTime = time.Minute*10 - time.Second // we need to give a second margin, to eliminate millisecond differences
 )
 
+// defaultExpireTime is how long txs signed without an explicit ExpiredAt stay valid, see SetDefaultExpireTime.
+var defaultExpireTime atomic.Int64
+
+func init() {
+	defaultExpireTime.Store(int64(MaxDefaultExpireTime))
+}
+
+// SetDefaultExpireTime sets how long txs signed without an explicit ExpiredAt stay valid, at most
+// MaxDefaultExpireTime.
+func SetDefaultExpireTime(d time.Duration) error {
+	if d < time.Second || d > MaxDefaultExpireTime {
+		return fmt.Errorf("default expire time should be between 1s and %s", MaxDefaultExpireTime)
+	}
+	defaultExpireTime.Store(int64(d))
+	return nil
+}
+
+// DefaultExpireTime returns how long txs signed without an explicit ExpiredAt stay valid.
+func DefaultExpireTime() time.Duration {
+	return time.Duration(defaultExpireTime.Load())
+}
+
+// TxCheck inspects a transaction before it is signed; returning an error aborts the signing.
+type TxCheck func(tx txtypes.TxInfo) error
+
+// namedTxCheck is a check installed by AddTxCheck, or by AddNamedTxCheck along with the name WithTxCheck
+// replaces it by.
+type namedTxCheck struct {
+	name  string
+	check TxCheck
+}
+
 type TxClient struct {
 	apiClient    *HTTPClient
 	chainId      uint32
 	keyManager   signer.KeyManager
 	accountIndex int64
 	apiKeyIndex  uint8
+	txCheck      TxCheck
+	extraChecks  []namedTxCheck
+	onRejected   func(tx txtypes.TxInfo, err error)
+	subAccounts  map[int64]bool
+	clock        func() time.Time
 }
 
 // NewTxClient is linked to a specific (account, apiKey) pair
 // apiKeyPrivateKey should be hex-encoded bytes generated using `hexutil.Encode(TxClient.GetKeyManager().PrvKeyBytes())`
 func NewTxClient(apiClient *HTTPClient, apiKeyPrivateKey string, accountIndex int64, apiKeyIndex uint8, chainId uint32) (*TxClient, error) {
-	// remove 0x from private key, if any, and parse to bytes
-	if len(apiKeyPrivateKey) < 2 {
-		return nil, fmt.Errorf("empty private key")
-	}
-	if apiKeyPrivateKey[:2] == "0x" {
-		apiKeyPrivateKey = apiKeyPrivateKey[2:]
-	}
-	b, err := hex.DecodeString(apiKeyPrivateKey)
+	keyManager, err := signer.NewKeyManagerFromHex(apiKeyPrivateKey)
 	if err != nil {
 		return nil, err
 	}
 
-	keyManager, err := signer.NewKeyManager(b)
-	if err != nil {
-		return nil, err
-	}
+	return NewTxClientWithKeyManager(apiClient, keyManager, accountIndex, apiKeyIndex, chainId), nil
+}
 
+// NewTxClientWithKeyManager is like NewTxClient, for keys which are not available as raw bytes,
+// e.g. when signing is delegated to an external signer.
+func NewTxClientWithKeyManager(apiClient *HTTPClient, keyManager signer.KeyManager, accountIndex int64, apiKeyIndex uint8, chainId uint32) *TxClient {
 	return &TxClient{
 		apiClient:    apiClient,
 		apiKeyIndex:  apiKeyIndex,
 		accountIndex: accountIndex,
 		chainId:      chainId,
 		keyManager:   keyManager,
-	}, nil
+	}
 }
 
 func (c *TxClient) FullFillDefaultOps(ops *types.TransactOpts) (*types.TransactOpts, error) {
@@ -55,7 +87,7 @@ func (c *TxClient) FullFillDefaultOps(ops *types.TransactOpts) (*types.TransactO
 		ops = new(types.TransactOpts)
 	}
 	if ops.ExpiredAt == 0 {
-		ops.ExpiredAt = time.Now().Add(defaultExpireTime).UnixMilli()
+		ops.ExpiredAt = c.Now().Add(DefaultExpireTime()).UnixMilli()
 	}
 	if ops.FromAccountIndex == nil {
 		ops.FromAccountIndex = &c.accountIndex
@@ -85,6 +117,10 @@ func (c *TxClient) GetApiKeyIndex() uint8 {
 	return c.apiKeyIndex
 }
 
+func (c *TxClient) GetChainId() uint32 {
+	return c.chainId
+}
+
 func (c *TxClient) GetKeyManager() signer.KeyManager {
 	return c.keyManager
 }
@@ -100,6 +136,18 @@ func (c *TxClient) GetAuthToken(deadline time.Time) (string, error) {
 	})
 }
 
+// GetScopedAuthToken is GetAuthToken binding the token to the origin and session id of opts.
+func (c *TxClient) GetScopedAuthToken(deadline time.Time, opts *types.AuthTokenOptions) (string, error) {
+	if time.Until(deadline) > (7 * time.Hour) {
+		return "", fmt.Errorf("deadline should be within 7 hours")
+	}
+
+	return types.ConstructScopedAuthToken(c.keyManager, deadline, &types.TransactOpts{
+		ApiKeyIndex:      &c.apiKeyIndex,
+		FromAccountIndex: &c.accountIndex,
+	}, opts)
+}
+
 func (c *TxClient) HTTP() *HTTPClient {
 	return c.apiClient
 }
@@ -107,3 +155,127 @@ func (c *TxClient) HTTP() *HTTPClient {
 func (c *TxClient) SwitchAPIKey(apiKey uint8) {
 	c.apiKeyIndex = apiKey
 }
+
+// SetSubAccounts records the sub-accounts controlled by the client's master account.
+func (c *TxClient) SetSubAccounts(accountIndexes []int64) {
+	c.subAccounts = make(map[int64]bool, len(accountIndexes))
+	for _, accountIndex := range accountIndexes {
+		c.subAccounts[accountIndex] = true
+	}
+}
+
+func (c *TxClient) IsSubAccount(accountIndex int64) bool {
+	return c.subAccounts[accountIndex]
+}
+
+// ControlsAccount reports whether the client may sign for accountIndex, i.e. it is the client's own account
+// or one of its sub-accounts.
+func (c *TxClient) ControlsAccount(accountIndex int64) bool {
+	return accountIndex == c.accountIndex || c.IsSubAccount(accountIndex)
+}
+
+// SetTxCheck installs a check run against every transaction before it is signed. Passing nil removes it.
+func (c *TxClient) SetTxCheck(check TxCheck) {
+	c.txCheck = check
+}
+
+// AddTxCheck installs a check run after the one set by SetTxCheck. Clients made from c afterwards keep it.
+func (c *TxClient) AddTxCheck(check TxCheck) {
+	c.AddNamedTxCheck("", check)
+}
+
+// AddNamedTxCheck is AddTxCheck for a check which copies of the client made by WithTxCheck may replace.
+func (c *TxClient) AddNamedTxCheck(name string, check TxCheck) {
+	c.extraChecks = append(c.extraChecks[:len(c.extraChecks):len(c.extraChecks)], namedTxCheck{name, check})
+}
+
+// WithTxCheck returns a copy of the client in which check replaces the checks installed under name, or is added
+// when there are none, e.g. to relax or tighten them for one call. A nil check removes them. c itself is left as
+// is.
+func (c *TxClient) WithTxCheck(name string, check TxCheck) *TxClient {
+	clone := *c
+	clone.extraChecks = make([]namedTxCheck, 0, len(c.extraChecks))
+	replaced := false
+	for _, named := range c.extraChecks {
+		if name == "" || named.name != name {
+			clone.extraChecks = append(clone.extraChecks, named)
+			continue
+		}
+		if check != nil && !replaced {
+			clone.extraChecks = append(clone.extraChecks, namedTxCheck{name, check})
+		}
+		replaced = true
+	}
+	if check != nil && !replaced {
+		clone.extraChecks = append(clone.extraChecks, namedTxCheck{name, check})
+	}
+	return &clone
+}
+
+// OnRejected installs a func receiving every tx refused by the client's checks, with the reason. Dry runs are not
+// reported. Clients made from c afterwards keep it.
+func (c *TxClient) OnRejected(observe func(tx txtypes.TxInfo, err error)) {
+	c.onRejected = observe
+}
+
+func (c *TxClient) checkTx(tx txtypes.TxInfo) error {
+	err := c.runChecks(tx)
+	if err != nil && c.onRejected != nil && !types.IsDryRun(tx) {
+		c.onRejected(tx, err)
+	}
+	return err
+}
+
+func (c *TxClient) runChecks(tx txtypes.TxInfo) error {
+	if c.txCheck != nil {
+		if err := c.txCheck(tx); err != nil {
+			return err
+		}
+	}
+	for _, named := range c.extraChecks {
+		if err := named.check(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithKeyManager returns a copy of the client which signs with keyManager instead of the current key.
+func (c *TxClient) WithKeyManager(keyManager signer.KeyManager) *TxClient {
+	clone := *c
+	clone.keyManager = keyManager
+	return &clone
+}
+
+// WithClock returns a copy of the client reading the time from now instead of Now, e.g. to compute the default
+// expiries of a call at the instant it was recorded.
+func (c *TxClient) WithClock(now func() time.Time) *TxClient {
+	clone := *c
+	clone.clock = now
+	return &clone
+}
+
+// Now returns the time the client computes default expiries from: its clock, see WithClock, or Now.
+func (c *TxClient) Now() time.Time {
+	if c.clock != nil {
+		return c.clock()
+	}
+	return Now()
+}
+
+// WithSubAccounts returns a copy of the client, sharing its key, whose sub-accounts are accountIndexes instead of
+// the client's. They are not checked against the exchange.
+func (c *TxClient) WithSubAccounts(accountIndexes []int64) *TxClient {
+	clone := *c
+	clone.SetSubAccounts(accountIndexes)
+	return &clone
+}
+
+// WithAccountIndex returns a copy of the client, sharing its key, which signs for accountIndex. The clone keeps
+// the client's tx check but not its sub-accounts.
+func (c *TxClient) WithAccountIndex(accountIndex int64) *TxClient {
+	clone := *c
+	clone.accountIndex = accountIndex
+	clone.subAccounts = nil
+	return &clone
+}
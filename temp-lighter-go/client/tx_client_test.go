@@ -0,0 +1,177 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/elliottech/lighter-go/signer"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+const (
+	testChainId      = 304
+	testAccountIndex = 42
+	testApiKeyIndex  = 3
+)
+
+// signBatch signs a batch of orders, a cancel and a transfer with c, from nonce on, and checks every signature
+// against the client's public key.
+func signBatch(t *testing.T, c *TxClient, nonce int64) {
+	pubKey := c.GetKeyManager().PubKeyBytes()
+	var batch []txtypes.TxInfo
+	for i := int64(0); i < 4; i++ {
+		n := nonce + i
+		tx, err := c.GetCreateOrderTransaction(&types.CreateOrderTxReq{
+			MarketIndex:      1,
+			ClientOrderIndex: n,
+			BaseAmount:       1000 + n,
+			Price:            uint32(2000 + n),
+			Type:             txtypes.LimitOrder,
+			TimeInForce:      txtypes.ImmediateOrCancel,
+			OrderExpiry:      txtypes.NilOrderExpiry,
+		}, &types.TransactOpts{Nonce: &n})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		batch = append(batch, tx)
+	}
+	n := nonce + 4
+	cancel, err := c.GetCancelOrderTransaction(&types.CancelOrderTxReq{MarketIndex: 1, Index: nonce}, &types.TransactOpts{Nonce: &n})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	n++
+	transfer, err := c.GetTransferTransaction(&types.TransferTxReq{ToAccountIndex: testAccountIndex + 1, USDCAmount: n}, &types.TransactOpts{Nonce: &n})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	batch = append(batch, cancel, transfer)
+
+	for _, tx := range batch {
+		if err := types.VerifyTxSignature(tx, c.GetChainId(), pubKey); err != nil {
+			t.Errorf("tx %d of batch %d: %v", tx.GetTxType(), nonce, err)
+		}
+	}
+}
+
+// TestConcurrentBatchSigning signs batches from many goroutines sharing one client, the way the wasm module signs
+// from promise bodies. Run it with -race.
+func TestConcurrentBatchSigning(t *testing.T) {
+	km, err := signer.GenerateKeyManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lockable, err := signer.NewLockableKeyManager(km, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lockable.Unlock("passphrase", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, keyManager := range map[string]signer.KeyManager{"key": km, "lockable key": lockable} {
+		t.Run(name, func(t *testing.T) {
+			c := NewTxClientWithKeyManager(nil, keyManager, testAccountIndex, testApiKeyIndex, testChainId)
+			var checked atomic.Int64
+			c.SetTxCheck(func(tx txtypes.TxInfo) error {
+				checked.Add(1)
+				return nil
+			})
+
+			const goroutines, batches = 4, 5
+			var wg sync.WaitGroup
+			for g := 0; g < goroutines; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					for b := 0; b < batches; b++ {
+						signBatch(t, c, int64((g*batches+b)*6+1))
+					}
+				}(g)
+			}
+			wg.Wait()
+
+			if want := int64(goroutines * batches * 6); checked.Load() != want {
+				t.Errorf("%d txs were checked, expected %d", checked.Load(), want)
+			}
+		})
+	}
+}
+
+func TestWithTxCheck(t *testing.T) {
+	km, err := signer.GenerateKeyManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ran []string
+	check := func(name string, refuse bool) TxCheck {
+		return func(tx txtypes.TxInfo) error {
+			ran = append(ran, name)
+			if refuse {
+				return fmt.Errorf("%s refused", name)
+			}
+			return nil
+		}
+	}
+	c := NewTxClientWithKeyManager(nil, km, testAccountIndex, testApiKeyIndex, testChainId)
+	c.AddTxCheck(check("first", false))
+	c.AddNamedTxCheck("named", check("named", true))
+	c.AddTxCheck(check("last", false))
+
+	nonce := int64(1)
+	sign := func(c *TxClient) error {
+		_, err := c.GetCancelOrderTransaction(&types.CancelOrderTxReq{MarketIndex: 1, Index: 77}, &types.TransactOpts{Nonce: &nonce})
+		return err
+	}
+	for _, tc := range []struct {
+		name   string
+		client *TxClient
+		ran    string
+		ok     bool
+	}{
+		{"original", c, "first named", false},
+		{"removed", c.WithTxCheck("named", nil), "first last", true},
+		{"replaced", c.WithTxCheck("named", check("replacement", false)), "first replacement last", true},
+		{"added", c.WithTxCheck("other", check("other", false)), "first named", false},
+		{"original after copies", c, "first named", false},
+	} {
+		ran = nil
+		err := sign(tc.client)
+		if (err == nil) != tc.ok {
+			t.Errorf("%s: got error %v", tc.name, err)
+		}
+		if got := strings.Join(ran, " "); got != tc.ran {
+			t.Errorf("%s: ran %q, expected %q", tc.name, got, tc.ran)
+		}
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	km, err := signer.GenerateKeyManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewTxClientWithKeyManager(nil, km, testAccountIndex, testApiKeyIndex, testChainId)
+	at := time.UnixMilli(1_700_000_000_000)
+	frozen := c.WithClock(func() time.Time { return at })
+
+	nonce := int64(1)
+	tx, err := frozen.GetCancelOrderTransaction(&types.CancelOrderTxReq{MarketIndex: 1, Index: 77}, &types.TransactOpts{Nonce: &nonce})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := at.Add(DefaultExpireTime()).UnixMilli(); tx.ExpiredAt != want {
+		t.Errorf("expiredAt is %d, expected %d", tx.ExpiredAt, want)
+	}
+	if !c.Now().After(at) {
+		t.Error("the client WithClock was called on reads the clock of the copy")
+	}
+}
@@ -13,6 +13,9 @@ func (c *TxClient) GetChangePubKeyTransaction(tx *types.ChangePubKeyReq, ops *ty
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkTx(types.ConvertChangePubKeyTx(tx, ops)); err != nil {
+		return nil, err
+	}
 	txInfo, err := types.ConstructChangePubKeyTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
@@ -33,6 +36,9 @@ func (c *TxClient) GetCreateSubAccountTransaction(ops *types.TransactOpts) (*txt
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkTx(types.ConvertCreateSubAccountTx(ops)); err != nil {
+		return nil, err
+	}
 	txInfo, err := types.ConstructCreateSubAccountTx(c.keyManager, c.chainId, ops)
 	if err != nil {
 		return nil, err
@@ -45,6 +51,9 @@ func (c *TxClient) GetCreatePublicPoolTransaction(tx *types.CreatePublicPoolTxRe
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkTx(types.ConvertCreatePublicPoolTx(tx, ops)); err != nil {
+		return nil, err
+	}
 	txInfo, err := types.ConstructCreatePublicPoolTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
@@ -57,6 +66,9 @@ func (c *TxClient) GetUpdatePublicPoolTransaction(tx *types.UpdatePublicPoolTxRe
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkTx(types.ConvertUpdatePublicPoolTx(tx, ops)); err != nil {
+		return nil, err
+	}
 	txInfo, err := types.ConstructUpdatePublicPoolTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
@@ -69,6 +81,9 @@ func (c *TxClient) GetTransferTransaction(tx *types.TransferTxReq, ops *types.Tr
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkTx(types.ConvertTransferTx(tx, ops)); err != nil {
+		return nil, err
+	}
 	txInfo, err := types.ConstructTransferTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
@@ -81,6 +96,9 @@ func (c *TxClient) GetWithdrawTransaction(tx *types.WithdrawTxReq, ops *types.Tr
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkTx(types.ConvertWithdrawTx(tx, ops)); err != nil {
+		return nil, err
+	}
 	txInfo, err := types.ConstructWithdrawTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
@@ -94,6 +112,9 @@ func (c *TxClient) GetCreateOrderTransaction(tx *types.CreateOrderTxReq, ops *ty
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkTx(types.ConvertCreateOrderTx(tx, ops)); err != nil {
+		return nil, err
+	}
 	txInfo, err := types.ConstructCreateOrderTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
@@ -106,6 +127,9 @@ func (c *TxClient) GetCancelOrderTransaction(tx *types.CancelOrderTxReq, ops *ty
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkTx(types.ConvertCancelOrderTx(tx, ops)); err != nil {
+		return nil, err
+	}
 	txInfo, err := types.ConstructL2CancelOrderTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
@@ -118,6 +142,9 @@ func (c *TxClient) GetModifyOrderTransaction(tx *types.ModifyOrderTxReq, ops *ty
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkTx(types.ConvertModifyOrderTx(tx, ops)); err != nil {
+		return nil, err
+	}
 
 	txInfo, err := types.ConstructL2ModifyOrderTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
@@ -132,6 +159,9 @@ func (c *TxClient) GetCancelAllOrdersTransaction(tx *types.CancelAllOrdersTxReq,
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkTx(types.ConvertCancelAllOrdersTx(tx, ops)); err != nil {
+		return nil, err
+	}
 	txInfo, err := types.ConstructL2CancelAllOrdersTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
@@ -144,6 +174,9 @@ func (c *TxClient) GetMintSharesTransaction(tx *types.MintSharesTxReq, ops *type
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkTx(types.ConvertMintSharesTx(tx, ops)); err != nil {
+		return nil, err
+	}
 	txInfo, err := types.ConstructMintSharesTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
@@ -156,6 +189,9 @@ func (c *TxClient) GetBurnSharesTransaction(tx *types.BurnSharesTxReq, ops *type
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkTx(types.ConvertBurnSharesTx(tx, ops)); err != nil {
+		return nil, err
+	}
 	txInfo, err := types.ConstructBurnSharesTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
@@ -168,6 +204,9 @@ func (c *TxClient) GetUpdateLeverageTransaction(tx *types.UpdateLeverageTxReq, o
 	if err != nil {
 		return nil, err
 	}
+	if err := c.checkTx(types.ConvertUpdateLeverageTx(tx, ops)); err != nil {
+		return nil, err
+	}
 	txInfo, err := types.ConstructUpdateLeverageTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
@@ -184,6 +223,10 @@ func (c *TxClient) GetUpdateMarginTransaction(tx *types.UpdateMarginTxReq, ops *
 		ops = new(types.TransactOpts)
 	}
 
+	if err := c.checkTx(types.ConvertUpdateMarginTx(tx, ops)); err != nil {
+		return nil, err
+	}
+
 	txInfo, err := types.ConstructUpdateMarginTx(c.keyManager, c.chainId, tx, ops)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/elliottech/lighter-go/signer"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+	p2 "github.com/elliottech/poseidon_crypto/hash/poseidon2_goldilocks"
+	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+)
+
+// PrepareTx runs the client's checks and the tx validation against an unsigned tx built by one of the
+// types.Convert functions, and returns the hash to be signed. Nothing is signed.
+func (c *TxClient) PrepareTx(tx txtypes.TxInfo) ([]byte, error) {
+	if err := c.checkTx(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Validate(); err != nil {
+		return nil, err
+	}
+	return tx.Hash(c.chainId)
+}
+
+// SimulateTx is PrepareTx for a tx which is not going to be signed: the checks run without their side effects,
+// see types.BeginDryRun.
+func (c *TxClient) SimulateTx(tx txtypes.TxInfo) ([]byte, error) {
+	defer types.BeginDryRun(tx)()
+	return c.PrepareTx(tx)
+}
+
+// FinalizeTx attaches sig to a tx prepared with PrepareTx, after checking it was produced by the client's key.
+// sig is normalized to its canonical encoding first.
+func (c *TxClient) FinalizeTx(tx txtypes.TxInfo, msgHash, sig []byte) error {
+	sig, err := signer.NormalizeSignature(sig)
+	if err != nil {
+		return err
+	}
+	pk := c.keyManager.PubKeyBytes()
+	if err := schnorr.Validate(pk[:], msgHash, sig); err != nil {
+		return fmt.Errorf("failed to validate signature. error: %v", err)
+	}
+	return types.AttachSignature(tx, msgHash, sig)
+}
+
+// SignPreparedTx signs a tx prepared with PrepareTx with the client's key.
+func (c *TxClient) SignPreparedTx(tx txtypes.TxInfo, msgHash []byte) error {
+	sig, err := c.keyManager.Sign(msgHash, p2.NewPoseidon2())
+	if err != nil {
+		return err
+	}
+	return types.AttachSignature(tx, msgHash, sig)
+}
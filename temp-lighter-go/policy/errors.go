@@ -0,0 +1,17 @@
+package policy
+
+import "fmt"
+
+var (
+	ErrOutsideTimeWindow      = fmt.Errorf("policy: transaction is outside of the allowed time window")
+	ErrMarketNotAllowed       = fmt.Errorf("policy: market is not allowed")
+	ErrOrderNotionalTooHigh   = fmt.Errorf("policy: order notional exceeds the allowed maximum")
+	ErrTransferNotAllowed     = fmt.Errorf("policy: transfers are not allowed")
+	ErrWithdrawalNotAllowed   = fmt.Errorf("policy: withdrawals are not allowed")
+	ErrTxTypeNotAllowed       = fmt.Errorf("policy: transaction type is not allowed")
+	ErrDestinationNotAllowed  = fmt.Errorf("policy: destination account is not allowed")
+	ErrDestinationNotInBook   = fmt.Errorf("policy: destination account is not in the address book")
+	ErrOrderRateExceeded      = fmt.Errorf("policy: too many orders signed within the last minute")
+	ErrHourlyNotionalExceeded = fmt.Errorf("policy: notional signed within the last hour exceeds the allowed maximum")
+	ErrDailyNotionalExceeded  = fmt.Errorf("policy: notional signed within the last day exceeds the allowed maximum")
+)
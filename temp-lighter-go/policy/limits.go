@@ -0,0 +1,130 @@
+package policy
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// Windows of the rolling limits.
+const (
+	OrderWindow          = time.Minute
+	HourlyNotionalWindow = time.Hour
+	DailyNotionalWindow  = 24 * time.Hour
+)
+
+// signedOrder is an order counted against the rolling limits.
+type signedOrder struct {
+	at       time.Time
+	notional *big.Int
+}
+
+// usage holds the orders signed under a policy within DailyNotionalWindow.
+type usage struct {
+	mu     sync.Mutex
+	orders []signedOrder
+}
+
+// Usage is how much of its rolling limits a policy has consumed at a given time.
+type Usage struct {
+	OrdersLastMinute   int64
+	NotionalLastHour   *big.Int
+	NotionalLastDay    *big.Int
+	MaxOrdersPerMinute int64
+	MaxNotionalPerHour int64
+	MaxNotionalPerDay  int64
+}
+
+func (p *Policy) hasLimits() bool {
+	return p.MaxOrdersPerMinute > 0 || p.MaxNotionalPerHour > 0 || p.MaxNotionalPerDay > 0
+}
+
+// orderNotionals returns the notional of every order tx places or modifies, or nil for other txs.
+func orderNotionals(tx txtypes.TxInfo) []*big.Int {
+	switch tx := tx.(type) {
+	case *txtypes.L2CreateOrderTxInfo:
+		return []*big.Int{Notional(tx.BaseAmount, tx.Price)}
+	case *txtypes.L2CreateGroupedOrdersTxInfo:
+		res := make([]*big.Int, 0, len(tx.Orders))
+		for _, order := range tx.Orders {
+			res = append(res, Notional(order.BaseAmount, order.Price))
+		}
+		return res
+	case *txtypes.L2ModifyOrderTxInfo:
+		return []*big.Int{Notional(tx.BaseAmount, tx.Price)}
+	}
+	return nil
+}
+
+// usageLocked drops the orders older than DailyNotionalWindow and sums the remaining ones by window.
+func (p *Policy) usageLocked(now time.Time) Usage {
+	res := Usage{
+		NotionalLastHour:   new(big.Int),
+		NotionalLastDay:    new(big.Int),
+		MaxOrdersPerMinute: p.MaxOrdersPerMinute,
+		MaxNotionalPerHour: p.MaxNotionalPerHour,
+		MaxNotionalPerDay:  p.MaxNotionalPerDay,
+	}
+	kept := p.usage.orders[:0]
+	for _, o := range p.usage.orders {
+		age := now.Sub(o.at)
+		if age >= DailyNotionalWindow {
+			continue
+		}
+		kept = append(kept, o)
+		res.NotionalLastDay.Add(res.NotionalLastDay, o.notional)
+		if age < HourlyNotionalWindow {
+			res.NotionalLastHour.Add(res.NotionalLastHour, o.notional)
+		}
+		if age < OrderWindow {
+			res.OrdersLastMinute++
+		}
+	}
+	p.usage.orders = kept
+	return res
+}
+
+// Usage returns the part of the rolling limits consumed at now.
+func (p *Policy) Usage(now time.Time) Usage {
+	p.usage.mu.Lock()
+	defer p.usage.mu.Unlock()
+	return p.usageLocked(now)
+}
+
+// checkLimits refuses tx when its orders would exceed a rolling limit, and counts them otherwise when count is
+// set. Orders are counted as soon as they pass, even if signing fails afterwards, so the limits err on the side
+// of caution.
+func (p *Policy) checkLimits(tx txtypes.TxInfo, now time.Time, count bool) error {
+	notionals := orderNotionals(tx)
+	if len(notionals) == 0 || !p.hasLimits() {
+		return nil
+	}
+
+	p.usage.mu.Lock()
+	defer p.usage.mu.Unlock()
+	u := p.usageLocked(now)
+
+	added := new(big.Int)
+	for _, n := range notionals {
+		added.Add(added, n)
+	}
+	if p.MaxOrdersPerMinute > 0 && u.OrdersLastMinute+int64(len(notionals)) > p.MaxOrdersPerMinute {
+		return ErrOrderRateExceeded
+	}
+	if p.MaxNotionalPerHour > 0 && new(big.Int).Add(u.NotionalLastHour, added).Cmp(big.NewInt(p.MaxNotionalPerHour)) > 0 {
+		return ErrHourlyNotionalExceeded
+	}
+	if p.MaxNotionalPerDay > 0 && new(big.Int).Add(u.NotionalLastDay, added).Cmp(big.NewInt(p.MaxNotionalPerDay)) > 0 {
+		return ErrDailyNotionalExceeded
+	}
+
+	if !count {
+		return nil
+	}
+	for _, n := range notionals {
+		p.usage.orders = append(p.usage.orders, signedOrder{at: now, notional: n})
+	}
+	return nil
+}
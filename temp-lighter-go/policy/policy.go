@@ -0,0 +1,146 @@
+package policy
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// Policy restricts which transactions a client may sign. The zero value allows orders, cancels, leverage and
+// margin changes; transfers and withdrawals have to be enabled explicitly, and any other tx type with
+// AllowAccountTxs.
+type Policy struct {
+	// NotBefore and NotAfter bound, in unix seconds, when signing is allowed. Zero leaves the bound open.
+	NotBefore int64 `json:"notBefore"`
+	NotAfter  int64 `json:"notAfter"`
+
+	// Markets lists the market indexes orders, cancels and margin changes may target. Empty allows all markets.
+	Markets []int `json:"markets"`
+
+	// MaxOrderNotional caps BaseAmount * Price of a single order, in the market's integer units. Zero disables it.
+	MaxOrderNotional int64 `json:"maxOrderNotional"`
+
+	AllowTransfers   bool `json:"allowTransfers"`
+	AllowWithdrawals bool `json:"allowWithdrawals"`
+
+	// AllowAccountTxs allows the txs managing the account rather than trading on it: api key changes, sub-accounts,
+	// public pools and shares. It is meant for the restrictions a client is created with, never for session keys,
+	// and is not serialized, so a policy decoded from JSON always refuses them.
+	AllowAccountTxs bool `json:"-"`
+
+	// Destinations lists the accounts transfers may send to. Empty allows all accounts. Withdrawals always go to
+	// the L1 address owning the withdrawing account, so they are not restricted by it.
+	Destinations []int64 `json:"destinations"`
+
+	// AddressBookOnly restricts transfers to the accounts InAddressBook reports, refusing them all while it is
+	// nil. InAddressBook is provided by the caller and is not serialized.
+	AddressBookOnly bool                          `json:"addressBookOnly"`
+	InAddressBook   func(accountIndex int64) bool `json:"-"`
+
+	// MaxOrdersPerMinute caps the orders signed within OrderWindow. MaxNotionalPerHour and MaxNotionalPerDay cap
+	// the summed notional of the orders signed within HourlyNotionalWindow and DailyNotionalWindow, in the same
+	// units as MaxOrderNotional. Modified orders count as new ones. Zero disables a limit.
+	MaxOrdersPerMinute int64 `json:"maxOrdersPerMinute"`
+	MaxNotionalPerHour int64 `json:"maxNotionalPerHour"`
+	MaxNotionalPerDay  int64 `json:"maxNotionalPerDay"`
+
+	usage usage
+}
+
+// Check returns the first rule tx violates, or nil. It has the signature of client.TxCheck. Txs checked for a
+// simulation are not counted against the rolling limits.
+func (p *Policy) Check(tx txtypes.TxInfo) error {
+	now := time.Now()
+	if err := p.checkTx(tx, now.Unix()); err != nil {
+		return err
+	}
+	return p.checkLimits(tx, now, !types.IsDryRun(tx))
+}
+
+func (p *Policy) checkTx(tx txtypes.TxInfo, now int64) error {
+	if p.NotBefore != 0 && now < p.NotBefore {
+		return ErrOutsideTimeWindow
+	}
+	if p.NotAfter != 0 && now > p.NotAfter {
+		return ErrOutsideTimeWindow
+	}
+
+	switch tx := tx.(type) {
+	case *txtypes.L2TransferTxInfo:
+		if !p.AllowTransfers {
+			return ErrTransferNotAllowed
+		}
+		return p.checkDestination(tx.ToAccountIndex)
+	case *txtypes.L2WithdrawTxInfo:
+		if !p.AllowWithdrawals {
+			return ErrWithdrawalNotAllowed
+		}
+	case *txtypes.L2CreateOrderTxInfo:
+		return p.checkOrder(tx.MarketIndex, tx.BaseAmount, tx.Price)
+	case *txtypes.L2CreateGroupedOrdersTxInfo:
+		for _, order := range tx.Orders {
+			if err := p.checkOrder(order.MarketIndex, order.BaseAmount, order.Price); err != nil {
+				return err
+			}
+		}
+	case *txtypes.L2ModifyOrderTxInfo:
+		return p.checkOrder(tx.MarketIndex, tx.BaseAmount, tx.Price)
+	case *txtypes.L2CancelOrderTxInfo:
+		return p.checkMarket(tx.MarketIndex)
+	case *txtypes.L2CancelAllOrdersTxInfo:
+	case *txtypes.L2UpdateLeverageTxInfo:
+		return p.checkMarket(tx.MarketIndex)
+	case *txtypes.L2UpdateMarginTxInfo:
+		return p.checkMarket(tx.MarketIndex)
+	default:
+		if !p.AllowAccountTxs {
+			return ErrTxTypeNotAllowed
+		}
+	}
+
+	return nil
+}
+
+func (p *Policy) checkMarket(marketIndex uint8) error {
+	if len(p.Markets) == 0 {
+		return nil
+	}
+	for _, m := range p.Markets {
+		if m == int(marketIndex) {
+			return nil
+		}
+	}
+	return ErrMarketNotAllowed
+}
+
+func (p *Policy) checkDestination(accountIndex int64) error {
+	if p.AddressBookOnly && (p.InAddressBook == nil || !p.InAddressBook(accountIndex)) {
+		return ErrDestinationNotInBook
+	}
+	if len(p.Destinations) == 0 {
+		return nil
+	}
+	for _, a := range p.Destinations {
+		if a == accountIndex {
+			return nil
+		}
+	}
+	return ErrDestinationNotAllowed
+}
+
+func (p *Policy) checkOrder(marketIndex uint8, baseAmount int64, price uint32) error {
+	if err := p.checkMarket(marketIndex); err != nil {
+		return err
+	}
+	if p.MaxOrderNotional > 0 && Notional(baseAmount, price).Cmp(big.NewInt(p.MaxOrderNotional)) > 0 {
+		return ErrOrderNotionalTooHigh
+	}
+	return nil
+}
+
+// Notional returns baseAmount * price without overflowing.
+func Notional(baseAmount int64, price uint32) *big.Int {
+	return new(big.Int).Mul(big.NewInt(baseAmount), new(big.Int).SetUint64(uint64(price)))
+}
@@ -0,0 +1,76 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// accountTxs are the tx types a session key's policy refuses whatever its flags.
+var accountTxs = []txtypes.TxInfo{
+	&txtypes.L2ChangePubKeyTxInfo{},
+	&txtypes.L2CreateSubAccountTxInfo{},
+	&txtypes.L2CreatePublicPoolTxInfo{},
+	&txtypes.L2UpdatePublicPoolTxInfo{},
+	&txtypes.L2MintSharesTxInfo{},
+	&txtypes.L2BurnSharesTxInfo{},
+}
+
+func TestCheckDeniesAccountTxs(t *testing.T) {
+	p := &Policy{AllowTransfers: true, AllowWithdrawals: true}
+	for _, tx := range accountTxs {
+		if err := p.Check(tx); !errors.Is(err, ErrTxTypeNotAllowed) {
+			t.Errorf("tx type %d: got %v, expected %v", tx.GetTxType(), err, ErrTxTypeNotAllowed)
+		}
+	}
+
+	// The flag allowing them cannot come from a policy passed as JSON.
+	decoded := &Policy{}
+	if err := json.Unmarshal([]byte(`{"AllowAccountTxs":true,"allowAccountTxs":true}`), decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.Check(accountTxs[0]); !errors.Is(err, ErrTxTypeNotAllowed) {
+		t.Errorf("a decoded policy allowed a ChangePubKey: %v", err)
+	}
+
+	allowing := &Policy{AllowAccountTxs: true}
+	for _, tx := range accountTxs {
+		if err := allowing.Check(tx); err != nil {
+			t.Errorf("tx type %d refused with AllowAccountTxs: %v", tx.GetTxType(), err)
+		}
+	}
+}
+
+func TestCheckAllowsTrading(t *testing.T) {
+	p := &Policy{Markets: []int{1}}
+	for _, tx := range []txtypes.TxInfo{
+		&txtypes.L2CreateOrderTxInfo{OrderInfo: &txtypes.OrderInfo{MarketIndex: 1}},
+		&txtypes.L2CreateGroupedOrdersTxInfo{Orders: []*txtypes.OrderInfo{{MarketIndex: 1}}},
+		&txtypes.L2ModifyOrderTxInfo{MarketIndex: 1},
+		&txtypes.L2CancelOrderTxInfo{MarketIndex: 1},
+		&txtypes.L2CancelAllOrdersTxInfo{},
+		&txtypes.L2UpdateLeverageTxInfo{MarketIndex: 1},
+		&txtypes.L2UpdateMarginTxInfo{MarketIndex: 1},
+	} {
+		if err := p.Check(tx); err != nil {
+			t.Errorf("tx type %d refused: %v", tx.GetTxType(), err)
+		}
+	}
+
+	for _, tc := range []struct {
+		policy *Policy
+		tx     txtypes.TxInfo
+		err    error
+	}{
+		{&Policy{}, &txtypes.L2TransferTxInfo{}, ErrTransferNotAllowed},
+		{&Policy{}, &txtypes.L2WithdrawTxInfo{}, ErrWithdrawalNotAllowed},
+		{&Policy{AllowTransfers: true}, &txtypes.L2TransferTxInfo{}, nil},
+		{&Policy{AllowWithdrawals: true}, &txtypes.L2WithdrawTxInfo{}, nil},
+	} {
+		if err := tc.policy.Check(tc.tx); !errors.Is(err, tc.err) {
+			t.Errorf("tx type %d: got %v, expected %v", tc.tx.GetTxType(), err, tc.err)
+		}
+	}
+}
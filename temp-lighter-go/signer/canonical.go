@@ -0,0 +1,48 @@
+package signer
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	curve "github.com/elliottech/poseidon_crypto/curve/ecgfp5"
+)
+
+var ErrNonCanonicalSignature = errors.New("signature is not canonical")
+
+const signatureLength = 80
+
+// scalarFromLittleEndian reads a 40 byte little endian scalar without reducing it.
+func scalarFromLittleEndian(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+// IsCanonicalSignature reports whether both scalars of sig, s || e in little endian, are reduced modulo the curve
+// order. A scalar encoded unreduced still verifies, which would make the same signature encodable in several
+// ways and the tx hash of a submission unpredictable.
+func IsCanonicalSignature(sig []byte) bool {
+	if len(sig) != signatureLength {
+		return false
+	}
+	order := curve.ORDER
+	return scalarFromLittleEndian(sig[:40]).Cmp(order) < 0 && scalarFromLittleEndian(sig[40:]).Cmp(order) < 0
+}
+
+// NormalizeSignature returns sig with both scalars reduced modulo the curve order.
+func NormalizeSignature(sig []byte) ([]byte, error) {
+	if len(sig) != signatureLength {
+		return nil, fmt.Errorf("signature length should be %d but is %d", signatureLength, len(sig))
+	}
+	if IsCanonicalSignature(sig) {
+		return sig, nil
+	}
+
+	res := make([]byte, 0, signatureLength)
+	res = append(res, curve.FromNonCanonicalBigInt(scalarFromLittleEndian(sig[:40])).ToLittleEndianBytes()...)
+	res = append(res, curve.FromNonCanonicalBigInt(scalarFromLittleEndian(sig[40:])).ToLittleEndianBytes()...)
+	return res, nil
+}
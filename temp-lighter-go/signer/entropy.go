@@ -0,0 +1,49 @@
+package signer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+var ErrInsufficientEntropy = errors.New("crypto/rand does not provide usable randomness")
+
+var (
+	entropyOnce sync.Once
+	entropyErr  error
+)
+
+// CheckEntropy verifies, once per process, that crypto/rand returns data that looks random. Some embedders
+// back it with a broken or constant source, which would silently produce guessable keys.
+func CheckEntropy() error {
+	entropyOnce.Do(func() {
+		entropyErr = checkEntropy(rand.Reader)
+	})
+	return entropyErr
+}
+
+func checkEntropy(r io.Reader) error {
+	a, b := make([]byte, 64), make([]byte, 64)
+	if _, err := io.ReadFull(r, a); err != nil {
+		return fmt.Errorf("%w: %v", ErrInsufficientEntropy, err)
+	}
+	if _, err := io.ReadFull(r, b); err != nil {
+		return fmt.Errorf("%w: %v", ErrInsufficientEntropy, err)
+	}
+	if bytes.Equal(a, b) {
+		return fmt.Errorf("%w: consecutive reads returned the same bytes", ErrInsufficientEntropy)
+	}
+
+	// 64 random bytes hold about 56 distinct values; fewer than 16 points to a degenerate source.
+	distinct := map[byte]bool{}
+	for _, c := range a {
+		distinct[c] = true
+	}
+	if len(distinct) < 16 {
+		return fmt.Errorf("%w: only %d distinct values in 64 bytes", ErrInsufficientEntropy, len(distinct))
+	}
+	return nil
+}
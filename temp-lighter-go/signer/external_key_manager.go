@@ -0,0 +1,60 @@
+package signer
+
+import (
+	"fmt"
+	"hash"
+
+	gFp5 "github.com/elliottech/poseidon_crypto/field/goldilocks_quintic_extension"
+	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+)
+
+// SignFunc produces a signature over an already hashed message, e.g. by delegating to a hardware wallet.
+type SignFunc func(hashedMessage []byte) ([]byte, error)
+
+var _ KeyManager = (*externalKeyManager)(nil)
+
+type externalKeyManager struct {
+	pubKey gFp5.Element
+	sign   SignFunc
+}
+
+// NewExternalKeyManager returns a KeyManager which never holds the private key: signing is delegated to sign
+// and every signature it returns is validated against pubKey before being used.
+func NewExternalKeyManager(pubKey [40]byte, sign SignFunc) (KeyManager, error) {
+	pk, err := gFp5.FromCanonicalLittleEndianBytes(pubKey[:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	return &externalKeyManager{pubKey: pk, sign: sign}, nil
+}
+
+func (key *externalKeyManager) Sign(hashedMessage []byte, hFunc hash.Hash) ([]byte, error) {
+	sig, err := key.sign(hashedMessage)
+	if err != nil {
+		return nil, fmt.Errorf("external signer failed: %w", err)
+	}
+	if sig, err = NormalizeSignature(sig); err != nil {
+		return nil, fmt.Errorf("external signer returned an invalid signature: %w", err)
+	}
+
+	pubKey := key.PubKeyBytes()
+	if err := schnorr.Validate(pubKey[:], hashedMessage, sig); err != nil {
+		return nil, fmt.Errorf("external signer returned an invalid signature: %w", err)
+	}
+	return sig, nil
+}
+
+func (key *externalKeyManager) PubKey() gFp5.Element {
+	return key.pubKey
+}
+
+func (key *externalKeyManager) PubKeyBytes() (res [40]byte) {
+	bytes := key.pubKey.ToLittleEndianBytes()
+	copy(res[:], bytes[:])
+	return
+}
+
+// PrvKeyBytes always returns nil, the private key never leaves the external signer.
+func (key *externalKeyManager) PrvKeyBytes() []byte {
+	return nil
+}
@@ -1,6 +1,8 @@
 package signer
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"hash"
 
@@ -31,12 +33,36 @@ func NewKeyManager(b []byte) (KeyManager, error) {
 	return &keyManager{key: curve.ScalarElementFromLittleEndianBytes(b)}, nil
 }
 
+// NewKeyManagerFromHex parses a hex-encoded private key, with or without the 0x prefix.
+func NewKeyManagerFromHex(privateKey string) (KeyManager, error) {
+	if len(privateKey) < 2 {
+		return nil, fmt.Errorf("empty private key")
+	}
+	if privateKey[:2] == "0x" {
+		privateKey = privateKey[2:]
+	}
+	b, err := hex.DecodeString(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyManager(b)
+}
+
+// GenerateKeyManager returns a KeyManager backed by a freshly sampled private key. It refuses to generate one
+// when CheckEntropy fails.
+func GenerateKeyManager() (KeyManager, error) {
+	if err := CheckEntropy(); err != nil {
+		return nil, err
+	}
+	return &keyManager{key: curve.SampleScalarCrypto()}, nil
+}
+
 func (key *keyManager) Sign(hashedMessage []byte, hFunc hash.Hash) ([]byte, error) {
 	hashedMessageAsQuinticExtension, err := gFp5.FromCanonicalLittleEndianBytes(hashedMessage)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse message while signing. message: %v err: %w", hashedMessage, err)
 	}
-	return schnorr.SchnorrSignHashedMessage(hashedMessageAsQuinticExtension, key.key).ToBytes(), nil
+	return NormalizeSignature(schnorr.SchnorrSignHashedMessage(hashedMessageAsQuinticExtension, key.key).ToBytes())
 }
 
 func (key *keyManager) PubKey() gFp5.Element {
@@ -52,3 +78,13 @@ func (key *keyManager) PubKeyBytes() (res [40]byte) {
 func (key *keyManager) PrvKeyBytes() []byte {
 	return key.key.ToLittleEndianBytes()
 }
+
+func (key *keyManager) wipe() {
+	key.key = curve.ZERO
+}
+
+// Fingerprint returns a short, display-friendly identifier of a public key: the first 8 bytes of its sha256, hex-encoded.
+func Fingerprint(pubKey [40]byte) string {
+	sum := sha256.Sum256(pubKey[:])
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,184 @@
+package signer
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"hash"
+	"sync"
+	"time"
+
+	gFp5 "github.com/elliottech/poseidon_crypto/field/goldilocks_quintic_extension"
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	ErrKeyLocked         = errors.New("key is locked")
+	ErrInvalidPassphrase = errors.New("invalid passphrase")
+	ErrKeyNotHeld        = errors.New("only a private key held in memory can be locked, not an external signer")
+)
+
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+var _ KeyManager = (*LockableKeyManager)(nil)
+
+// LockableKeyManager keeps the private key encrypted under a passphrase. Unlocking only keeps the derived
+// encryption key around; the private key itself is decrypted for the duration of a single Sign call.
+type LockableKeyManager struct {
+	mu sync.Mutex
+
+	pubKey     gFp5.Element
+	salt       []byte
+	nonce      []byte
+	ciphertext []byte
+
+	aead      cipher.AEAD
+	lockTimer *time.Timer
+}
+
+// NewLockableKeyManager encrypts the key held by km under passphrase. The returned manager starts locked.
+// km itself is left untouched, so callers should drop their references to it. km has to hold its private key,
+// ErrKeyNotHeld is returned otherwise.
+func NewLockableKeyManager(km KeyManager, passphrase string) (*LockableKeyManager, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("empty passphrase")
+	}
+	prvKey := km.PrvKeyBytes()
+	if len(prvKey) == 0 {
+		return nil, ErrKeyNotHeld
+	}
+	defer wipe(prvKey)
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	aead, err := deriveAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return &LockableKeyManager{
+		pubKey:     km.PubKey(),
+		salt:       salt,
+		nonce:      nonce,
+		ciphertext: aead.Seal(nil, nonce, prvKey, nil),
+	}, nil
+}
+
+func deriveAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key from passphrase: %w", err)
+	}
+	defer wipe(key)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// Unlock enables signing until Lock is called or, when timeout is positive, until timeout elapses.
+func (k *LockableKeyManager) Unlock(passphrase string, timeout time.Duration) error {
+	aead, err := deriveAEAD(passphrase, k.salt)
+	if err != nil {
+		return err
+	}
+	prvKey, err := aead.Open(nil, k.nonce, k.ciphertext, nil)
+	if err != nil {
+		return ErrInvalidPassphrase
+	}
+	wipe(prvKey)
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.aead = aead
+	if k.lockTimer != nil {
+		k.lockTimer.Stop()
+		k.lockTimer = nil
+	}
+	if timeout > 0 {
+		k.lockTimer = time.AfterFunc(timeout, k.Lock)
+	}
+	return nil
+}
+
+// Lock discards the derived encryption key; signing fails with ErrKeyLocked until the next Unlock.
+func (k *LockableKeyManager) Lock() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.aead = nil
+	if k.lockTimer != nil {
+		k.lockTimer.Stop()
+		k.lockTimer = nil
+	}
+}
+
+func (k *LockableKeyManager) IsLocked() bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.aead == nil
+}
+
+func (k *LockableKeyManager) decrypt() ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.aead == nil {
+		return nil, ErrKeyLocked
+	}
+	return k.aead.Open(nil, k.nonce, k.ciphertext, nil)
+}
+
+func (k *LockableKeyManager) Sign(hashedMessage []byte, hFunc hash.Hash) ([]byte, error) {
+	prvKey, err := k.decrypt()
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(prvKey)
+
+	km, err := NewKeyManager(prvKey)
+	if err != nil {
+		return nil, err
+	}
+	defer km.(*keyManager).wipe()
+
+	return km.Sign(hashedMessage, hFunc)
+}
+
+func (k *LockableKeyManager) PubKey() gFp5.Element {
+	return k.pubKey
+}
+
+func (k *LockableKeyManager) PubKeyBytes() (res [40]byte) {
+	bytes := k.pubKey.ToLittleEndianBytes()
+	copy(res[:], bytes[:])
+	return
+}
+
+// PrvKeyBytes returns nil while the key is locked.
+func (k *LockableKeyManager) PrvKeyBytes() []byte {
+	prvKey, err := k.decrypt()
+	if err != nil {
+		return nil
+	}
+	return prvKey
+}
@@ -0,0 +1,168 @@
+package types
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elliottech/lighter-go/signer"
+	g "github.com/elliottech/poseidon_crypto/field/goldilocks"
+	p2 "github.com/elliottech/poseidon_crypto/hash/poseidon2_goldilocks"
+	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+)
+
+// authTokenV2Prefix starts every message of a scoped auth token. Legacy messages start with the deadline, so a
+// signature over one format can never be read as a signature over the other.
+const authTokenV2Prefix = "v2"
+
+// AuthTokenOptions binds an auth token to more than the account and api key. A token minted with an Origin or a
+// SessionID is only accepted by VerifyAuthToken when the verifier expects the same values.
+type AuthTokenOptions struct {
+	Origin    string
+	SessionID string
+}
+
+func (o *AuthTokenOptions) scoped() bool {
+	return o != nil && (o.Origin != "" || o.SessionID != "")
+}
+
+// AuthToken is a parsed auth token.
+type AuthToken struct {
+	Version      int
+	Deadline     time.Time
+	AccountIndex int64
+	ApiKeyIndex  uint8
+	Origin       string
+	SessionID    string
+
+	// Message is the signed part of the token, Signature the signature over its hash.
+	Message   string
+	Signature []byte
+}
+
+func authMessageHash(message string) ([]byte, error) {
+	msgInField, err := g.ArrayFromCanonicalLittleEndianBytes([]byte(message))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert bytes to field element. message: %s, error: %w", message, err)
+	}
+	return p2.HashToQuinticExtension(msgInField).ToLittleEndianBytes(), nil
+}
+
+func signAuthMessage(key signer.Signer, message string) (string, error) {
+	msgHash, err := authMessageHash(message)
+	if err != nil {
+		return "", err
+	}
+	signatureBytes, err := key.Sign(msgHash, p2.NewPoseidon2())
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%v:%v", message, ethCommon.Bytes2Hex(signatureBytes)), nil
+}
+
+// ConstructScopedAuthToken is ConstructAuthToken binding the token to opts. Without origin or session id it
+// produces the legacy format, which the exchange accepts.
+//
+// Scoped tokens read v2:deadline:account:apiKey:origin:session:signature, where origin and session are base64url
+// encoded so that they can hold colons.
+func ConstructScopedAuthToken(key signer.Signer, deadline time.Time, ops *TransactOpts, opts *AuthTokenOptions) (string, error) {
+	if !opts.scoped() {
+		return ConstructAuthToken(key, deadline, ops)
+	}
+	if ops.FromAccountIndex == nil {
+		return "", fmt.Errorf("missing FromAccountIndex")
+	}
+	if ops.ApiKeyIndex == nil {
+		return "", fmt.Errorf("missing ApiKeyIndex")
+	}
+
+	message := strings.Join([]string{
+		authTokenV2Prefix,
+		strconv.FormatInt(deadline.Unix(), 10),
+		strconv.FormatInt(*ops.FromAccountIndex, 10),
+		strconv.FormatUint(uint64(*ops.ApiKeyIndex), 10),
+		base64.RawURLEncoding.EncodeToString([]byte(opts.Origin)),
+		base64.RawURLEncoding.EncodeToString([]byte(opts.SessionID)),
+	}, ":")
+	return signAuthMessage(key, message)
+}
+
+// ParseAuthToken decodes a legacy or scoped auth token without verifying it.
+func ParseAuthToken(token string) (*AuthToken, error) {
+	sep := strings.LastIndex(token, ":")
+	if sep < 0 {
+		return nil, fmt.Errorf("malformed auth token")
+	}
+	res := &AuthToken{Message: token[:sep], Signature: ethCommon.FromHex(token[sep+1:])}
+	if len(res.Signature) != 80 {
+		return nil, fmt.Errorf("malformed auth token signature")
+	}
+
+	fields := strings.Split(res.Message, ":")
+	res.Version = 1
+	if fields[0] == authTokenV2Prefix {
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("malformed auth token: expected 6 fields, got %d", len(fields))
+		}
+		origin, err := base64.RawURLEncoding.DecodeString(fields[4])
+		if err != nil {
+			return nil, fmt.Errorf("malformed auth token origin: %w", err)
+		}
+		session, err := base64.RawURLEncoding.DecodeString(fields[5])
+		if err != nil {
+			return nil, fmt.Errorf("malformed auth token session id: %w", err)
+		}
+		res.Version, res.Origin, res.SessionID = 2, string(origin), string(session)
+		fields = fields[1:4]
+	} else if len(fields) != 3 {
+		return nil, fmt.Errorf("malformed auth token: expected 3 fields, got %d", len(fields))
+	}
+
+	deadline, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed auth token deadline: %w", err)
+	}
+	if res.AccountIndex, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+		return nil, fmt.Errorf("malformed auth token account index: %w", err)
+	}
+	apiKeyIndex, err := strconv.ParseUint(fields[2], 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("malformed auth token api key index: %w", err)
+	}
+	res.Deadline, res.ApiKeyIndex = time.Unix(deadline, 0), uint8(apiKeyIndex)
+	return res, nil
+}
+
+// VerifyAuthToken checks that token was signed by pubKey, has not expired at now and is bound to expected. A
+// scoped token only verifies against the same origin and session id, a legacy one only when expected is empty.
+func VerifyAuthToken(token string, pubKey [40]byte, expected *AuthTokenOptions, now time.Time) (*AuthToken, error) {
+	t, err := ParseAuthToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	msgHash, err := authMessageHash(t.Message)
+	if err != nil {
+		return nil, err
+	}
+	if err := schnorr.Validate(pubKey[:], msgHash, t.Signature); err != nil {
+		return t, fmt.Errorf("auth token signature is invalid: %w", err)
+	}
+	if !now.Before(t.Deadline) {
+		return t, fmt.Errorf("auth token expired at %s", t.Deadline.UTC().Format(time.RFC3339))
+	}
+
+	if expected == nil {
+		expected = &AuthTokenOptions{}
+	}
+	if t.Origin != expected.Origin {
+		return t, fmt.Errorf("auth token is bound to origin %q, expected %q", t.Origin, expected.Origin)
+	}
+	if t.SessionID != expected.SessionID {
+		return t, fmt.Errorf("auth token is bound to another session")
+	}
+	return t, nil
+}
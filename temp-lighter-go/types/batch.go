@@ -0,0 +1,168 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// MaxBatchTxs is the number of txs the exchange accepts in a single sendTxBatch call.
+const MaxBatchTxs = 50
+
+// NewTxInfo returns an empty tx of txType to decode a txInfo into.
+func NewTxInfo(txType uint8) (txtypes.TxInfo, error) {
+	switch txType {
+	case txtypes.TxTypeL2ChangePubKey:
+		return &txtypes.L2ChangePubKeyTxInfo{}, nil
+	case txtypes.TxTypeL2CreateSubAccount:
+		return &txtypes.L2CreateSubAccountTxInfo{}, nil
+	case txtypes.TxTypeL2CreatePublicPool:
+		return &txtypes.L2CreatePublicPoolTxInfo{}, nil
+	case txtypes.TxTypeL2UpdatePublicPool:
+		return &txtypes.L2UpdatePublicPoolTxInfo{}, nil
+	case txtypes.TxTypeL2Transfer:
+		return &txtypes.L2TransferTxInfo{}, nil
+	case txtypes.TxTypeL2Withdraw:
+		return &txtypes.L2WithdrawTxInfo{}, nil
+	case txtypes.TxTypeL2CreateOrder:
+		return &txtypes.L2CreateOrderTxInfo{}, nil
+	case txtypes.TxTypeL2CreateGroupedOrders:
+		return &txtypes.L2CreateGroupedOrdersTxInfo{}, nil
+	case txtypes.TxTypeL2CancelOrder:
+		return &txtypes.L2CancelOrderTxInfo{}, nil
+	case txtypes.TxTypeL2ModifyOrder:
+		return &txtypes.L2ModifyOrderTxInfo{}, nil
+	case txtypes.TxTypeL2CancelAllOrders:
+		return &txtypes.L2CancelAllOrdersTxInfo{}, nil
+	case txtypes.TxTypeL2MintShares:
+		return &txtypes.L2MintSharesTxInfo{}, nil
+	case txtypes.TxTypeL2BurnShares:
+		return &txtypes.L2BurnSharesTxInfo{}, nil
+	case txtypes.TxTypeL2UpdateLeverage:
+		return &txtypes.L2UpdateLeverageTxInfo{}, nil
+	case txtypes.TxTypeL2UpdateMargin:
+		return &txtypes.L2UpdateMarginTxInfo{}, nil
+	}
+	return nil, fmt.Errorf("unsupported tx type: %d", txType)
+}
+
+// DecodeSignedTx decodes a signed txInfo of txType. Keys may be in any casing produced by ConvertKey; unknown
+// keys and unsigned txs are refused.
+func DecodeSignedTx(txType uint8, txInfo string) (txtypes.TxInfo, error) {
+	tx, err := NewTxInfo(txType)
+	if err != nil {
+		return nil, err
+	}
+	folded, err := txtypes.FoldJSONKeys([]byte(txInfo))
+	if err != nil {
+		return nil, fmt.Errorf("invalid txInfo: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader([]byte(folded)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(tx); err != nil {
+		return nil, fmt.Errorf("invalid txInfo for tx type %d: %w", txType, err)
+	}
+	if len(SignatureOf(tx)) == 0 {
+		return nil, fmt.Errorf("tx is not signed")
+	}
+	if err := tx.Validate(); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// batchSender identifies the nonce sequence a tx belongs to.
+type batchSender struct {
+	AccountIndex int64
+	ApiKeyIndex  uint8
+}
+
+func senderAndNonce(tx txtypes.TxInfo) (batchSender, int64, error) {
+	// Every signed tx carries its nonce and api key index; transfers and withdrawals name the account
+	// FromAccountIndex instead of AccountIndex.
+	var fields struct {
+		AccountIndex     *int64
+		FromAccountIndex *int64
+		ApiKeyIndex      uint8
+		Nonce            int64
+	}
+	data, err := json.Marshal(tx)
+	if err != nil {
+		return batchSender{}, 0, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return batchSender{}, 0, err
+	}
+
+	sender := batchSender{ApiKeyIndex: fields.ApiKeyIndex}
+	switch {
+	case fields.AccountIndex != nil:
+		sender.AccountIndex = *fields.AccountIndex
+	case fields.FromAccountIndex != nil:
+		sender.AccountIndex = *fields.FromAccountIndex
+	default:
+		return batchSender{}, 0, fmt.Errorf("tx type %d has no account index", tx.GetTxType())
+	}
+	return sender, fields.Nonce, nil
+}
+
+// ExpiredAtOf returns the ExpiredAt of tx, in milliseconds. Every tx type carries one.
+func ExpiredAtOf(tx txtypes.TxInfo) (int64, error) {
+	var fields struct {
+		ExpiredAt int64
+	}
+	data, err := json.Marshal(tx)
+	if err != nil {
+		return 0, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return 0, err
+	}
+	return fields.ExpiredAt, nil
+}
+
+// OrderTxBatch returns the permutation of txs to submit them in one batch: the txs of every (account, api key)
+// are sorted by nonce within the positions they occupy, the others keep their place. Batches larger than
+// MaxBatchTxs or holding a nonce twice for the same sender are refused.
+func OrderTxBatch(txs []txtypes.TxInfo) ([]int, error) {
+	if len(txs) == 0 {
+		return nil, fmt.Errorf("empty batch")
+	}
+	if len(txs) > MaxBatchTxs {
+		return nil, fmt.Errorf("batch holds %d txs, at most %d are allowed", len(txs), MaxBatchTxs)
+	}
+
+	nonces := make([]int64, len(txs))
+	slots := map[batchSender][]int{}
+	var senders []batchSender
+	for i, tx := range txs {
+		sender, nonce, err := senderAndNonce(tx)
+		if err != nil {
+			return nil, fmt.Errorf("txs[%d]: %w", i, err)
+		}
+		if _, ok := slots[sender]; !ok {
+			senders = append(senders, sender)
+		}
+		slots[sender] = append(slots[sender], i)
+		nonces[i] = nonce
+	}
+
+	order := make([]int, len(txs))
+	for _, sender := range senders {
+		positions := slots[sender]
+		sorted := append([]int(nil), positions...)
+		sort.SliceStable(sorted, func(a, b int) bool { return nonces[sorted[a]] < nonces[sorted[b]] })
+		for k := range sorted {
+			if k > 0 && nonces[sorted[k]] == nonces[sorted[k-1]] {
+				return nil, fmt.Errorf("txs[%d] and txs[%d] both use nonce %d of account %d, api key %d",
+					sorted[k-1], sorted[k], nonces[sorted[k]], sender.AccountIndex, sender.ApiKeyIndex)
+			}
+			order[positions[k]] = sorted[k]
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,54 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// MaxStrategySlots bounds the slots of a strategy per market, so that recognizing its orders stays cheap.
+const MaxStrategySlots = 1 << 16
+
+// DeriveClientOrderIndex returns the client order index of slot on marketIndex for the strategy strategyID. It
+// only depends on its arguments, so a restarted process derives the indices of the orders it placed before. Two
+// strategies may derive the same index, unlikely as it is among 2^48 values.
+func DeriveClientOrderIndex(strategyID string, marketIndex uint8, slot uint32) (int64, error) {
+	if strategyID == "" {
+		return 0, fmt.Errorf("strategy id should not be empty")
+	}
+	if slot >= MaxStrategySlots {
+		return 0, fmt.Errorf("slot should be below %d", MaxStrategySlots)
+	}
+
+	var buf [5]byte
+	buf[0] = marketIndex
+	binary.BigEndian.PutUint32(buf[1:], slot)
+	h := sha256.New()
+	h.Write([]byte("lighter client order index\x00"))
+	h.Write([]byte(strategyID))
+	h.Write([]byte{0})
+	h.Write(buf[:])
+	sum := h.Sum(nil)
+
+	span := uint64(txtypes.MaxClientOrderIndex - txtypes.MinClientOrderIndex + 1)
+	return txtypes.MinClientOrderIndex + int64(binary.BigEndian.Uint64(sum)%span), nil
+}
+
+// StrategySlots maps the client order indices of the first slots slots of strategyID on marketIndex to their
+// slot.
+func StrategySlots(strategyID string, marketIndex uint8, slots uint32) (map[int64]uint32, error) {
+	if slots > MaxStrategySlots {
+		return nil, fmt.Errorf("slots should not be larger than %d", MaxStrategySlots)
+	}
+	bySlot := make(map[int64]uint32, slots)
+	for slot := uint32(0); slot < slots; slot++ {
+		index, err := DeriveClientOrderIndex(strategyID, marketIndex, slot)
+		if err != nil {
+			return nil, err
+		}
+		bySlot[index] = slot
+	}
+	return bySlot, nil
+}
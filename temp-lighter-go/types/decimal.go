@@ -0,0 +1,190 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Decimal is an exact decimal number, unscaled / 10^scale. Amounts given as decimal strings go through Decimal
+// instead of float64, so that no binary rounding artifact can reach a signed tx.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int
+}
+
+// USDCDecimals is the number of decimals of USDC amounts: OneUSDC units make one USDC.
+const USDCDecimals = 6
+
+var bigTen = big.NewInt(10)
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// ParseDecimal parses a plain decimal such as "-12.345". The grammar is the same whatever the locale of the
+// caller: an optional leading minus, ASCII digits, then optionally a point and more digits, with surrounding
+// whitespace ignored. Anything else, e.g. "1 000,5", "1,000.5", "+5", "1e3" or ".5", is refused with the reason,
+// rather than read as another number than the one the caller meant.
+func ParseDecimal(s string) (Decimal, error) {
+	t := strings.TrimSpace(s)
+	neg := strings.HasPrefix(t, "-")
+	intPart, fracPart, hasPoint := strings.Cut(strings.TrimPrefix(t, "-"), ".")
+	if err := checkDecimalSyntax(t, intPart, fracPart, hasPoint); err != nil {
+		return Decimal{}, fmt.Errorf("invalid decimal %q: %w", s, err)
+	}
+
+	fracPart = strings.TrimRight(fracPart, "0")
+	unscaled, _ := new(big.Int).SetString("0"+intPart+fracPart, 10)
+	if neg {
+		unscaled.Neg(unscaled)
+	}
+	return Decimal{unscaled: unscaled, scale: len(fracPart)}, nil
+}
+
+// checkDecimalSyntax explains why t, split around its first point, does not follow the grammar of ParseDecimal.
+func checkDecimalSyntax(t, intPart, fracPart string, hasPoint bool) error {
+	if t == "" {
+		return fmt.Errorf("empty value")
+	}
+	for i, r := range t {
+		switch {
+		case r >= '0' && r <= '9', r == '.', r == '-' && i == 0:
+		case r == ',':
+			return fmt.Errorf("',' is neither accepted as decimal point nor as digit grouping, use '.' as the decimal point and no grouping")
+		case r == ' ' || r == '_' || r == '\'' || r == '\u00a0' || r == '\u202f':
+			return fmt.Errorf("digit grouping with %q is not accepted", r)
+		case r == 'e' || r == 'E':
+			return fmt.Errorf("exponents are not accepted, write the number out")
+		case r == '+' && i == 0:
+			return fmt.Errorf("a leading '+' is not accepted")
+		case r == '-':
+			return fmt.Errorf("'-' is only accepted as the first character")
+		default:
+			return fmt.Errorf("unexpected character %q at offset %d", r, i)
+		}
+	}
+	switch {
+	case strings.Contains(fracPart, "."):
+		return fmt.Errorf("more than one decimal point")
+	case intPart == "" && !hasPoint:
+		return fmt.Errorf("expected a digit")
+	case intPart == "":
+		return fmt.Errorf("expected a digit before the decimal point")
+	case hasPoint && fracPart == "":
+		return fmt.Errorf("expected a digit after the decimal point")
+	}
+	return nil
+}
+
+// DecimalFromUnits returns units / 10^decimals.
+func DecimalFromUnits(units int64, decimals uint8) Decimal {
+	return Decimal{unscaled: big.NewInt(units), scale: int(decimals)}
+}
+
+func (d Decimal) int() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// aligned returns the unscaled values of d and e at their common scale.
+func (d Decimal) aligned(e Decimal) (*big.Int, *big.Int, int) {
+	a, b := new(big.Int).Set(d.int()), new(big.Int).Set(e.int())
+	switch {
+	case d.scale < e.scale:
+		a.Mul(a, pow10(e.scale-d.scale))
+		return a, b, e.scale
+	case d.scale > e.scale:
+		b.Mul(b, pow10(d.scale-e.scale))
+	}
+	return a, b, d.scale
+}
+
+func (d Decimal) Add(e Decimal) Decimal {
+	a, b, scale := d.aligned(e)
+	return Decimal{unscaled: a.Add(a, b), scale: scale}
+}
+
+func (d Decimal) Sub(e Decimal) Decimal {
+	a, b, scale := d.aligned(e)
+	return Decimal{unscaled: a.Sub(a, b), scale: scale}
+}
+
+func (d Decimal) Mul(e Decimal) Decimal {
+	return Decimal{unscaled: new(big.Int).Mul(d.int(), e.int()), scale: d.scale + e.scale}
+}
+
+func (d Decimal) Cmp(e Decimal) int {
+	a, b, _ := d.aligned(e)
+	return a.Cmp(b)
+}
+
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// String formats d without exponent or trailing zeros.
+func (d Decimal) String() string {
+	abs := new(big.Int).Abs(d.int()).String()
+	if d.scale > 0 {
+		if len(abs) <= d.scale {
+			abs = strings.Repeat("0", d.scale-len(abs)+1) + abs
+		}
+		abs = strings.TrimRight(abs[:len(abs)-d.scale]+"."+abs[len(abs)-d.scale:], "0")
+		abs = strings.TrimSuffix(abs, ".")
+	}
+	if d.Sign() < 0 {
+		return "-" + abs
+	}
+	return abs
+}
+
+// Units converts d into integer units with decimals digits after the point, rounded to a multiple of step
+// following mode. Values above max in absolute value once rounded are rejected.
+func (d Decimal) Units(decimals uint8, step int64, max int64, mode RoundingMode) (int64, error) {
+	if step < 1 {
+		return 0, fmt.Errorf("invalid step %d", step)
+	}
+
+	// d * 10^decimals / step = num / den, rounded on the magnitude.
+	neg := d.Sign() < 0
+	num := new(big.Int).Abs(d.int())
+	den := big.NewInt(step)
+	if shift := int(decimals) - d.scale; shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	if r.Sign() != 0 {
+		roundAway := false
+		switch mode {
+		case RoundExact:
+			if step == 1 {
+				return 0, fmt.Errorf("%s has more than %d decimals", d, decimals)
+			}
+			return 0, fmt.Errorf("%s is not a multiple of %d units of %d decimals", d, step, decimals)
+		case RoundDown:
+			roundAway = neg
+		case RoundUp:
+			roundAway = !neg
+		case RoundNearest:
+			roundAway = r.Lsh(r, 1).Cmp(den) >= 0
+		}
+		if roundAway {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	q.Mul(q, big.NewInt(step))
+	if !q.IsInt64() || q.Int64() > max {
+		return 0, fmt.Errorf("%s is out of range", d)
+	}
+	if neg {
+		return -q.Int64(), nil
+	}
+	return q.Int64(), nil
+}
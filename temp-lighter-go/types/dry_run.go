@@ -0,0 +1,23 @@
+package types
+
+import (
+	"sync"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// dryRuns holds the txs being checked for a simulation rather than for signing.
+var dryRuns sync.Map
+
+// BeginDryRun marks tx as checked for a simulation until the returned func is called. Checks with side effects,
+// like counting tx against a rolling limit or asking the user to confirm it, skip them for such txs.
+func BeginDryRun(tx txtypes.TxInfo) (end func()) {
+	dryRuns.Store(tx, true)
+	return func() { dryRuns.Delete(tx) }
+}
+
+// IsDryRun reports whether tx is checked for a simulation, see BeginDryRun.
+func IsDryRun(tx txtypes.TxInfo) bool {
+	_, ok := dryRuns.Load(tx)
+	return ok
+}
@@ -0,0 +1,70 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// SessionExpiryPreset names the preset expiring orders at the end of the session set by the caller.
+const SessionExpiryPreset = "SESSION"
+
+// orderExpiryPresets maps the named good-till-time lifetimes to their duration.
+var orderExpiryPresets = map[string]time.Duration{
+	"GTT_1H":  time.Hour,
+	"GTT_1D":  24 * time.Hour,
+	"GTT_28D": 28 * 24 * time.Hour,
+}
+
+// OrderExpiryPresets lists the preset names, SessionExpiryPreset included, sorted by name.
+func OrderExpiryPresets() []string {
+	names := []string{SessionExpiryPreset}
+	for name := range orderExpiryPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsOrderExpiryPreset reports whether s names a preset. Names are case-insensitive.
+func IsOrderExpiryPreset(s string) bool {
+	s = strings.ToUpper(s)
+	_, ok := orderExpiryPresets[s]
+	return ok || s == SessionExpiryPreset
+}
+
+// CheckOrderExpiry checks that expiry, in milliseconds, is within the order lifetimes the protocol accepts.
+func CheckOrderExpiry(expiry int64, now time.Time) error {
+	period := expiry - now.UnixMilli()
+	if period < txtypes.MinOrderExpiryPeriod || period > txtypes.MaxOrderExpiryPeriod {
+		return fmt.Errorf("order expiry %d is %s from now, it should be between %s and %s", expiry,
+			time.Duration(period)*time.Millisecond, time.Duration(txtypes.MinOrderExpiryPeriod)*time.Millisecond,
+			time.Duration(txtypes.MaxOrderExpiryPeriod)*time.Millisecond)
+	}
+	return nil
+}
+
+// ResolveOrderExpiry returns the OrderExpiry, in milliseconds, of preset for an order signed at now. sessionEnd
+// is only used by SessionExpiryPreset and has to be set for it.
+func ResolveOrderExpiry(preset string, now, sessionEnd time.Time) (int64, error) {
+	preset = strings.ToUpper(preset)
+	var expiry int64
+	if d, ok := orderExpiryPresets[preset]; ok {
+		expiry = now.Add(d).UnixMilli()
+	} else if preset == SessionExpiryPreset {
+		if sessionEnd.IsZero() {
+			return 0, fmt.Errorf("%s expiry needs the session end to be set", SessionExpiryPreset)
+		}
+		expiry = sessionEnd.UnixMilli()
+	} else {
+		return 0, fmt.Errorf("unknown order expiry preset %q, expected one of %s", preset, strings.Join(OrderExpiryPresets(), ", "))
+	}
+
+	if err := CheckOrderExpiry(expiry, now); err != nil {
+		return 0, fmt.Errorf("%s: %w", preset, err)
+	}
+	return expiry, nil
+}
@@ -0,0 +1,79 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// OrderIntentHashSize is the size in bytes of an order intent hash.
+const OrderIntentHashSize = 16
+
+// OrderIntentHash returns, as hex, a hash of the fields of order a person approves placing from accountIndex:
+// market, side, size, price, type, time in force, reduce-only, trigger price and expiry. The client order index
+// and the fields of the tx around the order, nonce, api key and expiry, are left out; they do not change what the
+// order does.
+func OrderIntentHash(accountIndex int64, order *txtypes.OrderInfo) string {
+	var buf [8 + 1 + 8 + 4 + 1 + 1 + 1 + 1 + 4 + 8]byte
+	b := buf[:0]
+	b = binary.BigEndian.AppendUint64(b, uint64(accountIndex))
+	b = append(b, order.MarketIndex)
+	b = binary.BigEndian.AppendUint64(b, uint64(order.BaseAmount))
+	b = binary.BigEndian.AppendUint32(b, order.Price)
+	b = append(b, order.IsAsk, order.Type, order.TimeInForce, order.ReduceOnly)
+	b = binary.BigEndian.AppendUint32(b, order.TriggerPrice)
+	b = binary.BigEndian.AppendUint64(b, uint64(order.OrderExpiry))
+
+	h := sha256.New()
+	h.Write([]byte("lighter order intent\x00"))
+	h.Write(b)
+	return hex.EncodeToString(h.Sum(nil)[:OrderIntentHashSize])
+}
+
+// OrderIntentMismatchError is returned for an order whose intent hash differs from the one approved for it.
+type OrderIntentMismatchError struct {
+	Approved string
+	Actual   string
+}
+
+func (e *OrderIntentMismatchError) Error() string {
+	return fmt.Sprintf("order intent %s differs from the approved intent %s", e.Actual, e.Approved)
+}
+
+// CheckOrderIntent checks that order, placed from accountIndex, has the intent hash approved.
+func CheckOrderIntent(accountIndex int64, order *txtypes.OrderInfo, approved string) error {
+	if actual := OrderIntentHash(accountIndex, order); actual != approved {
+		return &OrderIntentMismatchError{Approved: approved, Actual: actual}
+	}
+	return nil
+}
+
+// ModifyIntentHash returns, as hex, a hash of what a person approves when changing a resting order with tx: the
+// order it targets, by account, market and index, and its new size, price and trigger price. It never equals an
+// OrderIntentHash, so an approved order cannot be signed as a modify or the other way around.
+func ModifyIntentHash(tx *txtypes.L2ModifyOrderTxInfo) string {
+	var buf [8 + 1 + 8 + 8 + 4 + 4]byte
+	b := buf[:0]
+	b = binary.BigEndian.AppendUint64(b, uint64(tx.AccountIndex))
+	b = append(b, tx.MarketIndex)
+	b = binary.BigEndian.AppendUint64(b, uint64(tx.Index))
+	b = binary.BigEndian.AppendUint64(b, uint64(tx.BaseAmount))
+	b = binary.BigEndian.AppendUint32(b, tx.Price)
+	b = binary.BigEndian.AppendUint32(b, tx.TriggerPrice)
+
+	h := sha256.New()
+	h.Write([]byte("lighter modify intent\x00"))
+	h.Write(b)
+	return hex.EncodeToString(h.Sum(nil)[:OrderIntentHashSize])
+}
+
+// CheckModifyIntent checks that tx has the modify intent hash approved.
+func CheckModifyIntent(tx *txtypes.L2ModifyOrderTxInfo, approved string) error {
+	if actual := ModifyIntentHash(tx); actual != approved {
+		return &OrderIntentMismatchError{Approved: approved, Actual: actual}
+	}
+	return nil
+}
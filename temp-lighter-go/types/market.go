@@ -0,0 +1,102 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// MarketRules are the size and price constraints the exchange enforces on the orders of a market, in protocol
+// units.
+type MarketRules struct {
+	MarketIndex uint8
+	// MinBaseAmount is the smallest base amount of an order.
+	MinBaseAmount int64
+	// MinQuoteAmount is the smallest notional of an order, in units of base amount times price ticks.
+	MinQuoteAmount int64
+	// BaseStep and PriceStep are what the base amount and price of an order must be multiples of, 1 when any
+	// value is allowed.
+	BaseStep  int64
+	PriceStep uint32
+	// SizeDecimals and PriceDecimals are the digits after the point of base amount units and price ticks, used
+	// to convert decimal sizes and prices. They are only known when HasDecimals is set.
+	SizeDecimals  uint8
+	PriceDecimals uint8
+	HasDecimals   bool
+}
+
+// OrderSizeError is returned for an order which breaks the rules of its market. SuggestedBaseAmount and
+// SuggestedPrice are the nearest values satisfying every rule, for the caller to offer as a correction.
+type OrderSizeError struct {
+	MarketIndex         uint8
+	Violations          []string
+	BaseAmount          int64
+	Price               uint32
+	SuggestedBaseAmount int64
+	SuggestedPrice      uint32
+}
+
+func (e *OrderSizeError) Error() string {
+	return fmt.Sprintf("order on market %d breaks the market rules (%v), nearest valid order is %d at %d",
+		e.MarketIndex, e.Violations, e.SuggestedBaseAmount, e.SuggestedPrice)
+}
+
+// roundToStep rounds v to the nearest multiple of step, halves rounding up.
+func roundToStep(v, step int64) int64 {
+	if step <= 1 {
+		return v
+	}
+	return (v + step/2) / step * step
+}
+
+// ceilToStep rounds v up to a multiple of step.
+func ceilToStep(v, step int64) int64 {
+	if step <= 1 {
+		return v
+	}
+	return (v + step - 1) / step * step
+}
+
+// CheckMarketRules checks the base amount and price of order against rules. The notional uses the order's price,
+// which for market orders is their worst price.
+func CheckMarketRules(order *txtypes.OrderInfo, rules *MarketRules) error {
+	e := &OrderSizeError{MarketIndex: order.MarketIndex, BaseAmount: order.BaseAmount, Price: order.Price}
+
+	price := order.Price
+	if rules.PriceStep > 1 && price%rules.PriceStep != 0 {
+		e.Violations = append(e.Violations, fmt.Sprintf("price should be a multiple of %d", rules.PriceStep))
+		price = uint32(roundToStep(int64(price), int64(rules.PriceStep)))
+		if price == 0 {
+			price = rules.PriceStep
+		}
+	}
+
+	minBase := rules.MinBaseAmount
+	if order.BaseAmount < rules.MinBaseAmount {
+		e.Violations = append(e.Violations, fmt.Sprintf("base amount should be at least %d", rules.MinBaseAmount))
+	}
+	if price != 0 && rules.MinQuoteAmount > 0 {
+		notional := new(big.Int).Mul(big.NewInt(order.BaseAmount), big.NewInt(int64(order.Price)))
+		if notional.Cmp(big.NewInt(rules.MinQuoteAmount)) < 0 {
+			e.Violations = append(e.Violations, fmt.Sprintf("notional should be at least %d", rules.MinQuoteAmount))
+		}
+		// The smallest base amount reaching the minimum notional at the suggested price.
+		if b := (rules.MinQuoteAmount + int64(price) - 1) / int64(price); b > minBase {
+			minBase = b
+		}
+	}
+	if rules.BaseStep > 1 && order.BaseAmount%rules.BaseStep != 0 {
+		e.Violations = append(e.Violations, fmt.Sprintf("base amount should be a multiple of %d", rules.BaseStep))
+	}
+	if len(e.Violations) == 0 {
+		return nil
+	}
+
+	base := roundToStep(order.BaseAmount, rules.BaseStep)
+	if base < minBase {
+		base = ceilToStep(minBase, rules.BaseStep)
+	}
+	e.SuggestedBaseAmount, e.SuggestedPrice = base, price
+	return e
+}
@@ -0,0 +1,146 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// MemoSize is the size of the memo of a transfer.
+const MemoSize = 32
+
+// MemoTemplate lays out structured metadata in a transfer memo, e.g. "inv:{invoice};st:{strategy}". Placeholders
+// are names of letters, digits and underscores in braces; the literal text between them is kept as is. Two
+// placeholders are always separated by literal text, so that a memo decodes back into the values it was rendered
+// from.
+type MemoTemplate struct {
+	source string
+	// parts alternate literal text and placeholder names, starting with literal text, possibly empty.
+	parts []string
+}
+
+func isMemoVarName(s string) bool {
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// ParseMemoTemplate parses template, checking that its literal text alone fits a memo.
+func ParseMemoTemplate(template string) (*MemoTemplate, error) {
+	t := &MemoTemplate{source: template}
+	seen := map[string]bool{}
+	rest := template
+	for {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			t.parts = append(t.parts, rest)
+			break
+		}
+		if rest[open] == '}' {
+			return nil, fmt.Errorf("unmatched '}' in memo template %q", template)
+		}
+		closing := strings.IndexByte(rest[open:], '}')
+		if closing < 0 {
+			return nil, fmt.Errorf("unclosed '{' in memo template %q", template)
+		}
+		name := rest[open+1 : open+closing]
+		if !isMemoVarName(name) {
+			return nil, fmt.Errorf("invalid placeholder {%s} in memo template %q, names hold letters, digits and underscores", name, template)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("placeholder {%s} appears twice in memo template %q", name, template)
+		}
+		seen[name] = true
+		if len(t.parts) > 0 && open == 0 {
+			return nil, fmt.Errorf("placeholders {%s} and {%s} of memo template %q should be separated by text", t.parts[len(t.parts)-1], name, template)
+		}
+		t.parts = append(t.parts, rest[:open], name)
+		rest = rest[open+closing+1:]
+	}
+
+	size := 0
+	for i := 0; i < len(t.parts); i += 2 {
+		size += len(t.parts[i])
+	}
+	if size > MemoSize {
+		return nil, fmt.Errorf("the text of memo template %q is %d bytes, over the %d bytes of a memo", template, size, MemoSize)
+	}
+	return t, nil
+}
+
+func (t *MemoTemplate) String() string {
+	return t.source
+}
+
+// Vars returns the placeholder names of t in order.
+func (t *MemoTemplate) Vars() []string {
+	var names []string
+	for i := 1; i < len(t.parts); i += 2 {
+		names = append(names, t.parts[i])
+	}
+	return names
+}
+
+// Render substitutes vars into t. Every placeholder needs a value, and no value may hold the text following its
+// placeholder or a NUL byte, which would make the memo decode differently. The memo has to fit MemoSize bytes.
+func (t *MemoTemplate) Render(vars map[string]string) (memo [MemoSize]byte, err error) {
+	var b strings.Builder
+	b.WriteString(t.parts[0])
+	for i := 1; i < len(t.parts); i += 2 {
+		name, next := t.parts[i], t.parts[i+1]
+		value, ok := vars[name]
+		if !ok {
+			return memo, fmt.Errorf("no value for placeholder {%s} of memo template %q", name, t.source)
+		}
+		if strings.IndexByte(value, 0) >= 0 {
+			return memo, fmt.Errorf("value of {%s} holds a NUL byte", name)
+		}
+		if next != "" && strings.Index(value+next, next) != len(value) {
+			return memo, fmt.Errorf("value %q of {%s} should not contain %q, which follows it in memo template %q", value, name, next, t.source)
+		}
+		b.WriteString(value)
+		b.WriteString(next)
+	}
+	for name := range vars {
+		if !strings.Contains(t.source, "{"+name+"}") {
+			return memo, fmt.Errorf("memo template %q has no placeholder {%s}", t.source, name)
+		}
+	}
+	if b.Len() > MemoSize {
+		return memo, fmt.Errorf("memo %q is %d bytes, over the %d bytes of a memo", b.String(), b.Len(), MemoSize)
+	}
+	copy(memo[:], b.String())
+	return memo, nil
+}
+
+// Decode returns the values memo was rendered from with t, failing when it does not follow t.
+func (t *MemoTemplate) Decode(memo [MemoSize]byte) (map[string]string, error) {
+	text := MemoText(memo)
+	rest, ok := strings.CutPrefix(text, t.parts[0])
+	if !ok {
+		return nil, fmt.Errorf("memo %q does not start with %q", text, t.parts[0])
+	}
+	vars := map[string]string{}
+	for i := 1; i < len(t.parts); i += 2 {
+		name, next := t.parts[i], t.parts[i+1]
+		end := len(rest)
+		if next != "" {
+			if end = strings.Index(rest, next); end < 0 {
+				return nil, fmt.Errorf("memo %q does not follow memo template %q", text, t.source)
+			}
+		}
+		vars[name], rest = rest[:end], rest[end+len(next):]
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("memo %q does not follow memo template %q", text, t.source)
+	}
+	return vars, nil
+}
+
+// MemoText returns memo without the NUL bytes padding it.
+func MemoText(memo [MemoSize]byte) string {
+	return string(bytes.TrimRight(memo[:], "\x00"))
+}
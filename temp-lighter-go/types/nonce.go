@@ -0,0 +1,44 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// SenderOf returns the account and api key index tx is signed for, and its nonce.
+func SenderOf(tx txtypes.TxInfo) (accountIndex int64, apiKeyIndex uint8, nonce int64, err error) {
+	sender, nonce, err := senderAndNonce(tx)
+	return sender.AccountIndex, sender.ApiKeyIndex, nonce, err
+}
+
+// WithNonce returns an unsigned copy of tx using nonce instead of its own, to be signed again.
+func WithNonce(tx txtypes.TxInfo, nonce int64) (txtypes.TxInfo, error) {
+	data, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if _, ok := fields["Nonce"]; !ok {
+		return nil, fmt.Errorf("tx type %d has no nonce", tx.GetTxType())
+	}
+	fields["Nonce"] = json.RawMessage(strconv.FormatInt(nonce, 10))
+	delete(fields, "Sig")
+
+	if data, err = json.Marshal(fields); err != nil {
+		return nil, err
+	}
+	res, err := NewTxInfo(tx.GetTxType())
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// ReduceOnlyError is returned for a reduce-only order which would open or grow a position instead of reducing
+// it.
+type ReduceOnlyError struct {
+	MarketIndex uint8
+	// Position is the signed base amount held in the market, negative for a short.
+	Position   int64
+	BaseAmount int64
+	IsAsk      uint8
+}
+
+func (e *ReduceOnlyError) Error() string {
+	switch {
+	case e.Position == 0:
+		return fmt.Sprintf("reduce-only order on market %d without an open position", e.MarketIndex)
+	case (e.Position > 0) == (e.IsAsk == 0):
+		side := "long"
+		if e.Position < 0 {
+			side = "short"
+		}
+		return fmt.Sprintf("reduce-only order on market %d is on the same side as the %s position", e.MarketIndex, side)
+	}
+	return fmt.Sprintf("reduce-only order on market %d for %d exceeds the position of %d", e.MarketIndex, e.BaseAmount, abs(e.Position))
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// CheckReduceOnly checks a reduce-only order against the position held in its market: it must be on the
+// opposite side and, unless it closes the whole position with a nil base amount, not larger than the position.
+// Orders which are not reduce-only always pass.
+func CheckReduceOnly(position int64, order *txtypes.OrderInfo) error {
+	if order.ReduceOnly != 1 {
+		return nil
+	}
+
+	err := &ReduceOnlyError{
+		MarketIndex: order.MarketIndex,
+		Position:    position,
+		BaseAmount:  order.BaseAmount,
+		IsAsk:       order.IsAsk,
+	}
+	if position == 0 || (position > 0) == (order.IsAsk == 0) {
+		return err
+	}
+	if order.BaseAmount != txtypes.NilOrderBaseAmount && order.BaseAmount > abs(position) {
+		return err
+	}
+	return nil
+}
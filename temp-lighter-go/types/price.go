@@ -0,0 +1,205 @@
+package types
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// MaxSlippageBps bounds the slippage accepted by MarketableLimitPrice, 50%.
+const MaxSlippageBps = 5000
+
+// ParsePriceTicks converts a decimal price, as returned by the API, into price ticks of a market quoting
+// decimals digits. Prices with more digits than the market quotes are rejected rather than rounded.
+func ParsePriceTicks(price string, decimals uint8) (uint32, error) {
+	ticks, err := ParseDecimalUnits(price, decimals, math.MaxUint32)
+	if err != nil {
+		return 0, fmt.Errorf("price: %w", err)
+	}
+	if ticks < 0 {
+		return 0, fmt.Errorf("price %q is negative", price)
+	}
+	return uint32(ticks), nil
+}
+
+// ParseDecimalUnits converts a signed decimal, as returned by the API, into integer units with decimals digits
+// after the point. Values with more digits are rejected rather than rounded, as are values above max in
+// absolute value.
+func ParseDecimalUnits(value string, decimals uint8, max int64) (int64, error) {
+	return RoundDecimalUnits(value, decimals, 1, max, RoundExact)
+}
+
+// MarketableLimitPrice returns the worst price a marketable order may fill at: maxSlippageBps above the best
+// ask for a buy, below the best bid for a sell. Only the side the order takes from is required.
+func MarketableLimitPrice(isAsk uint8, bestBid, bestAsk uint32, maxSlippageBps uint32) (price, reference uint32, err error) {
+	if maxSlippageBps > MaxSlippageBps {
+		return 0, 0, fmt.Errorf("max slippage should not be larger than %d bps", MaxSlippageBps)
+	}
+
+	// Rounding is toward the reference price, so that the slippage is never exceeded
+	if isAsk == 1 {
+		if bestBid == txtypes.NilOrderPrice {
+			return 0, 0, fmt.Errorf("no bid to sell into")
+		}
+		limit := (uint64(bestBid)*uint64(10_000-maxSlippageBps) + 9_999) / 10_000
+		return uint32(max(limit, uint64(txtypes.MinOrderPrice))), bestBid, nil
+	}
+
+	if bestAsk == txtypes.NilOrderPrice {
+		return 0, 0, fmt.Errorf("no ask to buy from")
+	}
+	limit := uint64(bestAsk) * uint64(10_000+maxSlippageBps) / 10_000
+	return uint32(min(limit, uint64(txtypes.MaxOrderPrice))), bestAsk, nil
+}
+
+// PostOnlyCrossError is returned for a post-only order which would take liquidity, and so be rejected by the
+// exchange, at the given top of book.
+type PostOnlyCrossError struct {
+	MarketIndex uint8
+	Price       uint32
+	IsAsk       uint8
+	// Reference is the best price on the other side of the book the order would cross.
+	Reference uint32
+}
+
+func (e *PostOnlyCrossError) Error() string {
+	if e.IsAsk == 1 {
+		return fmt.Sprintf("post-only sell on market %d at %d would cross the best bid of %d", e.MarketIndex, e.Price, e.Reference)
+	}
+	return fmt.Sprintf("post-only buy on market %d at %d would cross the best ask of %d", e.MarketIndex, e.Price, e.Reference)
+}
+
+// CheckPostOnlyCross checks that a post-only order rests on the book: a buy must be priced below the best ask,
+// a sell above the best bid. A nil price leaves its side of the book unchecked; orders which are not post-only
+// always pass.
+func CheckPostOnlyCross(order *txtypes.OrderInfo, bestBid, bestAsk uint32) error {
+	if order.TimeInForce != txtypes.PostOnly {
+		return nil
+	}
+
+	if order.IsAsk == 1 && bestBid != txtypes.NilOrderPrice && order.Price <= bestBid {
+		return &PostOnlyCrossError{MarketIndex: order.MarketIndex, Price: order.Price, IsAsk: 1, Reference: bestBid}
+	}
+	if order.IsAsk == 0 && bestAsk != txtypes.NilOrderPrice && order.Price >= bestAsk {
+		return &PostOnlyCrossError{MarketIndex: order.MarketIndex, Price: order.Price, IsAsk: 0, Reference: bestAsk}
+	}
+	return nil
+}
+
+// TriggerDirectionError is returned for a stop-loss or take-profit order whose trigger price is on the wrong
+// side of the market or of its own limit price, which the matching engine rejects.
+type TriggerDirectionError struct {
+	MarketIndex  uint8
+	OrderType    uint8
+	IsAsk        uint8
+	TriggerPrice uint32
+	// Against is "reference" when the trigger is checked against the top of book, "order" when it is checked
+	// against the order's own price.
+	Against   string
+	Reference uint32
+	// Above tells whether TriggerPrice has to be above Reference.
+	Above bool
+}
+
+func (e *TriggerDirectionError) Error() string {
+	kind := "stop-loss"
+	if e.OrderType == txtypes.TakeProfitOrder || e.OrderType == txtypes.TakeProfitLimitOrder {
+		kind = "take-profit"
+	}
+	side := "buy"
+	if e.IsAsk == 1 {
+		side = "sell"
+	}
+	want := "below"
+	if e.Above {
+		want = "above"
+	}
+	if e.Against == "order" {
+		want = "at or " + want
+	}
+	return fmt.Sprintf("%s %s on market %d: trigger price %d should be %s the %s price %d", kind, side, e.MarketIndex, e.TriggerPrice, want, e.Against, e.Reference)
+}
+
+// CheckTriggerDirection checks that a stop-loss or take-profit order triggers on a move away from the current
+// market. A stop-loss buy and a take-profit sell trigger when the price rises, so their trigger price must be
+// above the reference; the two others trigger when it falls. The reference is the mid of bestBid and bestAsk, or
+// the side that is set when the other is nil; without any, only the order's own price is checked. A buy's price
+// must be at or above its trigger price, a sell's at or below, or it could not fill once triggered. Orders
+// without trigger always pass.
+func CheckTriggerDirection(order *txtypes.OrderInfo, bestBid, bestAsk uint32) error {
+	var rises bool
+	switch order.Type {
+	case txtypes.StopLossOrder, txtypes.StopLossLimitOrder:
+		rises = order.IsAsk == 0
+	case txtypes.TakeProfitOrder, txtypes.TakeProfitLimitOrder:
+		rises = order.IsAsk == 1
+	default:
+		return nil
+	}
+	newErr := func(against string, reference uint32, above bool) error {
+		return &TriggerDirectionError{
+			MarketIndex:  order.MarketIndex,
+			OrderType:    order.Type,
+			IsAsk:        order.IsAsk,
+			TriggerPrice: order.TriggerPrice,
+			Against:      against,
+			Reference:    reference,
+			Above:        above,
+		}
+	}
+
+	if order.IsAsk == 0 && order.Price < order.TriggerPrice {
+		return newErr("order", order.Price, false)
+	}
+	if order.IsAsk == 1 && order.Price > order.TriggerPrice {
+		return newErr("order", order.Price, true)
+	}
+
+	var reference uint32
+	switch {
+	case bestBid != txtypes.NilOrderPrice && bestAsk != txtypes.NilOrderPrice:
+		reference = uint32((uint64(bestBid) + uint64(bestAsk)) / 2)
+	case bestBid != txtypes.NilOrderPrice:
+		reference = bestBid
+	case bestAsk != txtypes.NilOrderPrice:
+		reference = bestAsk
+	default:
+		return nil
+	}
+	if rises && order.TriggerPrice <= reference {
+		return newErr("reference", reference, true)
+	}
+	if !rises && order.TriggerPrice >= reference {
+		return newErr("reference", reference, false)
+	}
+	return nil
+}
+
+// PriceBandError is returned for an order priced further from the mark price of its market than the allowed
+// band, most likely a fat-finger.
+type PriceBandError struct {
+	MarketIndex     uint8
+	Price           uint32
+	MarkPrice       uint32
+	MaxDeviationBps uint32
+}
+
+func (e *PriceBandError) Error() string {
+	return fmt.Sprintf("order on market %d at %d is more than %d bps away from the mark price %d", e.MarketIndex, e.Price, e.MaxDeviationBps, e.MarkPrice)
+}
+
+// CheckPriceBand checks that order is priced within maxDeviationBps of markPrice. A nil mark price or a zero
+// band leaves the order unchecked.
+func CheckPriceBand(order *txtypes.OrderInfo, markPrice uint32, maxDeviationBps uint32) error {
+	if markPrice == txtypes.NilOrderPrice || maxDeviationBps == 0 {
+		return nil
+	}
+
+	low := uint64(markPrice) * uint64(10_000-min(maxDeviationBps, 10_000)) / 10_000
+	high := uint64(markPrice) * uint64(10_000+maxDeviationBps) / 10_000
+	if uint64(order.Price) < low || uint64(order.Price) > high {
+		return &PriceBandError{MarketIndex: order.MarketIndex, Price: order.Price, MarkPrice: markPrice, MaxDeviationBps: maxDeviationBps}
+	}
+	return nil
+}
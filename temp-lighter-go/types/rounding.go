@@ -0,0 +1,55 @@
+package types
+
+import (
+	"fmt"
+)
+
+// RoundingMode selects how a decimal with more digits than a market accepts is turned into units.
+type RoundingMode int
+
+const (
+	// RoundExact rejects values which are not a whole number of units.
+	RoundExact RoundingMode = iota
+	// RoundDown rounds toward negative infinity.
+	RoundDown
+	// RoundUp rounds toward positive infinity.
+	RoundUp
+	// RoundNearest rounds to the nearest unit, halves away from zero.
+	RoundNearest
+)
+
+func ParseRoundingMode(s string) (RoundingMode, error) {
+	switch s {
+	case "exact":
+		return RoundExact, nil
+	case "down":
+		return RoundDown, nil
+	case "up":
+		return RoundUp, nil
+	case "nearest":
+		return RoundNearest, nil
+	}
+	return 0, fmt.Errorf("unknown rounding mode %q, expected exact, down, up or nearest", s)
+}
+
+func (m RoundingMode) String() string {
+	switch m {
+	case RoundDown:
+		return "down"
+	case RoundUp:
+		return "up"
+	case RoundNearest:
+		return "nearest"
+	}
+	return "exact"
+}
+
+// RoundDecimalUnits converts a signed decimal into integer units with decimals digits after the point, rounded
+// to a multiple of step following mode. Values above max in absolute value once rounded are rejected.
+func RoundDecimalUnits(value string, decimals uint8, step int64, max int64, mode RoundingMode) (int64, error) {
+	d, err := ParseDecimal(value)
+	if err != nil {
+		return 0, err
+	}
+	return d.Units(decimals, step, max, mode)
+}
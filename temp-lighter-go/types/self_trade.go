@@ -0,0 +1,38 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// SelfTradeError is returned for an order which would match a resting order of the same account.
+type SelfTradeError struct {
+	MarketIndex uint8
+	Price       uint32
+	IsAsk       uint8
+	// Resting is the own order which would be matched.
+	Resting *txtypes.OrderInfo
+}
+
+func (e *SelfTradeError) Error() string {
+	side := "buy"
+	if e.IsAsk == 1 {
+		side = "sell"
+	}
+	return fmt.Sprintf("%s on market %d at %d would match the own resting order %d at %d", side, e.MarketIndex, e.Price, e.Resting.ClientOrderIndex, e.Resting.Price)
+}
+
+// CheckSelfTrade checks order against the resting orders of the same account on its market: a buy must be
+// priced below every own ask, a sell above every own bid.
+func CheckSelfTrade(order *txtypes.OrderInfo, resting []*txtypes.OrderInfo) error {
+	for _, r := range resting {
+		if r.MarketIndex != order.MarketIndex || r.IsAsk == order.IsAsk {
+			continue
+		}
+		if (order.IsAsk == 0 && order.Price >= r.Price) || (order.IsAsk == 1 && order.Price <= r.Price) {
+			return &SelfTradeError{MarketIndex: order.MarketIndex, Price: order.Price, IsAsk: order.IsAsk, Resting: r}
+		}
+	}
+	return nil
+}
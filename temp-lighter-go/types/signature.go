@@ -0,0 +1,104 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+)
+
+// AttachSignature sets the signature of a tx built by one of the Convert functions and records msgHash as its
+// signed hash, the same way the Construct functions do when signing directly.
+func AttachSignature(tx txtypes.TxInfo, msgHash, sig []byte) error {
+	signedHash := ethCommon.Bytes2Hex(msgHash)
+
+	switch tx := tx.(type) {
+	case *txtypes.L2ChangePubKeyTxInfo:
+		tx.Sig, tx.SignedHash = sig, signedHash
+	case *txtypes.L2CreateSubAccountTxInfo:
+		tx.Sig, tx.SignedHash = sig, signedHash
+	case *txtypes.L2CreatePublicPoolTxInfo:
+		tx.Sig, tx.SignedHash = sig, signedHash
+	case *txtypes.L2UpdatePublicPoolTxInfo:
+		tx.Sig, tx.SignedHash = sig, signedHash
+	case *txtypes.L2TransferTxInfo:
+		tx.Sig, tx.SignedHash = sig, signedHash
+	case *txtypes.L2WithdrawTxInfo:
+		tx.Sig, tx.SignedHash = sig, signedHash
+	case *txtypes.L2CreateOrderTxInfo:
+		tx.Sig, tx.SignedHash = sig, signedHash
+	case *txtypes.L2CreateGroupedOrdersTxInfo:
+		tx.Sig, tx.SignedHash = sig, signedHash
+	case *txtypes.L2CancelOrderTxInfo:
+		tx.Sig, tx.SignedHash = sig, signedHash
+	case *txtypes.L2ModifyOrderTxInfo:
+		tx.Sig, tx.SignedHash = sig, signedHash
+	case *txtypes.L2CancelAllOrdersTxInfo:
+		tx.Sig, tx.SignedHash = sig, signedHash
+	case *txtypes.L2MintSharesTxInfo:
+		tx.Sig, tx.SignedHash = sig, signedHash
+	case *txtypes.L2BurnSharesTxInfo:
+		tx.Sig, tx.SignedHash = sig, signedHash
+	case *txtypes.L2UpdateLeverageTxInfo:
+		tx.Sig, tx.SignedHash = sig, signedHash
+	case *txtypes.L2UpdateMarginTxInfo:
+		tx.Sig, tx.SignedHash = sig, signedHash
+	default:
+		return fmt.Errorf("unsupported tx type: %d", tx.GetTxType())
+	}
+
+	return nil
+}
+
+// SignatureOf returns the signature attached to tx, or nil when it is unsigned or of a type without signature.
+func SignatureOf(tx txtypes.TxInfo) []byte {
+	switch tx := tx.(type) {
+	case *txtypes.L2ChangePubKeyTxInfo:
+		return tx.Sig
+	case *txtypes.L2CreateSubAccountTxInfo:
+		return tx.Sig
+	case *txtypes.L2CreatePublicPoolTxInfo:
+		return tx.Sig
+	case *txtypes.L2UpdatePublicPoolTxInfo:
+		return tx.Sig
+	case *txtypes.L2TransferTxInfo:
+		return tx.Sig
+	case *txtypes.L2WithdrawTxInfo:
+		return tx.Sig
+	case *txtypes.L2CreateOrderTxInfo:
+		return tx.Sig
+	case *txtypes.L2CreateGroupedOrdersTxInfo:
+		return tx.Sig
+	case *txtypes.L2CancelOrderTxInfo:
+		return tx.Sig
+	case *txtypes.L2ModifyOrderTxInfo:
+		return tx.Sig
+	case *txtypes.L2CancelAllOrdersTxInfo:
+		return tx.Sig
+	case *txtypes.L2MintSharesTxInfo:
+		return tx.Sig
+	case *txtypes.L2BurnSharesTxInfo:
+		return tx.Sig
+	case *txtypes.L2UpdateLeverageTxInfo:
+		return tx.Sig
+	case *txtypes.L2UpdateMarginTxInfo:
+		return tx.Sig
+	default:
+		return nil
+	}
+}
+
+// VerifyTxSignature checks that the signature attached to tx was made with the key of pubKey over the hash of tx,
+// recomputed from its fields for chainId.
+func VerifyTxSignature(tx txtypes.TxInfo, chainId uint32, pubKey [40]byte) error {
+	sig := SignatureOf(tx)
+	if len(sig) == 0 {
+		return fmt.Errorf("tx is not signed")
+	}
+	msgHash, err := tx.Hash(chainId)
+	if err != nil {
+		return fmt.Errorf("failed to hash tx: %w", err)
+	}
+	return schnorr.Validate(pubKey[:], msgHash, sig)
+}
@@ -6,7 +6,6 @@ import (
 
 	"github.com/elliottech/lighter-go/signer"
 	"github.com/elliottech/lighter-go/types/txtypes"
-	g "github.com/elliottech/poseidon_crypto/field/goldilocks"
 	gFp5 "github.com/elliottech/poseidon_crypto/field/goldilocks_quintic_extension"
 	p2 "github.com/elliottech/poseidon_crypto/hash/poseidon2_goldilocks"
 	ethCommon "github.com/ethereum/go-ethereum/common"
@@ -117,20 +116,7 @@ func ConstructAuthToken(key signer.Signer, deadline time.Time, ops *TransactOpts
 	}
 	message := fmt.Sprintf("%v:%v:%v", deadline.Unix(), *ops.FromAccountIndex, *ops.ApiKeyIndex)
 
-	msgInField, err := g.ArrayFromCanonicalLittleEndianBytes([]byte(message))
-	if err != nil {
-		return "", fmt.Errorf("failed to convert bytes to field element. message: %s, error: %w", message, err)
-	}
-
-	msgHash := p2.HashToQuinticExtension(msgInField).ToLittleEndianBytes()
-
-	signatureBytes, err := key.Sign(msgHash, p2.NewPoseidon2())
-	if err != nil {
-		return "", err
-	}
-	signature := ethCommon.Bytes2Hex(signatureBytes)
-
-	return fmt.Sprintf("%v:%v", message, signature), err
+	return signAuthMessage(key, message)
 }
 
 func ConstructChangePubKeyTx(key signer.Signer, lighterChainId uint32, tx *ChangePubKeyReq, ops *TransactOpts) (*txtypes.L2ChangePubKeyTxInfo, error) {
@@ -641,6 +627,7 @@ func ConvertUpdateLeverageTx(tx *UpdateLeverageTxReq, ops *TransactOpts) *txtype
 		ApiKeyIndex:           *ops.ApiKeyIndex,
 		MarketIndex:           tx.MarketIndex,
 		InitialMarginFraction: tx.InitialMarginFraction,
+		MarginMode:            tx.MarginMode,
 		ExpiredAt:             ops.ExpiredAt,
 		Nonce:                 *ops.Nonce,
 	}
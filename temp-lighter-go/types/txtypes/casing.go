@@ -0,0 +1,130 @@
+package txtypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// KeyCasing selects how the keys of a txInfo are spelled.
+type KeyCasing uint8
+
+const (
+	// PascalCase keeps the Go field names, e.g. ApiKeyIndex. It is the default.
+	PascalCase KeyCasing = iota
+	// CamelCase spells keys like apiKeyIndex.
+	CamelCase
+	// SnakeCase spells keys like api_key_index, as the REST and WS APIs do.
+	SnakeCase
+)
+
+func ParseKeyCasing(s string) (KeyCasing, error) {
+	switch s {
+	case "pascal", "":
+		return PascalCase, nil
+	case "camel":
+		return CamelCase, nil
+	case "snake":
+		return SnakeCase, nil
+	default:
+		return PascalCase, fmt.Errorf("unknown key casing: %s", s)
+	}
+}
+
+func (c KeyCasing) String() string {
+	switch c {
+	case CamelCase:
+		return "camel"
+	case SnakeCase:
+		return "snake"
+	default:
+		return "pascal"
+	}
+}
+
+// splitWords splits a PascalCase identifier into its words, keeping acronyms and trailing digits together:
+// USDCAmount gives USDC, Amount and L1Sig gives L1, Sig.
+func splitWords(s string) []string {
+	runes := []rune(s)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		boundary := unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+			(unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])))
+		if boundary {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
+
+// ConvertKey spells a Go field name in casing.
+func ConvertKey(key string, casing KeyCasing) string {
+	if casing == PascalCase {
+		return key
+	}
+
+	words := splitWords(key)
+	for i, w := range words {
+		w = strings.ToLower(w)
+		if casing == CamelCase && i > 0 {
+			w = strings.ToUpper(w[:1]) + w[1:]
+		}
+		words[i] = w
+	}
+	if casing == SnakeCase {
+		return strings.Join(words, "_")
+	}
+	return strings.Join(words, "")
+}
+
+// ConvertJSONKeys respells every object key of a JSON document in casing and returns it in canonical form.
+func ConvertJSONKeys(data []byte, casing KeyCasing) (string, error) {
+	if casing == PascalCase {
+		return CanonicalizeJSON(data)
+	}
+	return mapJSONKeys(data, func(key string) string { return ConvertKey(key, casing) })
+}
+
+// FoldJSONKeys drops the underscores of every object key of a JSON document. encoding/json matches keys to field
+// names case-insensitively, so the result decodes the same whichever casing the keys were spelled in.
+func FoldJSONKeys(data []byte) (string, error) {
+	return mapJSONKeys(data, func(key string) string { return strings.ReplaceAll(key, "_", "") })
+}
+
+func mapJSONKeys(data []byte, fn func(string) string) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+
+	converted, err := json.Marshal(convertKeys(v, fn))
+	if err != nil {
+		return "", err
+	}
+	return CanonicalizeJSON(converted)
+}
+
+func convertKeys(v interface{}, fn func(string) string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			res[fn(k)] = convertKeys(val, fn)
+		}
+		return res
+	case []interface{}:
+		for i := range v {
+			v[i] = convertKeys(v[i], fn)
+		}
+		return v
+	default:
+		return v
+	}
+}
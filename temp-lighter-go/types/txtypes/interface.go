@@ -5,6 +5,7 @@ import g "github.com/elliottech/poseidon_crypto/field/goldilocks"
 type TxInfo interface {
 	GetTxType() uint8
 
+	// GetTxInfo returns the tx encoded as canonical JSON, see CanonicalizeJSON.
 	GetTxInfo() (string, error)
 
 	// GetTxHash returns the hash that was signed when creating this transaction.
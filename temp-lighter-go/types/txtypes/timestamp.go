@@ -0,0 +1,53 @@
+package txtypes
+
+import (
+	"fmt"
+	"time"
+)
+
+var (
+	// MinPlausibleTimestamp and MaxPlausibleTimestamp bound, in milliseconds, the timestamps a client is
+	// expected to sign. Anything outside almost always comes from mixing up seconds and milliseconds.
+	MinPlausibleTimestamp = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	MaxPlausibleTimestamp = time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+)
+
+// TimestampError reports a timestamp outside the plausible range.
+type TimestampError struct {
+	Name  string
+	Value int64
+}
+
+func (e *TimestampError) Error() string {
+	hint := ""
+	switch {
+	case e.Value*1000 >= MinPlausibleTimestamp && e.Value*1000 <= MaxPlausibleTimestamp:
+		hint = ", it looks like seconds instead of milliseconds"
+	case e.Value/1000 >= MinPlausibleTimestamp && e.Value/1000 <= MaxPlausibleTimestamp:
+		hint = ", it looks like microseconds instead of milliseconds"
+	}
+	return fmt.Sprintf("%s %d is in the year %d%s", e.Name, e.Value, time.UnixMilli(e.Value).UTC().Year(), hint)
+}
+
+// CheckTimestamp returns a *TimestampError when ms, a timestamp in milliseconds, is outside
+// [MinPlausibleTimestamp, MaxPlausibleTimestamp].
+func CheckTimestamp(name string, ms int64) error {
+	if ms < MinPlausibleTimestamp || ms > MaxPlausibleTimestamp {
+		return &TimestampError{Name: name, Value: ms}
+	}
+	return nil
+}
+
+// NormalizeTimestamp converts a timestamp given in seconds or microseconds to milliseconds when that brings it
+// into the plausible range. normalized reports whether it was converted.
+func NormalizeTimestamp(name string, v int64) (ms int64, normalized bool, err error) {
+	if CheckTimestamp(name, v) == nil {
+		return v, false, nil
+	}
+	for _, candidate := range []int64{v * 1000, v / 1000} {
+		if CheckTimestamp(name, candidate) == nil {
+			return candidate, true, nil
+		}
+	}
+	return v, false, &TimestampError{Name: name, Value: v}
+}
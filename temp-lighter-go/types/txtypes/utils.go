@@ -1,6 +1,12 @@
 package txtypes
 
-import "encoding/json"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
 
 func IsValidPubKey(bytes []byte) bool {
 	if len(bytes) != 40 {
@@ -24,5 +30,29 @@ func getTxInfo(tx interface{}) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return string(txInfoBytes), nil
+	return CanonicalizeJSON(txInfoBytes)
+}
+
+// CanonicalizeJSON re-encodes a JSON document in the canonical form used for every txInfo: object keys sorted
+// lexicographically at every level, no insignificant whitespace, no HTML escaping and numbers kept exactly as
+// written. The result only depends on the values, not on struct field order, so it is stable across builds.
+func CanonicalizeJSON(data []byte) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+	// More misses a stray closing delimiter, Token reports it.
+	if _, err := dec.Token(); err != io.EOF {
+		return "", fmt.Errorf("unexpected data after the JSON document")
+	}
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
 }
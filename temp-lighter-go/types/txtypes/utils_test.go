@@ -0,0 +1,174 @@
+package txtypes
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+var (
+	goldenSig  = []byte{0x01, 0x02, 0xfe, 0xff}
+	goldenMemo = [32]byte{'r', 'e', 'n', 't', '<', '&', '>'}
+	goldenKey  = []byte{0: 0xab, 39: 0xcd}
+)
+
+func goldenOrder(clientOrderIndex int64) *OrderInfo {
+	return &OrderInfo{
+		MarketIndex:      1,
+		ClientOrderIndex: clientOrderIndex,
+		BaseAmount:       1000,
+		Price:            250000,
+		IsAsk:            1,
+		Type:             LimitOrder,
+		TimeInForce:      GoodTillTime,
+		ReduceOnly:       0,
+		TriggerPrice:     0,
+		OrderExpiry:      1767225600000,
+	}
+}
+
+// goldenTxs holds one tx of every type, as signed, and its txInfo. Any change to these bytes changes what is
+// submitted to the exchange.
+var goldenTxs = []struct {
+	tx     TxInfo
+	txInfo string
+}{
+	{
+		&L2ChangePubKeyTxInfo{AccountIndex: 5, ApiKeyIndex: 2, PubKey: goldenKey, L1Sig: "0xl1sig", ExpiredAt: 1767225600000, Nonce: 7, Sig: goldenSig},
+		`{"AccountIndex":5,"ApiKeyIndex":2,"ExpiredAt":1767225600000,"L1Sig":"0xl1sig","Nonce":7,"PubKey":"qwAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAzQ==","Sig":"AQL+/w=="}`,
+	},
+	{
+		&L2CreateSubAccountTxInfo{AccountIndex: 5, ApiKeyIndex: 2, ExpiredAt: 1767225600000, Nonce: 7, Sig: goldenSig},
+		`{"AccountIndex":5,"ApiKeyIndex":2,"ExpiredAt":1767225600000,"Nonce":7,"Sig":"AQL+/w=="}`,
+	},
+	{
+		&L2CreatePublicPoolTxInfo{AccountIndex: 5, ApiKeyIndex: 2, OperatorFee: 1000, InitialTotalShares: 100000, MinOperatorShareRate: 500, ExpiredAt: 1767225600000, Nonce: 7, Sig: goldenSig},
+		`{"AccountIndex":5,"ApiKeyIndex":2,"ExpiredAt":1767225600000,"InitialTotalShares":100000,"MinOperatorShareRate":500,"Nonce":7,"OperatorFee":1000,"Sig":"AQL+/w=="}`,
+	},
+	{
+		&L2UpdatePublicPoolTxInfo{AccountIndex: 5, ApiKeyIndex: 2, PublicPoolIndex: 300, Status: 1, OperatorFee: 1000, MinOperatorShareRate: 500, ExpiredAt: 1767225600000, Nonce: 7, Sig: goldenSig},
+		`{"AccountIndex":5,"ApiKeyIndex":2,"ExpiredAt":1767225600000,"MinOperatorShareRate":500,"Nonce":7,"OperatorFee":1000,"PublicPoolIndex":300,"Sig":"AQL+/w==","Status":1}`,
+	},
+	{
+		&L2TransferTxInfo{FromAccountIndex: 5, ApiKeyIndex: 2, ToAccountIndex: 7, USDCAmount: 1000000, Fee: 10, Memo: goldenMemo, ExpiredAt: 1767225600000, Nonce: 7, Sig: goldenSig},
+		`{"ApiKeyIndex":2,"ExpiredAt":1767225600000,"Fee":10,"FromAccountIndex":5,"Memo":[114,101,110,116,60,38,62,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0],"Nonce":7,"Sig":"AQL+/w==","ToAccountIndex":7,"USDCAmount":1000000}`,
+	},
+	{
+		&L2WithdrawTxInfo{FromAccountIndex: 5, ApiKeyIndex: 2, USDCAmount: 1 << 62, ExpiredAt: 1767225600000, Nonce: 7, Sig: goldenSig},
+		`{"ApiKeyIndex":2,"ExpiredAt":1767225600000,"FromAccountIndex":5,"Nonce":7,"Sig":"AQL+/w==","USDCAmount":4611686018427387904}`,
+	},
+	{
+		&L2CreateOrderTxInfo{AccountIndex: 5, ApiKeyIndex: 2, OrderInfo: goldenOrder(77), ExpiredAt: 1767225600000, Nonce: 7, Sig: goldenSig},
+		`{"AccountIndex":5,"ApiKeyIndex":2,"BaseAmount":1000,"ClientOrderIndex":77,"ExpiredAt":1767225600000,"IsAsk":1,"MarketIndex":1,"Nonce":7,"OrderExpiry":1767225600000,"Price":250000,"ReduceOnly":0,"Sig":"AQL+/w==","TimeInForce":1,"TriggerPrice":0,"Type":0}`,
+	},
+	{
+		&L2CancelOrderTxInfo{AccountIndex: 5, ApiKeyIndex: 2, MarketIndex: 1, Index: 77, ExpiredAt: 1767225600000, Nonce: 7, Sig: goldenSig},
+		`{"AccountIndex":5,"ApiKeyIndex":2,"ExpiredAt":1767225600000,"Index":77,"MarketIndex":1,"Nonce":7,"Sig":"AQL+/w=="}`,
+	},
+	{
+		&L2CancelAllOrdersTxInfo{AccountIndex: 5, ApiKeyIndex: 2, TimeInForce: 1, Time: 1767225600000, ExpiredAt: 1767225600000, Nonce: 7, Sig: goldenSig},
+		`{"AccountIndex":5,"ApiKeyIndex":2,"ExpiredAt":1767225600000,"Nonce":7,"Sig":"AQL+/w==","Time":1767225600000,"TimeInForce":1}`,
+	},
+	{
+		&L2ModifyOrderTxInfo{AccountIndex: 5, ApiKeyIndex: 2, MarketIndex: 1, Index: 77, BaseAmount: 2000, Price: 251000, TriggerPrice: 0, ExpiredAt: 1767225600000, Nonce: 7, Sig: goldenSig},
+		`{"AccountIndex":5,"ApiKeyIndex":2,"BaseAmount":2000,"ExpiredAt":1767225600000,"Index":77,"MarketIndex":1,"Nonce":7,"Price":251000,"Sig":"AQL+/w==","TriggerPrice":0}`,
+	},
+	{
+		&L2MintSharesTxInfo{AccountIndex: 5, ApiKeyIndex: 2, PublicPoolIndex: 300, ShareAmount: 100, ExpiredAt: 1767225600000, Nonce: 7, Sig: goldenSig},
+		`{"AccountIndex":5,"ApiKeyIndex":2,"ExpiredAt":1767225600000,"Nonce":7,"PublicPoolIndex":300,"ShareAmount":100,"Sig":"AQL+/w=="}`,
+	},
+	{
+		&L2BurnSharesTxInfo{AccountIndex: 5, ApiKeyIndex: 2, PublicPoolIndex: 300, ShareAmount: 100, ExpiredAt: 1767225600000, Nonce: 7, Sig: goldenSig},
+		`{"AccountIndex":5,"ApiKeyIndex":2,"ExpiredAt":1767225600000,"Nonce":7,"PublicPoolIndex":300,"ShareAmount":100,"Sig":"AQL+/w=="}`,
+	},
+	{
+		&L2UpdateLeverageTxInfo{AccountIndex: 5, ApiKeyIndex: 2, MarketIndex: 1, InitialMarginFraction: 500, MarginMode: 1, ExpiredAt: 1767225600000, Nonce: 7, Sig: goldenSig},
+		`{"AccountIndex":5,"ApiKeyIndex":2,"ExpiredAt":1767225600000,"InitialMarginFraction":500,"MarginMode":1,"MarketIndex":1,"Nonce":7,"Sig":"AQL+/w=="}`,
+	},
+	{
+		&L2CreateGroupedOrdersTxInfo{AccountIndex: 5, ApiKeyIndex: 2, GroupingType: 1, Orders: []*OrderInfo{goldenOrder(77), goldenOrder(78)}, ExpiredAt: 1767225600000, Nonce: 7, Sig: goldenSig},
+		`{"AccountIndex":5,"ApiKeyIndex":2,"ExpiredAt":1767225600000,"GroupingType":1,"Nonce":7,"Orders":[{"BaseAmount":1000,"ClientOrderIndex":77,"IsAsk":1,"MarketIndex":1,"OrderExpiry":1767225600000,"Price":250000,"ReduceOnly":0,"TimeInForce":1,"TriggerPrice":0,"Type":0},{"BaseAmount":1000,"ClientOrderIndex":78,"IsAsk":1,"MarketIndex":1,"OrderExpiry":1767225600000,"Price":250000,"ReduceOnly":0,"TimeInForce":1,"TriggerPrice":0,"Type":0}],"Sig":"AQL+/w=="}`,
+	},
+	{
+		&L2UpdateMarginTxInfo{AccountIndex: 5, ApiKeyIndex: 2, MarketIndex: 1, USDCAmount: 1000000, Direction: 1, ExpiredAt: 1767225600000, Nonce: 7, Sig: goldenSig},
+		`{"AccountIndex":5,"ApiKeyIndex":2,"Direction":1,"ExpiredAt":1767225600000,"MarketIndex":1,"Nonce":7,"Sig":"AQL+/w==","USDCAmount":1000000}`,
+	},
+}
+
+func TestCanonicalizeJSON(t *testing.T) {
+	for _, c := range []struct {
+		name, in, out string
+	}{
+		{"sorts keys", `{"b":1,"a":2,"C":3}`, `{"C":3,"a":2,"b":1}`},
+		{"sorts nested keys", `{"z":{"y":1,"x":[{"b":1,"a":2}]},"a":null}`, `{"a":null,"z":{"x":[{"a":2,"b":1}],"y":1}}`},
+		{"drops whitespace", "{ \"a\" :\t[ 1 , 2 ]\n}", `{"a":[1,2]}`},
+		{"keeps large integers", `{"a":9223372036854775807,"b":-9223372036854775808,"c":18446744073709551615}`, `{"a":9223372036854775807,"b":-9223372036854775808,"c":18446744073709551615}`},
+		{"keeps integers past float64 precision", `{"a":9007199254740993}`, `{"a":9007199254740993}`},
+		{"keeps numbers as written", `{"a":1.50,"b":1e3}`, `{"a":1.50,"b":1e3}`},
+		{"does not escape HTML", `{"a":"<b>&amp;</b>"}`, `{"a":"<b>&amp;</b>"}`},
+		{"does not escape HTML written as escapes", `{"a":"\u003cb\u003e\u0026"}`, `{"a":"<b>&"}`},
+		{"keeps other strings", `{"a":"line\nbreak \"quoted\" é"}`, `{"a":"line\nbreak \"quoted\" é"}`},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := CanonicalizeJSON([]byte(c.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.out {
+				t.Errorf("got %s, expected %s", got, c.out)
+			}
+			again, err := CanonicalizeJSON([]byte(got))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again != got {
+				t.Errorf("not idempotent: %s became %s", got, again)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeJSONRejectsInvalidDocuments(t *testing.T) {
+	for _, in := range []string{``, `{`, `{"a":1} {"b":2}`, `{"a":1}]`, `{"a":01}`} {
+		if got, err := CanonicalizeJSON([]byte(in)); err == nil {
+			t.Errorf("%q was canonicalized to %s", in, got)
+		}
+	}
+}
+
+func TestTxInfoGolden(t *testing.T) {
+	seen := map[uint8]bool{}
+	for _, c := range goldenTxs {
+		txType := c.tx.GetTxType()
+		seen[txType] = true
+		got, err := c.tx.GetTxInfo()
+		if err != nil {
+			t.Fatalf("tx type %d: %v", txType, err)
+		}
+		if got != c.txInfo {
+			t.Errorf("tx type %d:\ngot      %s\nexpected %s", txType, got, c.txInfo)
+		}
+		again, err := CanonicalizeJSON([]byte(got))
+		if err != nil {
+			t.Fatalf("tx type %d: %v", txType, err)
+		}
+		if again != got {
+			t.Errorf("tx type %d: txInfo is not canonical, %s became %s", txType, got, again)
+		}
+
+		// Re-encoding the decoded txInfo, in whatever field order, gives back the same bytes.
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(got), &fields); err != nil {
+			t.Fatal(err)
+		}
+		reencoded, err := json.Marshal(fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if canonical, err := CanonicalizeJSON(reencoded); err != nil || canonical != got {
+			t.Errorf("tx type %d: re-encoding gives %s (%v)", txType, canonical, err)
+		}
+	}
+	if len(seen) != len(goldenTxs) {
+		t.Errorf("goldenTxs repeats a tx type")
+	}
+}
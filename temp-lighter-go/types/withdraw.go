@@ -0,0 +1,23 @@
+package types
+
+import "fmt"
+
+// WithdrawalFeeError is returned for a withdrawal prepared with a fee too far from the one quoted by the exchange.
+type WithdrawalFeeError struct {
+	Fee       int64
+	Quoted    int64
+	Tolerance int64
+}
+
+func (e *WithdrawalFeeError) Error() string {
+	return fmt.Sprintf("withdrawal fee %d differs from the quoted fee %d by more than %d", e.Fee, e.Quoted, e.Tolerance)
+}
+
+// CheckWithdrawalFee checks that fee, in USDC units, is within toleranceBps of the quoted fee.
+func CheckWithdrawalFee(fee, quoted int64, toleranceBps uint32) error {
+	tolerance := quoted * int64(toleranceBps) / 10_000
+	if fee < quoted-tolerance || fee > quoted+tolerance {
+		return &WithdrawalFeeError{Fee: fee, Quoted: quoted, Tolerance: tolerance}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+const (
+	// maxAddressBookEntries bounds the address book.
+	maxAddressBookEntries = 1000
+	maxAddressLabelLength = 64
+)
+
+// addressBook maps the labels of the transfer destinations the host saved to their account index, guarded by
+// stateMu. It is persisted through ExportSession.
+var addressBook = map[string]int64{}
+
+// checkAddressLabel trims label and checks it is usable.
+func checkAddressLabel(label string) (string, error) {
+	label = strings.TrimSpace(label)
+	if label == "" || len(label) > maxAddressLabelLength {
+		return "", fmt.Errorf("label should be between 1 and %d characters", maxAddressLabelLength)
+	}
+	return label, nil
+}
+
+// checkAddressBook checks every entry of book, as restored from a session.
+func checkAddressBook(book map[string]int64) error {
+	if len(book) > maxAddressBookEntries {
+		return fmt.Errorf("address book holds more than %d entries", maxAddressBookEntries)
+	}
+	for label, accountIndex := range book {
+		if trimmed, err := checkAddressLabel(label); err != nil || trimmed != label {
+			return fmt.Errorf("invalid address book label %q", label)
+		}
+		if accountIndex < txtypes.MinAccountIndex || accountIndex > txtypes.MaxAccountIndex {
+			return fmt.Errorf("invalid account index %d of address book label %q", accountIndex, label)
+		}
+	}
+	return nil
+}
+
+// SetAddressBookEntry saves accountIndex under label, replacing the account previously saved under it.
+func SetAddressBookEntry(label string, accountIndex int64) error {
+	label, err := checkAddressLabel(label)
+	if err != nil {
+		return err
+	}
+	if accountIndex < txtypes.MinAccountIndex || accountIndex > txtypes.MaxAccountIndex {
+		return fmt.Errorf("accountIndex should be an integer between %d and %d", txtypes.MinAccountIndex, txtypes.MaxAccountIndex)
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if _, ok := addressBook[label]; !ok && len(addressBook) >= maxAddressBookEntries {
+		return fmt.Errorf("address book is full (%d entries)", maxAddressBookEntries)
+	}
+	addressBook[label] = accountIndex
+	return nil
+}
+
+// ResolveAddressLabel returns the account saved under label.
+func ResolveAddressLabel(label string) (int64, error) {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	accountIndex, ok := addressBook[strings.TrimSpace(label)]
+	if !ok {
+		return 0, fmt.Errorf("no account saved under label %q", label)
+	}
+	return accountIndex, nil
+}
+
+// inAddressBook reports whether accountIndex is saved under some label. Policies restricted to the address book
+// consult it.
+func inAddressBook(accountIndex int64) bool {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	for _, a := range addressBook {
+		if a == accountIndex {
+			return true
+		}
+	}
+	return false
+}
+
+// destinationArg reads the destination account of a transfer: an account index, or the label it is saved under
+// in the address book.
+func destinationArg(name string, v js.Value) (int64, error) {
+	if v.Type() == js.TypeString {
+		return ResolveAddressLabel(v.String())
+	}
+	return intArg(name, v, txtypes.MinAccountIndex, txtypes.MaxAccountIndex)
+}
+
+func addressBookResult() []any {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	labels := make([]string, 0, len(addressBook))
+	for label := range addressBook {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	entries := make([]any, 0, len(labels))
+	for _, label := range labels {
+		entries = append(entries, map[string]any{"label": label, "accountIndex": addressBook[label]})
+	}
+	return entries
+}
+
+// jsSetAddressBookEntry expects (label, accountIndex) and returns {entries}. Transfers accept label in place of
+// the account index.
+func jsSetAddressBookEntry(this js.Value, args []js.Value) any {
+	if len(args) < 2 {
+		return js.ValueOf(map[string]any{"error": "SetAddressBookEntry expects 2 args: label, accountIndex"})
+	}
+	if args[0].Type() != js.TypeString {
+		return js.ValueOf(map[string]any{"error": "label should be a string"})
+	}
+	accountIndex, err := intArg("accountIndex", args[1], txtypes.MinAccountIndex, txtypes.MaxAccountIndex)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	if err := SetAddressBookEntry(args[0].String(), accountIndex); err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"entries": addressBookResult(), "error": ""})
+}
+
+// jsRemoveAddressBookEntry expects (label) and returns {removed}, false when nothing was saved under label.
+func jsRemoveAddressBookEntry(this js.Value, args []js.Value) any {
+	if len(args) < 1 || args[0].Type() != js.TypeString {
+		return js.ValueOf(map[string]any{"error": "RemoveAddressBookEntry expects 1 arg: label"})
+	}
+	label := strings.TrimSpace(args[0].String())
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	_, ok := addressBook[label]
+	delete(addressBook, label)
+	return js.ValueOf(map[string]any{"removed": ok, "error": ""})
+}
+
+// jsGetAddressBook expects () and returns {entries}, the saved {label, accountIndex} sorted by label.
+func jsGetAddressBook(this js.Value, args []js.Value) any {
+	return js.ValueOf(map[string]any{"entries": addressBookResult(), "error": ""})
+}
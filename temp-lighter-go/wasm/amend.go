@@ -0,0 +1,270 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+var amendOrderSchema = objectSchema{
+	"marketIndex":      requiredIntField(0, int64(txtypes.MaxMarketIndex)),
+	"clientOrderIndex": requiredIntField(txtypes.MinClientOrderIndex, txtypes.MaxClientOrderIndex),
+	"nonce":            requiredIntField(txtypes.MinNonce, math.MaxInt64),
+	"baseAmount":       intField(txtypes.MinOrderBaseAmount, txtypes.MaxOrderBaseAmount),
+	"price":            intField(int64(txtypes.MinOrderPrice), int64(txtypes.MaxOrderPrice)),
+	"triggerPrice":     intField(0, int64(txtypes.MaxOrderTriggerPrice)),
+	"isAsk":            intField(0, 1),
+	"timeInForce":      intField(0, 2),
+	"reduceOnly":       intField(0, 1),
+	"orderExpiry":      intField(-1, txtypes.MaxOrderExpiry),
+	"mode":             {Type: "string", MaxLength: 16},
+	"clientIndex":      intField(0, math.MaxInt32),
+	"intentHash":       {Type: "string"},
+}
+
+// amendOrder changes a resting order. Nil fields keep the value of the order being amended.
+type amendOrder struct {
+	MarketIndex      uint8   `json:"marketIndex"`
+	ClientOrderIndex int64   `json:"clientOrderIndex"`
+	Nonce            int64   `json:"nonce"`
+	BaseAmount       *int64  `json:"baseAmount"`
+	Price            *uint32 `json:"price"`
+	TriggerPrice     *uint32 `json:"triggerPrice"`
+	IsAsk            *uint8  `json:"isAsk"`
+	TimeInForce      *uint8  `json:"timeInForce"`
+	ReduceOnly       *uint8  `json:"reduceOnly"`
+	OrderExpiry      *int64  `json:"orderExpiry"`
+	Mode             string  `json:"mode"`
+	ClientIndex      int     `json:"clientIndex"`
+}
+
+// Amend paths, as reported by AmendOrder.
+const (
+	amendModify        = "modify"
+	amendCancelReplace = "cancelReplace"
+)
+
+// modifiable reports whether a modify tx can express a, i.e. a only changes the size, price or trigger price of
+// order. Side, time in force, reduce-only and expiry can only change by replacing the order.
+func (a *amendOrder) modifiable(order *txtypes.OrderInfo) bool {
+	return (a.IsAsk == nil || *a.IsAsk == order.IsAsk) &&
+		(a.TimeInForce == nil || *a.TimeInForce == order.TimeInForce) &&
+		(a.ReduceOnly == nil || *a.ReduceOnly == order.ReduceOnly) &&
+		(a.OrderExpiry == nil || *a.OrderExpiry == order.OrderExpiry)
+}
+
+// keepsTerms reports whether a leaves the side, time in force, reduce-only and expiry of the order unset.
+func (a *amendOrder) keepsTerms() bool {
+	return a.IsAsk == nil && a.TimeInForce == nil && a.ReduceOnly == nil && a.OrderExpiry == nil
+}
+
+// trackedOrderByClientIndex returns a copy of the resting order of accountIndex on marketIndex with
+// clientOrderIndex, or nil when the module does not know it.
+func trackedOrderByClientIndex(accountIndex int64, marketIndex uint8, clientOrderIndex int64) *txtypes.OrderInfo {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	for _, o := range ownOrders[accountIndex] {
+		if o.MarketIndex == marketIndex && o.ClientOrderIndex == clientOrderIndex {
+			info := *o.OrderInfo
+			return &info
+		}
+	}
+	return nil
+}
+
+// amendPlan is what AmendOrder signs for an amendOrder: a modify tx, or a cancel tx followed by a create tx.
+type amendPlan struct {
+	path   string
+	modify *types.ModifyOrderTxReq
+	create *types.CreateOrderTxReq
+}
+
+// planAmend resolves the path and the txs amending the order of c's account with a.ClientOrderIndex, see
+// AmendOrder.
+func planAmend(c *client.TxClient, a *amendOrder) (*amendPlan, error) {
+	order := trackedOrderByClientIndex(c.GetAccountIndex(), a.MarketIndex, a.ClientOrderIndex)
+
+	var path string
+
+	switch a.Mode {
+	case "", "auto":
+		path = amendModify
+		if order != nil && !a.modifiable(order) {
+			path = amendCancelReplace
+		}
+	case "modify":
+		path = amendModify
+		if order != nil && !a.modifiable(order) {
+			return nil, fmt.Errorf("a modify tx can only change the size, price and trigger price of an order")
+		}
+	case "replace":
+		path = amendCancelReplace
+	default:
+		return nil, fmt.Errorf("unknown mode %q, expected auto, modify or replace", a.Mode)
+	}
+	if order == nil {
+		if path == amendCancelReplace || a.BaseAmount == nil || a.Price == nil || !a.keepsTerms() {
+			return nil, fmt.Errorf("order %d on market %d is not tracked: pass its baseAmount and price and only change its size, price or trigger price, or apply the open orders first", a.ClientOrderIndex, a.MarketIndex)
+		}
+		order = &txtypes.OrderInfo{MarketIndex: a.MarketIndex, ClientOrderIndex: a.ClientOrderIndex, TriggerPrice: txtypes.NilOrderTriggerPrice}
+	}
+	baseAmount, price, triggerPrice := derefOr(a.BaseAmount, order.BaseAmount), derefOr(a.Price, order.Price), derefOr(a.TriggerPrice, order.TriggerPrice)
+
+	if path == amendModify {
+		return &amendPlan{path: path, modify: &types.ModifyOrderTxReq{
+			MarketIndex:  a.MarketIndex,
+			Index:        a.ClientOrderIndex,
+			BaseAmount:   baseAmount,
+			Price:        price,
+			TriggerPrice: triggerPrice,
+		}}, nil
+	}
+	return &amendPlan{path: path, create: &types.CreateOrderTxReq{
+		MarketIndex:      a.MarketIndex,
+		ClientOrderIndex: a.ClientOrderIndex,
+		BaseAmount:       baseAmount,
+		Price:            price,
+		IsAsk:            derefOr(a.IsAsk, order.IsAsk),
+		Type:             order.Type,
+		TimeInForce:      derefOr(a.TimeInForce, order.TimeInForce),
+		ReduceOnly:       derefOr(a.ReduceOnly, order.ReduceOnly),
+		TriggerPrice:     triggerPrice,
+		OrderExpiry:      derefOr(a.OrderExpiry, order.OrderExpiry),
+	}}, nil
+}
+
+// amendOps returns the TransactOpts of the amend tx signed with nonce by c.
+func amendOps(c *client.TxClient, nonce int64) *types.TransactOpts {
+	fromAcc, apiIdx := c.GetAccountIndex(), c.GetApiKeyIndex()
+	return &types.TransactOpts{FromAccountIndex: &fromAcc, ApiKeyIndex: &apiIdx, Nonce: &nonce}
+}
+
+// AmendOrder changes the order of c's account with a.ClientOrderIndex, keeping its client order index. In mode
+// "auto", the default, it signs a modify tx when one can express the change and a cancel tx followed by a create
+// tx otherwise; "modify" and "replace" force a path. The txs use consecutive nonces from a.Nonce and must be
+// submitted in order. Replacing, and modifying without giving baseAmount and price, need the order to be tracked,
+// see ApplyOpenOrders.
+func AmendOrder(c *client.TxClient, a *amendOrder) (path string, txInfos []string, err error) {
+	plan, err := planAmend(c, a)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var txs []txtypes.TxInfo
+	if plan.modify != nil {
+		tx, err := c.GetModifyOrderTransaction(plan.modify, amendOps(c, a.Nonce))
+		if err != nil {
+			return "", nil, err
+		}
+		txs = append(txs, tx)
+	} else {
+		cancel, err := c.GetCancelOrderTransaction(&types.CancelOrderTxReq{MarketIndex: a.MarketIndex, Index: a.ClientOrderIndex}, amendOps(c, a.Nonce))
+		if err != nil {
+			return "", nil, err
+		}
+		create, err := c.GetCreateOrderTransaction(plan.create, amendOps(c, a.Nonce+1))
+		if err != nil {
+			return "", nil, err
+		}
+		txs = append(txs, cancel, create)
+	}
+
+	for _, tx := range txs {
+		txInfo, err := formatTxInfo(tx)
+		if err != nil {
+			return "", nil, err
+		}
+		txInfos = append(txInfos, txInfo)
+	}
+	return plan.path, txInfos, nil
+}
+
+// AmendIntentHash returns the intent hash AmendOrder checks a's intentHash against: the ModifyIntentHash of the
+// modify tx, or the OrderIntentHash of the replacing order, along with the path it takes.
+func AmendIntentHash(c *client.TxClient, a *amendOrder) (hash, path string, err error) {
+	plan, err := planAmend(c, a)
+	if err != nil {
+		return "", "", err
+	}
+	if plan.modify != nil {
+		return types.ModifyIntentHash(types.ConvertModifyOrderTx(plan.modify, amendOps(c, a.Nonce))), plan.path, nil
+	}
+	create := types.ConvertCreateOrderTx(plan.create, amendOps(c, a.Nonce+1))
+	return types.OrderIntentHash(create.AccountIndex, create.OrderInfo), plan.path, nil
+}
+
+func derefOr[T any](p *T, def T) T {
+	if p == nil {
+		return def
+	}
+	return *p
+}
+
+// jsAmendOrder expects (amend). amend holds marketIndex, clientOrderIndex and nonce, and optionally baseAmount,
+// price, triggerPrice, isAsk, timeInForce, reduceOnly, orderExpiry, mode ("auto", "modify" or "replace"),
+// clientIndex and intentHash, the hash HashAmendIntent returned for the change the user approved, required once
+// RequireOrderIntent was called. Returns {path, txInfos, txTypes, nextNonce}: path is "modify" or
+// "cancelReplace" and txInfos lists the txs to submit in order.
+func jsAmendOrder(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "AmendOrder expects 1 arg: amend"})
+	}
+	a := &amendOrder{}
+	if err := decodeStrict("amend", args[0], amendOrderSchema, a); err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	c, err := getClient(a.ClientIndex)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	if c, err = intentClient(c, args, 0); err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+
+	path, txInfos, err := AmendOrder(c, a)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	txTypes := []any{txtypes.TxTypeL2ModifyOrder}
+	if path == amendCancelReplace {
+		txTypes = []any{txtypes.TxTypeL2CancelOrder, txtypes.TxTypeL2CreateOrder}
+	}
+	infos := make([]any, len(txInfos))
+	for i, txInfo := range txInfos {
+		infos[i] = txInfo
+	}
+	return js.ValueOf(map[string]any{
+		"path":      path,
+		"txInfos":   infos,
+		"txTypes":   txTypes,
+		"nextNonce": a.Nonce + int64(len(txInfos)),
+		"error":     "",
+	})
+}
+
+// jsHashAmendIntent expects the args of AmendOrder and returns {hash, path}. hash covers what the amend changes,
+// see AmendIntentHash; a UI shows it in its confirmation dialog and passes it back as amend.intentHash to
+// AmendOrder, which refuses to sign any other change.
+func jsHashAmendIntent(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "HashAmendIntent expects 1 arg: amend"})
+	}
+	a := &amendOrder{}
+	if err := decodeStrict("amend", args[0], amendOrderSchema, a); err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	c, err := getClient(a.ClientIndex)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+
+	hash, path, err := AmendIntentHash(c, a)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	return js.ValueOf(map[string]any{"hash": hash, "path": path, "error": ""})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/types"
+)
+
+// amountArg reads an amount passed from JS to a signing export. A number is taken as integer units and must be a
+// safe integer; a string is a decimal in whole units, e.g. "12.5" USDC, converted exactly into units of decimals
+// digits. Fractional numbers are refused rather than rounded, so that float artifacts such as 0.1 + 0.2 never
+// reach a signed tx. decimals is only called for strings, and may fail when they are unknown.
+func amountArg(name string, v js.Value, decimals func() (uint8, error), max int64) (int64, error) {
+	switch v.Type() {
+	case js.TypeNumber:
+		if !js.Global().Get("Number").Call("isSafeInteger", v).Bool() {
+			return 0, fmt.Errorf("%s %s is not an integer number of units, pass decimal amounts as strings", name, js.Global().Get("String").Invoke(v).String())
+		}
+		units := int64(v.Int())
+		if units < 0 || units > max {
+			return 0, fmt.Errorf("%s should be between 0 and %d units, got %d", name, max, units)
+		}
+		return units, nil
+	case js.TypeString:
+		d, err := types.ParseDecimal(v.String())
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", name, err)
+		}
+		if d.Sign() < 0 {
+			return 0, fmt.Errorf("%s %s is negative", name, d)
+		}
+		n, err := decimals()
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", name, err)
+		}
+		units, err := d.Units(n, 1, max, types.RoundExact)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", name, err)
+		}
+		return units, nil
+	}
+	return 0, fmt.Errorf("%s should be a number of units or a decimal string, got %s", name, v.Type())
+}
+
+// usdcDecimals gives the decimals of USDC amounts to amountArg.
+func usdcDecimals() (uint8, error) {
+	return types.USDCDecimals, nil
+}
+
+// marketDecimals gives amountArg the price or size decimals of marketIndex, from SetMarketRules or
+// FetchMarketRules.
+func marketDecimals(marketIndex uint8, price bool) func() (uint8, error) {
+	return func() (uint8, error) {
+		stateMu.RLock()
+		rules, ok := marketRules[marketIndex]
+		stateMu.RUnlock()
+		if !ok || !rules.HasDecimals {
+			return 0, fmt.Errorf("decimals of market %d are unknown, call FetchMarketRules or SetMarketRules first", marketIndex)
+		}
+		if price {
+			return rules.PriceDecimals, nil
+		}
+		return rules.SizeDecimals, nil
+	}
+}
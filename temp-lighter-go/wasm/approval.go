@@ -0,0 +1,429 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/signer"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+	p2 "github.com/elliottech/poseidon_crypto/hash/poseidon2_goldilocks"
+	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// txParams are the fields accepted by PrepareTx; each tx type only reads the ones it needs.
+type txParams struct {
+	Nonce     int64 `json:"nonce"`
+	ExpiredAt int64 `json:"expiredAt"`
+
+	ToAccountIndex int64  `json:"toAccountIndex"`
+	USDCAmount     int64  `json:"usdcAmount"`
+	Fee            int64  `json:"fee"`
+	Memo           string `json:"memo"`
+
+	MarketIndex      uint8  `json:"marketIndex"`
+	ClientOrderIndex int64  `json:"clientOrderIndex"`
+	OrderIndex       int64  `json:"orderIndex"`
+	BaseAmount       int64  `json:"baseAmount"`
+	Price            uint32 `json:"price"`
+	IsAsk            uint8  `json:"isAsk"`
+	OrderType        uint8  `json:"orderType"`
+	TimeInForce      uint8  `json:"timeInForce"`
+	ReduceOnly       uint8  `json:"reduceOnly"`
+	TriggerPrice     uint32 `json:"triggerPrice"`
+	OrderExpiry      int64  `json:"orderExpiry"`
+}
+
+// checkTimes applies the timestamp sanity checks to the time fields of p that are set.
+func (p *txParams) checkTimes() error {
+	for _, field := range []struct {
+		name  string
+		value *int64
+	}{{"expiredAt", &p.ExpiredAt}, {"orderExpiry", &p.OrderExpiry}} {
+		if *field.value == 0 || *field.value == -1 {
+			continue
+		}
+		ms, err := checkTimestamp(field.name, *field.value)
+		if err != nil {
+			return err
+		}
+		*field.value = ms
+	}
+	return nil
+}
+
+// txParamsSchema bounds every txParams field by its type; the tx specific checks are left to Validate.
+var txParamsSchema = objectSchema{
+	"nonce":     requiredIntField(-1, math.MaxInt64),
+	"expiredAt": intField(0, math.MaxInt64),
+
+	"toAccountIndex": intField(0, txtypes.MaxAccountIndex),
+	"usdcAmount":     intField(0, math.MaxInt64),
+	"fee":            intField(0, math.MaxInt64),
+	"memo":           {Type: "string", MaxLength: 32},
+
+	"marketIndex":      intField(0, math.MaxUint8),
+	"clientOrderIndex": intField(0, txtypes.MaxClientOrderIndex),
+	"orderIndex":       intField(0, txtypes.MaxOrderIndex),
+	"baseAmount":       intField(0, txtypes.MaxOrderBaseAmount),
+	"price":            intField(0, math.MaxUint32),
+	"isAsk":            intField(0, 1),
+	"orderType":        intField(0, math.MaxUint8),
+	"timeInForce":      intField(0, math.MaxUint8),
+	"reduceOnly":       intField(0, 1),
+	"triggerPrice":     intField(0, math.MaxUint32),
+	"orderExpiry":      intField(-1, math.MaxInt64),
+}
+
+// pendingTx is a prepared tx waiting for its approvals and signature. It is signed with the client registered at
+// clientIndex when it is finalized, which may have been rotated or locked meanwhile, see pendingClient.
+type pendingTx struct {
+	clientIndex int
+	tx          txtypes.TxInfo
+	msgHash     []byte
+	expiredAt   int64
+	signed      bool
+	approvers   map[[40]byte]bool
+	threshold   int
+}
+
+func (p *pendingTx) approvals() int {
+	n := 0
+	for _, approved := range p.approvers {
+		if approved {
+			n++
+		}
+	}
+	return n
+}
+
+// pendingTxs holds the prepared txs by the hex of their hash. FinalizeTx is synchronous, so a pending tx is only
+// ever updated by one call at a time; stateMu guards the map itself. Expired txs are dropped by the next PrepareTx
+// or FinalizeTx, and at most maxPendingTxs are kept.
+var pendingTxs = map[string]*pendingTx{}
+
+// maxPendingTxs bounds pendingTxs, as a tx may be prepared with an expiry far in the future and never finalized.
+const maxPendingTxs = 256
+
+// dropExpiredPendingTxsLocked drops the pending txs which expired before now. The caller holds stateMu.
+func dropExpiredPendingTxsLocked(now int64) {
+	for txId, pending := range pendingTxs {
+		if now > pending.expiredAt {
+			delete(pendingTxs, txId)
+		}
+	}
+}
+
+func memoFromString(memo string) ([32]byte, error) {
+	var res [32]byte
+	if len(memo) > len(res) {
+		return res, fmt.Errorf("memo should not be longer than %d bytes", len(res))
+	}
+	copy(res[:], memo)
+	return res, nil
+}
+
+// buildUnsignedTx converts params into the unsigned tx of txType, filling the transact opts from c.
+func buildUnsignedTx(c *client.TxClient, txType string, params *txParams) (txtypes.TxInfo, *types.TransactOpts, error) {
+	ops, err := c.FullFillDefaultOps(&types.TransactOpts{Nonce: &params.Nonce, ExpiredAt: params.ExpiredAt})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch txType {
+	case "transfer":
+		memo, err := memoFromString(params.Memo)
+		if err != nil {
+			return nil, nil, err
+		}
+		return types.ConvertTransferTx(&types.TransferTxReq{
+			ToAccountIndex: params.ToAccountIndex,
+			USDCAmount:     params.USDCAmount,
+			Fee:            params.Fee,
+			Memo:           memo,
+		}, ops), ops, nil
+	case "withdraw":
+		return types.ConvertWithdrawTx(&types.WithdrawTxReq{USDCAmount: uint64(params.USDCAmount)}, ops), ops, nil
+	case "createOrder":
+		return types.ConvertCreateOrderTx(&types.CreateOrderTxReq{
+			MarketIndex:      params.MarketIndex,
+			ClientOrderIndex: params.ClientOrderIndex,
+			BaseAmount:       params.BaseAmount,
+			Price:            params.Price,
+			IsAsk:            params.IsAsk,
+			Type:             params.OrderType,
+			TimeInForce:      params.TimeInForce,
+			ReduceOnly:       params.ReduceOnly,
+			TriggerPrice:     params.TriggerPrice,
+			OrderExpiry:      params.OrderExpiry,
+		}, ops), ops, nil
+	case "cancelOrder":
+		return types.ConvertCancelOrderTx(&types.CancelOrderTxReq{
+			MarketIndex: params.MarketIndex,
+			Index:       params.OrderIndex,
+		}, ops), ops, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported tx type: %s", txType)
+	}
+}
+
+// PrepareTx builds and validates, with c, an unsigned tx and keeps it until FinalizeTx is called with enough
+// approvals. c is the client registered at clientIndex, or a copy of it for this call. approvers lists the public
+// keys allowed to approve it, threshold how many of them must approve.
+func PrepareTx(c *client.TxClient, clientIndex int, txType string, params *txParams, approvers []string, threshold int) (*pendingTx, string, error) {
+	if threshold < 0 || threshold > len(approvers) {
+		return nil, "", fmt.Errorf("threshold should be between 0 and the number of approvers (%d)", len(approvers))
+	}
+
+	tx, ops, err := buildUnsignedTx(c, txType, params)
+	if err != nil {
+		return nil, "", err
+	}
+	msgHash, err := c.PrepareTx(tx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pending := &pendingTx{
+		clientIndex: clientIndex,
+		tx:          tx,
+		msgHash:     msgHash,
+		expiredAt:   ops.ExpiredAt,
+		approvers:   map[[40]byte]bool{},
+		threshold:   threshold,
+	}
+	for _, approver := range approvers {
+		pubKey, err := parsePublicKey(approver)
+		if err != nil {
+			return nil, "", fmt.Errorf("approver: %w", err)
+		}
+		pending.approvers[pubKey] = false
+	}
+
+	txId := hexutil.Encode(msgHash)
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	dropExpiredPendingTxsLocked(client.Now().UnixMilli())
+	if _, ok := pendingTxs[txId]; !ok && len(pendingTxs) >= maxPendingTxs {
+		return nil, "", fmt.Errorf("%d prepared txs are already pending, finalize them or let them expire", maxPendingTxs)
+	}
+	pendingTxs[txId] = pending
+	return pending, txId, nil
+}
+
+// FinalizeTx records signatures for a prepared tx. A signature is either the tx signature made by the client's
+// key, or an approval made by one of the approvers. Once enough approvals were collected the tx is signed with
+// the client's key, unless its signature was provided, and its txInfo returned.
+func FinalizeTx(txId string, signatures []string) (pending *pendingTx, txInfo string, err error) {
+	stateMu.Lock()
+	pending, ok := pendingTxs[txId]
+	expired := ok && client.Now().UnixMilli() > pending.expiredAt
+	dropExpiredPendingTxsLocked(client.Now().UnixMilli())
+	stateMu.Unlock()
+	if !ok {
+		return nil, "", fmt.Errorf("unknown or already finalized tx: %s", txId)
+	}
+	if expired {
+		return nil, "", fmt.Errorf("prepared tx has expired")
+	}
+	c, err := pendingClient(pending)
+	if err != nil {
+		dropPendingTx(txId)
+		return nil, "", err
+	}
+
+	for i, signature := range signatures {
+		sig, err := hexutil.Decode("0x" + strings.TrimPrefix(signature, "0x"))
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid signature %d: %w", i, err)
+		}
+		if c.FinalizeTx(pending.tx, pending.msgHash, sig) == nil {
+			pending.signed = true
+			continue
+		}
+
+		matched := false
+		for approver := range pending.approvers {
+			if schnorr.Validate(approver[:], pending.msgHash, sig) == nil {
+				pending.approvers[approver] = true
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, "", fmt.Errorf("signature %d matches neither the client key nor an approver", i)
+		}
+	}
+
+	if pending.approvals() < pending.threshold {
+		return pending, "", nil
+	}
+	if !pending.signed {
+		if err := c.SignPreparedTx(pending.tx, pending.msgHash); err != nil {
+			return nil, "", err
+		}
+		pending.signed = true
+	}
+
+	txInfo, err = formatTxInfo(pending.tx)
+	if err != nil {
+		return nil, "", err
+	}
+	dropPendingTx(txId)
+	return pending, txInfo, nil
+}
+
+// pendingClient returns the client pending is signed with: the one registered at its clientIndex now, which has
+// to sign for the account and api key the tx was prepared for. A session revoked or a client replaced since then
+// fails the tx, and a rotated key is replaced by the new one, so no key dropped from the module signs it.
+func pendingClient(pending *pendingTx) (*client.TxClient, error) {
+	c, err := getClient(pending.clientIndex)
+	if err != nil {
+		return nil, fmt.Errorf("the client the tx was prepared with is gone: %w", err)
+	}
+	accountIndex, apiKeyIndex, _, err := types.SenderOf(pending.tx)
+	if err != nil {
+		return nil, err
+	}
+	if !c.ControlsAccount(accountIndex) || c.GetApiKeyIndex() != apiKeyIndex {
+		return nil, fmt.Errorf("client %d no longer signs for account %d with api key %d, the tx was prepared for them", pending.clientIndex, accountIndex, apiKeyIndex)
+	}
+	return c, nil
+}
+
+func dropPendingTx(txId string) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	delete(pendingTxs, txId)
+}
+
+// SignApproval signs a prepared tx hash with an approver's private key.
+func SignApproval(privateKey, txId string) (string, error) {
+	registerSecret(privateKey)
+	keyManager, err := signer.NewKeyManagerFromHex(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid private key: %w", err)
+	}
+	msgHash, err := hexutil.Decode(txId)
+	if err != nil {
+		return "", fmt.Errorf("invalid tx id: %w", err)
+	}
+	sig, err := keyManager.Sign(msgHash, p2.NewPoseidon2())
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(sig), nil
+}
+
+// jsPrepareTx expects (txType, params, options?) where params is an object or a JSON string and options may
+// hold clientIndex, approvers, threshold and allowCross. threshold defaults to the number of approvers.
+func jsPrepareTx(this js.Value, args []js.Value) any {
+	if len(args) < 2 {
+		return js.ValueOf(map[string]any{"error": "PrepareTx expects at least 2 args: txType, params"})
+	}
+
+	if args[0].Type() != js.TypeString {
+		return js.ValueOf(map[string]any{"error": "txType should be a string"})
+	}
+	data, exact, err := readTxRequest(args[1])
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	params, err := parseTxRequest(args[0].String(), data, exact)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+
+	clientIndex := defaultClientIndex
+	var approvers []string
+	threshold := -1
+	if len(args) > 2 && args[2].Type() == js.TypeObject {
+		opts := args[2]
+		if v := opts.Get("clientIndex"); v.Type() == js.TypeNumber {
+			clientIndex = v.Int()
+		}
+		if v := opts.Get("approvers"); v.Type() == js.TypeObject {
+			for i := 0; i < v.Length(); i++ {
+				approvers = append(approvers, v.Index(i).String())
+			}
+		}
+		if v := opts.Get("threshold"); v.Type() == js.TypeNumber {
+			threshold = v.Int()
+		}
+	}
+	if threshold == -1 {
+		threshold = len(approvers)
+	}
+
+	c, err := getClient(clientIndex)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	pending, txId, err := PrepareTx(allowCrossClient(c, args, 2), clientIndex, args[0].String(), params, approvers, threshold)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	payload, err := formatTxInfo(pending.tx)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{
+		"txId":              txId,
+		"txType":            int(pending.tx.GetTxType()),
+		"payload":           payload,
+		"hash":              txId,
+		"requiredApprovals": pending.threshold,
+		"error":             "",
+	})
+}
+
+// jsFinalizeTx expects (txId, signatures) where signatures is a hex string or an array of them.
+func jsFinalizeTx(this js.Value, args []js.Value) any {
+	if len(args) < 2 || args[0].Type() != js.TypeString {
+		return js.ValueOf(map[string]any{"error": "FinalizeTx expects 2 args: txId, signatures"})
+	}
+
+	var signatures []string
+	switch {
+	case args[1].Type() == js.TypeString:
+		signatures = []string{args[1].String()}
+	case js.Global().Get("Array").Call("isArray", args[1]).Bool():
+		for i := 0; i < args[1].Length(); i++ {
+			if args[1].Index(i).Type() != js.TypeString {
+				return js.ValueOf(map[string]any{"error": fmt.Sprintf("signatures[%d] should be a string", i)})
+			}
+			signatures = append(signatures, args[1].Index(i).String())
+		}
+	default:
+		return js.ValueOf(map[string]any{"error": "signatures should be a string or an array of strings"})
+	}
+
+	pending, txInfo, err := FinalizeTx(args[0].String(), signatures)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{
+		"finalized":         txInfo != "",
+		"txInfo":            txInfo,
+		"approvals":         pending.approvals(),
+		"requiredApprovals": pending.threshold,
+		"error":             "",
+	})
+}
+
+// jsSignApproval expects (privateKey, txId).
+func jsSignApproval(this js.Value, args []js.Value) any {
+	if len(args) < 2 {
+		return js.ValueOf(map[string]any{"error": "SignApproval expects 2 args: privateKey, txId"})
+	}
+
+	sig, err := SignApproval(args[0].String(), args[1].String())
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"signature": sig, "error": ""})
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"syscall/js"
+	"testing"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/policy"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+func TestFinalizeTxArgs(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args []any
+	}{
+		{"missing signatures", []any{"0x01"}},
+		{"txId not a string", []any{1, "0x01"}},
+		{"signatures a number", []any{"0x01", 7}},
+		{"signatures an object", []any{"0x01", map[string]any{"length": 2}}},
+		{"signatures null", []any{"0x01", nil}},
+		{"signature not a string", []any{"0x01", []any{"0x01", 2}}},
+		{"unknown tx", []any{"0x01", []any{"0x01"}}},
+	} {
+		args := make([]js.Value, len(tc.args))
+		for i, arg := range tc.args {
+			args[i] = js.ValueOf(arg)
+		}
+		res := js.ValueOf(jsFinalizeTx(js.Undefined(), args))
+		if res.Get("error").String() == "" {
+			t.Errorf("%s: no error", tc.name)
+		}
+	}
+}
+
+// TestPendingTxsBounded checks that expired prepared txs are dropped and that no more than maxPendingTxs are kept.
+func TestPendingTxsBounded(t *testing.T) {
+	km, err := generateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := client.NewTxClientWithKeyManager(nil, km, 5, 2, 300)
+	clientIndex := registerClient(c)
+	defer unregisterClient(clientIndex)
+	prepare := func(nonce int64) (string, error) {
+		_, txId, err := PrepareTx(c, clientIndex, "withdraw", &txParams{Nonce: nonce, USDCAmount: 1000000}, nil, 0)
+		return txId, err
+	}
+	defer func() {
+		stateMu.Lock()
+		pendingTxs = map[string]*pendingTx{}
+		stateMu.Unlock()
+	}()
+
+	stateMu.Lock()
+	pendingTxs["expired"] = &pendingTx{expiredAt: client.Now().UnixMilli() - 1}
+	stateMu.Unlock()
+	txId, err := prepare(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stateMu.RLock()
+	_, kept := pendingTxs["expired"]
+	stateMu.RUnlock()
+	if kept {
+		t.Errorf("an expired tx was kept")
+	}
+	if _, _, err := FinalizeTx(txId, nil); err != nil {
+		t.Errorf("a prepared tx could not be finalized: %v", err)
+	}
+
+	stateMu.Lock()
+	for i := 0; i < maxPendingTxs; i++ {
+		pendingTxs[fmt.Sprint(i)] = &pendingTx{expiredAt: math.MaxInt64}
+	}
+	stateMu.Unlock()
+	if _, err := prepare(2); err == nil {
+		t.Errorf("a tx was prepared beyond %d pending ones", maxPendingTxs)
+	}
+}
+
+// TestFinalizeTxAfterRevocation checks that a tx prepared with a session key is not signed once the session is
+// revoked, and that a tx prepared before a key rotation is signed with the new key.
+func TestFinalizeTxAfterRevocation(t *testing.T) {
+	privateKey, _, errStr := GenerateAPIKey("")
+	if errStr != "" {
+		t.Fatal(errStr)
+	}
+	res := js.ValueOf(jsCreateClient(js.Undefined(), []js.Value{js.ValueOf(privateKey), js.ValueOf(5), js.ValueOf(2), js.ValueOf(300)}))
+	if errStr := res.Get("error").String(); errStr != "" {
+		t.Fatal(errStr)
+	}
+	defer setDefaultClient(nil)
+
+	s, err := CreateSessionKey(4, &policy.Policy{AllowWithdrawals: true}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer setClientPolicy(s.ClientIndex, nil)
+	session, err := getClient(s.ClientIndex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, txId, err := PrepareTx(session, s.ClientIndex, "withdraw", &txParams{Nonce: 1, USDCAmount: 1000000}, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RevokeSessionKey(s.ClientIndex); err != nil {
+		t.Fatal(err)
+	}
+	if _, txInfo, err := FinalizeTx(txId, nil); err == nil {
+		t.Errorf("a tx prepared with a revoked session key was signed: %s", txInfo)
+	}
+
+	primary, _ := getClient(defaultClientIndex)
+	_, txId, err = PrepareTx(primary, defaultClientIndex, "withdraw", &txParams{Nonce: 2, USDCAmount: 1000000}, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dropPendingTx(txId)
+	r, err := RotateAPIKey(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CommitAPIKeyRotation(); err != nil {
+		t.Fatal(err)
+	}
+	_, txInfo, err := FinalizeTx(txId, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := parsePublicKey(r.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := types.DecodeSignedTx(txtypes.TxTypeL2Withdraw, txInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := types.VerifyTxSignature(tx, 300, newKey); err != nil {
+		t.Errorf("the tx prepared before the rotation is not signed with the new key: %v", err)
+	}
+
+	// A client replaced by one signing for another account fails the txs prepared with the former.
+	_, txId, err = PrepareTx(primary, defaultClientIndex, "withdraw", &txParams{Nonce: 4, USDCAmount: 1000000}, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setDefaultClient(primary.WithAccountIndex(6))
+	if _, txInfo, err := FinalizeTx(txId, nil); err == nil {
+		t.Errorf("a tx prepared for account 5 was signed by the client of account 6: %s", txInfo)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+)
+
+var authTokenOptionsSchema = objectSchema{
+	"origin":    {Type: "string", MaxLength: 512},
+	"sessionId": {Type: "string", MaxLength: 512},
+}
+
+// parseAuthTokenOptions reads the optional {origin, sessionId} object passed to the auth token exports.
+func parseAuthTokenOptions(args []js.Value, i int) (*types.AuthTokenOptions, error) {
+	if len(args) <= i || args[i].IsUndefined() || args[i].IsNull() {
+		return nil, nil
+	}
+	var opts struct {
+		Origin    string `json:"origin"`
+		SessionID string `json:"sessionId"`
+	}
+	if err := decodeStrict("auth token options", args[i], authTokenOptionsSchema, &opts); err != nil {
+		return nil, err
+	}
+	return &types.AuthTokenOptions{Origin: opts.Origin, SessionID: opts.SessionID}, nil
+}
+
+// authTokenResult describes t. expiresIn is the number of seconds left until its deadline, 0 once it expired,
+// so callers can schedule the refresh without comparing clocks.
+func authTokenResult(t *types.AuthToken) map[string]any {
+	now := client.Now()
+	return map[string]any{
+		"version":      t.Version,
+		"deadline":     t.Deadline.Unix(),
+		"accountIndex": t.AccountIndex,
+		"apiKeyIndex":  int(t.ApiKeyIndex),
+		"origin":       t.Origin,
+		"sessionId":    t.SessionID,
+		"expired":      !now.Before(t.Deadline),
+		"expiresIn":    int64(max(t.Deadline.Sub(now), 0) / time.Second),
+	}
+}
+
+// jsCreateAuthToken expects (deadline?, options?). options may bind the token to an origin and a session id;
+// without them the legacy token format is produced.
+func jsCreateAuthToken(this js.Value, args []js.Value) any {
+	c, err := getClient(defaultClientIndex)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	deadline := client.Now().Add(authTokenTtl()).Unix()
+	if len(args) > 0 && (args[0].Type() == js.TypeNumber || args[0].Type() == js.TypeString) {
+		deadline, err = parseTimeParam("deadline", args[0], time.Second)
+		if err != nil {
+			return js.ValueOf(map[string]any{"error": wrapErr(err)})
+		}
+	}
+	opts, err := parseAuthTokenOptions(args, 1)
+	if err != nil {
+		return errorResult(err)
+	}
+
+	token, err := c.GetScopedAuthToken(time.Unix(deadline, 0), opts)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	recordSign(nil)
+	return js.ValueOf(map[string]any{"authToken": token, "error": ""})
+}
+
+// jsDecodeAuthToken expects (token). The token is decoded but its signature is not checked.
+func jsDecodeAuthToken(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "DecodeAuthToken expects 1 arg: token"})
+	}
+
+	t, err := types.ParseAuthToken(args[0].String())
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	res := authTokenResult(t)
+	res["error"] = ""
+	return js.ValueOf(res)
+}
+
+// jsVerifyAuthToken expects (token, publicKey, options?). The token must be signed by publicKey, unexpired and
+// bound to exactly the origin and session id of options.
+func jsVerifyAuthToken(this js.Value, args []js.Value) any {
+	if len(args) < 2 {
+		return js.ValueOf(map[string]any{"error": "VerifyAuthToken expects at least 2 args: token, publicKey"})
+	}
+
+	pubKey, err := parsePublicKey(args[1].String())
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	opts, err := parseAuthTokenOptions(args, 2)
+	if err != nil {
+		return errorResult(err)
+	}
+
+	t, err := types.VerifyAuthToken(args[0].String(), pubKey, opts, client.Now())
+	if t == nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	res := authTokenResult(t)
+	res["valid"] = err == nil
+	res["reason"] = ""
+	if err != nil {
+		res["reason"] = err.Error()
+	}
+	res["error"] = ""
+	return js.ValueOf(res)
+}
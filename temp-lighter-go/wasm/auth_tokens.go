@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+)
+
+// maxAuthTokenLabel bounds the length of auth token labels, in bytes.
+const maxAuthTokenLabel = 64
+
+// labeledAuthToken is an auth token minted through MintAuthToken, with what is needed to refresh it. It is
+// refreshed with the client registered at clientIndex at the time.
+type labeledAuthToken struct {
+	clientIndex int
+	ttl         time.Duration
+	opts        *types.AuthTokenOptions
+	token       string
+	deadline    time.Time
+}
+
+// authTokens holds the live labeled auth tokens, e.g. one per websocket connection and one for REST.
+var authTokens = map[string]*labeledAuthToken{}
+
+// mint replaces the token of t with a new one valid for t.ttl from now.
+func (t *labeledAuthToken) mint() error {
+	c, err := getClient(t.clientIndex)
+	if err != nil {
+		return err
+	}
+	deadline := client.Now().Add(t.ttl).Truncate(time.Second)
+	token, err := c.GetScopedAuthToken(deadline, t.opts)
+	if err != nil {
+		return err
+	}
+	recordSign(nil)
+	t.token, t.deadline = token, deadline
+	return nil
+}
+
+// MintAuthToken mints, with the client at clientIndex, an auth token valid for ttl and keeps it under label,
+// replacing the token it held.
+func MintAuthToken(label string, clientIndex int, ttl time.Duration, opts *types.AuthTokenOptions) (*labeledAuthToken, error) {
+	if label == "" || len(label) > maxAuthTokenLabel {
+		return nil, fmt.Errorf("label should be between 1 and %d bytes", maxAuthTokenLabel)
+	}
+	t := &labeledAuthToken{clientIndex: clientIndex, ttl: ttl, opts: opts}
+	if err := t.mint(); err != nil {
+		return nil, err
+	}
+	authTokens[label] = t
+	return t, nil
+}
+
+// RefreshAuthToken replaces the token held under label with a new one, minted with the client at the same index,
+// validity and options.
+func RefreshAuthToken(label string) (*labeledAuthToken, error) {
+	t, ok := authTokens[label]
+	if !ok {
+		return nil, fmt.Errorf("no auth token labeled %q", label)
+	}
+	if err := t.mint(); err != nil {
+		return nil, err
+	}
+	emitEvent("tokenRefreshed", map[string]any{"label": label, "deadline": t.deadline.Unix()})
+	return t, nil
+}
+
+func labeledAuthTokenResult(label string, t *labeledAuthToken) map[string]any {
+	now := client.Now()
+	return map[string]any{
+		"label":       label,
+		"authToken":   t.token,
+		"clientIndex": t.clientIndex,
+		"deadline":    t.deadline.Unix(),
+		"expired":     !now.Before(t.deadline),
+		"expiresIn":   int64(max(t.deadline.Sub(now), 0) / time.Second),
+	}
+}
+
+// jsMintAuthToken expects (label, ttlSeconds?, options?, clientIndex?). The token is valid for ttlSeconds, the
+// authTokenTtlSec of the profile by default, and bound to the origin and session id of options when given.
+// Minting under an existing label replaces its token.
+func jsMintAuthToken(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "MintAuthToken expects at least 1 arg: label"})
+	}
+	clientIndex := defaultClientIndex
+	if len(args) > 3 && args[3].Type() == js.TypeNumber {
+		clientIndex = args[3].Int()
+	}
+
+	ttl := authTokenTtl()
+	if len(args) > 1 && args[1].Type() == js.TypeNumber {
+		ttl = time.Duration(args[1].Int()) * time.Second
+	}
+	if ttl <= 0 {
+		return js.ValueOf(map[string]any{"error": "ttlSeconds should be positive"})
+	}
+	opts, err := parseAuthTokenOptions(args, 2)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+
+	label := args[0].String()
+	t, err := MintAuthToken(label, clientIndex, ttl, opts)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	res := labeledAuthTokenResult(label, t)
+	res["error"] = ""
+	return js.ValueOf(res)
+}
+
+// jsRefreshAuthToken expects (label).
+func jsRefreshAuthToken(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "RefreshAuthToken expects 1 arg: label"})
+	}
+
+	label := args[0].String()
+	t, err := RefreshAuthToken(label)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	res := labeledAuthTokenResult(label, t)
+	res["error"] = ""
+	return js.ValueOf(res)
+}
+
+// jsRevokeAuthToken expects (label). The module forgets the token; the exchange keeps accepting it until its
+// deadline, which is why short validities are advised.
+func jsRevokeAuthToken(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "RevokeAuthToken expects 1 arg: label"})
+	}
+
+	label := args[0].String()
+	if _, ok := authTokens[label]; !ok {
+		return js.ValueOf(map[string]any{"error": fmt.Sprintf("no auth token labeled %q", label)})
+	}
+	delete(authTokens, label)
+	return js.ValueOf(map[string]any{"error": ""})
+}
+
+// jsGetAuthTokens lists the labeled auth tokens, sorted by label.
+func jsGetAuthTokens(this js.Value, args []js.Value) any {
+	labels := make([]string, 0, len(authTokens))
+	for label := range authTokens {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	tokens := make([]any, 0, len(labels))
+	for _, label := range labels {
+		tokens = append(tokens, labeledAuthTokenResult(label, authTokens[label]))
+	}
+	return js.ValueOf(map[string]any{"tokens": tokens, "error": ""})
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/signer"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+const sendTxBatchPath = "/api/v1/sendTxBatch"
+
+// The statuses of the requests of a batch.
+const (
+	batchSigned    = "signed"
+	batchSubmitted = "submitted"
+	batchFailed    = "failed"
+	// batchSkipped requests were not attempted, as a previous one failed.
+	batchSkipped = "skipped"
+	// batchRolledBack requests were signed, then discarded as another request of an atomic batch failed.
+	batchRolledBack = "rolledBack"
+)
+
+// batchRequest is one tx of a batch to sign, as accepted by PrepareTx.
+type batchRequest struct {
+	txType string
+	params *txParams
+}
+
+// batchItem is the outcome of the request of a batch at index.
+type batchItem struct {
+	index  int
+	txType string
+	nonce  int64
+	status string
+	tx     txtypes.TxInfo
+	txInfo string
+	txHash string
+	err    error
+}
+
+func newBatchItems(reqs []batchRequest) []batchItem {
+	items := make([]batchItem, len(reqs))
+	for i, r := range reqs {
+		items[i] = batchItem{index: i, txType: r.txType, nonce: r.params.Nonce, status: batchSkipped}
+	}
+	return items
+}
+
+// readBatchRequests reads requests, a non-empty array of {txType, params} as accepted by PrepareTx.
+func readBatchRequests(v js.Value) ([]batchRequest, error) {
+	reqs := make([]batchRequest, 0, v.Length())
+	for i := 0; i < v.Length(); i++ {
+		r := v.Index(i)
+		name := fmt.Sprintf("requests[%d]", i)
+		if r.Type() != js.TypeObject || r.Get("txType").Type() != js.TypeString {
+			return nil, fmt.Errorf("%s should be an object with a string txType and params", name)
+		}
+		data, exact, err := readTxRequest(r.Get("params"))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		params, err := parseTxRequest(r.Get("txType").String(), data, exact)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		reqs = append(reqs, batchRequest{txType: r.Get("txType").String(), params: params})
+	}
+	return reqs, nil
+}
+
+// signTxParams builds, checks and signs with c the tx of txType described by params. The tx is not recorded as
+// signed until it is passed to formatTxInfo.
+func signTxParams(c *client.TxClient, txType string, params *txParams) (txtypes.TxInfo, error) {
+	tx, _, err := buildUnsignedTx(c, txType, params)
+	if err != nil {
+		return nil, err
+	}
+	msgHash, err := c.PrepareTx(tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.SignPreparedTx(tx, msgHash); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// signBatchItems signs the request of every item with c, in order. Requests are signed independently unless
+// atomic is set: then the requests following a failed one are skipped and the ones signed before are rolled
+// back, so that none of their nonces is used. Only the txs kept are recorded as signed. Orders rolled back
+// still count against the rolling limits of the client's policy.
+func signBatchItems(c *client.TxClient, reqs []batchRequest, items []batchItem, atomic bool) {
+	failed := false
+	for i, r := range reqs {
+		if failed && atomic {
+			break
+		}
+		tx, err := signTxParams(c, r.txType, r.params)
+		if err != nil {
+			items[i].status, items[i].err = batchFailed, err
+			failed = true
+			continue
+		}
+		items[i].tx = tx
+	}
+
+	for i := range items {
+		item := &items[i]
+		if item.tx == nil {
+			continue
+		}
+		if failed && atomic {
+			item.tx, item.status = nil, batchRolledBack
+			continue
+		}
+		txInfo, err := formatTxInfo(item.tx)
+		if err != nil {
+			item.tx, item.status, item.err = nil, batchFailed, err
+			continue
+		}
+		item.txInfo, item.txHash, item.status = txInfo, item.tx.GetTxHash(), batchSigned
+	}
+}
+
+// countBatchItems counts the items of status.
+func countBatchItems(items []batchItem, status string) int {
+	n := 0
+	for _, item := range items {
+		if item.status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// batchStatus is "ok" when every item reached status, "failed" when none did and "partial" otherwise.
+func batchStatus(items []batchItem, status string) string {
+	switch countBatchItems(items, status) {
+	case len(items):
+		return "ok"
+	case 0:
+		return "failed"
+	default:
+		return "partial"
+	}
+}
+
+// batchItemsResult lists {index, txType, nonce, status, txHash, txInfo?, error} for every item, in request order.
+func batchItemsResult(items []batchItem) []any {
+	res := make([]any, len(items))
+	for i, item := range items {
+		entry := map[string]any{
+			"index":  item.index,
+			"txType": item.txType,
+			"nonce":  item.nonce,
+			"status": item.status,
+			"txHash": item.txHash,
+			"error":  wrapErr(item.err),
+		}
+		if item.txInfo != "" {
+			entry["txInfo"] = item.txInfo
+		}
+		res[i] = entry
+	}
+	return res
+}
+
+// SignBatch signs reqs with c, see signBatchItems, and reports every request in request order.
+func SignBatch(c *client.TxClient, reqs []batchRequest, atomic bool) []batchItem {
+	items := newBatchItems(reqs)
+	signBatchItems(c, reqs, items, atomic)
+	return items
+}
+
+// jsSignBatch expects (requests, options?, clientIndex?). requests lists {txType, params} as accepted by
+// PrepareTx, options is {atomic?}. Requests are signed independently unless atomic is set, which keeps none
+// of them when one fails. The result is {status, signed, items}: status is "ok", "partial" or "failed" and
+// items holds {index, txType, nonce, status, txHash, txInfo?, error} for every request, in request order,
+// status being "signed", "failed", "skipped" or "rolledBack".
+func jsSignBatch(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "SignBatch expects at least 1 arg: requests"})
+	}
+	c, err := clientFromArgs(args, 2)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	if !js.Global().Get("Array").Call("isArray", args[0]).Bool() || args[0].Length() == 0 {
+		return js.ValueOf(map[string]any{"error": "requests should be a non-empty array"})
+	}
+	if n := args[0].Length(); n > types.MaxBatchTxs {
+		return js.ValueOf(map[string]any{"error": fmt.Sprintf("batch holds %d requests, at most %d are allowed", n, types.MaxBatchTxs)})
+	}
+	reqs, err := readBatchRequests(args[0])
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	var opts struct {
+		Atomic bool `json:"atomic"`
+	}
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		if err := decodeStrict("options", args[1], objectSchema{"atomic": batchOptionsSchema["atomic"]}, &opts); err != nil {
+			return js.ValueOf(errorResult(err))
+		}
+	}
+
+	items := SignBatch(c, reqs, opts.Atomic)
+	return js.ValueOf(map[string]any{
+		"status": batchStatus(items, batchSigned),
+		"signed": countBatchItems(items, batchSigned),
+		"items":  batchItemsResult(items),
+		"error":  "",
+	})
+}
+
+var batchTxSchema = objectSchema{
+	"txType": requiredIntField(0, 255),
+	"txInfo": {Type: "string", Required: true, MaxLength: maxTxRequestBytes},
+}
+
+// BuildSendTxBatchBody decodes signed txs given as (txType, txInfo) pairs and encodes them as the form body of
+// a sendTxBatch call, in the order the exchange expects them. order maps every position of the batch to the
+// index of the input tx it holds.
+func BuildSendTxBatchBody(txTypes []uint8, txInfos []string) (body string, order []int, err error) {
+	txs := make([]txtypes.TxInfo, len(txInfos))
+	for i, txInfo := range txInfos {
+		tx, err := types.DecodeSignedTx(txTypes[i], txInfo)
+		if err != nil {
+			return "", nil, fmt.Errorf("txs[%d]: %w", i, err)
+		}
+		if !signer.IsCanonicalSignature(types.SignatureOf(tx)) {
+			return "", nil, fmt.Errorf("txs[%d]: %w", i, signer.ErrNonCanonicalSignature)
+		}
+		txs[i] = tx
+	}
+
+	order, err = types.OrderTxBatch(txs)
+	if err != nil {
+		return "", nil, err
+	}
+	ordered := make([]txtypes.TxInfo, len(txs))
+	for k, i := range order {
+		ordered[k] = txs[i]
+	}
+	form, err := client.TxBatchForm(ordered)
+	if err != nil {
+		return "", nil, err
+	}
+	return form.Encode(), order, nil
+}
+
+// jsBuildSendTxBatchBody expects (txs). txs lists {txType, txInfo} as returned by the signing exports, in any
+// response casing. The result is POSTed as is to path with the given content type.
+func jsBuildSendTxBatchBody(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "BuildSendTxBatchBody expects 1 arg: txs"})
+	}
+	if !js.Global().Get("Array").Call("isArray", args[0]).Bool() {
+		return js.ValueOf(map[string]any{"error": "txs should be an array"})
+	}
+	if n := args[0].Length(); n > types.MaxBatchTxs {
+		return js.ValueOf(map[string]any{"error": fmt.Sprintf("batch holds %d txs, at most %d are allowed", n, types.MaxBatchTxs)})
+	}
+
+	txTypes := make([]uint8, 0, args[0].Length())
+	txInfos := make([]string, 0, args[0].Length())
+	for i := 0; i < args[0].Length(); i++ {
+		var tx struct {
+			TxType uint8  `json:"txType"`
+			TxInfo string `json:"txInfo"`
+		}
+		if err := decodeStrict(fmt.Sprintf("txs[%d]", i), args[0].Index(i), batchTxSchema, &tx); err != nil {
+			return js.ValueOf(errorResult(err))
+		}
+		txTypes = append(txTypes, tx.TxType)
+		txInfos = append(txInfos, tx.TxInfo)
+	}
+
+	body, order, err := BuildSendTxBatchBody(txTypes, txInfos)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	orderRes := make([]any, len(order))
+	for k, i := range order {
+		orderRes[k] = i
+	}
+	return js.ValueOf(map[string]any{
+		"path":        sendTxBatchPath,
+		"contentType": "application/x-www-form-urlencoded",
+		"body":        body,
+		"order":       orderRes,
+		"error":       "",
+	})
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+const (
+	defaultBenchmarkOrders = 200
+	maxBenchmarkOrders     = 10000
+	benchmarkChainId       = 304
+)
+
+// BenchmarkResult reports the cost of signing one create order tx.
+type BenchmarkResult struct {
+	Orders      int
+	Elapsed     time.Duration
+	OpsPerSec   float64
+	NsPerOp     int64
+	AllocsPerOp uint64
+	BytesPerOp  uint64
+	NumGC       uint32
+}
+
+// RunBenchmark signs n synthetic create order txs with a throwaway key. Neither the loaded client nor the health
+// statistics are touched. It is refused in strict mode.
+func RunBenchmark(n int) (*BenchmarkResult, error) {
+	if err := checkNotMock("RunBenchmark"); err != nil {
+		return nil, err
+	}
+	if n < 1 || n > maxBenchmarkOrders {
+		return nil, fmt.Errorf("orders must be between 1 and %d, got %d", maxBenchmarkOrders, n)
+	}
+
+	km, err := generateAPIKey("lighter-signer benchmark")
+	if err != nil {
+		return nil, err
+	}
+	c := client.NewTxClientWithKeyManager(nil, km, 1, 0, benchmarkChainId)
+	req := &types.CreateOrderTxReq{
+		MarketIndex: 1,
+		BaseAmount:  1000,
+		Price:       100000,
+		Type:        txtypes.LimitOrder,
+		TimeInForce: txtypes.GoodTillTime,
+		OrderExpiry: time.Now().Add(time.Hour).UnixMilli(),
+	}
+
+	// Warm up so one-off initialization is not attributed to the first orders
+	if err := benchmarkOrder(c, req, 0); err != nil {
+		return nil, err
+	}
+
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 1; i <= n; i++ {
+		if err := benchmarkOrder(c, req, int64(i)); err != nil {
+			return nil, err
+		}
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	return &BenchmarkResult{
+		Orders:      n,
+		Elapsed:     elapsed,
+		OpsPerSec:   float64(n) / elapsed.Seconds(),
+		NsPerOp:     elapsed.Nanoseconds() / int64(n),
+		AllocsPerOp: (after.Mallocs - before.Mallocs) / uint64(n),
+		BytesPerOp:  (after.TotalAlloc - before.TotalAlloc) / uint64(n),
+		NumGC:       after.NumGC - before.NumGC,
+	}, nil
+}
+
+func benchmarkOrder(c *client.TxClient, req *types.CreateOrderTxReq, nonce int64) error {
+	fromAcc, apiIdx := c.GetAccountIndex(), c.GetApiKeyIndex()
+	req.ClientOrderIndex = nonce
+	_, err := c.GetCreateOrderTransaction(req, &types.TransactOpts{
+		FromAccountIndex: &fromAcc,
+		ApiKeyIndex:      &apiIdx,
+		Nonce:            &nonce,
+	})
+	return err
+}
+
+// jsRunBenchmark expects (orders?). It blocks the event loop while signing, so it should not be run while the
+// page or process has other work to do.
+func jsRunBenchmark(this js.Value, args []js.Value) any {
+	n := defaultBenchmarkOrders
+	if len(args) > 0 && args[0].Type() == js.TypeNumber {
+		n = args[0].Int()
+	}
+
+	res, err := RunBenchmark(n)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	return js.ValueOf(map[string]any{
+		"orders":      res.Orders,
+		"elapsedMs":   float64(res.Elapsed.Microseconds()) / 1000,
+		"opsPerSec":   res.OpsPerSec,
+		"nsPerOp":     res.NsPerOp,
+		"allocsPerOp": res.AllocsPerOp,
+		"bytesPerOp":  res.BytesPerOp,
+		"numGC":       res.NumGC,
+		"runtime":     hostRuntime(),
+		"version":     version,
+		"error":       "",
+	})
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// jsCanonicalizeTxInfo expects (json) and returns it in the canonical form every txInfo is emitted in, so
+// payloads produced elsewhere can be compared byte for byte.
+func jsCanonicalizeTxInfo(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "CanonicalizeTxInfo expects 1 arg: json"})
+	}
+
+	canonical, err := txtypes.CanonicalizeJSON([]byte(args[0].String()))
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"txInfo": canonical, "error": ""})
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"sort"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// capabilitiesVersion is bumped whenever the shape of the GetCapabilities result changes.
+const capabilitiesVersion = 1
+
+// apiVersions lists the exchange API versions the HTTP client speaks.
+var apiVersions = []string{"v1"}
+
+// signableTxTypes maps the tx types this build can sign to the exports signing them.
+var signableTxTypes = []struct {
+	name    string
+	txType  uint8
+	exports []string
+}{
+	{"changePubKey", txtypes.TxTypeL2ChangePubKey, []string{"RotateAPIKey", "CreateSessionKey", "BuildOnboarding"}},
+	{"transfer", txtypes.TxTypeL2Transfer, []string{"SignTransfer", "SignSubAccountTransfer", "PrepareTx"}},
+	{"withdraw", txtypes.TxTypeL2Withdraw, []string{"SignWithdraw", "PrepareTx"}},
+	{"createOrder", txtypes.TxTypeL2CreateOrder, []string{"SignCreateOrder", "PrepareTx", "AmendOrder"}},
+	{"cancelOrder", txtypes.TxTypeL2CancelOrder, []string{"SignCancelOrder", "PrepareTx", "AmendOrder"}},
+	{"modifyOrder", txtypes.TxTypeL2ModifyOrder, []string{"AmendOrder"}},
+	{"cancelAllOrders", txtypes.TxTypeL2CancelAllOrders, []string{"SignCancelAllOrders"}},
+	{"updateLeverage", txtypes.TxTypeL2UpdateLeverage, []string{"SignUpdateLeverage"}},
+}
+
+// features tells the TS SDK which optional parts of the API this build has. Flags are only ever added, a
+// feature that is removed stays listed as false.
+var features = map[string]bool{
+	"batch":                  true,
+	"wsSubmit":               false,
+	"nonceManager":           true,
+	"multiClient":            true,
+	"externalSigner":         true,
+	"prepareFinalize":        true,
+	"approvals":              true,
+	"sessionKeys":            true,
+	"keyLock":                true,
+	"apiKeyRotation":         true,
+	"subAccounts":            true,
+	"responseCasing":         true,
+	"timeStrings":            true,
+	"schemaErrors":           true,
+	"httpFetch":              true,
+	"legacyAliases":          true,
+	"shutdown":               true,
+	"heartbeat":              true,
+	"memoryStats":            true,
+	"moduleHash":             true,
+	"scopedAuthTokens":       true,
+	"redaction":              true,
+	"benchmark":              true,
+	"marketableOrders":       true,
+	"reduceOnlyChecks":       true,
+	"postOnlyCrossChecks":    true,
+	"selfTradePrevention":    true,
+	"openOrdersCache":        true,
+	"expiryPresets":          true,
+	"triggerDirectionChecks": true,
+	"marketRules":            true,
+	"roundingModes":          true,
+	"decimalStrings":         true,
+	"withdrawalFeeChecks":    true,
+	"destinationAllowList":   true,
+	"rollingLimits":          true,
+	"confirmationHook":       true,
+	"labeledAuthTokens":      true,
+	"endpointFailover":       true,
+	"offlineTxQueue":         true,
+	"sessionSnapshots":       true,
+	"pendingOperations":      true,
+	"batchScheduler":         true,
+	"priceFeed":              true,
+	"signedOrderIndex":       true,
+	"orderAmend":             true,
+	"clockSync":              true,
+	"profiles":               true,
+	"events":                 true,
+	"strategyClientIds":      true,
+	"addressBook":            true,
+	"orderSimulation":        true,
+	"batchItemResults":       true,
+	"nonceRepair":            true,
+	"exchangeHalt":           true,
+	"marketStats":            true,
+	"cancelLane":             true,
+	"warmup":                 true,
+	"strictMode":             true,
+	"httpConfig":             true,
+	"onboarding":             true,
+	"orderExpiryWatch":       true,
+	"killSwitch":             true,
+	"replay":                 true,
+	"memoTemplates":          true,
+	"orderIntentHash":        true,
+	"initOptions":            true,
+	"requireOrderIntent":     true,
+}
+
+func jsGetCapabilities(this js.Value, args []js.Value) any {
+	txTypes := make([]any, 0, len(signableTxTypes))
+	for _, t := range signableTxTypes {
+		exports := make([]any, 0, len(t.exports))
+		for _, e := range t.exports {
+			exports = append(exports, e)
+		}
+		txTypes = append(txTypes, map[string]any{"name": t.name, "txType": int(t.txType), "exports": exports})
+	}
+
+	names := make([]string, 0, len(features))
+	for name := range features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	featureMap := map[string]any{}
+	supported := make([]any, 0, len(names))
+	for _, name := range names {
+		featureMap[name] = features[name]
+		if features[name] {
+			supported = append(supported, name)
+		}
+	}
+
+	versions := make([]any, 0, len(apiVersions))
+	for _, v := range apiVersions {
+		versions = append(versions, v)
+	}
+
+	return js.ValueOf(map[string]any{
+		"capabilitiesVersion": capabilitiesVersion,
+		"version":             version,
+		"txTypes":             txTypes,
+		"features":            featureMap,
+		"supportedFeatures":   supported,
+		"apiVersions":         versions,
+		"error":               "",
+	})
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/signer"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// responseCasing is how the keys of every txInfo and decoded object returned to JS are spelled, see
+// currentCasing.
+var responseCasing = txtypes.PascalCase
+
+// currentCasing returns responseCasing.
+func currentCasing() txtypes.KeyCasing {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	return responseCasing
+}
+
+// formatTxInfo encodes tx the way it is returned to JS, honoring responseCasing. Signed txs whose signature is
+// not in canonical form are refused.
+func formatTxInfo(tx txtypes.TxInfo) (string, error) {
+	if sig := types.SignatureOf(tx); sig != nil && !signer.IsCanonicalSignature(sig) {
+		return "", signer.ErrNonCanonicalSignature
+	}
+	txInfo, err := tx.GetTxInfo()
+	if err != nil {
+		return "", err
+	}
+	recordSign(tx)
+	trackSignedTx(tx)
+	if tx.GetTxHash() != "" {
+		indexSignedOrder(tx, txInfo)
+		emitEvent("signed", map[string]any{"txType": tx.GetTxType(), "txHash": tx.GetTxHash()})
+	}
+	return casedTxInfo(txInfo)
+}
+
+// casedTxInfo converts txInfo, as returned by GetTxInfo, to the response casing.
+func casedTxInfo(txInfo string) (string, error) {
+	casing := currentCasing()
+	if casing == txtypes.PascalCase {
+		return txInfo, nil
+	}
+	return txtypes.ConvertJSONKeys([]byte(txInfo), casing)
+}
+
+// jsSetResponseCasing expects ("pascal" | "camel" | "snake").
+func jsSetResponseCasing(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "SetResponseCasing expects 1 arg: casing"})
+	}
+
+	casing, err := txtypes.ParseKeyCasing(args[0].String())
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	stateMu.Lock()
+	responseCasing = casing
+	stateMu.Unlock()
+	return js.ValueOf(map[string]any{"casing": casing.String(), "error": ""})
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+)
+
+// maxClockSkew bounds the offset accepted from the host through SetClockSkew.
+const maxClockSkew = 24 * time.Hour
+
+func clockSkewResult(skew client.ClockSkew) map[string]any {
+	var syncedAt int64
+	if !skew.SyncedAt.IsZero() {
+		syncedAt = skew.SyncedAt.UnixMilli()
+	}
+	return map[string]any{
+		"offsetMs":    skew.Offset.Milliseconds(),
+		"roundTripMs": skew.RoundTrip.Milliseconds(),
+		"syncedAt":    syncedAt,
+		"error":       "",
+	}
+}
+
+// jsSyncClock expects (clientIndex?) and returns a Promise. It measures the offset of the exchange clock through
+// the client's HTTP client and applies it to every deadline and expiry the module computes.
+func jsSyncClock(this js.Value, args []js.Value) any {
+	c, err := clientFromArgs(args, 0)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	if c.HTTP() == nil {
+		return js.ValueOf(map[string]any{"error": "HTTP client not configured, cannot sync the clock"})
+	}
+
+	return newPromise(func(callClock) map[string]any {
+		skew, err := c.HTTP().SyncClock()
+		if err != nil {
+			return map[string]any{"error": wrapErr(err)}
+		}
+		logEvent("info", "clock.synced", fmt.Sprintf("exchange clock offset %s", skew.Offset), map[string]any{"offsetMs": skew.Offset.Milliseconds(), "roundTripMs": skew.RoundTrip.Milliseconds()})
+		return clockSkewResult(skew)
+	})
+}
+
+// jsSetClockSkew expects (offsetMs), the offset of the exchange clock from the local one as measured by the
+// host. 0 stops correcting the local time.
+func jsSetClockSkew(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "SetClockSkew expects 1 arg: offsetMs"})
+	}
+	if args[0].Type() != js.TypeNumber || math.Abs(args[0].Float()) > float64(maxClockSkew.Milliseconds()) {
+		return js.ValueOf(map[string]any{"error": fmt.Sprintf("offsetMs should be a number of milliseconds within %s", maxClockSkew)})
+	}
+
+	skew := client.ClockSkew{Offset: time.Duration(args[0].Int()) * time.Millisecond}
+	if skew.Offset != 0 {
+		skew.SyncedAt = time.Now()
+	}
+	client.SetClockSkew(skew)
+	return js.ValueOf(clockSkewResult(skew))
+}
+
+// jsGetClockSkew expects () and returns {offsetMs, roundTripMs, syncedAt}: offsetMs is added to the local time
+// to get the exchange time, syncedAt is 0 until the clock was synced.
+func jsGetClockSkew(this js.Value, args []js.Value) any {
+	return js.ValueOf(clockSkewResult(client.GetClockSkew()))
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// errNotConfirmed is returned when the confirmation callback declines a tx.
+var errNotConfirmed = errors.New("transaction was not confirmed")
+
+// confirmation is the callback set through SetConfirmationHook and the thresholds above which it is asked.
+// Amounts are in USDC units, leverage is a whole multiple.
+var confirmation struct {
+	callback        js.Value
+	transferAbove   int64
+	withdrawalAbove int64
+	leverageAbove   int64
+}
+
+var confirmationThresholdsSchema = objectSchema{
+	"transferAbove":   intField(0, math.MaxInt64),
+	"withdrawalAbove": intField(0, math.MaxInt64),
+	"leverageAbove":   intField(0, txtypes.MarginFractionTick),
+}
+
+// confirmationRequest describes tx to the confirmation callback when it is a transfer, withdrawal or leverage
+// change above its threshold, and returns nil otherwise.
+func confirmationRequest(tx txtypes.TxInfo) map[string]any {
+	switch tx := tx.(type) {
+	case *txtypes.L2TransferTxInfo:
+		if tx.USDCAmount > confirmation.transferAbove {
+			return map[string]any{
+				"kind":           "transfer",
+				"accountIndex":   tx.FromAccountIndex,
+				"toAccountIndex": tx.ToAccountIndex,
+				"usdcAmount":     tx.USDCAmount,
+				"fee":            tx.Fee,
+			}
+		}
+	case *txtypes.L2WithdrawTxInfo:
+		if tx.USDCAmount > uint64(confirmation.withdrawalAbove) {
+			return map[string]any{
+				"kind":         "withdrawal",
+				"accountIndex": tx.FromAccountIndex,
+				"usdcAmount":   tx.USDCAmount,
+			}
+		}
+	case *txtypes.L2UpdateLeverageTxInfo:
+		// The leverage is MarginFractionTick / InitialMarginFraction.
+		if txtypes.MarginFractionTick > confirmation.leverageAbove*int64(tx.InitialMarginFraction) {
+			return map[string]any{
+				"kind":                  "leverage",
+				"accountIndex":          tx.AccountIndex,
+				"marketIndex":           tx.MarketIndex,
+				"initialMarginFraction": tx.InitialMarginFraction,
+				"marginMode":            tx.MarginMode,
+			}
+		}
+	}
+	return nil
+}
+
+// checkConfirmation is installed on every client created from JS, see installChecks. It asks the confirmation
+// callback, when one is set, to approve the high risk txs. Simulated txs are not asked about.
+func checkConfirmation(tx txtypes.TxInfo) error {
+	if types.IsDryRun(tx) {
+		return nil
+	}
+	stateMu.RLock()
+	callback := confirmation.callback
+	var req map[string]any
+	if callback.Type() == js.TypeFunction {
+		req = confirmationRequest(tx)
+	}
+	stateMu.RUnlock()
+	if req == nil {
+		return nil
+	}
+	req["txType"] = tx.GetTxType()
+
+	res := callback.Invoke(js.ValueOf(req))
+	if res.Type() == js.TypeObject && res.Get("then").Type() == js.TypeFunction {
+		return fmt.Errorf("confirmation callback returned a Promise; it has to answer synchronously")
+	}
+	if !res.Truthy() {
+		return errNotConfirmed
+	}
+	return nil
+}
+
+// jsSetConfirmationHook expects (callback, thresholds?). callback is called synchronously with a description
+// of every transfer, withdrawal and leverage change above its threshold before it is signed, and the tx is only
+// signed if it returns a truthy value; null removes it. thresholds is {transferAbove?, withdrawalAbove?,
+// leverageAbove?}, amounts in USDC units and leverage as a whole multiple, all 0 by default.
+func jsSetConfirmationHook(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "SetConfirmationHook expects at least 1 arg: callback"})
+	}
+	if args[0].IsNull() || args[0].IsUndefined() {
+		stateMu.Lock()
+		confirmation.callback = js.Undefined()
+		stateMu.Unlock()
+		return js.ValueOf(map[string]any{"error": ""})
+	}
+	if args[0].Type() != js.TypeFunction {
+		return js.ValueOf(map[string]any{"error": "callback must be a function"})
+	}
+
+	var thresholds struct {
+		TransferAbove   int64 `json:"transferAbove"`
+		WithdrawalAbove int64 `json:"withdrawalAbove"`
+		LeverageAbove   int64 `json:"leverageAbove"`
+	}
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		if err := decodeStrict("thresholds", args[1], confirmationThresholdsSchema, &thresholds); err != nil {
+			return js.ValueOf(errorResult(err))
+		}
+	}
+
+	stateMu.Lock()
+	confirmation.callback = args[0]
+	confirmation.transferAbove = thresholds.TransferAbove
+	confirmation.withdrawalAbove = thresholds.WithdrawalAbove
+	confirmation.leverageAbove = thresholds.LeverageAbove
+	stateMu.Unlock()
+	return js.ValueOf(map[string]any{
+		"transferAbove":   thresholds.TransferAbove,
+		"withdrawalAbove": thresholds.WithdrawalAbove,
+		"leverageAbove":   thresholds.LeverageAbove,
+		"error":           "",
+	})
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/client"
+)
+
+// httpClientFromArgs reads the optional baseUrl argument at position i: one API base URL, or an array of them
+// by priority to fail over between. Failovers are reported to the logger. It returns nil when the arg is not
+// given, as signing never requires an HTTP client, and while a session is replayed, see replayOffline.
+func httpClientFromArgs(args []js.Value, i int) (*client.HTTPClient, error) {
+	if len(args) <= i || replayOffline.Load() {
+		return nil, nil
+	}
+
+	var baseUrls []string
+	switch v := args[i]; {
+	case v.Type() == js.TypeString:
+		if v.String() == "" {
+			return nil, nil
+		}
+		baseUrls = []string{v.String()}
+	case js.Global().Get("Array").Call("isArray", v).Bool():
+		for k := 0; k < v.Length(); k++ {
+			if v.Index(k).Type() != js.TypeString || v.Index(k).String() == "" {
+				return nil, fmt.Errorf("baseUrl[%d] should be a non-empty string", k)
+			}
+			baseUrls = append(baseUrls, v.Index(k).String())
+		}
+		if len(baseUrls) == 0 {
+			return nil, fmt.Errorf("baseUrl should list at least one URL")
+		}
+	default:
+		return nil, nil
+	}
+
+	httpClient := client.NewHTTPClientWithFallbacks(baseUrls)
+	httpClient.SetFailoverHandler(logFailover)
+	httpClient.SetNonceHandler(emitNonceResync)
+	return httpClient, nil
+}
+
+func endpointsResult(statuses []client.EndpointStatus) map[string]any {
+	endpoints := make([]any, 0, len(statuses))
+	for _, s := range statuses {
+		var downUntil int64
+		if !s.DownUntil.IsZero() {
+			downUntil = s.DownUntil.UnixMilli()
+		}
+		endpoints = append(endpoints, map[string]any{
+			"url":       s.URL,
+			"active":    s.Active,
+			"healthy":   s.Healthy,
+			"failures":  s.Failures,
+			"downUntil": downUntil,
+		})
+	}
+	return map[string]any{"endpoints": endpoints, "error": ""}
+}
+
+// jsGetEndpoints expects (clientIndex?) and lists the API base URLs of the client by priority.
+func jsGetEndpoints(this js.Value, args []js.Value) any {
+	c, err := clientFromArgs(args, 0)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	if c.HTTP() == nil {
+		return js.ValueOf(map[string]any{"error": "HTTP client not configured"})
+	}
+	return js.ValueOf(endpointsResult(c.HTTP().Endpoints()))
+}
+
+// jsCheckEndpoints expects (clientIndex?) and returns a Promise. Every API base URL of the client is probed, so
+// that failing ones are skipped and recovered ones used again without waiting for traffic.
+func jsCheckEndpoints(this js.Value, args []js.Value) any {
+	c, err := clientFromArgs(args, 0)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	if c.HTTP() == nil {
+		return js.ValueOf(map[string]any{"error": "HTTP client not configured"})
+	}
+
+	return newPromise(func(callClock) map[string]any {
+		return endpointsResult(c.HTTP().CheckEndpoints())
+	})
+}
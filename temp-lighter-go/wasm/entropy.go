@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/signer"
+	curve "github.com/elliottech/poseidon_crypto/curve/ecgfp5"
+)
+
+// entropyErr is the result of the startup check of the host's randomness. Key generation refuses to run while
+// it is set.
+var entropyErr error
+
+// checkEntropy checks that the host provides crypto.getRandomValues, which crypto/rand is backed by on js/wasm,
+// before letting signer.CheckEntropy read from it: a failing crypto/rand aborts the program rather than
+// returning an error.
+func checkEntropy() {
+	cryptoObj := js.Global().Get("crypto")
+	if cryptoObj.Type() != js.TypeObject || cryptoObj.Get("getRandomValues").Type() != js.TypeFunction {
+		entropyErr = fmt.Errorf("%w: crypto.getRandomValues is not available", signer.ErrInsufficientEntropy)
+	} else {
+		entropyErr = signer.CheckEntropy()
+	}
+
+	features["secureRandom"] = entropyErr == nil
+	if entropyErr != nil {
+		warn(fmt.Sprintf("key generation is disabled: %v", entropyErr))
+	}
+}
+
+// generateKey samples a new private key, refusing to when the startup entropy check failed.
+func generateKey() (signer.KeyManager, error) {
+	if entropyErr != nil {
+		return nil, entropyErr
+	}
+	return signer.GenerateKeyManager()
+}
+
+// generateAPIKey returns a new key pair. A non-empty seed derives it deterministically instead, such a key is only
+// as hard to guess as the seed, and is refused in strict mode.
+func generateAPIKey(seed string) (signer.KeyManager, error) {
+	if seed == "" {
+		return generateKey()
+	}
+	if err := checkNotMock("seeded key generation"); err != nil {
+		return nil, err
+	}
+	return signer.NewKeyManager(curve.SampleScalar(&seed).ToLittleEndianBytes())
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/elliottech/lighter-go/types"
+)
+
+// errorCode gives the errors callers are expected to handle a code which, unlike the message, is stable.
+func errorCode(err error) string {
+	var reduceOnlyErr *types.ReduceOnlyError
+	var postOnlyErr *types.PostOnlyCrossError
+	var selfTradeErr *types.SelfTradeError
+	var triggerErr *types.TriggerDirectionError
+	var sizeErr *types.OrderSizeError
+	var withdrawalFeeErr *types.WithdrawalFeeError
+	var priceBandErr *types.PriceBandError
+	var intentErr *types.OrderIntentMismatchError
+	switch {
+	case errors.As(err, &reduceOnlyErr):
+		return "REDUCE_ONLY_VIOLATION"
+	case errors.As(err, &postOnlyErr):
+		return "POST_ONLY_WOULD_CROSS"
+	case errors.As(err, &selfTradeErr):
+		return "SELF_TRADE"
+	case errors.As(err, &triggerErr):
+		return "TRIGGER_DIRECTION"
+	case errors.As(err, &sizeErr):
+		return "MARKET_RULES_VIOLATION"
+	case errors.As(err, &withdrawalFeeErr):
+		return "WITHDRAWAL_FEE_MISMATCH"
+	case errors.As(err, &priceBandErr):
+		return "PRICE_BAND_VIOLATION"
+	case errors.As(err, &intentErr):
+		return "ORDER_INTENT_MISMATCH"
+	case errors.Is(err, errOrderIntentRequired):
+		return "ORDER_INTENT_REQUIRED"
+	case errors.Is(err, errNotConfirmed):
+		return "NOT_CONFIRMED"
+	case errors.Is(err, errExchangeHalted):
+		return "EXCHANGE_HALTED"
+	case errors.Is(err, errMockPathDisabled):
+		return "MOCK_PATH_DISABLED"
+	}
+	return ""
+}
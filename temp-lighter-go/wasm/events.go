@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// eventNames lists the events Subscribe accepts:
+//   - signed: a tx was signed, {txType, txHash}
+//   - submitted: the exchange accepted a tx, {txType, txHash}
+//   - rejected: the exchange refused a tx, {txType, txHash, error}
+//   - nonceResync: the next nonce of a key was fetched from the exchange, {accountIndex, apiKeyIndex, nonce}
+//   - nonceGap: the exchange refused a tx for its nonce, {accountIndex, apiKeyIndex, nonce, txHash, error}
+//   - tokenRefreshed: a labeled auth token was replaced, {label, deadline}
+//   - failover: an HTTP client switched endpoints, {from, to, reason}
+//   - halted: the exchange was seen halted, e.g. for maintenance, {reason}
+//   - resumed: the exchange was seen accepting txs again after a halt, {}
+//   - orderExpiring: a resting order is about to expire, see WatchOrderExpiry, {accountIndex, marketIndex,
+//     orderIndex, clientOrderIndex, txHash, orderExpiry, expiresInMs, ...}
+var eventNames = []string{"signed", "submitted", "rejected", "nonceResync", "nonceGap", "tokenRefreshed", "failover", "halted", "resumed", "orderExpiring"}
+
+// subscriptions holds the callbacks passed to Subscribe by event name and subscription id.
+var subscriptions = struct {
+	mu      sync.Mutex
+	nextID  int
+	byEvent map[string]map[int]js.Value
+}{byEvent: map[string]map[int]js.Value{}}
+
+// emitEvent passes {event, time, ...fields} to the callbacks subscribed to event, in subscription order. A
+// callback that throws is reported to the logger and does not keep the others from being called.
+func emitEvent(event string, fields map[string]any) {
+	subscriptions.mu.Lock()
+	ids := make([]int, 0, len(subscriptions.byEvent[event]))
+	for id := range subscriptions.byEvent[event] {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	callbacks := make([]js.Value, len(ids))
+	for i, id := range ids {
+		callbacks[i] = subscriptions.byEvent[event][id]
+	}
+	subscriptions.mu.Unlock()
+	if len(callbacks) == 0 {
+		return
+	}
+
+	entry := map[string]any{}
+	for k, v := range fields {
+		entry[k] = v
+	}
+	entry["event"] = event
+	entry["time"] = time.Now().UnixMilli()
+	v := js.ValueOf(entry)
+	for _, callback := range callbacks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logEvent("warn", "warning", fmt.Sprintf("%s subscriber threw: %v", event, r), nil)
+				}
+			}()
+			callback.Invoke(v)
+		}()
+	}
+}
+
+// emitSubmission reports the outcome of submitting tx, hashed txHash: "submitted" when err is nil, "rejected"
+// when the exchange refused it. Network failures and throttling are neither.
+func emitSubmission(tx txtypes.TxInfo, txHash string, err error) {
+	noteExchangeStatus(err)
+	fields := map[string]any{"txType": tx.GetTxType(), "txHash": txHash}
+	if err == nil {
+		emitEvent("submitted", fields)
+		return
+	}
+	var netErr *client.NetworkError
+	var rateErr *client.RateLimitError
+	if errors.As(err, &netErr) || errors.As(err, &rateErr) {
+		return
+	}
+	fields["error"] = wrapErr(err)
+	emitEvent("rejected", fields)
+	noteNonceRejection(tx, txHash, err)
+}
+
+// emitNonceResync is installed on every HTTP client created from JS.
+func emitNonceResync(e client.NonceEvent) {
+	emitEvent("nonceResync", map[string]any{"accountIndex": e.AccountIndex, "apiKeyIndex": e.ApiKeyIndex, "nonce": e.Nonce})
+}
+
+// jsSubscribe expects (event, callback) and returns {subscriptionId}. callback receives {event, time, ...} every
+// time event happens; see eventNames for the events and what they carry.
+func jsSubscribe(this js.Value, args []js.Value) any {
+	if len(args) < 2 {
+		return js.ValueOf(map[string]any{"error": "Subscribe expects 2 args: event, callback"})
+	}
+	event := args[0].String()
+	known := false
+	for _, name := range eventNames {
+		known = known || name == event
+	}
+	if args[0].Type() != js.TypeString || !known {
+		return js.ValueOf(map[string]any{"error": fmt.Sprintf("event should be one of %s", strings.Join(eventNames, ", "))})
+	}
+	if args[1].Type() != js.TypeFunction {
+		return js.ValueOf(map[string]any{"error": "callback must be a function"})
+	}
+
+	subscriptions.mu.Lock()
+	defer subscriptions.mu.Unlock()
+	subscriptions.nextID++
+	if subscriptions.byEvent[event] == nil {
+		subscriptions.byEvent[event] = map[int]js.Value{}
+	}
+	subscriptions.byEvent[event][subscriptions.nextID] = args[1]
+	return js.ValueOf(map[string]any{"subscriptionId": subscriptions.nextID, "error": ""})
+}
+
+// jsUnsubscribe expects (subscriptionId) and returns {removed}, false when the subscription did not exist.
+func jsUnsubscribe(this js.Value, args []js.Value) any {
+	if len(args) < 1 || args[0].Type() != js.TypeNumber {
+		return js.ValueOf(map[string]any{"error": "Unsubscribe expects 1 arg: subscriptionId"})
+	}
+	id := args[0].Int()
+
+	subscriptions.mu.Lock()
+	defer subscriptions.mu.Unlock()
+	for event, callbacks := range subscriptions.byEvent {
+		if _, ok := callbacks[id]; ok {
+			delete(callbacks, id)
+			if len(callbacks) == 0 {
+				delete(subscriptions.byEvent, event)
+			}
+			return js.ValueOf(map[string]any{"removed": true, "error": ""})
+		}
+	}
+	return js.ValueOf(map[string]any{"removed": false, "error": ""})
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"sort"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// sessionEnd, set through SetSessionEnd, is when orders using the SESSION expiry preset expire.
+var sessionEnd time.Time
+
+// parseOrderExpiry reads the orderExpiry parameter passed from JS: one of types.OrderExpiryPresets, or any value
+// accepted by parseTimeParam.
+func parseOrderExpiry(v js.Value) (int64, error) {
+	if v.Type() == js.TypeString && types.IsOrderExpiryPreset(v.String()) {
+		return types.ResolveOrderExpiry(v.String(), client.Now(), sessionEnd)
+	}
+	return parseTimeParam("orderExpiry", v, time.Millisecond, txtypes.NilOrderExpiry, -1)
+}
+
+// jsSetSessionEnd expects (sessionEnd), any time parameter accepted by the signing exports. 0 unsets it.
+func jsSetSessionEnd(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "SetSessionEnd expects 1 arg: sessionEnd"})
+	}
+
+	ms, err := parseTimeParam("sessionEnd", args[0], time.Millisecond, 0)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	if ms == 0 {
+		sessionEnd = time.Time{}
+	} else {
+		sessionEnd = time.UnixMilli(ms)
+	}
+	return js.ValueOf(map[string]any{"sessionEnd": ms, "error": ""})
+}
+
+// jsResolveOrderExpiry expects (preset) and returns the orderExpiry an order signed now would get from it.
+func jsResolveOrderExpiry(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "ResolveOrderExpiry expects 1 arg: preset"})
+	}
+
+	orderExpiry, err := types.ResolveOrderExpiry(args[0].String(), client.Now(), sessionEnd)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"orderExpiry": orderExpiry, "error": ""})
+}
+
+// expiringOrdersLocked returns the resting orders of accountIndex, or of every account when nil, that expire
+// within window from now but did not yet, soonest first.
+func expiringOrdersLocked(accountIndex *int64, now time.Time, window time.Duration) []*trackedOrder {
+	from, until := now.UnixMilli(), now.Add(window).UnixMilli()
+	var res []*trackedOrder
+	for account, orders := range ownOrders {
+		if accountIndex != nil && *accountIndex != account {
+			continue
+		}
+		for _, o := range orders {
+			if o.OrderExpiry != txtypes.NilOrderExpiry && o.OrderExpiry > from && o.OrderExpiry <= until {
+				res = append(res, o)
+			}
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].OrderExpiry < res[j].OrderExpiry })
+	return res
+}
+
+// expiryWatch is the watcher started by WatchOrderExpiry, guarded by stateMu. notified holds the orders
+// "orderExpiring" was emitted for, so that it is emitted once per order.
+var expiryWatch struct {
+	lead     time.Duration
+	stop     chan struct{}
+	notified map[*trackedOrder]bool
+}
+
+// WatchOrderExpiry emits "orderExpiring" once for every resting order lead before it expires, checking every
+// second, until called again; a zero lead stops watching.
+func WatchOrderExpiry(lead time.Duration) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if expiryWatch.stop != nil {
+		close(expiryWatch.stop)
+		expiryWatch.stop = nil
+	}
+	expiryWatch.lead = lead
+	if lead <= 0 {
+		return
+	}
+	stop := make(chan struct{})
+	expiryWatch.stop, expiryWatch.notified = stop, map[*trackedOrder]bool{}
+
+	done := trackTask(taskRefresher, "orderExpiry")
+	go func() {
+		defer done()
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			notifyExpiringOrders(stop)
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			case <-shutdown:
+				return
+			}
+		}
+	}()
+}
+
+// notifyExpiringOrders emits "orderExpiring" for the orders entering the lead window of the watcher stop
+// belongs to, and forgets the notified orders no longer tracked.
+func notifyExpiringOrders(stop chan struct{}) {
+	now := client.Now()
+	stateMu.Lock()
+	if expiryWatch.stop != stop {
+		stateMu.Unlock()
+		return
+	}
+	tracked := map[*trackedOrder]bool{}
+	for _, orders := range ownOrders {
+		for _, o := range orders {
+			tracked[o] = true
+		}
+	}
+	for o := range expiryWatch.notified {
+		if !tracked[o] {
+			delete(expiryWatch.notified, o)
+		}
+	}
+	var fields []map[string]any
+	for _, o := range expiringOrdersLocked(nil, now, expiryWatch.lead) {
+		if expiryWatch.notified[o] {
+			continue
+		}
+		expiryWatch.notified[o] = true
+		f := o.result()
+		f["expiresInMs"] = o.OrderExpiry - now.UnixMilli()
+		fields = append(fields, f)
+	}
+	stateMu.Unlock()
+
+	for _, f := range fields {
+		emitEvent("orderExpiring", f)
+	}
+}
+
+// jsGetExpiringOrders expects (withinSeconds, accountIndex?) and returns {orders}, the resting orders, of
+// accountIndex or of every account, expiring within withinSeconds, soonest first, each with expiresInMs.
+func jsGetExpiringOrders(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "GetExpiringOrders expects at least 1 arg: withinSeconds"})
+	}
+	within, err := intArg("withinSeconds", args[0], 0, int64(txtypes.MaxOrderExpiryPeriod/1000))
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	var accountIndex *int64
+	if len(args) > 1 && args[1].Type() == js.TypeNumber {
+		accIdx, err := intArg("accountIndex", args[1], txtypes.MinAccountIndex, txtypes.MaxAccountIndex)
+		if err != nil {
+			return js.ValueOf(map[string]any{"error": wrapErr(err)})
+		}
+		accountIndex = &accIdx
+	}
+
+	now := client.Now()
+	stateMu.RLock()
+	orders := expiringOrdersLocked(accountIndex, now, time.Duration(within)*time.Second)
+	res := make([]any, 0, len(orders))
+	for _, o := range orders {
+		r := o.result()
+		r["expiresInMs"] = o.OrderExpiry - now.UnixMilli()
+		res = append(res, r)
+	}
+	stateMu.RUnlock()
+	return js.ValueOf(map[string]any{"orders": res, "error": ""})
+}
+
+// jsWatchOrderExpiry expects (leadSeconds) and returns {leadSeconds}. Subscribers of "orderExpiring" are then
+// notified once for every resting order leadSeconds before its expiry, so that it can be refreshed in time;
+// 0 stops watching.
+func jsWatchOrderExpiry(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "WatchOrderExpiry expects 1 arg: leadSeconds"})
+	}
+	lead, err := intArg("leadSeconds", args[0], 0, int64(txtypes.MaxOrderExpiryPeriod/1000))
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	WatchOrderExpiry(time.Duration(lead) * time.Second)
+	return js.ValueOf(map[string]any{"leadSeconds": lead, "error": ""})
+}
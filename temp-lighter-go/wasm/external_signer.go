@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/policy"
+	"github.com/elliottech/lighter-go/signer"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// jsSignFunc adapts a JS callback to a signer.SignFunc. The callback receives the 0x-prefixed hex of the hashed
+// message and must synchronously return the signature, either hex-encoded or as a Uint8Array.
+func jsSignFunc(callback js.Value) signer.SignFunc {
+	return func(hashedMessage []byte) ([]byte, error) {
+		res := callback.Invoke(hexutil.Encode(hashedMessage))
+
+		switch {
+		case res.Type() == js.TypeString:
+			return hexutil.Decode(res.String())
+		case res.InstanceOf(js.Global().Get("Uint8Array")):
+			sig := make([]byte, res.Get("length").Int())
+			js.CopyBytesToGo(sig, res)
+			return sig, nil
+		case res.Type() == js.TypeObject && res.Get("then").Type() == js.TypeFunction:
+			return nil, fmt.Errorf("callback returned a Promise; asynchronous signers have to use PrepareTx and FinalizeTx")
+		default:
+			return nil, fmt.Errorf("callback returned %s, expected a hex string or Uint8Array", res.Type())
+		}
+	}
+}
+
+// CreateExternalSignerClient registers a client for publicKey whose signatures are produced by callback instead
+// of a private key loaded into the module. restrictions, when not nil, is enforced for the lifetime of the client.
+func CreateExternalSignerClient(publicKey string, accountIndex int64, apiKeyIndex uint8, chainId uint32, callback js.Value, httpClient *client.HTTPClient, restrictions *policy.Policy) (int, error) {
+	pubKey, err := parsePublicKey(publicKey)
+	if err != nil {
+		return 0, err
+	}
+	keyManager, err := signer.NewExternalKeyManager(pubKey, jsSignFunc(callback))
+	if err != nil {
+		return 0, err
+	}
+
+	c := installChecks(client.NewTxClientWithKeyManager(httpClient, keyManager, accountIndex, apiKeyIndex, chainId))
+	if restrictions != nil {
+		c.AddTxCheck(restrictions.Check)
+	}
+	clientIndex := registerClient(c)
+	setClientPolicy(clientIndex, restrictions)
+	return clientIndex, nil
+}
+
+// jsCreateExternalSignerClient expects (publicKey, accountIndex, apiKeyIndex, chainId, signCallback, baseUrl?,
+// options?), options being the same as for CreateClient.
+func jsCreateExternalSignerClient(this js.Value, args []js.Value) any {
+	if len(args) < 5 {
+		return js.ValueOf(map[string]any{"error": "CreateExternalSignerClient expects 5 args: publicKey, accountIndex, apiKeyIndex, chainId, signCallback"})
+	}
+	if args[4].Type() != js.TypeFunction {
+		return js.ValueOf(map[string]any{"error": "signCallback must be a function"})
+	}
+
+	httpClient, err := httpClientFromArgs(args, 5)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+
+	options := js.Undefined()
+	if len(args) > 6 {
+		options = args[6]
+	}
+	restrictions, err := readClientOptions("options", options)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+
+	clientIndex, err := CreateExternalSignerClient(args[0].String(), int64(args[1].Int()), uint8(args[2].Int()), uint32(args[3].Int()), args[4], httpClient, restrictions)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"clientIndex": clientIndex, "error": ""})
+}
@@ -0,0 +1,3 @@
+package main
+
+//go:generate go run ./internal/gendts -dts ../../wasm/lighter-signer.d.ts -manifest ../../wasm/lighter-signer.manifest.json
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+)
+
+// errExchangeHalted is returned for submissions attempted while the exchange is halted.
+var errExchangeHalted = errors.New("exchange is halted")
+
+// exchangeHalt is whether the exchange was last seen halted, e.g. for maintenance, guarded by stateMu. It is
+// learned from rejections, CheckExchangeStatus and SetExchangeHalted.
+var exchangeHalt struct {
+	halted bool
+	reason string
+	since  time.Time
+}
+
+// setExchangeHalted records whether the exchange is halted and, when that changes, emits "halted" or "resumed".
+// On resumption, the txs queued meanwhile are flushed.
+func setExchangeHalted(halted bool, reason string) {
+	if !halted {
+		reason = ""
+	}
+	stateMu.Lock()
+	if exchangeHalt.halted == halted {
+		if halted && reason != "" {
+			exchangeHalt.reason = reason
+		}
+		stateMu.Unlock()
+		return
+	}
+	exchangeHalt.halted, exchangeHalt.reason, exchangeHalt.since = halted, reason, time.Now()
+	stateMu.Unlock()
+
+	if halted {
+		logEvent("warn", "exchange.halted", "exchange is halted: "+reason, nil)
+		emitEvent("halted", map[string]any{"reason": reason})
+		return
+	}
+	logEvent("info", "exchange.resumed", "exchange resumed", nil)
+	emitEvent("resumed", map[string]any{})
+	done := trackTask(taskAsync, "resumed")
+	go func() {
+		defer done()
+		FlushTxQueue(callClock{})
+	}()
+}
+
+// noteExchangeStatus updates the halt state from the outcome of a submission: an accepted tx means the exchange
+// is up, a refusal for maintenance that it is halted. Other failures tell nothing.
+func noteExchangeStatus(err error) {
+	if err == nil {
+		setExchangeHalted(false, "")
+	} else if client.IsHaltError(err) {
+		setExchangeHalted(true, err.Error())
+	}
+}
+
+// checkNotHalted returns errExchangeHalted, with the reason, while the exchange is halted.
+func checkNotHalted() error {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	if !exchangeHalt.halted {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", errExchangeHalted, exchangeHalt.reason)
+}
+
+func exchangeHaltResult() map[string]any {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	var since int64
+	if exchangeHalt.halted {
+		since = exchangeHalt.since.UnixMilli()
+	}
+	return map[string]any{"halted": exchangeHalt.halted, "reason": exchangeHalt.reason, "since": since, "error": ""}
+}
+
+// jsCheckExchangeStatus expects (clientIndex?) and returns a Promise resolving to {halted, reason, since,
+// status}: the status the exchange reports, and whether it is halted since the millisecond timestamp since.
+// Submissions fail fast with code EXCHANGE_HALTED while it is, unless queued, see SubmitTx.
+func jsCheckExchangeStatus(this js.Value, args []js.Value) any {
+	c, err := clientFromArgs(args, 0)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	if c.HTTP() == nil {
+		return js.ValueOf(map[string]any{"error": "HTTP client not configured"})
+	}
+
+	return newPromise(func(callClock) map[string]any {
+		status, err := c.HTTP().GetExchangeStatus()
+		if err != nil {
+			return map[string]any{"error": wrapErr(err)}
+		}
+		reason := status.Message
+		if reason == "" {
+			reason = fmt.Sprintf("status %d", status.Status)
+		}
+		setExchangeHalted(status.Status != client.CodeOK, reason)
+		res := exchangeHaltResult()
+		res["status"] = status.Status
+		return res
+	})
+}
+
+// jsGetExchangeStatus expects () and returns {halted, reason, since} as last seen, see CheckExchangeStatus.
+func jsGetExchangeStatus(this js.Value, args []js.Value) any {
+	return js.ValueOf(exchangeHaltResult())
+}
+
+// jsSetExchangeHalted expects (halted, reason?) and returns {halted, reason, since}, for hosts learning of halts
+// from elsewhere, e.g. a status feed. Clearing the halt flushes the txs queued meanwhile.
+func jsSetExchangeHalted(this js.Value, args []js.Value) any {
+	if len(args) < 1 || args[0].Type() != js.TypeBoolean {
+		return js.ValueOf(map[string]any{"error": "SetExchangeHalted expects at least 1 arg: halted, a boolean"})
+	}
+	reason := "set by host"
+	if len(args) > 1 && args[1].Type() == js.TypeString {
+		reason = args[1].String()
+	}
+	setExchangeHalted(args[0].Bool(), reason)
+	return js.ValueOf(exchangeHaltResult())
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// heartbeatInterval is how often the background heartbeat ticks. Ping reports the instance as unhealthy once
+// the heartbeat is staleHeartbeats intervals late, i.e. goroutines are no longer being scheduled.
+const (
+	heartbeatInterval = time.Second
+	staleHeartbeats   = 3
+)
+
+var startedAt = time.Now()
+
+// Kinds of background tasks: async ones settle a Promise or handle a host event, refreshers run until Shutdown.
+const (
+	taskAsync     = "async"
+	taskRefresher = "refresher"
+)
+
+// task is a goroutine registered through trackTask. name is the export that started it, or what it handles or
+// refreshes.
+type task struct {
+	kind      string
+	name      string
+	startedAt time.Time
+}
+
+// health is updated by the heartbeat goroutine, every successful signature and every background task.
+var health struct {
+	mu            sync.Mutex
+	lastHeartbeat time.Time
+	lastSignAt    time.Time
+	signCount     uint64
+	nextTaskID    uint64
+	tasks         map[uint64]task
+}
+
+func startHeartbeat() {
+	health.mu.Lock()
+	health.lastHeartbeat = time.Now()
+	health.tasks = map[uint64]task{}
+	health.mu.Unlock()
+
+	done := trackTask(taskRefresher, "heartbeat")
+	go func() {
+		defer done()
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				health.mu.Lock()
+				health.lastHeartbeat = now
+				health.mu.Unlock()
+			case <-shutdown:
+				return
+			}
+		}
+	}()
+}
+
+// recordSign notes the signature of tx, or of an auth token when tx is nil. Unsigned txs, e.g. prepared ones,
+// are ignored.
+func recordSign(tx txtypes.TxInfo) {
+	if tx != nil && tx.GetTxHash() == "" {
+		return
+	}
+	health.mu.Lock()
+	health.lastSignAt = time.Now()
+	health.signCount++
+	health.mu.Unlock()
+	if tx != nil {
+		recordMarketSign(tx)
+	}
+}
+
+// trackTask registers a running background task of kind and returns the func marking it as done.
+func trackTask(kind, name string) func() {
+	health.mu.Lock()
+	defer health.mu.Unlock()
+	id := health.nextTaskID
+	health.nextTaskID++
+	health.tasks[id] = task{kind: kind, name: name, startedAt: time.Now()}
+	return func() {
+		health.mu.Lock()
+		delete(health.tasks, id)
+		health.mu.Unlock()
+	}
+}
+
+func millis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+func jsPing(this js.Value, args []js.Value) any {
+	now := time.Now()
+
+	health.mu.Lock()
+	heartbeatAge := now.Sub(health.lastHeartbeat)
+	var pendingTasks int
+	var oldestTask time.Duration
+	for _, t := range health.tasks {
+		if t.kind == taskRefresher {
+			continue
+		}
+		pendingTasks++
+		if age := now.Sub(t.startedAt); age > oldestTask {
+			oldestTask = age
+		}
+	}
+	res := map[string]any{
+		"healthy":            heartbeatAge < staleHeartbeats*heartbeatInterval,
+		"uptimeMs":           now.Sub(startedAt).Milliseconds(),
+		"lastSignAt":         millis(health.lastSignAt),
+		"signCount":          health.signCount,
+		"lastHeartbeatAgeMs": heartbeatAge.Milliseconds(),
+		"pendingTasks":       pendingTasks,
+		"oldestTaskAgeMs":    oldestTask.Milliseconds(),
+		"goroutines":         runtime.NumGoroutine(),
+		"error":              "",
+	}
+	health.mu.Unlock()
+
+	return js.ValueOf(res)
+}
+
+// jsGetPendingOperations lists the background tasks still running, async exports and refreshers, and the txs
+// queued by SubmitTx, so that callers can drain them before Shutdown or spot a stuck one.
+func jsGetPendingOperations(this js.Value, args []js.Value) any {
+	now := time.Now()
+
+	health.mu.Lock()
+	ids := make([]uint64, 0, len(health.tasks))
+	for id := range health.tasks {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	tasks := make([]any, 0, len(ids))
+	for _, id := range ids {
+		t := health.tasks[id]
+		tasks = append(tasks, map[string]any{
+			"id":        id,
+			"kind":      t.kind,
+			"name":      t.name,
+			"startedAt": t.startedAt.UnixMilli(),
+			"ageMs":     now.Sub(t.startedAt).Milliseconds(),
+		})
+	}
+	health.mu.Unlock()
+
+	txQueue.mu.Lock()
+	queued := make([]any, 0, len(txQueue.txs))
+	for _, q := range txQueue.txs {
+		queued = append(queued, map[string]any{
+			"txHash":    q.txHash,
+			"txType":    q.tx.GetTxType(),
+			"queuedAt":  q.queuedAt.UnixMilli(),
+			"ageMs":     now.Sub(q.queuedAt).Milliseconds(),
+			"expiresAt": q.expiresAt.UnixMilli(),
+		})
+	}
+	txQueue.mu.Unlock()
+
+	return js.ValueOf(map[string]any{"tasks": tasks, "queued": queued, "error": ""})
+}
+
+// jsGetMemoryStats expects (forceGC?). When forceGC is true a collection runs first, so that heapInUse reflects
+// live memory only.
+func jsGetMemoryStats(this js.Value, args []js.Value) any {
+	if len(args) > 0 && args[0].Truthy() {
+		runtime.GC()
+	}
+
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	var lastPause uint64
+	if m.NumGC > 0 {
+		lastPause = m.PauseNs[(m.NumGC+255)%256]
+	}
+	var lastGC int64
+	if m.LastGC != 0 {
+		lastGC = time.Unix(0, int64(m.LastGC)).UnixMilli()
+	}
+
+	return js.ValueOf(map[string]any{
+		"heapInUse":      m.HeapInuse,
+		"heapAlloc":      m.HeapAlloc,
+		"heapSys":        m.HeapSys,
+		"heapObjects":    m.HeapObjects,
+		"sys":            m.Sys,
+		"totalAlloc":     m.TotalAlloc,
+		"mallocs":        m.Mallocs,
+		"frees":          m.Frees,
+		"numGC":          m.NumGC,
+		"lastGCAt":       lastGC,
+		"lastGCPauseNs":  lastPause,
+		"totalGCPauseNs": m.PauseTotalNs,
+		"goroutines":     runtime.NumGoroutine(),
+		"error":          "",
+	})
+}
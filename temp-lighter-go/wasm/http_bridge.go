@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"syscall/js"
+)
+
+// fetchTransport is an http.RoundTripper backed by the host's fetch function. Go's own js/wasm transport
+// refuses to use fetch under Node and whenever a custom dialer is configured, which leaves the HTTP client
+// unusable from the signer otherwise. fetch, when set, is called instead of the global one, and init is merged
+// into the options of every call, e.g. {dispatcher} to route them through a proxy under Node.
+type fetchTransport struct {
+	fetch js.Value
+	init  map[string]any
+}
+
+func (t *fetchTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if replayOffline.Load() {
+		return nil, errReplayOffline
+	}
+	fetch := t.fetch
+	if fetch.Type() != js.TypeFunction {
+		fetch = js.Global().Get("fetch")
+	}
+	if fetch.Type() != js.TypeFunction {
+		return nil, fmt.Errorf("fetch is not available in this runtime")
+	}
+
+	headers := map[string]any{}
+	for k, v := range req.Header {
+		if len(v) > 0 {
+			headers[k] = v[0]
+		}
+	}
+	init := map[string]any{}
+	for k, v := range t.init {
+		init[k] = v
+	}
+	init["method"], init["headers"] = req.Method, headers
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		arr := js.Global().Get("Uint8Array").New(len(body))
+		js.CopyBytesToJS(arr, body)
+		init["body"] = arr
+	}
+
+	resp, err := await(req, fetch.Invoke(req.URL.String(), init))
+	if err != nil {
+		return nil, err
+	}
+	buf, err := await(req, resp.Call("arrayBuffer"))
+	if err != nil {
+		return nil, err
+	}
+	arr := js.Global().Get("Uint8Array").New(buf)
+	data := make([]byte, arr.Get("length").Int())
+	js.CopyBytesToGo(data, arr)
+
+	header := http.Header{}
+	forEach := js.FuncOf(func(this js.Value, args []js.Value) any {
+		header.Add(args[1].String(), args[0].String())
+		return nil
+	})
+	resp.Get("headers").Call("forEach", forEach)
+	forEach.Release()
+
+	status := resp.Get("status").Int()
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + resp.Get("statusText").String(),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}, nil
+}
+
+// await blocks the calling goroutine until the promise returned by fetch for req settles.
+func await(req *http.Request, promise js.Value) (js.Value, error) {
+	v, rejected, err := awaitPromise(req.Context(), promise)
+	if rejected {
+		return js.Undefined(), fmt.Errorf("fetch failed: %s", v.Call("toString").String())
+	}
+	return v, err
+}
+
+// awaitPromise blocks the calling goroutine until promise settles or ctx is done, and returns the value it was
+// fulfilled with, or the reason it was rejected with and rejected set. It must never be called from the goroutine
+// running a js.FuncOf handler, as the event loop cannot make progress while that handler is blocked.
+func awaitPromise(ctx context.Context, promise js.Value) (value js.Value, rejected bool, err error) {
+	type settled struct {
+		value    js.Value
+		rejected bool
+	}
+	done := make(chan settled, 1)
+
+	// The callbacks release themselves, since the promise may settle after ctx is done.
+	var onFulfilled, onRejected js.Func
+	onFulfilled = js.FuncOf(func(this js.Value, args []js.Value) any {
+		done <- settled{value: args[0]}
+		onFulfilled.Release()
+		onRejected.Release()
+		return nil
+	})
+	onRejected = js.FuncOf(func(this js.Value, args []js.Value) any {
+		done <- settled{value: args[0], rejected: true}
+		onFulfilled.Release()
+		onRejected.Release()
+		return nil
+	})
+
+	promise.Call("then", onFulfilled, onRejected)
+	select {
+	case res := <-done:
+		return res.value, res.rejected, nil
+	case <-ctx.Done():
+		return js.Undefined(), false, ctx.Err()
+	}
+}
+
+// newPromise runs fn on its own goroutine and returns a JS Promise resolved with its result, so that exports
+// doing network round trips do not block the event loop. fn reads the time from clock, the clock of the export
+// call, see callClock.
+func newPromise(fn func(clock callClock) map[string]any) js.Value {
+	clock := clockOfCall()
+	executor := js.FuncOf(func(this js.Value, args []js.Value) any {
+		resolve := args[0]
+		done := trackTask(taskAsync, activeExport)
+		go func() {
+			var res map[string]any
+			defer done()
+			defer func() {
+				if r := recover(); r != nil {
+					res = map[string]any{"error": wrapErr(fmt.Errorf("%v", r))}
+				}
+				resolve.Invoke(js.ValueOf(res))
+			}()
+			res = fn(clock)
+		}()
+		return nil
+	})
+	defer executor.Release()
+
+	return js.Global().Get("Promise").New(executor)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/client"
+)
+
+// maxHTTPHeaders bounds the headers ConfigureHTTP accepts.
+const maxHTTPHeaders = 32
+
+// httpConfig is how the requests of a client reach the exchange, as set by ConfigureHTTP.
+type httpConfig struct {
+	headers    map[string]string
+	proxyURL   string
+	tls        js.Value
+	dispatcher js.Value
+	fetch      js.Value
+}
+
+// readHTTPConfig reads options, {headers?, proxyUrl?, tls?, dispatcher?, fetch?}. The header values are
+// registered as secrets so that they never reach the logs.
+func readHTTPConfig(options js.Value) (*httpConfig, error) {
+	cfg := &httpConfig{headers: map[string]string{}}
+	keys := js.Global().Get("Object").Call("keys", options)
+	for i := 0; i < keys.Length(); i++ {
+		switch key := keys.Index(i).String(); key {
+		case "headers", "proxyUrl", "tls", "dispatcher", "fetch":
+		default:
+			return nil, fmt.Errorf("options: unknown field %s", key)
+		}
+	}
+
+	if headers := options.Get("headers"); headers.Type() != js.TypeUndefined {
+		if headers.Type() != js.TypeObject {
+			return nil, fmt.Errorf("headers should be an object of strings")
+		}
+		names := js.Global().Get("Object").Call("keys", headers)
+		if names.Length() > maxHTTPHeaders {
+			return nil, fmt.Errorf("at most %d headers are allowed", maxHTTPHeaders)
+		}
+		for i := 0; i < names.Length(); i++ {
+			name := names.Index(i).String()
+			v := headers.Get(name)
+			if v.Type() != js.TypeString {
+				return nil, fmt.Errorf("headers.%s should be a string", name)
+			}
+			registerSecret(v.String())
+			cfg.headers[name] = v.String()
+		}
+	}
+	if v := options.Get("proxyUrl"); v.Type() != js.TypeUndefined {
+		if v.Type() != js.TypeString || v.String() == "" {
+			return nil, fmt.Errorf("proxyUrl should be a non-empty string")
+		}
+		registerSecret(v.String())
+		cfg.proxyURL = v.String()
+	}
+	if v := options.Get("tls"); v.Type() != js.TypeUndefined {
+		if v.Type() != js.TypeObject {
+			return nil, fmt.Errorf("tls should be an object, e.g. {ca, rejectUnauthorized}")
+		}
+		cfg.tls = v
+	}
+	if v := options.Get("dispatcher"); v.Type() != js.TypeUndefined {
+		if v.Type() != js.TypeObject {
+			return nil, fmt.Errorf("dispatcher should be an object")
+		}
+		if cfg.proxyURL != "" || cfg.tls.Type() == js.TypeObject {
+			return nil, fmt.Errorf("dispatcher cannot be combined with proxyUrl or tls, configure them on it")
+		}
+		cfg.dispatcher = v
+	}
+	if v := options.Get("fetch"); v.Type() != js.TypeUndefined {
+		if v.Type() != js.TypeFunction {
+			return nil, fmt.Errorf("fetch should be a function")
+		}
+		cfg.fetch = v
+	}
+	return cfg, nil
+}
+
+// undiciDispatcher builds, with the undici module of the host, the dispatcher routing fetch calls through
+// proxyURL, when set, with the TLS options tls, {ca?, rejectUnauthorized?, ...} as accepted by undici. Only hosts
+// exposing a global require, e.g. Node running wasm_exec_node.js, can.
+func undiciDispatcher(proxyURL string, tls js.Value) (dispatcher js.Value, err error) {
+	require := js.Global().Get("require")
+	if require.Type() != js.TypeFunction {
+		return js.Undefined(), fmt.Errorf("proxyUrl and tls need the undici module, which cannot be loaded here; pass a dispatcher instead")
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to create the undici dispatcher: %v", r)
+		}
+	}()
+
+	undici := require.Invoke("undici")
+	opts := map[string]any{}
+	if proxyURL == "" {
+		if tls.Type() == js.TypeObject {
+			opts["connect"] = tls
+		}
+		return undici.Get("Agent").New(opts), nil
+	}
+	opts["uri"] = proxyURL
+	if tls.Type() == js.TypeObject {
+		opts["requestTls"] = tls
+		opts["proxyTls"] = tls
+	}
+	return undici.Get("ProxyAgent").New(opts), nil
+}
+
+// ConfigureHTTP applies cfg to the HTTP client of c, shared with the clients cloned from it: its headers are sent with every request and the requests
+// go through cfg.fetch, cfg.dispatcher or the dispatcher built for cfg.proxyURL and cfg.tls.
+func ConfigureHTTP(c *client.TxClient, cfg *httpConfig) error {
+	if c.HTTP() == nil {
+		return fmt.Errorf("HTTP client not configured")
+	}
+	dispatcher := cfg.dispatcher
+	if cfg.proxyURL != "" || cfg.tls.Type() == js.TypeObject {
+		var err error
+		if dispatcher, err = undiciDispatcher(cfg.proxyURL, cfg.tls); err != nil {
+			return err
+		}
+	}
+
+	c.HTTP().SetHeaders(cfg.headers)
+	if dispatcher.Type() == js.TypeUndefined && cfg.fetch.Type() == js.TypeUndefined {
+		c.HTTP().SetRoundTripper(nil)
+		return nil
+	}
+	t := &fetchTransport{fetch: cfg.fetch, init: map[string]any{}}
+	if dispatcher.Type() != js.TypeUndefined {
+		t.init["dispatcher"] = dispatcher
+	}
+	c.HTTP().SetRoundTripper(t)
+	return nil
+}
+
+// jsConfigureHTTP expects (options, clientIndex?) and returns {headers, proxy, customFetch}: the names of the
+// headers now sent with every request of the client, and whether its requests go through a proxy or dispatcher
+// and a custom fetch. options is {headers?, proxyUrl?, tls?, dispatcher?, fetch?}: headers are sent with every
+// request, e.g. the credentials of a gateway; proxyUrl and tls, e.g. {ca, rejectUnauthorized}, configure an
+// undici dispatcher under Node; dispatcher is passed to fetch as is, and fetch replaces the global one. Each
+// call replaces the previous configuration; {} restores the defaults.
+func jsConfigureHTTP(this js.Value, args []js.Value) any {
+	if len(args) < 1 || args[0].Type() != js.TypeObject {
+		return js.ValueOf(map[string]any{"error": "ConfigureHTTP expects at least 1 arg: options"})
+	}
+	c, err := clientFromArgs(args, 1)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	cfg, err := readHTTPConfig(args[0])
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	if err := ConfigureHTTP(c, cfg); err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+
+	names := make([]string, 0, len(cfg.headers))
+	for name := range cfg.headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	headers := make([]any, len(names))
+	for i, name := range names {
+		headers[i] = name
+	}
+	proxy := cfg.proxyURL != "" || cfg.tls.Type() == js.TypeObject || cfg.dispatcher.Type() != js.TypeUndefined
+	return js.ValueOf(map[string]any{"headers": headers, "proxy": proxy, "customFetch": cfg.fetch.Type() == js.TypeFunction, "error": ""})
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"syscall/js"
+)
+
+// maxTxRequestBytes bounds the JSON accepted for one tx request; real requests are a few hundred bytes.
+const maxTxRequestBytes = 16 << 10
+
+// maxSafeInteger is the largest integer a JS number holds exactly. Larger values read from JS objects may have
+// been rounded before they reached the module, so they must be passed in a JSON string instead.
+const maxSafeInteger = 1<<53 - 1
+
+// txTypeFields lists the txParams fields each tx type reads, besides nonce and expiredAt. Fields of other tx
+// types are rejected rather than ignored, as they usually mean the request was built for another tx type.
+var txTypeFields = map[string]struct{ required, optional []string }{
+	"transfer": {
+		required: []string{"toAccountIndex", "usdcAmount"},
+		optional: []string{"fee", "memo"},
+	},
+	"withdraw": {
+		required: []string{"usdcAmount"},
+	},
+	"createOrder": {
+		required: []string{"marketIndex", "baseAmount", "price", "isAsk", "orderType", "timeInForce"},
+		optional: []string{"clientOrderIndex", "reduceOnly", "triggerPrice", "orderExpiry"},
+	},
+	"cancelOrder": {
+		required: []string{"marketIndex", "orderIndex"},
+	},
+}
+
+// txRequestSchema narrows txParamsSchema to the fields of txType.
+func txRequestSchema(txType string) (objectSchema, error) {
+	fields, ok := txTypeFields[txType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported tx type: %q", txType)
+	}
+
+	s := objectSchema{"nonce": txParamsSchema["nonce"], "expiredAt": txParamsSchema["expiredAt"]}
+	for _, name := range fields.required {
+		field := txParamsSchema[name]
+		field.Required = true
+		s[name] = field
+	}
+	for _, name := range fields.optional {
+		s[name] = txParamsSchema[name]
+	}
+	return s, nil
+}
+
+// readTxRequest returns the JSON of v, either a JSON string or a plain object. Objects JSON.stringify throws on,
+// like cyclic ones or ones holding a BigInt, are reported as errors. exact is false for objects, whose numbers
+// went through float64.
+func readTxRequest(v js.Value) (data []byte, exact bool, err error) {
+	switch v.Type() {
+	case js.TypeString:
+		data, exact = []byte(v.String()), true
+	case js.TypeObject:
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("params cannot be serialized: %v", r)
+			}
+		}()
+		data = []byte(js.Global().Get("JSON").Call("stringify", v).String())
+	default:
+		return nil, false, fmt.Errorf("params should be an object or a JSON string, got %s", v.Type())
+	}
+	if len(data) > maxTxRequestBytes {
+		return nil, false, fmt.Errorf("params are %d bytes, more than the %d allowed", len(data), maxTxRequestBytes)
+	}
+	return data, exact, nil
+}
+
+// checkFlatObject checks that data holds exactly one JSON object, without duplicate keys nor nested values, and
+// that every integer in it is exact unless exact is set.
+func checkFlatObject(data []byte, exact bool) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return fmt.Errorf("expected a JSON object")
+	}
+	seen := map[string]bool{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("malformed JSON: %w", err)
+		}
+		key := tok.(string)
+		if seen[key] {
+			return fmt.Errorf("%s: duplicate field", key)
+		}
+		seen[key] = true
+
+		tok, err = dec.Token()
+		if err != nil {
+			return fmt.Errorf("malformed JSON: %w", err)
+		}
+		switch v := tok.(type) {
+		case json.Delim:
+			return fmt.Errorf("%s: nested values are not accepted", key)
+		case json.Number:
+			if n, err := strconv.ParseInt(v.String(), 10, 64); err == nil && !exact && (n > maxSafeInteger || n < -maxSafeInteger) {
+				return fmt.Errorf("%s: %d is not a safe integer, pass params as a JSON string to use it", key, n)
+			}
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("malformed JSON: %w", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after the JSON object")
+	}
+	return nil
+}
+
+// parseTxRequest is the only way tx requests from JS become txParams. It never panics, whatever data holds, and
+// the params it returns hold every field txType needs within the bounds of its type. The tx specific checks are
+// still left to Validate.
+func parseTxRequest(txType string, data []byte, exact bool) (*txParams, error) {
+	schema, err := txRequestSchema(txType)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxTxRequestBytes {
+		return nil, fmt.Errorf("params are %d bytes, more than the %d allowed", len(data), maxTxRequestBytes)
+	}
+	if err := checkFlatObject(data, exact); err != nil {
+		return nil, &SchemaError{Name: "params", Violations: []string{err.Error()}}
+	}
+	if violations := schema.validate(data); len(violations) > 0 {
+		return nil, &SchemaError{Name: "params", Violations: violations}
+	}
+
+	params := &txParams{}
+	if err := json.Unmarshal(data, params); err != nil {
+		return nil, err
+	}
+	if err := params.checkTimes(); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+	"testing"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+)
+
+// txRequestSeeds are valid requests of every tx type PrepareTx takes, and near misses of them.
+var txRequestSeeds = []struct {
+	txType string
+	data   string
+}{
+	{"transfer", `{"nonce":1,"toAccountIndex":7,"usdcAmount":100,"fee":0,"memo":"rent"}`},
+	{"withdraw", `{"nonce":2,"usdcAmount":1000000}`},
+	{"createOrder", `{"nonce":3,"marketIndex":1,"baseAmount":10,"price":1000,"isAsk":0,"orderType":0,"timeInForce":0}`},
+	{"createOrder", `{"nonce":4,"marketIndex":1,"clientOrderIndex":77,"baseAmount":10,"price":1000,"isAsk":1,"orderType":1,"timeInForce":1,"reduceOnly":1,"triggerPrice":990,"orderExpiry":-1}`},
+	{"cancelOrder", `{"nonce":5,"marketIndex":1,"orderIndex":281474976710656}`},
+	{"transfer", `{"nonce":1,"toAccountIndex":7,"usdcAmount":100,"toAccountIndex":8}`},
+	{"transfer", `{"nonce":1,"toAccountIndex":{"value":7},"usdcAmount":100}`},
+	{"withdraw", `{"nonce":2,"usdcAmount":1e3}`},
+	{"withdraw", `{"nonce":2,"usdcAmount":18446744073709551616}`},
+	{"withdraw", `{"nonce":2,"usdcAmount":9007199254740993}`},
+	{"withdraw", `{"nonce":2,"usdcAmount":1} {}`},
+	{"createOrder", `{"nonce":3,"marketIndex":1,"baseAmount":10}`},
+	{"cancelOrder", `{"nonce":5,"marketIndex":1,"orderIndex":3,"price":1}`},
+	{"mint", `{"nonce":1}`},
+	{"transfer", `[1,2]`},
+	{"transfer", `null`},
+	{"transfer", ``},
+}
+
+// parseJS parses data with JSON.parse, as page code would before passing it, reporting false when it throws.
+func parseJS(data string) (v js.Value, ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return js.Global().Get("JSON").Call("parse", data), true
+}
+
+// FuzzDecodeStrict checks that decodeStrict never panics, and that whatever it decodes satisfies the schema.
+func FuzzDecodeStrict(f *testing.F) {
+	for _, seed := range txRequestSeeds {
+		f.Add(seed.data)
+	}
+	f.Add(`{"nonce":"1"}`)
+	f.Add(`{"memo":"` + string(make([]byte, 40)) + `"}`)
+	f.Add(`{"isAsk":true}`)
+
+	f.Fuzz(func(t *testing.T, data string) {
+		v, ok := parseJS(data)
+		if !ok {
+			return
+		}
+		var out map[string]any
+		if err := decodeStrict("params", v, txParamsSchema, &out); err != nil {
+			return
+		}
+		decoded, err := json.Marshal(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if violations := txParamsSchema.validate(decoded); len(violations) > 0 {
+			t.Fatalf("decodeStrict accepted %s, which violates its schema: %v", data, violations)
+		}
+	})
+}
+
+// FuzzParseTxRequest checks that parseTxRequest never panics, and that every request it accepts either is refused
+// before signing or signs a tx which decodes and validates as a signed tx of its type.
+func FuzzParseTxRequest(f *testing.F) {
+	for _, seed := range txRequestSeeds {
+		f.Add(seed.txType, []byte(seed.data), true)
+		f.Add(seed.txType, []byte(seed.data), false)
+	}
+
+	km, err := generateKey()
+	if err != nil {
+		f.Fatal(err)
+	}
+	c := client.NewTxClientWithKeyManager(nil, km, 5, 2, benchmarkChainId)
+
+	f.Fuzz(func(t *testing.T, txType string, data []byte, exact bool) {
+		check := func(params *txParams) {
+			tx, _, err := buildUnsignedTx(c, txType, params)
+			if err != nil {
+				return
+			}
+			msgHash, err := c.PrepareTx(tx)
+			if err != nil {
+				return
+			}
+			if err := c.SignPreparedTx(tx, msgHash); err != nil {
+				t.Fatal(err)
+			}
+			txInfo, err := tx.GetTxInfo()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := types.DecodeSignedTx(tx.GetTxType(), txInfo); err != nil {
+				t.Fatalf("%s request %s signed an invalid tx: %v", txType, data, err)
+			}
+		}
+
+		if params, err := parseTxRequest(txType, data, exact); err == nil {
+			check(params)
+		}
+
+		// The same request passed as an object rather than a JSON string.
+		v, ok := parseJS(string(data))
+		if !ok || v.Type() != js.TypeObject {
+			return
+		}
+		objData, objExact, err := readTxRequest(v)
+		if err != nil {
+			return
+		}
+		if params, err := parseTxRequest(txType, objData, objExact); err == nil {
+			check(params)
+		}
+	})
+}
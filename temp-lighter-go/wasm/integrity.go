@@ -0,0 +1,120 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+	curve "github.com/elliottech/poseidon_crypto/curve/ecgfp5"
+	g "github.com/elliottech/poseidon_crypto/field/goldilocks"
+	p2 "github.com/elliottech/poseidon_crypto/hash/poseidon2_goldilocks"
+	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// moduleHashComponents lists what GetModuleHash covers: the build identity without the build time, the protocol
+// constants and known answers of the hash, key derivation and tx hashing code. Two builds of the same source
+// give the same hash; a binary with altered constants or crypto does not.
+func moduleHashComponents() ([][2]string, error) {
+	info := GetVersion()
+	res := [][2]string{
+		{"version", info.Version},
+		{"commit", info.Commit},
+		{"lighterGoVersion", info.LighterGoVersion},
+	}
+	deps := make([]string, 0, len(info.Dependencies))
+	for path := range info.Dependencies {
+		deps = append(deps, path)
+	}
+	sort.Strings(deps)
+	for _, path := range deps {
+		res = append(res, [2]string{"dep:" + path, info.Dependencies[path]})
+	}
+
+	for _, c := range []struct {
+		name  string
+		value any
+	}{
+		{"TxTypeL2ChangePubKey", txtypes.TxTypeL2ChangePubKey},
+		{"TxTypeL2Transfer", txtypes.TxTypeL2Transfer},
+		{"TxTypeL2Withdraw", txtypes.TxTypeL2Withdraw},
+		{"TxTypeL2CreateOrder", txtypes.TxTypeL2CreateOrder},
+		{"TxTypeL2CancelOrder", txtypes.TxTypeL2CancelOrder},
+		{"TxTypeL2CancelAllOrders", txtypes.TxTypeL2CancelAllOrders},
+		{"TxTypeL2ModifyOrder", txtypes.TxTypeL2ModifyOrder},
+		{"TxTypeL2UpdateLeverage", txtypes.TxTypeL2UpdateLeverage},
+		{"TxTypeL2CreateGroupedOrders", txtypes.TxTypeL2CreateGroupedOrders},
+		{"TxTypeL2UpdateMargin", txtypes.TxTypeL2UpdateMargin},
+		{"MaxAccountIndex", txtypes.MaxAccountIndex},
+		{"MaxApiKeyIndex", txtypes.MaxApiKeyIndex},
+		{"MaxMarketIndex", txtypes.MaxMarketIndex},
+		{"MaxClientOrderIndex", txtypes.MaxClientOrderIndex},
+		{"MaxOrderIndex", txtypes.MaxOrderIndex},
+		{"MaxOrderBaseAmount", txtypes.MaxOrderBaseAmount},
+		{"MaxOrderPrice", txtypes.MaxOrderPrice},
+		{"MinOrderExpiryPeriod", txtypes.MinOrderExpiryPeriod},
+		{"MaxOrderExpiryPeriod", txtypes.MaxOrderExpiryPeriod},
+		{"MaxTransferAmount", txtypes.MaxTransferAmount},
+		{"FeeTick", txtypes.FeeTick},
+		{"CurveOrder", curve.ORDER},
+	} {
+		res = append(res, [2]string{"const:" + c.name, fmt.Sprint(c.value)})
+	}
+
+	preImage := make([]g.Element, 10)
+	for i := range preImage {
+		preImage[i] = g.FromUint64(uint64(i))
+	}
+	res = append(res, [2]string{"kat:poseidon2", hexutil.Encode(p2.HashToQuinticExtension(preImage).ToLittleEndianBytes())})
+	res = append(res, [2]string{"kat:publicKey", hexutil.Encode(schnorr.SchnorrPkFromSk(curve.ONE).ToLittleEndianBytes())})
+
+	order := &txtypes.L2CreateOrderTxInfo{
+		AccountIndex: 1,
+		ApiKeyIndex:  2,
+		OrderInfo: &txtypes.OrderInfo{
+			MarketIndex:      3,
+			ClientOrderIndex: 4,
+			BaseAmount:       5,
+			Price:            6,
+			TimeInForce:      txtypes.GoodTillTime,
+			OrderExpiry:      txtypes.MinPlausibleTimestamp,
+		},
+		ExpiredAt: txtypes.MinPlausibleTimestamp,
+		Nonce:     7,
+	}
+	orderHash, err := order.Hash(304)
+	if err != nil {
+		return nil, err
+	}
+	res = append(res, [2]string{"kat:createOrderHash", hexutil.Encode(orderHash)})
+	return res, nil
+}
+
+// GetModuleHash returns the sha256, hex-encoded, of the module hash components, one name=value line each.
+func GetModuleHash() (string, [][2]string, error) {
+	components, err := moduleHashComponents()
+	if err != nil {
+		return "", nil, err
+	}
+	var b strings.Builder
+	for _, c := range components {
+		fmt.Fprintf(&b, "%s=%s\n", c[0], c[1])
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hexutil.Encode(sum[:]), components, nil
+}
+
+func jsGetModuleHash(this js.Value, args []js.Value) any {
+	hash, components, err := GetModuleHash()
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	comps := map[string]any{}
+	for _, c := range components {
+		comps[c[0]] = c[1]
+	}
+	return js.ValueOf(map[string]any{"hash": hash, "algorithm": "sha256", "components": comps, "error": ""})
+}
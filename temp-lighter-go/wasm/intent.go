@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// orderIntentCheck names the check installed by installChecks which intentClient replaces for one call.
+const orderIntentCheck = "orderIntent"
+
+// requireIntent is set by RequireOrderIntent. From then on, orders are only signed by calls carrying the intent
+// hash the user approved. It is never cleared.
+var requireIntent atomic.Bool
+
+var errOrderIntentRequired = errors.New("an approved order intent is required to sign orders, pass options.intentHash")
+
+// checkOrderIntent is installed on every client created from JS, see installChecks, and refuses orders and
+// modifies while intents are required, as a modify could change an approved order once placed. Calls carrying an
+// intent hash sign with a client checking that intent instead, see intentClient. Simulations are not refused.
+func checkOrderIntent(tx txtypes.TxInfo) error {
+	if !requireIntent.Load() || types.IsDryRun(tx) {
+		return nil
+	}
+	switch tx.(type) {
+	case *txtypes.L2CreateOrderTxInfo, *txtypes.L2CreateGroupedOrdersTxInfo, *txtypes.L2ModifyOrderTxInfo:
+		return errOrderIntentRequired
+	}
+	return nil
+}
+
+// intentClient reads the intentHash of the options object at args[i]. When set, it returns a copy of c which only
+// signs the order or the modify with that intent, so that page code swapping the order between its confirmation
+// and its signing is caught. Cancels, which AmendOrder signs before a replacing order, are left to the other
+// checks. Calls nested in this one, e.g. from a confirmation hook, sign with their own client and are
+// not affected.
+func intentClient(c *client.TxClient, args []js.Value, i int) (*client.TxClient, error) {
+	if len(args) <= i || args[i].Type() != js.TypeObject {
+		return c, nil
+	}
+	v := args[i].Get("intentHash")
+	if v.IsUndefined() || v.IsNull() {
+		return c, nil
+	}
+	if v.Type() != js.TypeString {
+		return nil, fmt.Errorf("intentHash should be a string")
+	}
+	approved := strings.ToLower(strings.TrimSpace(v.String()))
+	if b, err := hex.DecodeString(approved); err != nil || len(b) != types.OrderIntentHashSize {
+		return nil, fmt.Errorf("intentHash should be the %d hex characters HashOrderIntent returns", 2*types.OrderIntentHashSize)
+	}
+
+	return c.WithTxCheck(orderIntentCheck, func(tx txtypes.TxInfo) error {
+		switch tx := tx.(type) {
+		case *txtypes.L2CreateOrderTxInfo:
+			return types.CheckOrderIntent(tx.AccountIndex, tx.OrderInfo, approved)
+		case *txtypes.L2ModifyOrderTxInfo:
+			return types.CheckModifyIntent(tx, approved)
+		case *txtypes.L2CancelOrderTxInfo:
+			return nil
+		}
+		return fmt.Errorf("an order intent was approved, not a tx of type %d", tx.GetTxType())
+	}), nil
+}
+
+// jsRequireOrderIntent expects () and returns {required}. From then on, SignCreateOrder only signs orders whose
+// options.intentHash matches them, AmendOrder only signs changes whose amend.intentHash matches them, see
+// HashAmendIntent, and the other ways of signing orders and modifies fail with code ORDER_INTENT_REQUIRED; there
+// is no way back short of reloading the module.
+func jsRequireOrderIntent(this js.Value, args []js.Value) any {
+	requireIntent.Store(true)
+	logEvent("info", "intent.required", "order intents required", nil)
+	return js.ValueOf(map[string]any{"required": true, "error": ""})
+}
+
+// jsHashOrderIntent expects the args of SignCreateOrder and returns {hash, accountIndex, orderExpiry}. hash covers
+// the fields of the order a person approves, see types.OrderIntentHash; a UI shows it in its confirmation dialog
+// and passes it back as options.intentHash to SignCreateOrder, which refuses to sign any other order. orderExpiry
+// is the resolved expiry, to sign with when the order used a preset, as the preset resolves again at sign time.
+func jsHashOrderIntent(this js.Value, args []js.Value) any {
+	if len(args) < 11 {
+		return js.ValueOf(map[string]any{"error": "HashOrderIntent expects at least 11 args: marketIndex, clientOrderIndex, baseAmount, price, isAsk, orderType, timeInForce, reduceOnly, triggerPrice, orderExpiry, nonce"})
+	}
+	_, ops, req, err := createOrderArgs(args)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+
+	hash := types.OrderIntentHash(*ops.FromAccountIndex, &txtypes.OrderInfo{
+		MarketIndex:      req.MarketIndex,
+		ClientOrderIndex: req.ClientOrderIndex,
+		BaseAmount:       req.BaseAmount,
+		Price:            req.Price,
+		IsAsk:            req.IsAsk,
+		Type:             req.Type,
+		TimeInForce:      req.TimeInForce,
+		ReduceOnly:       req.ReduceOnly,
+		TriggerPrice:     req.TriggerPrice,
+		OrderExpiry:      req.OrderExpiry,
+	})
+	return js.ValueOf(map[string]any{
+		"hash":         hash,
+		"accountIndex": *ops.FromAccountIndex,
+		"orderExpiry":  req.OrderExpiry,
+		"error":        "",
+	})
+}
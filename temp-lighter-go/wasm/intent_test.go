@@ -0,0 +1,123 @@
+package main
+
+import (
+	"syscall/js"
+	"testing"
+	"time"
+)
+
+// orderArgs returns the args of SignCreateOrder for a bid of 10 at price on market 1, with options.
+func orderArgs(clientOrderIndex, price, nonce int64, options map[string]any) []js.Value {
+	args := []any{1, clientOrderIndex, 10, price, 0, 0, 1, 0, 0, time.Now().Add(time.Hour).UnixMilli(), nonce, defaultClientIndex, js.Undefined()}
+	if options != nil {
+		args = append(args, options)
+	}
+	values := make([]js.Value, len(args))
+	for i, a := range args {
+		values[i] = js.ValueOf(a)
+	}
+	return values
+}
+
+func TestOrderIntent(t *testing.T) {
+	privateKey, _, errStr := GenerateAPIKey("")
+	if errStr != "" {
+		t.Fatal(errStr)
+	}
+	res := js.ValueOf(jsCreateClient(js.Undefined(), []js.Value{js.ValueOf(privateKey), js.ValueOf(5), js.ValueOf(2), js.ValueOf(300)}))
+	if errStr := res.Get("error").String(); errStr != "" {
+		t.Fatal(errStr)
+	}
+	defer setDefaultClient(nil)
+
+	intent := js.ValueOf(jsHashOrderIntent(js.Undefined(), orderArgs(1, 1000, 1, nil)))
+	if errStr := intent.Get("error").String(); errStr != "" {
+		t.Fatal(errStr)
+	}
+	hash := intent.Get("hash").String()
+	sign := func(clientOrderIndex, price, nonce int64, options map[string]any) js.Value {
+		args := orderArgs(clientOrderIndex, price, nonce, options)
+		args[9] = intent.Get("orderExpiry")
+		return js.ValueOf(jsSignCreateOrder(js.Undefined(), args))
+	}
+	expect := func(name string, res js.Value, code string) {
+		t.Helper()
+		errStr := res.Get("error").String()
+		switch {
+		case code == "" && errStr != "":
+			t.Errorf("%s: %s", name, errStr)
+		case code != "" && res.Get("code").String() != code:
+			t.Errorf("%s: got %q (%s), expected code %s", name, res.Get("code").String(), errStr, code)
+		}
+	}
+
+	expect("approved order", sign(1, 1000, 1, map[string]any{"intentHash": hash}), "")
+	expect("other client order index", sign(2, 1000, 2, map[string]any{"intentHash": hash}), "")
+	expect("other price", sign(3, 1001, 3, map[string]any{"intentHash": hash}), "ORDER_INTENT_MISMATCH")
+	expect("without intent", sign(4, 1001, 4, nil), "")
+	for _, bad := range []any{"", "  ", "abc", hash + "00", 12} {
+		if res := sign(5, 1000, 5, map[string]any{"intentHash": bad}); res.Get("error").String() == "" {
+			t.Errorf("intentHash %v was accepted", bad)
+		}
+	}
+
+	requireIntent.Store(true)
+	defer requireIntent.Store(false)
+	expect("required, without intent", sign(6, 1000, 6, nil), "ORDER_INTENT_REQUIRED")
+	expect("required, other price", sign(7, 1001, 7, map[string]any{"intentHash": hash}), "ORDER_INTENT_MISMATCH")
+	expect("required, approved order", sign(8, 1000, 8, map[string]any{"intentHash": hash}), "")
+	simulated := js.ValueOf(jsSimulateCreateOrder(js.Undefined(), orderArgs(9, 1000, 9, nil)))
+	expect("required, simulation", simulated, "")
+}
+
+func TestAmendIntent(t *testing.T) {
+	privateKey, _, errStr := GenerateAPIKey("")
+	if errStr != "" {
+		t.Fatal(errStr)
+	}
+	res := js.ValueOf(jsCreateClient(js.Undefined(), []js.Value{js.ValueOf(privateKey), js.ValueOf(5), js.ValueOf(2), js.ValueOf(300)}))
+	if errStr := res.Get("error").String(); errStr != "" {
+		t.Fatal(errStr)
+	}
+	defer setDefaultClient(nil)
+
+	amend := func(price, nonce int64, intentHash any) map[string]any {
+		a := map[string]any{"marketIndex": 1, "clientOrderIndex": 77, "nonce": nonce, "baseAmount": 10, "price": price, "mode": "modify"}
+		if intentHash != nil {
+			a["intentHash"] = intentHash
+		}
+		return a
+	}
+	intent := js.ValueOf(jsHashAmendIntent(js.Undefined(), []js.Value{js.ValueOf(amend(1000, 1, nil))}))
+	if errStr := intent.Get("error").String(); errStr != "" {
+		t.Fatal(errStr)
+	}
+	if path := intent.Get("path").String(); path != amendModify {
+		t.Fatalf("path is %q, expected %q", path, amendModify)
+	}
+	hash := intent.Get("hash").String()
+	orderIntent := js.ValueOf(jsHashOrderIntent(js.Undefined(), orderArgs(77, 1000, 1, nil))).Get("hash").String()
+
+	requireIntent.Store(true)
+	defer requireIntent.Store(false)
+	for _, tc := range []struct {
+		name  string
+		price int64
+		hash  any
+		code  string
+	}{
+		{"without intent", 1000, nil, "ORDER_INTENT_REQUIRED"},
+		{"other price", 1001, hash, "ORDER_INTENT_MISMATCH"},
+		{"order intent", 1000, orderIntent, "ORDER_INTENT_MISMATCH"},
+		{"approved change", 1000, hash, ""},
+	} {
+		res := js.ValueOf(jsAmendOrder(js.Undefined(), []js.Value{js.ValueOf(amend(tc.price, 2, tc.hash))}))
+		errStr := res.Get("error").String()
+		switch {
+		case tc.code == "" && errStr != "":
+			t.Errorf("%s: %s", tc.name, errStr)
+		case tc.code != "" && res.Get("code").String() != tc.code:
+			t.Errorf("%s: got %q (%s), expected code %s", tc.name, res.Get("code").String(), errStr, tc.code)
+		}
+	}
+}
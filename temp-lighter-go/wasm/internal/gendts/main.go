@@ -0,0 +1,878 @@
+// Command gendts generates the TypeScript declarations and the JSON manifest of the functions exported by the
+// wasm signer. It reads the export calls registering them and derives the parameters from the handlers'
+// doc comments, argument checks and uses of args, and the results from the maps they return.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Param struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Optional bool   `json:"optional"`
+}
+
+type Field struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Optional bool   `json:"optional"`
+}
+
+type Function struct {
+	Name   string  `json:"name"`
+	Doc    string  `json:"doc,omitempty"`
+	Params []Param `json:"params"`
+	Async  bool    `json:"async"`
+	// Result lists the fields of a successful result. Every result also has an error field, which is empty on
+	// success.
+	Result []Field `json:"result"`
+}
+
+type Manifest struct {
+	Source    string     `json:"source"`
+	Functions []Function `json:"functions"`
+}
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the wasm package")
+	dtsOut := flag.String("dts", "", "output path of the TypeScript declarations")
+	manifestOut := flag.String("manifest", "", "output path of the JSON manifest")
+	flag.Parse()
+
+	g, err := load(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	manifest := Manifest{Source: "temp-lighter-go/wasm", Functions: g.functions()}
+
+	if *dtsOut != "" {
+		if err := os.WriteFile(*dtsOut, renderDTS(manifest), 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *manifestOut != "" {
+		data, err := json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(*manifestOut, append(data, '\n'), 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+type generator struct {
+	fset  *token.FileSet
+	files []*ast.File
+	info  *types.Info
+	funcs map[string]*ast.FuncDecl
+}
+
+// load parses and type checks the package in dir as it is built for js/wasm.
+func load(dir string) (*generator, error) {
+	ctx := build.Default
+	ctx.GOOS, ctx.GOARCH = "js", "wasm"
+	pkg, err := ctx.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		fset:  token.NewFileSet(),
+		funcs: map[string]*ast.FuncDecl{},
+		info: &types.Info{
+			Types: map[ast.Expr]types.TypeAndValue{},
+			Defs:  map[*ast.Ident]types.Object{},
+			Uses:  map[*ast.Ident]types.Object{},
+		},
+	}
+	for _, name := range pkg.GoFiles {
+		f, err := parser.ParseFile(g.fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		g.files = append(g.files, f)
+		for _, decl := range f.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok && fd.Recv == nil {
+				g.funcs[fd.Name.Name] = fd
+			}
+		}
+	}
+
+	exports, err := exportData(dir)
+	if err != nil {
+		return nil, err
+	}
+	conf := types.Config{
+		Importer: importer.ForCompiler(g.fset, "gc", func(path string) (io.ReadCloser, error) {
+			file, ok := exports[path]
+			if !ok {
+				return nil, fmt.Errorf("no export data for %s", path)
+			}
+			return os.Open(file)
+		}),
+		Error: func(err error) {},
+	}
+	if _, err := conf.Check(pkg.ImportPath, g.fset, g.files, g.info); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// exportData maps the dependencies of the package in dir to their compiled export data.
+func exportData(dir string) (map[string]string, error) {
+	cmd := exec.Command("go", "list", "-export", "-deps", "-f", "{{.ImportPath}}={{.Export}}", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %w", err)
+	}
+
+	res := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if path, file, ok := strings.Cut(line, "="); ok && file != "" {
+			res[path] = file
+		}
+	}
+	return res, nil
+}
+
+// functions returns the exported functions in registration order.
+func (g *generator) functions() []Function {
+	var res []Function
+	for _, f := range g.files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || !isIdent(call.Fun, "export") || len(call.Args) != 2 {
+				return true
+			}
+			name, ok := stringLit(call.Args[0])
+			if !ok {
+				return true
+			}
+
+			switch h := call.Args[1].(type) {
+			case *ast.FuncLit:
+				res = append(res, g.analyze(name, "", h.Type, h.Body))
+			case *ast.Ident:
+				if fd, ok := g.funcs[h.Name]; ok {
+					res = append(res, g.analyze(name, docText(fd.Doc, fd.Name.Name), fd.Type, fd.Body))
+				}
+			}
+			return true
+		})
+	}
+	return res
+}
+
+func isIdent(e ast.Expr, name string) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == name
+}
+
+func isSelector(e ast.Expr, pkg, name string) bool {
+	sel, ok := e.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	id, ok := sel.X.(*ast.Ident)
+	return ok && id.Name == pkg
+}
+
+// docText returns the doc comment of a handler without its leading name.
+func docText(doc *ast.CommentGroup, name string) string {
+	if doc == nil {
+		return ""
+	}
+	text := strings.Join(strings.Fields(doc.Text()), " ")
+	return strings.TrimSpace(strings.TrimPrefix(text, name))
+}
+
+var (
+	expectsDoc = regexp.MustCompile(`expects \(([^)]*)\)`)
+	expectsMsg = regexp.MustCompile(`expects (?:at least )?\d+ args?: ([^"]*)`)
+)
+
+type paramInfo struct {
+	name     string
+	types    map[string]bool
+	optional bool
+}
+
+const (
+	txOptsType    = "{ accountIndex?: number; apiKeyIndex?: number; nonce?: number; expiredAt?: number }"
+	orderOptsType = "{ allowCross?: boolean; intentHash?: string; accountIndex?: number; apiKeyIndex?: number; nonce?: number; expiredAt?: number }"
+)
+
+// argHelpers are the helpers reading an optional trailing arg, with the name and type of that arg. The type is
+// only used when the handler does not read the arg itself.
+var argHelpers = map[string]Param{
+	"clientFromArgs":        {Name: "clientIndex", Type: "number"},
+	"accountFromArgs":       {Name: "accountIndex", Type: "number"},
+	"allowCrossFromArgs":    {Name: "options", Type: orderOptsType},
+	"orderOptsFromArgs":     {Name: "options", Type: orderOptsType},
+	"txOptsFromArgs":        {Name: "options", Type: txOptsType},
+	"txOverridesFromArgs":   {Name: "options", Type: txOptsType},
+	"parseAuthTokenOptions": {Name: "options", Type: "{ origin?: string; sessionId?: string }"},
+	"httpClientFromArgs":    {Name: "baseUrl", Type: "string | string[]"},
+}
+
+// namedArgHelpers take the name of the arg they parse as their first argument.
+var namedArgHelpers = map[string][]string{
+	"parseTimeParam":    {"number", "string"},
+	"decodeStrict":      {"object"},
+	"readRoundingModes": {"object"},
+	"readClientOptions": {"object"},
+	"decimalArg":        {"string", "number", "null"},
+	"amountArg":         {"number", "string"},
+	"destinationArg":    {"number", "string"},
+	"memoArg":           {"string", "{ template: string; vars?: Record<string, string | number> }"},
+	"memoBytesArg":      {"number[]", "string"},
+	"intArg":            {"number"},
+}
+
+func (g *generator) analyze(name, doc string, ft *ast.FuncType, body *ast.BlockStmt) Function {
+	argsName := "args"
+	if ps := ft.Params.List; len(ps) == 2 && len(ps[1].Names) == 1 {
+		argsName = ps[1].Names[0].Name
+	}
+
+	params := map[int]*paramInfo{}
+	param := func(i int) *paramInfo {
+		if params[i] == nil {
+			params[i] = &paramInfo{types: map[string]bool{}}
+		}
+		return params[i]
+	}
+	minArgs := -1
+
+	// walk inspects body, reading the args as argsName. Package funcs the args are passed to, other than the arg
+	// helpers, are inspected too, as they read args on behalf of the handler.
+	visited := map[*ast.FuncDecl]bool{}
+	var walk func(body *ast.BlockStmt, argsName string)
+	walk = func(body *ast.BlockStmt, argsName string) {
+		var stack []ast.Node
+		ast.Inspect(body, func(n ast.Node) bool {
+			if n == nil {
+				stack = stack[:len(stack)-1]
+				return true
+			}
+			stack = append(stack, n)
+
+			switch n := n.(type) {
+			case *ast.BinaryExpr:
+				if n.Op == token.LSS && minArgs == -1 && isLenOf(n.X, argsName) {
+					if v, ok := intLit(n.Y); ok {
+						minArgs = v
+					}
+				}
+			case *ast.CallExpr:
+				id, ok := n.Fun.(*ast.Ident)
+				if !ok {
+					return true
+				}
+				if helper, ok := argHelpers[id.Name]; ok {
+					if i, ok := intLit(n.Args[len(n.Args)-1]); ok {
+						p := param(i)
+						p.name, p.optional = helper.Name, true
+						if len(p.types) == 0 {
+							p.types[helper.Type] = true
+						}
+					}
+					return true
+				}
+				if fd, ok := g.funcs[id.Name]; ok && !visited[fd] {
+					if _, ok := namedArgHelpers[id.Name]; !ok {
+						if name := passedArgs(fd, n, argsName); name != "" {
+							visited[fd] = true
+							walk(fd.Body, name)
+						}
+					}
+				}
+			case *ast.IndexExpr:
+				id, ok := n.X.(*ast.Ident)
+				if !ok || id.Name != argsName {
+					return true
+				}
+				i, ok := intLit(n.Index)
+				if !ok {
+					return true
+				}
+				p := param(i)
+				g.inferArg(p, stack)
+			}
+			return true
+		})
+	}
+	walk(body, argsName)
+
+	// Doc comments and arg count errors name the params more reliably than the variables they are read into.
+	var docNames, msgNames []string
+	if m := expectsDoc.FindStringSubmatch(doc); m != nil {
+		docNames = splitList(m[1])
+	}
+	if m := expectsMsg.FindStringSubmatch(stringLits(body)); m != nil {
+		msgNames = splitList(m[1])
+	}
+	names := docNames
+	if names == nil {
+		names = msgNames
+	}
+	for i, n := range names {
+		p := param(i)
+		if strings.HasSuffix(n, "?") {
+			n = strings.TrimSuffix(n, "?")
+			p.optional = true
+		}
+		// A doc comment may list the accepted literals instead of a name.
+		if strings.Contains(n, `"`) {
+			p.types = map[string]bool{n: true}
+			if i < len(msgNames) {
+				p.name = strings.TrimSuffix(msgNames[i], "?")
+			} else if p.name == "" {
+				p.name = "value"
+			}
+			continue
+		}
+		p.name = n
+	}
+
+	fn := Function{Name: name, Doc: doc, Params: []Param{}}
+	count := 0
+	for i := range params {
+		if i+1 > count {
+			count = i + 1
+		}
+	}
+	for i := 0; i < count; i++ {
+		p := param(i)
+		if p.name == "" {
+			p.name = fmt.Sprintf("arg%d", i)
+		}
+		fn.Params = append(fn.Params, Param{
+			Name:     p.name,
+			Type:     unionOf(p.types),
+			Optional: p.optional || (minArgs >= 0 && i >= minArgs) || minArgs == -1,
+		})
+	}
+	// Params after an optional one are optional too.
+	for i := 1; i < len(fn.Params); i++ {
+		if fn.Params[i-1].Optional {
+			fn.Params[i].Optional = true
+		}
+	}
+
+	fn.Async, fn.Result = g.results(body)
+	return fn
+}
+
+// inferArg fills p from how args[i], the last node of stack, is used.
+func (g *generator) inferArg(p *paramInfo, stack []ast.Node) {
+	parent := func(k int) ast.Node {
+		if len(stack) > k {
+			return stack[len(stack)-1-k]
+		}
+		return nil
+	}
+
+	if sel, ok := parent(1).(*ast.SelectorExpr); ok {
+		switch sel.Sel.Name {
+		case "Int", "Float":
+			p.types["number"] = true
+		case "String":
+			p.types["string"] = true
+		case "Bool", "Truthy":
+			p.types["boolean"] = true
+		case "Invoke":
+			p.types["(...args: any[]) => any"] = true
+		case "Length", "Index":
+			p.types["unknown[]"] = true
+		case "Get":
+			p.types["object"] = true
+		case "Type":
+			if bin, ok := parent(3).(*ast.BinaryExpr); ok {
+				if t := jsTypeName(bin.Y); t != "" {
+					p.types[t] = true
+				}
+			}
+		}
+	}
+
+	for k := 1; k < len(stack); k++ {
+		switch n := parent(k).(type) {
+		case *ast.CallExpr:
+			id, ok := n.Fun.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			if ts, ok := namedArgHelpers[id.Name]; ok && len(n.Args) > 0 {
+				if lit, ok := n.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					p.name, _ = strconv.Unquote(lit.Value)
+				}
+				for _, t := range ts {
+					p.types[t] = true
+				}
+				return
+			}
+			if id.Name == "parsePolicy" {
+				p.name = "policy"
+				p.types["object"] = true
+				return
+			}
+			if id.Name == "parseOrderExpiry" {
+				p.name = "orderExpiry"
+				p.types["number"], p.types["string"] = true, true
+				return
+			}
+		case *ast.AssignStmt:
+			if p.name != "" {
+				return
+			}
+			for _, lhs := range n.Lhs {
+				if id, ok := lhs.(*ast.Ident); ok && id.Name != "_" && id.Name != "err" {
+					p.name = id.Name
+					return
+				}
+			}
+			return
+		case ast.Stmt:
+			return
+		}
+	}
+}
+
+func jsTypeName(e ast.Expr) string {
+	sel, ok := e.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	switch sel.Sel.Name {
+	case "TypeNumber":
+		return "number"
+	case "TypeString":
+		return "string"
+	case "TypeBoolean":
+		return "boolean"
+	case "TypeFunction":
+		return "(...args: any[]) => any"
+	case "TypeObject":
+		return "object"
+	}
+	return ""
+}
+
+// passedArgs returns the name fd calls the args by when call passes them as argsName, or "".
+func passedArgs(fd *ast.FuncDecl, call *ast.CallExpr, argsName string) string {
+	var names []string
+	for _, field := range fd.Type.Params.List {
+		if len(field.Names) == 0 {
+			names = append(names, "")
+		}
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+	}
+	for i, arg := range call.Args {
+		if isIdent(arg, argsName) && i < len(names) {
+			return names[i]
+		}
+	}
+	return ""
+}
+
+func isLenOf(e ast.Expr, name string) bool {
+	call, ok := e.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return false
+	}
+	fn, ok := call.Fun.(*ast.Ident)
+	arg, ok2 := call.Args[0].(*ast.Ident)
+	return ok && ok2 && fn.Name == "len" && arg.Name == name
+}
+
+func intLit(e ast.Expr) (int, bool) {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.INT {
+		return 0, false
+	}
+	v, err := strconv.Atoi(lit.Value)
+	return v, err == nil
+}
+
+func splitList(s string) []string {
+	var res []string
+	for _, n := range strings.Split(s, ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			res = append(res, n)
+		}
+	}
+	return res
+}
+
+// stringLits concatenates the string literals of body, one per line.
+func stringLits(body *ast.BlockStmt) string {
+	var buf bytes.Buffer
+	ast.Inspect(body, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			buf.WriteString(lit.Value)
+			buf.WriteByte('\n')
+		}
+		return true
+	})
+	return buf.String()
+}
+
+func unionOf(ts map[string]bool) string {
+	if len(ts) == 0 {
+		return "unknown"
+	}
+	var res []string
+	for t := range ts {
+		res = append(res, t)
+	}
+	sort.Strings(res)
+	return strings.Join(res, " | ")
+}
+
+// results collects the maps returned by a handler, directly or through the Promise of newPromise.
+func (g *generator) results(body *ast.BlockStmt) (async bool, fields []Field) {
+	var shapes []map[string]Field
+
+	var walk func(n ast.Node, fnBody *ast.BlockStmt)
+	walk = func(n ast.Node, fnBody *ast.BlockStmt) {
+		ast.Inspect(n, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.ReturnStmt:
+				if len(n.Results) != 1 {
+					return true
+				}
+				call, ok := n.Results[0].(*ast.CallExpr)
+				if !ok || len(call.Args) != 1 {
+					if shape := g.shape(n.Results[0], fnBody); shape != nil {
+						shapes = append(shapes, shape)
+					}
+					return true
+				}
+				if id, ok := call.Fun.(*ast.Ident); ok && id.Name == "newPromise" {
+					if lit, ok := call.Args[0].(*ast.FuncLit); ok {
+						async = true
+						walk(lit.Body, lit.Body)
+					}
+					return true
+				}
+				if isSelector(call.Fun, "js", "ValueOf") {
+					if shape := g.shape(call.Args[0], fnBody); shape != nil {
+						shapes = append(shapes, shape)
+					}
+				} else if shape := g.shape(call, fnBody); shape != nil {
+					shapes = append(shapes, shape)
+				}
+			}
+			return true
+		})
+	}
+	walk(body, body)
+
+	// Results holding nothing but the error describe failures, the others are merged into the success shape.
+	var success []map[string]Field
+	for _, s := range shapes {
+		for k := range s {
+			if k != "error" && k != "violations" {
+				success = append(success, s)
+				break
+			}
+		}
+	}
+	merged := map[string]Field{}
+	for _, s := range success {
+		for k, f := range s {
+			if prev, ok := merged[k]; ok && prev.Type != f.Type && f.Type != "unknown" {
+				if prev.Type == "unknown" {
+					prev.Type = f.Type
+				} else {
+					prev.Type = prev.Type + " | " + f.Type
+				}
+				merged[k] = prev
+			} else if !ok {
+				merged[k] = f
+			}
+		}
+	}
+	for k, f := range merged {
+		for _, s := range success {
+			if _, ok := s[k]; !ok {
+				f.Optional = true
+			}
+		}
+		if k == "error" {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	if fields == nil {
+		fields = []Field{}
+	}
+	return async, fields
+}
+
+// shape returns the fields of the map literal e evaluates to, following a variable to its definition and the
+// keys set on it afterwards.
+func (g *generator) shape(e ast.Expr, body *ast.BlockStmt) map[string]Field {
+	switch e := e.(type) {
+	case *ast.CompositeLit:
+		return g.literalShape(e, body)
+	case *ast.CallExpr:
+		if id, ok := e.Fun.(*ast.Ident); ok && id.Name == "errorResult" {
+			return map[string]Field{"error": {Name: "error", Type: "string"}}
+		}
+		return g.returnedShape(e)
+	case *ast.Ident:
+		obj := g.info.Uses[e]
+		if obj == nil {
+			return nil
+		}
+		var res map[string]Field
+		ast.Inspect(body, func(n ast.Node) bool {
+			assign, ok := n.(*ast.AssignStmt)
+			if !ok {
+				return true
+			}
+			for i, lhs := range assign.Lhs {
+				switch lhs := lhs.(type) {
+				case *ast.Ident:
+					if g.info.Defs[lhs] == obj && i < len(assign.Rhs) {
+						switch rhs := assign.Rhs[i].(type) {
+						case *ast.CompositeLit:
+							res = g.literalShape(rhs, body)
+						case *ast.CallExpr:
+							res = g.returnedShape(rhs)
+						}
+					}
+				case *ast.IndexExpr:
+					id, ok := lhs.X.(*ast.Ident)
+					if !ok || g.info.Uses[id] != obj || res == nil {
+						continue
+					}
+					if key, ok := stringLit(lhs.Index); ok {
+						if _, exists := res[key]; !exists {
+							res[key] = Field{Name: key, Type: g.tsType(assign.Rhs[i], body), Optional: true}
+						}
+					}
+				}
+			}
+			return true
+		})
+		return res
+	}
+	return nil
+}
+
+// returnedShape returns the fields of the map literal returned by call, a call to a function of the package.
+func (g *generator) returnedShape(call *ast.CallExpr) map[string]Field {
+	id, ok := call.Fun.(*ast.Ident)
+	if !ok || g.funcs[id.Name] == nil {
+		return nil
+	}
+	fd := g.funcs[id.Name]
+	var res map[string]Field
+	ast.Inspect(fd.Body, func(n ast.Node) bool {
+		if ret, ok := n.(*ast.ReturnStmt); ok && res == nil && len(ret.Results) == 1 {
+			if lit, ok := ret.Results[0].(*ast.CompositeLit); ok {
+				res = g.literalShape(lit, fd.Body)
+			}
+		}
+		return res == nil
+	})
+	return res
+}
+
+func (g *generator) literalShape(lit *ast.CompositeLit, body *ast.BlockStmt) map[string]Field {
+	if _, ok := lit.Type.(*ast.MapType); !ok {
+		return nil
+	}
+	res := map[string]Field{}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := stringLit(kv.Key); ok {
+			res[key] = Field{Name: key, Type: g.tsType(kv.Value, body)}
+		}
+	}
+	return res
+}
+
+func stringLit(e ast.Expr) (string, bool) {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// tsType maps the Go type of e to TypeScript. Slices of any are resolved through the values appended to them.
+func (g *generator) tsType(e ast.Expr, body *ast.BlockStmt) string {
+	t := g.info.TypeOf(e)
+	if t == nil {
+		return "unknown"
+	}
+	if s, ok := t.Underlying().(*types.Slice); ok && isAny(s.Elem()) {
+		if id, ok := e.(*ast.Ident); ok {
+			if elem := g.appendedType(id, body); elem != "" {
+				return elem + "[]"
+			}
+		}
+	}
+	return goToTS(t)
+}
+
+// appendedType returns the TypeScript type of the values appended to the slice variable id.
+func (g *generator) appendedType(id *ast.Ident, body *ast.BlockStmt) string {
+	obj := g.info.Uses[id]
+	ts := map[string]bool{}
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) < 2 {
+			return true
+		}
+		fn, ok := call.Fun.(*ast.Ident)
+		slice, ok2 := call.Args[0].(*ast.Ident)
+		if !ok || !ok2 || fn.Name != "append" || g.info.Uses[slice] != obj {
+			return true
+		}
+		for _, arg := range call.Args[1:] {
+			if lit, ok := arg.(*ast.CompositeLit); ok {
+				if fields := g.literalShape(lit, body); fields != nil {
+					ts[objectType(fields)] = true
+					continue
+				}
+			}
+			ts[goToTS(g.info.TypeOf(arg))] = true
+		}
+		return true
+	})
+	if len(ts) == 0 {
+		return ""
+	}
+	u := unionOf(ts)
+	if len(ts) > 1 {
+		u = "(" + u + ")"
+	}
+	return u
+}
+
+func objectType(fields map[string]Field) string {
+	var keys []string
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s: %s", k, fields[k].Type))
+	}
+	return "{ " + strings.Join(parts, "; ") + " }"
+}
+
+func isAny(t types.Type) bool {
+	i, ok := t.Underlying().(*types.Interface)
+	return ok && i.Empty()
+}
+
+func goToTS(t types.Type) string {
+	if t == nil {
+		return "unknown"
+	}
+	if named, ok := t.(*types.Named); ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "syscall/js" {
+		return "unknown"
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsString != 0:
+			return "string"
+		case u.Info()&types.IsBoolean != 0:
+			return "boolean"
+		case u.Info()&types.IsNumeric != 0:
+			return "number"
+		}
+	case *types.Slice:
+		if isAny(u.Elem()) {
+			return "unknown[]"
+		}
+		return goToTS(u.Elem()) + "[]"
+	case *types.Map:
+		return "Record<string, " + goToTS(u.Elem()) + ">"
+	}
+	return "unknown"
+}
+
+func renderDTS(m Manifest) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by gendts from %s. DO NOT EDIT.\n\n", m.Source)
+	b.WriteString("export {};\n\ndeclare global {\n")
+	b.WriteString("  /** Returned by every function on failure. violations lists each schema violation of an object argument. */\n")
+	b.WriteString("  interface LighterErrorResult {\n    error: string;\n    code?: string;\n    violations?: string[];\n    suggested?: { baseAmount: number; price: number };\n  }\n")
+
+	for _, fn := range m.Functions {
+		result := "LighterErrorResult"
+		if len(fn.Result) > 0 {
+			result = fn.Name + "Result"
+			fmt.Fprintf(&b, "\n  interface %s {\n", result)
+			for _, f := range fn.Result {
+				opt := ""
+				if f.Optional {
+					opt = "?"
+				}
+				fmt.Fprintf(&b, "    %s%s: %s;\n", f.Name, opt, f.Type)
+			}
+			b.WriteString("    error: string;\n  }\n")
+			result += " | LighterErrorResult"
+		}
+		if fn.Async {
+			result = "Promise<" + result + ">"
+		}
+
+		var params []string
+		for _, p := range fn.Params {
+			opt := ""
+			if p.Optional {
+				opt = "?"
+			}
+			params = append(params, fmt.Sprintf("%s%s: %s", p.Name, opt, p.Type))
+		}
+		b.WriteString("\n")
+		if fn.Doc != "" {
+			fmt.Fprintf(&b, "  /** %s */\n", strings.ReplaceAll(fn.Doc, "*/", "* /"))
+		}
+		fmt.Fprintf(&b, "  function %s(%s): %s;\n", fn.Name, strings.Join(params, ", "), result)
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
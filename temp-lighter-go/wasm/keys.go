@@ -0,0 +1,336 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/signer"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// keyRotation is a rotation started by RotateAPIKey: the api key it changes, the public key it replaces and the
+// key replacing it.
+type keyRotation struct {
+	accountIndex int64
+	apiKeyIndex  uint8
+	oldKey       [40]byte
+	newKey       signer.KeyManager
+}
+
+// rotates reports whether c signs for the api key the rotation changes, with the key it replaces.
+func (r *keyRotation) rotates(c *client.TxClient) bool {
+	return c.GetAccountIndex() == r.accountIndex && c.GetApiKeyIndex() == r.apiKeyIndex && c.GetKeyManager().PubKeyBytes() == r.oldKey
+}
+
+// pendingRotation holds the last rotation started by RotateAPIKey until it is committed.
+var pendingRotation *keyRotation
+
+// ExportPublicKey derives the public key and its fingerprint from privateKey, or from the loaded client's key
+// when privateKey is empty.
+func ExportPublicKey(privateKey string) (publicKey, fingerprint string, err error) {
+	var keyManager signer.KeyManager
+	if privateKey != "" {
+		registerSecret(privateKey)
+		keyManager, err = signer.NewKeyManagerFromHex(privateKey)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid private key: %w", err)
+		}
+	} else {
+		c, err := getClient(defaultClientIndex)
+		if err != nil {
+			return "", "", err
+		}
+		keyManager = c.GetKeyManager()
+	}
+
+	pubKey := keyManager.PubKeyBytes()
+	return hexutil.Encode(pubKey[:]), signer.Fingerprint(pubKey), nil
+}
+
+// GetKeyFingerprint computes the fingerprint of a hex-encoded public key, e.g. the one registered on the exchange.
+func GetKeyFingerprint(publicKey string) (string, error) {
+	pubKey, err := parsePublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return signer.Fingerprint(pubKey), nil
+}
+
+func parsePublicKey(publicKey string) (res [40]byte, err error) {
+	b, err := hexutil.Decode("0x" + strings.TrimPrefix(publicKey, "0x"))
+	if err != nil {
+		return res, fmt.Errorf("invalid public key: %w", err)
+	}
+	if len(b) != len(res) {
+		return res, fmt.Errorf("invalid public key length. expected: %v got: %v", len(res), len(b))
+	}
+	copy(res[:], b)
+	return res, nil
+}
+
+// KeyPairReport describes how a private key relates to an expected public key and, optionally, to the key
+// registered on the exchange.
+type KeyPairReport struct {
+	DerivedPublicKey    string
+	DerivedFingerprint  string
+	ExpectedPublicKey   string
+	RegisteredPublicKey string
+	Mismatches          []string
+}
+
+func (r *KeyPairReport) Match() bool {
+	return len(r.Mismatches) == 0
+}
+
+// ValidateAPIKeyPair checks that privateKey derives publicKey. When accountIndex is not nil and the loaded
+// client has an HTTP client, the key registered for (accountIndex, apiKeyIndex) is fetched and compared too.
+func ValidateAPIKeyPair(privateKey, publicKey string, accountIndex *int64, apiKeyIndex uint8) (*KeyPairReport, error) {
+	derived, fingerprint, err := ExportPublicKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	report := &KeyPairReport{
+		DerivedPublicKey:   derived,
+		DerivedFingerprint: fingerprint,
+		Mismatches:         []string{},
+	}
+
+	if publicKey != "" {
+		expected, err := parsePublicKey(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		report.ExpectedPublicKey = hexutil.Encode(expected[:])
+		if report.ExpectedPublicKey != derived {
+			report.Mismatches = append(report.Mismatches, "private key does not derive the given public key")
+		}
+	}
+
+	if accountIndex == nil {
+		return report, nil
+	}
+	c, _ := getClient(defaultClientIndex)
+	if c == nil || c.HTTP() == nil {
+		return nil, fmt.Errorf("HTTP client not configured, cannot fetch the registered api key")
+	}
+	apiKeys, err := c.HTTP().GetApiKey(*accountIndex, apiKeyIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch registered api key: %w", err)
+	}
+	for _, apiKey := range apiKeys.ApiKeys {
+		if apiKey.ApiKeyIndex != apiKeyIndex {
+			continue
+		}
+		registered, err := parsePublicKey(apiKey.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("registered api key: %w", err)
+		}
+		report.RegisteredPublicKey = hexutil.Encode(registered[:])
+	}
+	switch report.RegisteredPublicKey {
+	case "":
+		report.Mismatches = append(report.Mismatches, fmt.Sprintf("no api key registered for account %d at index %d", *accountIndex, apiKeyIndex))
+	case derived:
+	default:
+		report.Mismatches = append(report.Mismatches, "private key does not derive the registered public key")
+	}
+
+	return report, nil
+}
+
+// KeyRotation is the outcome of RotateAPIKey: the new key pair, the ChangePubKey tx registering it and the
+// message the account's L1 wallet signs to authorize it.
+type KeyRotation struct {
+	TxInfo     string
+	L1Message  string
+	PrivateKey string
+	PublicKey  string
+}
+
+// RotateAPIKey generates a new key pair and signs, with the new key, the ChangePubKey tx registering it for the
+// loaded client's api key index, as BuildOnboarding does. The tx still needs the L1 signature of L1Message. The
+// new key only replaces the loaded one once CommitAPIKeyRotation is called.
+func RotateAPIKey(nonce int64) (*KeyRotation, error) {
+	c, err := getClient(defaultClientIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	newKey, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+	fromAcc := c.GetAccountIndex()
+	apiIdx := c.GetApiKeyIndex()
+	ops := &types.TransactOpts{
+		FromAccountIndex: &fromAcc,
+		ApiKeyIndex:      &apiIdx,
+		Nonce:            &nonce,
+	}
+
+	tx, err := c.WithKeyManager(newKey).GetChangePubKeyTransaction(&types.ChangePubKeyReq{PubKey: newKey.PubKeyBytes()}, ops)
+	if err != nil {
+		return nil, err
+	}
+	txInfo, err := formatTxInfo(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey := newKey.PubKeyBytes()
+	r := &KeyRotation{
+		TxInfo:     txInfo,
+		L1Message:  tx.GetL1SignatureBody(),
+		PrivateKey: hexutil.Encode(newKey.PrvKeyBytes()),
+		PublicKey:  hexutil.Encode(pubKey[:]),
+	}
+	registerSecret(r.PrivateKey)
+	stateMu.Lock()
+	pendingRotation = &keyRotation{accountIndex: fromAcc, apiKeyIndex: apiIdx, oldKey: c.GetKeyManager().PubKeyBytes(), newKey: newKey}
+	stateMu.Unlock()
+	return r, nil
+}
+
+// CommitAPIKeyRotation swaps every registered client signing for the api key changed by the last RotateAPIKey
+// call with the key it replaced to the new key. Clones targeting other accounts keep the old key, which the
+// ChangePubKey tx left registered for them. It should only be called once the ChangePubKey tx has been accepted
+// by the exchange.
+func CommitAPIKeyRotation() error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if pendingRotation == nil {
+		return fmt.Errorf("no pending key rotation")
+	}
+
+	swapped := 0
+	if txClient != nil && pendingRotation.rotates(txClient) {
+		txClient = txClient.WithKeyManager(pendingRotation.newKey)
+		swapped++
+	}
+	for clientIndex, c := range clients {
+		if pendingRotation.rotates(c) {
+			clients[clientIndex] = c.WithKeyManager(pendingRotation.newKey)
+			swapped++
+		}
+	}
+	if swapped == 0 {
+		return fmt.Errorf("no client holds the rotated key anymore")
+	}
+	pendingRotation = nil
+	return nil
+}
+
+// jsRotateAPIKey expects (nonce, confirm?) and returns {txInfo, l1Message, privateKey, publicKey, rotated}. When
+// confirm is a function it is called synchronously with the rotation result and the rotation is committed if it
+// returns a truthy value.
+func jsRotateAPIKey(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "RotateAPIKey expects at least 1 arg: nonce"})
+	}
+	nonce, err := intArg("nonce", args[0], 0, math.MaxInt64)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+
+	r, err := RotateAPIKey(nonce)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+
+	result := map[string]any{
+		"txInfo":     r.TxInfo,
+		"l1Message":  r.L1Message,
+		"privateKey": r.PrivateKey,
+		"publicKey":  r.PublicKey,
+		"rotated":    false,
+		"error":      "",
+	}
+
+	if len(args) > 1 && args[1].Type() == js.TypeFunction {
+		if !args[1].Invoke(js.ValueOf(result)).Truthy() {
+			return js.ValueOf(result)
+		}
+		if err := CommitAPIKeyRotation(); err != nil {
+			result["error"] = wrapErr(err)
+			return js.ValueOf(result)
+		}
+		result["rotated"] = true
+	}
+
+	return js.ValueOf(result)
+}
+
+func jsCommitAPIKeyRotation(this js.Value, args []js.Value) any {
+	return js.ValueOf(map[string]any{"error": wrapErr(CommitAPIKeyRotation())})
+}
+
+func jsExportPublicKey(this js.Value, args []js.Value) any {
+	var privateKey string
+	if len(args) > 0 && args[0].Type() == js.TypeString {
+		privateKey = args[0].String()
+	}
+
+	pub, fingerprint, err := ExportPublicKey(privateKey)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"publicKey": pub, "fingerprint": fingerprint, "error": ""})
+}
+
+func jsGetKeyFingerprint(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "GetKeyFingerprint expects 1 arg: publicKey"})
+	}
+
+	fingerprint, err := GetKeyFingerprint(args[0].String())
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"fingerprint": fingerprint, "error": ""})
+}
+
+// jsValidateAPIKeyPair expects (privateKey, publicKey, accountIndex?, apiKeyIndex?) and returns a Promise, as
+// checking the registered key needs a network round trip. publicKey may be empty when only the registered key
+// should be checked.
+func jsValidateAPIKeyPair(this js.Value, args []js.Value) any {
+	if len(args) < 2 {
+		return js.ValueOf(map[string]any{"error": "ValidateAPIKeyPair expects at least 2 args: privateKey, publicKey"})
+	}
+
+	var accountIndex *int64
+	var apiKeyIndex uint8
+	if len(args) > 2 && args[2].Type() == js.TypeNumber {
+		accIdx := int64(args[2].Int())
+		accountIndex = &accIdx
+	}
+	if len(args) > 3 && args[3].Type() == js.TypeNumber {
+		apiKeyIndex = uint8(args[3].Int())
+	}
+
+	privateKey, publicKey := args[0].String(), args[1].String()
+
+	return newPromise(func(callClock) map[string]any {
+		report, err := ValidateAPIKeyPair(privateKey, publicKey, accountIndex, apiKeyIndex)
+		if err != nil {
+			return map[string]any{"error": wrapErr(err)}
+		}
+
+		mismatches := make([]any, 0, len(report.Mismatches))
+		for _, m := range report.Mismatches {
+			mismatches = append(mismatches, m)
+		}
+		return map[string]any{
+			"match":               report.Match(),
+			"derivedPublicKey":    report.DerivedPublicKey,
+			"derivedFingerprint":  report.DerivedFingerprint,
+			"expectedPublicKey":   report.ExpectedPublicKey,
+			"registeredPublicKey": report.RegisteredPublicKey,
+			"mismatches":          mismatches,
+			"error":               "",
+		}
+	})
+}
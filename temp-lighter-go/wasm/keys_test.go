@@ -0,0 +1,82 @@
+package main
+
+import (
+	"syscall/js"
+	"testing"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+func TestRotateAPIKey(t *testing.T) {
+	privateKey, _, errStr := GenerateAPIKey("")
+	if errStr != "" {
+		t.Fatal(errStr)
+	}
+	res := js.ValueOf(jsCreateClient(js.Undefined(), []js.Value{js.ValueOf(privateKey), js.ValueOf(5), js.ValueOf(2), js.ValueOf(300)}))
+	if errStr := res.Get("error").String(); errStr != "" {
+		t.Fatal(errStr)
+	}
+	defer setDefaultClient(nil)
+	primary, _ := getClient(defaultClientIndex)
+	oldKey := primary.GetKeyManager().PubKeyBytes()
+
+	cloneIndex, err := CloneClient(defaultClientIndex, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unregisterClient(cloneIndex)
+	sameKeyIndex := registerClient(client.NewTxClientWithKeyManager(nil, primary.GetKeyManager(), 5, 2, 300))
+	defer unregisterClient(sameKeyIndex)
+	km, err := generateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherIndex := registerClient(client.NewTxClientWithKeyManager(nil, km, 8, 3, 300))
+	defer unregisterClient(otherIndex)
+
+	r, err := RotateAPIKey(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := parsePublicKey(r.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := types.DecodeSignedTx(txtypes.TxTypeL2ChangePubKey, r.TxInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := types.VerifyTxSignature(tx, 300, newKey); err != nil {
+		t.Errorf("the ChangePubKey tx is not signed by the new key: %v", err)
+	}
+	if want := tx.(*txtypes.L2ChangePubKeyTxInfo).GetL1SignatureBody(); r.L1Message == "" || r.L1Message != want {
+		t.Errorf("l1Message is %q, expected %q", r.L1Message, want)
+	}
+	if primary, _ := getClient(defaultClientIndex); primary.GetKeyManager().PubKeyBytes() != oldKey {
+		t.Errorf("the key was replaced before the rotation was committed")
+	}
+
+	if err := CommitAPIKeyRotation(); err != nil {
+		t.Fatal(err)
+	}
+	for _, clientIndex := range []int{defaultClientIndex, sameKeyIndex} {
+		c, err := getClient(clientIndex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.GetKeyManager().PubKeyBytes() != newKey {
+			t.Errorf("client %d still holds the old key", clientIndex)
+		}
+	}
+	if clone, _ := getClient(cloneIndex); clone.GetKeyManager().PubKeyBytes() != oldKey {
+		t.Errorf("the clone targeting account 6 was rotated with the key of account 5")
+	}
+	if other, _ := getClient(otherIndex); other.GetKeyManager().PubKeyBytes() != km.PubKeyBytes() {
+		t.Errorf("a client holding another key was rotated")
+	}
+	if err := CommitAPIKeyRotation(); err == nil {
+		t.Errorf("a rotation was committed twice")
+	}
+}
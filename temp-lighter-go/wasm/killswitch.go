@@ -0,0 +1,296 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// defaultKillSwitchSlippageBps bounds the flattening orders of KillSwitch when no slippage is given.
+const defaultKillSwitchSlippageBps = 500
+
+var killSwitchOptionsSchema = objectSchema{
+	"flatten":        {Type: "boolean"},
+	"maxSlippageBps": intField(0, types.MaxSlippageBps),
+	"nonces":         {Type: "array", Min: -1, Max: math.MaxInt64},
+}
+
+// killSwitchOptions select what KillSwitch does besides canceling every order. Nonces holds the next nonce of
+// the clients without HTTP client, by clientIndex; -1 or a missing entry fetches it.
+type killSwitchOptions struct {
+	Flatten        bool    `json:"flatten"`
+	MaxSlippageBps *uint32 `json:"maxSlippageBps"`
+	Nonces         []int64 `json:"nonces"`
+}
+
+// killSwitchTx is a tx signed by KillSwitch and the outcome of its submission.
+type killSwitchTx struct {
+	tx        txtypes.TxInfo
+	TxInfo    string
+	TxHash    string
+	Submitted bool
+	Err       error
+}
+
+func (t *killSwitchTx) result() map[string]any {
+	res := map[string]any{"txInfo": t.TxInfo, "txHash": t.TxHash, "submitted": t.Submitted, "error": ""}
+	if t.Err != nil {
+		res["error"] = wrapErr(t.Err)
+	}
+	return res
+}
+
+// killSwitchAccount is what KillSwitch did for the account of one client. Flatten lists the reduce-only orders
+// closing its positions, by market.
+type killSwitchAccount struct {
+	ClientIndex  int
+	AccountIndex int64
+	CancelAll    *killSwitchTx
+	Flatten      map[uint8]*killSwitchTx
+	Err          error
+}
+
+// KillSwitch stops trading on every account of the registered clients, with one client per account, see
+// killSwitchClients: it signs a cancel of all its orders then, with opts.Flatten, a reduce-only marketable order
+// closing each of its positions, bounded by opts.MaxSlippageBps from the top of book. The txs of clients with an
+// HTTP client are submitted, cancels first; positions are fetched from the exchange, or taken from SetPositions
+// otherwise. The txs expire relative to clock. A failure on one account never stops the others.
+func KillSwitch(opts killSwitchOptions, clock callClock) []*killSwitchAccount {
+	slippage := uint32(defaultKillSwitchSlippageBps)
+	if opts.MaxSlippageBps != nil {
+		slippage = *opts.MaxSlippageBps
+	}
+
+	indices, all := killSwitchClients(opts.Nonces)
+	res := make([]*killSwitchAccount, len(all))
+	for i, c := range all {
+		res[i] = stopAccount(indices[i], clock.bind(c), killSwitchNonce(opts.Nonces, indices[i]), opts.Flatten, slippage)
+		if res[i].Err != nil {
+			logEvent("error", "killswitch.failed", fmt.Sprintf("kill switch failed for account %d: %v", res[i].AccountIndex, res[i].Err), map[string]any{"clientIndex": indices[i]})
+		}
+	}
+	logEvent("warn", "killswitch.triggered", fmt.Sprintf("kill switch triggered for %d accounts", len(res)), nil)
+	return res
+}
+
+// killSwitchNonce returns the next nonce of the client at clientIndex given in nonces, or -1.
+func killSwitchNonce(nonces []int64, clientIndex int) int64 {
+	if clientIndex < len(nonces) {
+		return nonces[clientIndex]
+	}
+	return -1
+}
+
+// killSwitchClients picks, by ascending clientIndex, one registered client for each account: clones and
+// sessions share the account of their source, whose orders a single cancel all covers. An account's client is
+// the first one whose next nonce is in nonces, else the first with an HTTP client, else the first non-session
+// client, else the first one.
+func killSwitchClients(nonces []int64) (indices []int, picked []*client.TxClient) {
+	allIndices, all := registeredClients()
+	rank := func(i int) int {
+		switch {
+		case killSwitchNonce(nonces, allIndices[i]) >= 0:
+			return 0
+		case all[i].HTTP() != nil:
+			return 1
+		}
+		stateMu.RLock()
+		defer stateMu.RUnlock()
+		if !sessionClients[allIndices[i]] {
+			return 2
+		}
+		return 3
+	}
+
+	best := map[int64]int{}
+	var accounts []int64
+	for i, c := range all {
+		j, ok := best[c.GetAccountIndex()]
+		if !ok {
+			accounts = append(accounts, c.GetAccountIndex())
+		}
+		if !ok || rank(i) < rank(j) {
+			best[c.GetAccountIndex()] = i
+		}
+	}
+	chosen := make([]int, 0, len(accounts))
+	for _, accountIndex := range accounts {
+		chosen = append(chosen, best[accountIndex])
+	}
+	sort.Ints(chosen)
+	for _, i := range chosen {
+		indices, picked = append(indices, allIndices[i]), append(picked, all[i])
+	}
+	return indices, picked
+}
+
+// stopAccount runs KillSwitch for client c, starting at nonce, fetched when -1.
+func stopAccount(clientIndex int, c *client.TxClient, nonce int64, flatten bool, maxSlippageBps uint32) *killSwitchAccount {
+	acc := &killSwitchAccount{ClientIndex: clientIndex, AccountIndex: c.GetAccountIndex(), Flatten: map[uint8]*killSwitchTx{}}
+	if nonce < 0 {
+		if c.HTTP() == nil {
+			acc.Err = fmt.Errorf("HTTP client not configured, pass the next nonce of client %d", clientIndex)
+			return acc
+		}
+		n, err := c.HTTP().GetNextNonce(c.GetAccountIndex(), c.GetApiKeyIndex())
+		if err != nil {
+			acc.Err = fmt.Errorf("failed to fetch the next nonce: %w", err)
+			return acc
+		}
+		nonce = n
+	}
+
+	fromAcc, apiIdx := c.GetAccountIndex(), c.GetApiKeyIndex()
+	cancelNonce := nonce
+	cancel, err := c.GetCancelAllOrdersTransaction(&types.CancelAllOrdersTxReq{
+		TimeInForce: txtypes.ImmediateCancelAll,
+		Time:        txtypes.NilOrderExpiry,
+	}, &types.TransactOpts{FromAccountIndex: &fromAcc, ApiKeyIndex: &apiIdx, Nonce: &cancelNonce})
+	if err != nil {
+		acc.Err = fmt.Errorf("failed to sign the cancel of all orders: %w", err)
+		return acc
+	}
+	acc.CancelAll = &killSwitchTx{tx: cancel}
+	nonce++
+
+	if flatten {
+		var markets map[uint8]int64
+		if c.HTTP() != nil {
+			markets, err = FetchPositions(c, fromAcc)
+		} else {
+			stateMu.RLock()
+			snapshot, ok := positions[fromAcc]
+			markets = copyPositions(snapshot)
+			stateMu.RUnlock()
+			if !ok {
+				err = fmt.Errorf("no positions known for account %d, call SetPositions first", fromAcc)
+			}
+		}
+		if err != nil {
+			acc.Err = fmt.Errorf("positions not flattened: %w", err)
+		}
+		for market, size := range markets {
+			if size == 0 {
+				continue
+			}
+			o := &marketableOrder{MarketIndex: market, BaseAmount: size, IsAsk: 1, MaxSlippageBps: maxSlippageBps, Nonce: nonce, ReduceOnly: 1}
+			if size < 0 {
+				o.BaseAmount, o.IsAsk = -size, 0
+			}
+			flat := &killSwitchTx{}
+			if tx, _, _, err := marketableOrderTx(c, o); err != nil {
+				flat.Err = err
+			} else {
+				flat.tx = tx
+				nonce++
+			}
+			acc.Flatten[market] = flat
+		}
+	}
+
+	for _, t := range acc.txs() {
+		if t.TxInfo, err = formatTxInfo(t.tx); err != nil {
+			t.Err = err
+			continue
+		}
+		t.TxHash = t.tx.GetTxHash()
+		if c.HTTP() == nil {
+			continue
+		}
+		if t.Err = checkNotHalted(); t.Err != nil {
+			continue
+		}
+		_, t.Err = c.HTTP().SendRawTx(t.tx)
+		emitSubmission(t.tx, t.TxHash, t.Err)
+		t.Submitted = t.Err == nil
+	}
+	return acc
+}
+
+// txs returns the signed txs of acc in nonce order.
+func (acc *killSwitchAccount) txs() []*killSwitchTx {
+	res := []*killSwitchTx{acc.CancelAll}
+	markets := make([]int, 0, len(acc.Flatten))
+	for market := range acc.Flatten {
+		markets = append(markets, int(market))
+	}
+	sort.Ints(markets)
+	for _, market := range markets {
+		if t := acc.Flatten[uint8(market)]; t.tx != nil {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+func copyPositions(markets map[uint8]int64) map[uint8]int64 {
+	res := make(map[uint8]int64, len(markets))
+	for market, size := range markets {
+		res[market] = size
+	}
+	return res
+}
+
+func (acc *killSwitchAccount) result() map[string]any {
+	var cancelAll any
+	if acc.CancelAll != nil {
+		cancelAll = acc.CancelAll.result()
+	}
+	flatten := []any{}
+	for market := 0; market <= math.MaxUint8; market++ {
+		if t, ok := acc.Flatten[uint8(market)]; ok {
+			r := t.result()
+			r["marketIndex"] = market
+			flatten = append(flatten, r)
+		}
+	}
+	res := map[string]any{"clientIndex": acc.ClientIndex, "accountIndex": acc.AccountIndex, "cancelAll": cancelAll, "flatten": flatten, "error": ""}
+	if acc.Err != nil {
+		res["error"] = wrapErr(acc.Err)
+	}
+	return res
+}
+
+// failed reports whether anything KillSwitch attempted for acc went wrong.
+func (acc *killSwitchAccount) failed() bool {
+	if acc.Err != nil || acc.CancelAll == nil || acc.CancelAll.Err != nil {
+		return true
+	}
+	for _, t := range acc.Flatten {
+		if t.Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// jsKillSwitch expects (options?) and returns a Promise resolving to {accounts, failed}. options is {flatten?,
+// maxSlippageBps?, nonces?}, see KillSwitch; maxSlippageBps defaults to 500. accounts holds one entry per account,
+// by clientIndex of the client it used, {clientIndex, accountIndex, cancelAll, flatten, error}, where cancelAll
+// and each flatten order are {txInfo, txHash, submitted, error} and flatten orders also have their marketIndex.
+// failed counts the accounts with an error on any of them.
+func jsKillSwitch(this js.Value, args []js.Value) any {
+	var opts killSwitchOptions
+	if len(args) > 0 && args[0].Type() != js.TypeUndefined && args[0].Type() != js.TypeNull {
+		if err := decodeStrict("options", args[0], killSwitchOptionsSchema, &opts); err != nil {
+			return js.ValueOf(errorResult(err))
+		}
+	}
+
+	return newPromise(func(clock callClock) map[string]any {
+		accounts := KillSwitch(opts, clock)
+		res, failed := make([]any, len(accounts)), 0
+		for i, acc := range accounts {
+			res[i] = acc.result()
+			if acc.failed() {
+				failed++
+			}
+		}
+		return map[string]any{"accounts": res, "failed": failed, "error": ""}
+	})
+}
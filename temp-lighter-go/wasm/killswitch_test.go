@@ -0,0 +1,54 @@
+package main
+
+import (
+	"syscall/js"
+	"testing"
+
+	"github.com/elliottech/lighter-go/client"
+)
+
+// TestKillSwitchOncePerAccount registers two clients on the default client's account and one on another, and
+// checks that KillSwitch signs a single cancel all per account, with the client it was given a nonce for.
+func TestKillSwitchOncePerAccount(t *testing.T) {
+	privateKey, _, errStr := GenerateAPIKey("")
+	if errStr != "" {
+		t.Fatal(errStr)
+	}
+	res := js.ValueOf(jsCreateClient(js.Undefined(), []js.Value{js.ValueOf(privateKey), js.ValueOf(5), js.ValueOf(2), js.ValueOf(300)}))
+	if errStr := res.Get("error").String(); errStr != "" {
+		t.Fatal(errStr)
+	}
+	defer setDefaultClient(nil)
+	primary, _ := getClient(defaultClientIndex)
+
+	sameIndex := registerClient(client.NewTxClientWithKeyManager(nil, primary.GetKeyManager(), 5, 4, 300))
+	defer unregisterClient(sameIndex)
+	km, err := generateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherIndex := registerClient(client.NewTxClientWithKeyManager(nil, km, 8, 3, 300))
+	defer unregisterClient(otherIndex)
+
+	nonces := make([]int64, otherIndex+1)
+	for i := range nonces {
+		nonces[i] = -1
+	}
+	nonces[sameIndex], nonces[otherIndex] = 10, 20
+	accounts := KillSwitch(killSwitchOptions{Nonces: nonces}, callClock{})
+	if len(accounts) != 2 {
+		t.Fatalf("%d accounts stopped, expected 2", len(accounts))
+	}
+	for i, want := range []struct {
+		clientIndex  int
+		accountIndex int64
+	}{{sameIndex, 5}, {otherIndex, 8}} {
+		acc := accounts[i]
+		if acc.ClientIndex != want.clientIndex || acc.AccountIndex != want.accountIndex {
+			t.Errorf("account %d was stopped with client %d, expected client %d", acc.AccountIndex, acc.ClientIndex, want.clientIndex)
+		}
+		if acc.Err != nil || acc.CancelAll == nil || acc.CancelAll.TxInfo == "" {
+			t.Errorf("account %d: no cancel all signed: %v", acc.AccountIndex, acc.Err)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import "sort"
+
+// cancelLane lists the tx types sent ahead of the others of a batch when cancels go first. It only holds the
+// cancel types a batch request may have, see txTypeFields.
+var cancelLane = map[string]bool{"cancelOrder": true}
+
+// prioritizeCancels moves the requests of the cancel lane ahead of the others, each lane keeping its order, and
+// hands the nonces of the batch out again in the new order, so that the cancels are signed and sent first with
+// the lowest ones. order maps the position of every request in res to its position in reqs. The params of reqs
+// are not modified.
+func prioritizeCancels(reqs []batchRequest) (res []batchRequest, order []int) {
+	nonces := make([]int64, len(reqs))
+	for i, r := range reqs {
+		nonces[i] = r.params.Nonce
+		if cancelLane[r.txType] {
+			order = append(order, i)
+		}
+	}
+	for i, r := range reqs {
+		if !cancelLane[r.txType] {
+			order = append(order, i)
+		}
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+
+	res = make([]batchRequest, len(reqs))
+	for k, i := range order {
+		params := *reqs[i].params
+		params.Nonce = nonces[k]
+		res[k] = batchRequest{txType: reqs[i].txType, params: &params}
+	}
+	return res, order
+}
+
+// restoreRequestOrder puts back in request order the items of a batch reordered by prioritizeCancels.
+func restoreRequestOrder(items []batchItem, order []int) []batchItem {
+	res := make([]batchItem, len(items))
+	for k, i := range order {
+		res[i] = items[k]
+		res[i].index = i
+	}
+	return res
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+)
+
+// legacyAlias maps a name exported by the former browser build to the export implementing it now. adapt, when
+// set, converts the legacy arguments to the current convention.
+type legacyAlias struct {
+	legacy  string
+	current string
+	adapt   func(args []js.Value) []any
+}
+
+var legacyAliases = []legacyAlias{
+	{"generateAPIKey", "GenerateAPIKey", nil},
+	{"createClient", "CreateClient", adaptLegacyCreateClient},
+	{"signCreateOrder", "SignCreateOrder", nil},
+	{"signCancelOrder", "SignCancelOrder", nil},
+	{"signCancelAllOrders", "SignCancelAllOrders", nil},
+	{"signTransfer", "SignTransfer", nil},
+	{"signUpdateLeverage", "SignUpdateLeverage", nil},
+	{"createAuthToken", "CreateAuthToken", nil},
+	{"checkClient", "CheckClient", nil},
+}
+
+// adaptLegacyCreateClient converts (url, privateKey, chainId, apiKeyIndex, accountIndex) to the arguments of
+// CreateClient. An empty url leaves the client without HTTP client, as before.
+func adaptLegacyCreateClient(args []js.Value) []any {
+	if len(args) < 5 {
+		return toAny(args)
+	}
+	res := []any{args[1], args[4], args[3], args[2]}
+	if args[0].Type() == js.TypeString && args[0].String() != "" {
+		res = append(res, args[0])
+	}
+	return res
+}
+
+func toAny(args []js.Value) []any {
+	res := make([]any, len(args))
+	for i, a := range args {
+		res[i] = a
+	}
+	return res
+}
+
+// warnedLegacy records which legacy names were already reported, so each is only warned about once.
+var warnedLegacy = map[string]bool{}
+
+// registerLegacyAliases exposes the legacy names both as globals and on the lighterWasmFunctions object the
+// older loaders look them up on. Each forwards to the current export, so both share a single implementation.
+func registerLegacyAliases() {
+	ns := js.Global().Get("Object").New()
+	for _, alias := range legacyAliases {
+		alias := alias
+		export(alias.legacy, func(this js.Value, args []js.Value) any {
+			if !warnedLegacy[alias.legacy] {
+				warnedLegacy[alias.legacy] = true
+				warn(fmt.Sprintf("%s is deprecated, use %s instead", alias.legacy, alias.current))
+			}
+
+			forwarded := toAny(args)
+			if alias.adapt != nil {
+				forwarded = alias.adapt(args)
+			}
+			return exported(alias.current).Invoke(forwarded...)
+		})
+		ns.Set(alias.legacy, exported(alias.legacy))
+	}
+	if exportGlobals {
+		js.Global().Set("lighterWasmFunctions", ns)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+)
+
+// exports holds the funcs registered on the global object and exportTarget by name, so that Shutdown can
+// release them.
+var exports = map[string]js.Func{}
+
+// shutdown is closed by Shutdown to let main return.
+var shutdown = make(chan struct{})
+
+// activeExport is the export being called, naming the background tasks it starts.
+var activeExport string
+
+// activeCallTime is the instant the recorded call being made sees, zero while none is.
+var activeCallTime time.Time
+
+// callClock is the clock of an export call: the instant a recorded call sees, or client.Now. The Promise of a
+// recorded call keeps reading that instant once callExport unfroze client.Now, so that its replay computes the same
+// deadlines.
+type callClock time.Time
+
+// clockOfCall returns the clock of the export being called.
+func clockOfCall() callClock {
+	return callClock(activeCallTime)
+}
+
+func (k callClock) Now() time.Time {
+	if t := time.Time(k); !t.IsZero() {
+		return t
+	}
+	return client.Now()
+}
+
+// bind returns c reading k, see client.TxClient.WithClock.
+func (k callClock) bind(c *client.TxClient) *client.TxClient {
+	if time.Time(k).IsZero() {
+		return c
+	}
+	return c.WithClock(k.Now)
+}
+
+// export registers fn as name on exportTarget and, unless the init options said otherwise, on the global object. Registering a name twice is a bug, the first func would leak.
+func export(name string, fn func(this js.Value, args []js.Value) any) {
+	if _, ok := exports[name]; ok {
+		panic(fmt.Sprintf("%s is exported twice", name))
+	}
+	f := js.FuncOf(func(this js.Value, args []js.Value) any {
+		return callExport(name, fn, this, args)
+	})
+	exports[name] = f
+	exportTarget.Set(name, f)
+	if exportGlobals {
+		js.Global().Set(name, f)
+	}
+}
+
+// callExport calls fn, exported as name, with this and args.
+func callExport(name string, fn func(this js.Value, args []js.Value) any, this js.Value, args []js.Value) (res any) {
+	// A recorded call sees a single instant, which its replay freezes the clock at again. The Promise it may
+	// return reads it from its callClock.
+	if recording() && activeExport == "" {
+		now := client.Now()
+		client.FreezeClock(now)
+		activeCallTime = now
+		defer func() {
+			client.FreezeClock(time.Time{})
+			activeCallTime = time.Time{}
+			recordCall(name, now, args, res)
+		}()
+	}
+	// A panic escaping a handler would terminate the Go program, and with it every later call. Whatever the
+	// arguments, callers get an error result instead.
+	defer func() {
+		if r := recover(); r != nil {
+			res = js.ValueOf(map[string]any{"error": wrapErr(fmt.Errorf("%s failed: %v", name, r))})
+		}
+	}()
+	// A callback into JS may call another export before this one returns.
+	prev := activeExport
+	activeExport = name
+	defer func() { activeExport = prev }()
+	return fn(this, args)
+}
+
+// releasePreviousInstance shuts down a signer left in the same global scope, typically by a hot reload that
+// instantiated the module again without calling Shutdown. Instances kept off the global object do not clash.
+func releasePreviousInstance() {
+	if !exportGlobals {
+		return
+	}
+	prev := js.Global().Get("Shutdown")
+	if prev.Type() != js.TypeFunction {
+		return
+	}
+	warn("a previous signer instance is still registered, shutting it down")
+	prev.Invoke()
+}
+
+// Shutdown removes every export from the global object and exportTarget, releases their funcs and lets the Go program exit.
+// The module has to be instantiated again to be used afterwards.
+func Shutdown() {
+	select {
+	case <-shutdown:
+		return
+	default:
+	}
+
+	for name, f := range exports {
+		exportTarget.Delete(name)
+		if exportGlobals {
+			js.Global().Delete(name)
+		}
+		f.Release()
+	}
+	exports = map[string]js.Func{}
+	if exportGlobals {
+		js.Global().Delete("lighterWasmFunctions")
+	}
+	close(shutdown)
+}
+
+func jsShutdown(this js.Value, args []js.Value) any {
+	Shutdown()
+	return js.ValueOf(map[string]any{"error": ""})
+}
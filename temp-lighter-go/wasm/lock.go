@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/signer"
+)
+
+const defaultAutoLockTimeout = 15 * time.Minute
+
+// LockClient encrypts the loaded client's key under passphrase the first time it is called, and locks it.
+// Every registered client holding the same key, e.g. the clones of the loaded client, switches to the encrypted
+// key along with it; the prepared and queued txs and the labeled auth tokens look their client up when they
+// sign, so none of them keeps using the plain key. Once encrypted, subsequent calls only lock the key and
+// passphrase is ignored. The keys of external signers cannot be locked.
+func LockClient(passphrase string) error {
+	registerSecret(passphrase)
+	c, err := getClient(defaultClientIndex)
+	if err != nil {
+		return err
+	}
+
+	if lockable, ok := c.GetKeyManager().(*signer.LockableKeyManager); ok {
+		lockable.Lock()
+		return nil
+	}
+
+	lockable, err := signer.NewLockableKeyManager(c.GetKeyManager(), passphrase)
+	if err != nil {
+		return err
+	}
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if txClient != c {
+		return fmt.Errorf("client was replaced while its key was being encrypted, lock it again")
+	}
+	swapKeyManagerLocked(c.GetKeyManager(), lockable)
+	return nil
+}
+
+// UnlockClient allows signing with the loaded client's key until it is locked again, either explicitly or after
+// autoLock elapses. A non-positive autoLock disables the auto-lock.
+func UnlockClient(passphrase string, autoLock time.Duration) error {
+	registerSecret(passphrase)
+	c, err := getClient(defaultClientIndex)
+	if err != nil {
+		return err
+	}
+
+	lockable, ok := c.GetKeyManager().(*signer.LockableKeyManager)
+	if !ok {
+		return fmt.Errorf("client key is not locked")
+	}
+	return lockable.Unlock(passphrase, autoLock)
+}
+
+func isClientLocked() bool {
+	c, err := getClient(defaultClientIndex)
+	if err != nil {
+		return false
+	}
+	lockable, ok := c.GetKeyManager().(*signer.LockableKeyManager)
+	return ok && lockable.IsLocked()
+}
+
+func jsLockClient(this js.Value, args []js.Value) any {
+	var passphrase string
+	if len(args) > 0 && args[0].Type() == js.TypeString {
+		passphrase = args[0].String()
+	}
+
+	if err := LockClient(passphrase); err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"locked": isClientLocked(), "error": ""})
+}
+
+// jsUnlockClient expects (passphrase, autoLockSeconds?). autoLockSeconds defaults to 15 minutes, 0 disables it.
+func jsUnlockClient(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "UnlockClient expects at least 1 arg: passphrase"})
+	}
+
+	autoLock := defaultAutoLockTimeout
+	if len(args) > 1 && args[1].Type() == js.TypeNumber {
+		autoLock = time.Duration(args[1].Int()) * time.Second
+	}
+
+	if err := UnlockClient(args[0].String(), autoLock); err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"locked": isClientLocked(), "error": ""})
+}
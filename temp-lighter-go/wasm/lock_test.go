@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/signer"
+	p2 "github.com/elliottech/poseidon_crypto/hash/poseidon2_goldilocks"
+)
+
+const testLockPassphrase = "a passphrase for the test"
+
+// TestLockClientLocksEveryHolder locks the loaded client and checks that its clones, its prepared txs and its
+// labeled auth tokens can no longer sign until it is unlocked.
+func TestLockClientLocksEveryHolder(t *testing.T) {
+	privateKey, _, errStr := GenerateAPIKey("")
+	if errStr != "" {
+		t.Fatal(errStr)
+	}
+	res := js.ValueOf(jsCreateClient(js.Undefined(), []js.Value{js.ValueOf(privateKey), js.ValueOf(5), js.ValueOf(2), js.ValueOf(300)}))
+	if errStr := res.Get("error").String(); errStr != "" {
+		t.Fatal(errStr)
+	}
+	defer setDefaultClient(nil)
+	cloneIndex, err := CloneClient(defaultClientIndex, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unregisterClient(cloneIndex)
+	primary, _ := getClient(defaultClientIndex)
+	_, txId, err := PrepareTx(primary, defaultClientIndex, "withdraw", &txParams{Nonce: 1, USDCAmount: 1000000}, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dropPendingTx(txId)
+	if _, err := MintAuthToken("lock test", defaultClientIndex, time.Hour, nil); err != nil {
+		t.Fatal(err)
+	}
+	defer delete(authTokens, "lock test")
+
+	if err := LockClient(testLockPassphrase); err != nil {
+		t.Fatal(err)
+	}
+	for _, clientIndex := range []int{defaultClientIndex, cloneIndex} {
+		c, _ := getClient(clientIndex)
+		if _, err := c.GetKeyManager().Sign(make([]byte, 40), p2.NewPoseidon2()); !errors.Is(err, signer.ErrKeyLocked) {
+			t.Errorf("client %d signs while locked: %v", clientIndex, err)
+		}
+	}
+	if _, _, err := FinalizeTx(txId, nil); !errors.Is(err, signer.ErrKeyLocked) {
+		t.Errorf("a prepared tx was signed while locked: %v", err)
+	}
+	if _, err := RefreshAuthToken("lock test"); !errors.Is(err, signer.ErrKeyLocked) {
+		t.Errorf("an auth token was refreshed while locked: %v", err)
+	}
+
+	if err := UnlockClient(testLockPassphrase, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, txInfo, err := FinalizeTx(txId, nil); err != nil || txInfo == "" {
+		t.Errorf("the prepared tx was not signed once unlocked: %v", err)
+	}
+	clone, _ := getClient(cloneIndex)
+	if _, err := clone.GetKeyManager().Sign(make([]byte, 40), p2.NewPoseidon2()); err != nil {
+		t.Errorf("the clone does not sign once unlocked: %v", err)
+	}
+}
+
+func TestLockClientRefusesExternalSigner(t *testing.T) {
+	km, err := generateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	external, err := signer.NewExternalKeyManager(km.PubKeyBytes(), func(hashedMessage []byte) ([]byte, error) {
+		return km.Sign(hashedMessage, p2.NewPoseidon2())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := client.NewTxClientWithKeyManager(nil, external, 5, 2, 300)
+	setDefaultClient(c)
+	defer setDefaultClient(nil)
+
+	if err := LockClient(testLockPassphrase); !errors.Is(err, signer.ErrKeyNotHeld) {
+		t.Errorf("got %v, expected %v", err, signer.ErrKeyNotHeld)
+	}
+	if current, _ := getClient(defaultClientIndex); current != c {
+		t.Errorf("the external signer client was replaced")
+	}
+	if _, err := c.GetKeyManager().Sign(make([]byte, 40), p2.NewPoseidon2()); err != nil {
+		t.Errorf("the external signer no longer signs: %v", err)
+	}
+}
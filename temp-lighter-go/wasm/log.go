@@ -0,0 +1,65 @@
+package main
+
+import (
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+)
+
+// logger is the callback set through SetLogger. Without it, warnings go to the host console and other events
+// are dropped.
+var logger js.Value
+
+// logEvent passes {level, event, message, time, ...fields} to the logger. message and the string fields are
+// redacted.
+func logEvent(level, event, message string, fields map[string]any) {
+	if logger.Type() != js.TypeFunction {
+		if level == "warn" {
+			js.Global().Get("console").Call("warn", "lighter-signer: "+redact(message))
+		}
+		return
+	}
+
+	entry := map[string]any{}
+	for k, v := range fields {
+		if s, ok := v.(string); ok {
+			v = redact(s)
+		}
+		entry[k] = v
+	}
+	entry["level"] = level
+	entry["event"] = event
+	entry["message"] = redact(message)
+	entry["time"] = time.Now().UnixMilli()
+	logger.Invoke(js.ValueOf(entry))
+}
+
+// logFailover reports the endpoint switches of an HTTP client. Subscribers are called on their own goroutine,
+// as the client's endpoints are locked while it runs and a subscriber may send a request.
+func logFailover(e client.FailoverEvent) {
+	fields := map[string]any{
+		"from":   e.From,
+		"to":     e.To,
+		"reason": redact(e.Reason),
+	}
+	logEvent("warn", "failover", "switched API endpoint from "+e.From+" to "+e.To+": "+e.Reason, fields)
+	go emitEvent("failover", fields)
+}
+
+// jsSetLogger expects (callback). callback receives every log entry as {level, event, message, time, ...};
+// null restores the default of warning on the console.
+func jsSetLogger(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "SetLogger expects 1 arg: callback"})
+	}
+	if args[0].IsNull() || args[0].IsUndefined() {
+		logger = js.Undefined()
+		return js.ValueOf(map[string]any{"error": ""})
+	}
+	if args[0].Type() != js.TypeFunction {
+		return js.ValueOf(map[string]any{"error": "callback must be a function"})
+	}
+	logger = args[0]
+	return js.ValueOf(map[string]any{"error": ""})
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// marketCounters are the signing statistics of a market since the module started.
+type marketCounters struct {
+	ordersSigned   uint64
+	baseAmountSum  float64
+	modified       uint64
+	canceled       uint64
+	rejected       uint64
+	rejectedByCode map[string]uint64
+}
+
+// marketStats holds the counters by market, updated on every signature and every tx refused by the checks.
+var marketStats = struct {
+	mu       sync.Mutex
+	byMarket map[uint8]*marketCounters
+}{byMarket: map[uint8]*marketCounters{}}
+
+func marketCountersLocked(marketIndex uint8) *marketCounters {
+	m := marketStats.byMarket[marketIndex]
+	if m == nil {
+		m = &marketCounters{rejectedByCode: map[string]uint64{}}
+		marketStats.byMarket[marketIndex] = m
+	}
+	return m
+}
+
+// recordMarketSign counts the orders, modifications and cancellations signed in tx by market.
+func recordMarketSign(tx txtypes.TxInfo) {
+	marketStats.mu.Lock()
+	defer marketStats.mu.Unlock()
+	switch tx := tx.(type) {
+	case *txtypes.L2CreateOrderTxInfo:
+		m := marketCountersLocked(tx.MarketIndex)
+		m.ordersSigned++
+		m.baseAmountSum += float64(tx.BaseAmount)
+	case *txtypes.L2CreateGroupedOrdersTxInfo:
+		for _, order := range tx.Orders {
+			m := marketCountersLocked(order.MarketIndex)
+			m.ordersSigned++
+			m.baseAmountSum += float64(order.BaseAmount)
+		}
+	case *txtypes.L2ModifyOrderTxInfo:
+		marketCountersLocked(tx.MarketIndex).modified++
+	case *txtypes.L2CancelOrderTxInfo:
+		marketCountersLocked(tx.MarketIndex).canceled++
+	}
+}
+
+// recordMarketRejection counts tx, refused by the pre-sign checks with err, against its market. Txs bound to no
+// market are not counted.
+func recordMarketRejection(tx txtypes.TxInfo, err error) {
+	var markets []uint8
+	switch tx := tx.(type) {
+	case *txtypes.L2CreateOrderTxInfo:
+		markets = []uint8{tx.MarketIndex}
+	case *txtypes.L2CreateGroupedOrdersTxInfo:
+		for _, order := range tx.Orders {
+			markets = append(markets, order.MarketIndex)
+		}
+	case *txtypes.L2ModifyOrderTxInfo:
+		markets = []uint8{tx.MarketIndex}
+	case *txtypes.L2CancelOrderTxInfo:
+		markets = []uint8{tx.MarketIndex}
+	}
+	code := errorCode(err)
+	if code == "" {
+		code = "OTHER"
+	}
+
+	marketStats.mu.Lock()
+	defer marketStats.mu.Unlock()
+	for _, marketIndex := range markets {
+		m := marketCountersLocked(marketIndex)
+		m.rejected++
+		m.rejectedByCode[code]++
+	}
+}
+
+func (m *marketCounters) result(marketIndex uint8) map[string]any {
+	var averageBaseAmount int64
+	if m.ordersSigned > 0 {
+		averageBaseAmount = int64(math.Round(m.baseAmountSum / float64(m.ordersSigned)))
+	}
+	var rejectRate float64
+	if attempts := m.ordersSigned + m.modified + m.canceled + m.rejected; attempts > 0 {
+		rejectRate = float64(m.rejected) / float64(attempts)
+	}
+	byCode := map[string]any{}
+	for code, n := range m.rejectedByCode {
+		byCode[code] = n
+	}
+	return map[string]any{
+		"marketIndex":       marketIndex,
+		"ordersSigned":      m.ordersSigned,
+		"averageBaseAmount": averageBaseAmount,
+		"modified":          m.modified,
+		"canceled":          m.canceled,
+		"rejected":          m.rejected,
+		"rejectRate":        rejectRate,
+		"rejectedByCode":    byCode,
+	}
+}
+
+// jsGetMarketStats expects (marketIndex?) and returns {markets}, the signing statistics of every market seen, or
+// of marketIndex only, sorted by market: {marketIndex, ordersSigned, averageBaseAmount, modified, canceled,
+// rejected, rejectRate, rejectedByCode}. rejected counts the order, modify and cancel txs the pre-sign checks
+// refused, by error code, "OTHER" when the error has none; rejectRate is their share of the attempts.
+func jsGetMarketStats(this js.Value, args []js.Value) any {
+	only := -1
+	if len(args) > 0 && args[0].Type() != js.TypeUndefined && args[0].Type() != js.TypeNull {
+		marketIndex, err := intArg("marketIndex", args[0], 0, int64(txtypes.MaxMarketIndex))
+		if err != nil {
+			return js.ValueOf(map[string]any{"error": wrapErr(err)})
+		}
+		only = int(marketIndex)
+	}
+
+	marketStats.mu.Lock()
+	defer marketStats.mu.Unlock()
+	indexes := make([]int, 0, len(marketStats.byMarket))
+	for marketIndex := range marketStats.byMarket {
+		if only == -1 || int(marketIndex) == only {
+			indexes = append(indexes, int(marketIndex))
+		}
+	}
+	sort.Ints(indexes)
+	markets := make([]any, 0, len(indexes))
+	for _, marketIndex := range indexes {
+		markets = append(markets, marketStats.byMarket[uint8(marketIndex)].result(uint8(marketIndex)))
+	}
+	return js.ValueOf(map[string]any{"markets": markets, "error": ""})
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+var marketableOrderSchema = objectSchema{
+	"marketIndex":      requiredIntField(0, int64(txtypes.MaxMarketIndex)),
+	"baseAmount":       requiredIntField(txtypes.MinOrderBaseAmount, txtypes.MaxOrderBaseAmount),
+	"isAsk":            requiredIntField(0, 1),
+	"maxSlippageBps":   requiredIntField(0, types.MaxSlippageBps),
+	"nonce":            requiredIntField(txtypes.MinNonce, math.MaxInt64),
+	"clientOrderIndex": intField(0, txtypes.MaxClientOrderIndex),
+	"reduceOnly":       intField(0, 1),
+	"bestBid":          intField(0, math.MaxUint32),
+	"bestAsk":          intField(0, math.MaxUint32),
+	"clientIndex":      intField(0, math.MaxInt32),
+	"accountIndex":     intField(txtypes.MinAccountIndex, txtypes.MaxAccountIndex),
+	"apiKeyIndex":      intField(0, int64(txtypes.MaxApiKeyIndex)),
+	"expiredAt":        intField(0, txtypes.MaxTimestamp),
+}
+
+type marketableOrder struct {
+	MarketIndex      uint8  `json:"marketIndex"`
+	BaseAmount       int64  `json:"baseAmount"`
+	IsAsk            uint8  `json:"isAsk"`
+	MaxSlippageBps   uint32 `json:"maxSlippageBps"`
+	Nonce            int64  `json:"nonce"`
+	ClientOrderIndex int64  `json:"clientOrderIndex"`
+	ReduceOnly       uint8  `json:"reduceOnly"`
+	BestBid          uint32 `json:"bestBid"`
+	BestAsk          uint32 `json:"bestAsk"`
+	ClientIndex      int    `json:"clientIndex"`
+	// AccountIndex, ApiKeyIndex and ExpiredAt override the TransactOpts, see transactOpts.
+	AccountIndex *int64 `json:"accountIndex"`
+	ApiKeyIndex  *uint8 `json:"apiKeyIndex"`
+	ExpiredAt    *int64 `json:"expiredAt"`
+}
+
+// SignMarketableOrder signs an immediate-or-cancel market order whose price is bounded by o.MaxSlippageBps from
+// the top of book. When o does not hold the side of the book the order takes from, the fresh mark price of the
+// price feed is used instead, or the top of book is fetched through the client's HTTP client. Its default expiry
+// is computed from clock.
+func SignMarketableOrder(o *marketableOrder, clock callClock) (txInfo string, price, reference uint32, err error) {
+	c, err := getClient(o.ClientIndex)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	tx, price, reference, err := marketableOrderTx(clock.bind(c), o)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	txInfo, err = formatTxInfo(tx)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	return txInfo, price, reference, nil
+}
+
+// marketableOrderTx signs o with c, see SignMarketableOrder, and returns the signed tx unformatted.
+func marketableOrderTx(c *client.TxClient, o *marketableOrder) (tx *txtypes.L2CreateOrderTxInfo, price, reference uint32, err error) {
+	ops, err := transactOpts(c, c.GetAccountIndex(), o.Nonce, &txOverrides{AccountIndex: o.AccountIndex, ApiKeyIndex: o.ApiKeyIndex, ExpiredAt: o.ExpiredAt})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	bestBid, bestAsk := o.BestBid, o.BestAsk
+	if (o.IsAsk == 1 && bestBid == 0) || (o.IsAsk == 0 && bestAsk == 0) {
+		// A fresh mark price from the feed stands in for the missing side of the book
+		mark := freshMarkPrice(o.MarketIndex)
+		if bestBid == txtypes.NilOrderPrice {
+			bestBid = mark
+		}
+		if bestAsk == txtypes.NilOrderPrice {
+			bestAsk = mark
+		}
+	}
+	if (o.IsAsk == 1 && bestBid == 0) || (o.IsAsk == 0 && bestAsk == 0) {
+		if c.HTTP() == nil {
+			return nil, 0, 0, fmt.Errorf("top of book not given and HTTP client not configured, pass bestBid and bestAsk")
+		}
+		bestBid, bestAsk, err = c.HTTP().GetTopOfBook(o.MarketIndex)
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("failed to fetch the top of book: %w", err)
+		}
+	}
+	price, reference, err = types.MarketableLimitPrice(o.IsAsk, bestBid, bestAsk, o.MaxSlippageBps)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	tx, err = c.GetCreateOrderTransaction(&types.CreateOrderTxReq{
+		MarketIndex:      o.MarketIndex,
+		ClientOrderIndex: o.ClientOrderIndex,
+		BaseAmount:       o.BaseAmount,
+		Price:            price,
+		IsAsk:            o.IsAsk,
+		Type:             txtypes.MarketOrder,
+		TimeInForce:      txtypes.ImmediateOrCancel,
+		ReduceOnly:       o.ReduceOnly,
+		TriggerPrice:     txtypes.NilOrderTriggerPrice,
+		OrderExpiry:      txtypes.NilOrderExpiry,
+	}, ops)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return tx, price, reference, nil
+}
+
+// jsSignMarketableOrder expects (order) and returns a Promise, as the top of book may have to be fetched.
+// order holds marketIndex, baseAmount, isAsk, maxSlippageBps and nonce, and optionally clientOrderIndex,
+// reduceOnly, bestBid, bestAsk and clientIndex, along with the TransactOpts overrides accountIndex, apiKeyIndex
+// and expiredAt, see SignCreateOrder. Prices are in ticks.
+func jsSignMarketableOrder(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "SignMarketableOrder expects 1 arg: order"})
+	}
+
+	o := &marketableOrder{}
+	if err := decodeStrict("order", args[0], marketableOrderSchema, o); err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+
+	return newPromise(func(clock callClock) map[string]any {
+		txInfo, price, reference, err := SignMarketableOrder(o, clock)
+		if err != nil {
+			return errorResult(err)
+		}
+		return map[string]any{
+			"txInfo":         txInfo,
+			"price":          price,
+			"referencePrice": reference,
+			"error":          "",
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// marketRules holds the order constraints of each market, as supplied through SetMarketRules or fetched
+// through FetchMarketRules. Orders on markets without rules are not checked.
+var marketRules = map[uint8]*types.MarketRules{}
+
+// checkMarketRules is installed on every client created from JS, see installChecks.
+func checkMarketRules(tx txtypes.TxInfo) error {
+	var orders []*txtypes.OrderInfo
+	switch tx := tx.(type) {
+	case *txtypes.L2CreateOrderTxInfo:
+		orders = []*txtypes.OrderInfo{tx.OrderInfo}
+	case *txtypes.L2CreateGroupedOrdersTxInfo:
+		orders = tx.Orders
+	}
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	for _, order := range orders {
+		rules, ok := marketRules[order.MarketIndex]
+		if !ok {
+			continue
+		}
+		if err := types.CheckMarketRules(order, rules); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var marketRulesSchema = objectSchema{
+	"minBaseAmount":  intField(0, txtypes.MaxOrderBaseAmount),
+	"minQuoteAmount": intField(0, math.MaxInt64),
+	"baseStep":       intField(1, txtypes.MaxOrderBaseAmount),
+	"priceStep":      intField(1, int64(txtypes.MaxOrderPrice)),
+	"sizeDecimals":   intField(0, maxDecimals),
+	"priceDecimals":  intField(0, maxDecimals),
+}
+
+// maxDecimals bounds the decimals of a market; 10^18 is the largest power of ten in an int64.
+const maxDecimals = 18
+
+// FetchMarketRules replaces the rules of marketIndex with the ones reported by the exchange.
+func FetchMarketRules(c *client.TxClient, marketIndex uint8) (*types.MarketRules, error) {
+	if c.HTTP() == nil {
+		return nil, fmt.Errorf("HTTP client not configured, cannot fetch market rules")
+	}
+	rules, err := c.HTTP().GetMarketRules(marketIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch market rules: %w", err)
+	}
+	stateMu.Lock()
+	marketRules[marketIndex] = rules
+	stateMu.Unlock()
+	return rules, nil
+}
+
+func marketRulesResult(rules *types.MarketRules) map[string]any {
+	return map[string]any{
+		"marketIndex":    rules.MarketIndex,
+		"minBaseAmount":  rules.MinBaseAmount,
+		"minQuoteAmount": rules.MinQuoteAmount,
+		"baseStep":       rules.BaseStep,
+		"priceStep":      rules.PriceStep,
+		"sizeDecimals":   rules.SizeDecimals,
+		"priceDecimals":  rules.PriceDecimals,
+		"hasDecimals":    rules.HasDecimals,
+		"error":          "",
+	}
+}
+
+// jsSetMarketRules expects (marketIndex, rules). rules is {minBaseAmount?, minQuoteAmount?, baseStep?,
+// priceStep?, sizeDecimals?, priceDecimals?} in protocol units, minQuoteAmount being counted in base amount
+// times price ticks; the decimals go together. null removes the rules of the market.
+func jsSetMarketRules(this js.Value, args []js.Value) any {
+	if len(args) < 2 {
+		return js.ValueOf(map[string]any{"error": "SetMarketRules expects 2 args: marketIndex, rules"})
+	}
+	if args[0].Type() != js.TypeNumber || args[0].Int() < 0 || args[0].Int() > int(txtypes.MaxMarketIndex) {
+		return js.ValueOf(map[string]any{"error": fmt.Sprintf("marketIndex should be an integer between 0 and %d", txtypes.MaxMarketIndex)})
+	}
+	marketIndex := uint8(args[0].Int())
+
+	if args[1].IsNull() || args[1].IsUndefined() {
+		stateMu.Lock()
+		delete(marketRules, marketIndex)
+		stateMu.Unlock()
+		return js.ValueOf(map[string]any{"error": ""})
+	}
+	var r struct {
+		MinBaseAmount  int64  `json:"minBaseAmount"`
+		MinQuoteAmount int64  `json:"minQuoteAmount"`
+		BaseStep       int64  `json:"baseStep"`
+		PriceStep      uint32 `json:"priceStep"`
+		SizeDecimals   *uint8 `json:"sizeDecimals"`
+		PriceDecimals  *uint8 `json:"priceDecimals"`
+	}
+	if err := decodeStrict("rules", args[1], marketRulesSchema, &r); err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	if (r.SizeDecimals == nil) != (r.PriceDecimals == nil) {
+		return js.ValueOf(map[string]any{"error": "sizeDecimals and priceDecimals should be set together"})
+	}
+	rules := &types.MarketRules{
+		MarketIndex:    marketIndex,
+		MinBaseAmount:  r.MinBaseAmount,
+		MinQuoteAmount: r.MinQuoteAmount,
+		BaseStep:       max(r.BaseStep, 1),
+		PriceStep:      max(r.PriceStep, 1),
+	}
+	if r.SizeDecimals != nil {
+		rules.SizeDecimals, rules.PriceDecimals, rules.HasDecimals = *r.SizeDecimals, *r.PriceDecimals, true
+	}
+	stateMu.Lock()
+	marketRules[marketIndex] = rules
+	stateMu.Unlock()
+	return js.ValueOf(marketRulesResult(rules))
+}
+
+// jsFetchMarketRules expects (marketIndex, clientIndex?) and returns a Promise.
+func jsFetchMarketRules(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "FetchMarketRules expects at least 1 arg: marketIndex"})
+	}
+	c, err := clientFromArgs(args, 1)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	if args[0].Type() != js.TypeNumber || args[0].Int() < 0 || args[0].Int() > int(txtypes.MaxMarketIndex) {
+		return js.ValueOf(map[string]any{"error": fmt.Sprintf("marketIndex should be an integer between 0 and %d", txtypes.MaxMarketIndex)})
+	}
+	marketIndex := uint8(args[0].Int())
+
+	return newPromise(func(callClock) map[string]any {
+		rules, err := FetchMarketRules(c, marketIndex)
+		if err != nil {
+			return map[string]any{"error": wrapErr(err)}
+		}
+		return marketRulesResult(rules)
+	})
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/types"
+)
+
+// maxMemoTemplates bounds the memo templates.
+const maxMemoTemplates = 100
+
+// memoTemplates holds the memo templates set through SetMemoTemplate by name, guarded by stateMu.
+var memoTemplates = map[string]*types.MemoTemplate{}
+
+// SetMemoTemplate saves template under name, replacing the template previously saved under it. A nil template
+// removes it.
+func SetMemoTemplate(name string, template *types.MemoTemplate) error {
+	name, err := checkAddressLabel(name)
+	if err != nil {
+		return fmt.Errorf("memo template name: %w", err)
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if template == nil {
+		delete(memoTemplates, name)
+		return nil
+	}
+	if _, ok := memoTemplates[name]; !ok && len(memoTemplates) >= maxMemoTemplates {
+		return fmt.Errorf("too many memo templates (%d)", maxMemoTemplates)
+	}
+	memoTemplates[name] = template
+	return nil
+}
+
+func getMemoTemplate(name string) (*types.MemoTemplate, error) {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	t, ok := memoTemplates[strings.TrimSpace(name)]
+	if !ok {
+		return nil, fmt.Errorf("no memo template saved under %q", name)
+	}
+	return t, nil
+}
+
+// DecodeMemo returns the values memo was rendered from with the template saved under name or, when name is
+// empty, with the first template, by name, it follows. No template is not an error, the memo may be free text.
+func DecodeMemo(memo [types.MemoSize]byte, name string) (template string, vars map[string]string, err error) {
+	if name != "" {
+		t, err := getMemoTemplate(name)
+		if err != nil {
+			return "", nil, err
+		}
+		vars, err := t.Decode(memo)
+		return name, vars, err
+	}
+
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	names := make([]string, 0, len(memoTemplates))
+	for n := range memoTemplates {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if vars, err := memoTemplates[n].Decode(memo); err == nil {
+			return n, vars, nil
+		}
+	}
+	return "", nil, nil
+}
+
+// memoArg reads the memo of a transfer: free text, or {template, vars} rendering the memo template saved under
+// template with vars, whose values are strings or integers.
+func memoArg(name string, v js.Value) ([types.MemoSize]byte, error) {
+	switch v.Type() {
+	case js.TypeString:
+		return memoFromString(v.String())
+	case js.TypeObject:
+		if v.Get("template").Type() != js.TypeString {
+			return [types.MemoSize]byte{}, fmt.Errorf("%s.template should be the name of a memo template", name)
+		}
+		t, err := getMemoTemplate(v.Get("template").String())
+		if err != nil {
+			return [types.MemoSize]byte{}, fmt.Errorf("%s: %w", name, err)
+		}
+		vars := map[string]string{}
+		if obj := v.Get("vars"); obj.Type() == js.TypeObject {
+			keys := js.Global().Get("Object").Call("keys", obj)
+			for i := 0; i < keys.Length(); i++ {
+				key := keys.Index(i).String()
+				switch value := obj.Get(key); value.Type() {
+				case js.TypeString:
+					vars[key] = value.String()
+				case js.TypeNumber:
+					n, err := intArg(name+".vars."+key, value, -maxSafeInteger, maxSafeInteger)
+					if err != nil {
+						return [types.MemoSize]byte{}, err
+					}
+					vars[key] = fmt.Sprint(n)
+				default:
+					return [types.MemoSize]byte{}, fmt.Errorf("%s.vars.%s should be a string or an integer, got %s", name, key, value.Type())
+				}
+			}
+		} else if !obj.IsUndefined() {
+			return [types.MemoSize]byte{}, fmt.Errorf("%s.vars should be an object", name)
+		}
+		memo, err := t.Render(vars)
+		if err != nil {
+			return [types.MemoSize]byte{}, fmt.Errorf("%s: %w", name, err)
+		}
+		return memo, nil
+	}
+	return [types.MemoSize]byte{}, fmt.Errorf("%s should be a string or {template, vars}, got %s", name, v.Type())
+}
+
+// memoBytesArg reads a memo as found in a txInfo, an array of up to 32 bytes, or as a hex string.
+func memoBytesArg(name string, v js.Value) ([types.MemoSize]byte, error) {
+	var memo [types.MemoSize]byte
+	if v.Type() == js.TypeString {
+		b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(v.String(), "0x"), "0X"))
+		if err != nil || len(b) > types.MemoSize {
+			return memo, fmt.Errorf("%s should be the hex of at most %d bytes", name, types.MemoSize)
+		}
+		copy(memo[:], b)
+		return memo, nil
+	}
+	if v.Type() != js.TypeObject || !js.Global().Get("Array").Call("isArray", v).Bool() || v.Length() > types.MemoSize {
+		return memo, fmt.Errorf("%s should be an array of at most %d bytes or a hex string", name, types.MemoSize)
+	}
+	for i := 0; i < v.Length(); i++ {
+		b, err := intArg(fmt.Sprintf("%s[%d]", name, i), v.Index(i), 0, 255)
+		if err != nil {
+			return memo, err
+		}
+		memo[i] = byte(b)
+	}
+	return memo, nil
+}
+
+// jsSetMemoTemplate expects (name, template) and returns {vars}, the placeholders of template. Transfers then
+// accept {template: name, vars} as memo, e.g. template "inv:{invoice};st:{strategy}" with {invoice: 1042,
+// strategy: "mm"}. A null template removes it.
+func jsSetMemoTemplate(this js.Value, args []js.Value) any {
+	if len(args) < 2 || args[0].Type() != js.TypeString {
+		return js.ValueOf(map[string]any{"error": "SetMemoTemplate expects 2 args: name, template"})
+	}
+	if args[1].IsNull() {
+		if err := SetMemoTemplate(args[0].String(), nil); err != nil {
+			return js.ValueOf(map[string]any{"error": wrapErr(err)})
+		}
+		return js.ValueOf(map[string]any{"vars": []any{}, "error": ""})
+	}
+	if args[1].Type() != js.TypeString {
+		return js.ValueOf(map[string]any{"error": "template should be a string"})
+	}
+	t, err := types.ParseMemoTemplate(args[1].String())
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	if err := SetMemoTemplate(args[0].String(), t); err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	vars := []any{}
+	for _, v := range t.Vars() {
+		vars = append(vars, v)
+	}
+	return js.ValueOf(map[string]any{"vars": vars, "error": ""})
+}
+
+// jsDecodeMemo expects (memo, template?) and returns {text, hex, template, vars}. memo is the Memo of a transfer
+// txInfo, an array of bytes, or its hex. vars holds the values of the placeholders of the memo template saved
+// under template or, without it, of the first template the memo follows, whose name is returned; template is
+// empty and vars null when it follows none.
+func jsDecodeMemo(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "DecodeMemo expects at least 1 arg: memo"})
+	}
+	memo, err := memoBytesArg("memo", args[0])
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	name := ""
+	if len(args) > 1 && args[1].Type() == js.TypeString {
+		name = args[1].String()
+	}
+
+	template, vars, err := DecodeMemo(memo, name)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	var fields any
+	if template != "" {
+		m := map[string]any{}
+		for k, v := range vars {
+			m[k] = v
+		}
+		fields = m
+	}
+	return js.ValueOf(map[string]any{
+		"text":     types.MemoText(memo),
+		"hex":      hex.EncodeToString(memo[:]),
+		"template": template,
+		"vars":     fields,
+		"error":    "",
+	})
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// jsFetchNextNonce expects (accountIndex?, apiKeyIndex?, clientIndex?) and returns a Promise resolving to
+// {nonce}, the next nonce the exchange expects from the key. accountIndex and apiKeyIndex default to the
+// client's. Subscribers of "nonceResync" are notified.
+func jsFetchNextNonce(this js.Value, args []js.Value) any {
+	c, err := clientFromArgs(args, 2)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	if c.HTTP() == nil {
+		return js.ValueOf(map[string]any{"error": "HTTP client not configured, cannot fetch the next nonce"})
+	}
+	accountIndex, apiKeyIndex := c.GetAccountIndex(), c.GetApiKeyIndex()
+	if len(args) > 0 && args[0].Type() == js.TypeNumber {
+		n, err := intArg("accountIndex", args[0], txtypes.MinAccountIndex, txtypes.MaxAccountIndex)
+		if err != nil {
+			return js.ValueOf(map[string]any{"error": wrapErr(err)})
+		}
+		accountIndex = n
+	}
+	if len(args) > 1 && args[1].Type() == js.TypeNumber {
+		n, err := intArg("apiKeyIndex", args[1], 0, int64(txtypes.MaxApiKeyIndex))
+		if err != nil {
+			return js.ValueOf(map[string]any{"error": wrapErr(err)})
+		}
+		apiKeyIndex = uint8(n)
+	}
+
+	return newPromise(func(callClock) map[string]any {
+		nonce, err := c.HTTP().GetNextNonce(accountIndex, apiKeyIndex)
+		if err != nil {
+			return map[string]any{"error": wrapErr(fmt.Errorf("failed to fetch the next nonce: %w", err))}
+		}
+		return map[string]any{"nonce": nonce, "error": ""}
+	})
+}
+
+// nonceKey identifies the nonce sequence of an api key.
+type nonceKey struct {
+	accountIndex int64
+	apiKeyIndex  uint8
+}
+
+// nonceRejection is the lowest nonce of a sequence the exchange refused for its nonce.
+type nonceRejection struct {
+	nonce  int64
+	txHash string
+}
+
+// nonceRejections holds the nonce rejections seen since the last RepairNonceGap, by sequence, guarded by stateMu.
+var nonceRejections = map[nonceKey]nonceRejection{}
+
+// noteNonceRejection records tx, hashed txHash, when the exchange refused it with err for its nonce, and emits
+// "nonceGap".
+func noteNonceRejection(tx txtypes.TxInfo, txHash string, err error) {
+	if !client.IsNonceError(err) {
+		return
+	}
+	accountIndex, apiKeyIndex, nonce, sErr := types.SenderOf(tx)
+	if sErr != nil {
+		return
+	}
+	key := nonceKey{accountIndex, apiKeyIndex}
+	stateMu.Lock()
+	if r, ok := nonceRejections[key]; !ok || nonce < r.nonce {
+		nonceRejections[key] = nonceRejection{nonce: nonce, txHash: txHash}
+	}
+	stateMu.Unlock()
+
+	logEvent("warn", "nonce.rejected", fmt.Sprintf("nonce %d of account %d, api key %d was refused: %v", nonce, accountIndex, apiKeyIndex, err), nil)
+	emitEvent("nonceGap", map[string]any{"accountIndex": accountIndex, "apiKeyIndex": apiKeyIndex, "nonce": nonce, "txHash": txHash, "error": wrapErr(err)})
+}
+
+// queuedTxsOf returns the queued txs of the sequence key, oldest first.
+func queuedTxsOf(key nonceKey) []*queuedTx {
+	txQueue.mu.Lock()
+	defer txQueue.mu.Unlock()
+	var res []*queuedTx
+	for _, q := range txQueue.txs {
+		if accountIndex, apiKeyIndex, _, err := types.SenderOf(q.tx); err == nil && accountIndex == key.accountIndex && apiKeyIndex == key.apiKeyIndex {
+			res = append(res, q)
+		}
+	}
+	return res
+}
+
+// nonceGapReport compares the nonce the exchange expects next from a sequence with the ones the module used.
+type nonceGapReport struct {
+	Expected int64
+	// LowestRejected and LowestQueued are -1 when no tx was refused for its nonce or none is queued.
+	LowestRejected int64
+	LowestQueued   int64
+	Queued         int
+	// Gap is set when a tx was refused or is queued with a nonce above Expected: the nonces in between were
+	// never used, so those txs cannot be applied. Stale is set when queued txs use nonces already consumed.
+	Gap   bool
+	Stale bool
+}
+
+// CheckNonceGap fetches the next nonce of the sequence of c and compares it with the refused and queued txs.
+func CheckNonceGap(c *client.TxClient) (*nonceGapReport, error) {
+	if c.HTTP() == nil {
+		return nil, fmt.Errorf("HTTP client not configured, cannot fetch the next nonce")
+	}
+	key := nonceKey{c.GetAccountIndex(), c.GetApiKeyIndex()}
+	expected, err := c.HTTP().GetNextNonce(key.accountIndex, key.apiKeyIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the next nonce: %w", err)
+	}
+
+	r := &nonceGapReport{Expected: expected, LowestRejected: -1, LowestQueued: -1}
+	stateMu.RLock()
+	if rejected, ok := nonceRejections[key]; ok {
+		r.LowestRejected = rejected.nonce
+	}
+	stateMu.RUnlock()
+	for _, q := range queuedTxsOf(key) {
+		_, _, nonce, _ := types.SenderOf(q.tx)
+		if r.LowestQueued == -1 || nonce < r.LowestQueued {
+			r.LowestQueued = nonce
+		}
+		r.Queued++
+	}
+	r.Gap = r.LowestRejected > expected || r.LowestQueued > expected
+	r.Stale = r.LowestQueued != -1 && r.LowestQueued < expected
+	return r, nil
+}
+
+// resignedTx is a queued tx signed again with another nonce.
+type resignedTx struct {
+	OldTxHash string
+	TxHash    string
+	Nonce     int64
+}
+
+// RepairNonceGap re-syncs the sequence of c with the exchange and forgets the nonce rejections seen. When resign
+// is set, the queued txs of the sequence are signed again by c, oldest first, with the nonces following the one
+// the exchange expects, and replace the old ones in the queue. nextNonce is the nonce to sign with next.
+func RepairNonceGap(c *client.TxClient, resign bool) (expected, nextNonce int64, resigned []resignedTx, err error) {
+	if c.HTTP() == nil {
+		return 0, 0, nil, fmt.Errorf("HTTP client not configured, cannot fetch the next nonce")
+	}
+	key := nonceKey{c.GetAccountIndex(), c.GetApiKeyIndex()}
+	expected, err = c.HTTP().GetNextNonce(key.accountIndex, key.apiKeyIndex)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("failed to fetch the next nonce: %w", err)
+	}
+	stateMu.Lock()
+	delete(nonceRejections, key)
+	stateMu.Unlock()
+
+	nextNonce = expected
+	if !resign {
+		return expected, nextNonce, nil, nil
+	}
+	for _, q := range queuedTxsOf(key) {
+		tx, err := types.WithNonce(q.tx, nextNonce)
+		if err != nil {
+			return expected, nextNonce, resigned, err
+		}
+		msgHash, err := c.PrepareTx(tx)
+		if err == nil {
+			err = c.SignPreparedTx(tx, msgHash)
+		}
+		if err != nil {
+			return expected, nextNonce, resigned, fmt.Errorf("failed to sign queued tx %s again: %w", q.txHash, err)
+		}
+
+		oldTxHash := q.txHash
+		stateMu.Lock()
+		dropOrdersLocked(key.accountIndex, func(o *trackedOrder) bool { return o.TxHash == oldTxHash })
+		stateMu.Unlock()
+		if _, err := formatTxInfo(tx); err != nil {
+			return expected, nextNonce, resigned, err
+		}
+		txQueue.mu.Lock()
+		q.tx, q.txHash = tx, tx.GetTxHash()
+		txQueue.mu.Unlock()
+
+		resigned = append(resigned, resignedTx{OldTxHash: oldTxHash, TxHash: q.txHash, Nonce: nextNonce})
+		nextNonce++
+	}
+	return expected, nextNonce, resigned, nil
+}
+
+// jsCheckNonceGap expects (clientIndex?) and returns a Promise resolving to {expected, lowestRejected,
+// lowestQueued, queued, gap, stale} for the api key of the client, see RepairNonceGap.
+func jsCheckNonceGap(this js.Value, args []js.Value) any {
+	c, err := clientFromArgs(args, 0)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+
+	return newPromise(func(callClock) map[string]any {
+		r, err := CheckNonceGap(c)
+		if err != nil {
+			return map[string]any{"error": wrapErr(err)}
+		}
+		return map[string]any{
+			"expected":       r.Expected,
+			"lowestRejected": r.LowestRejected,
+			"lowestQueued":   r.LowestQueued,
+			"queued":         r.Queued,
+			"gap":            r.Gap,
+			"stale":          r.Stale,
+			"error":          "",
+		}
+	})
+}
+
+// jsRepairNonceGap expects (options?, clientIndex?) and returns a Promise resolving to {expected, nextNonce,
+// resigned}. options is {resign?}: when set, the queued txs of the client's api key are signed again with the
+// nonces following the one the exchange expects, and resigned lists {oldTxHash, txHash, nonce} for each.
+func jsRepairNonceGap(this js.Value, args []js.Value) any {
+	c, err := clientFromArgs(args, 1)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	var opts struct {
+		Resign bool `json:"resign"`
+	}
+	if len(args) > 0 && args[0].Type() == js.TypeObject {
+		if err := decodeStrict("options", args[0], objectSchema{"resign": {Type: "boolean"}}, &opts); err != nil {
+			return js.ValueOf(errorResult(err))
+		}
+	}
+
+	return newPromise(func(callClock) map[string]any {
+		expected, nextNonce, resigned, err := RepairNonceGap(c, opts.Resign)
+		list := make([]any, 0, len(resigned))
+		for _, r := range resigned {
+			list = append(list, map[string]any{"oldTxHash": r.OldTxHash, "txHash": r.TxHash, "nonce": r.Nonce})
+		}
+		if err != nil {
+			return map[string]any{"resigned": list, "error": wrapErr(err)}
+		}
+		logEvent("info", "nonce.repaired", fmt.Sprintf("nonces of account %d, api key %d resynced at %d", c.GetAccountIndex(), c.GetApiKeyIndex(), expected), map[string]any{"resigned": len(resigned)})
+		return map[string]any{"expected": expected, "nextNonce": nextNonce, "resigned": list, "error": ""}
+	})
+}
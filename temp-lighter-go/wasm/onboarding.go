@@ -0,0 +1,141 @@
+package main
+
+import (
+	"math"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var onboardingOptionsSchema = objectSchema{
+	"seed":              {Type: "string", MaxLength: 1024},
+	"authTokenDeadline": intField(0, math.MaxInt64),
+}
+
+// onboardingBundle holds what a new user needs to register an api key on an account and start trading with it.
+type onboardingBundle struct {
+	PrivateKey  string
+	PublicKey   string
+	Fingerprint string
+	// TxInfo is the ChangePubKey tx registering the key, signed with it. L1Message is what the L1 wallet of the
+	// account signs to authorize it, the signature going in its L1Sig field.
+	TxInfo    string
+	L1Message string
+	// AuthToken, valid until AuthTokenDeadline, is signed with the key and usable once the tx is accepted.
+	AuthToken         string
+	AuthTokenDeadline time.Time
+}
+
+// BuildOnboarding generates the api key apiKeyIndex of accountIndex on chainId, derived from seed when it is not
+// empty, and assembles its registration tx, signed with nonce, and a first auth token expiring at deadline.
+// Nothing is sent and the loaded clients are left as they are.
+func BuildOnboarding(seed string, accountIndex int64, apiKeyIndex uint8, chainId uint32, nonce int64, deadline time.Time) (*onboardingBundle, error) {
+	key, err := generateAPIKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	c := client.NewTxClientWithKeyManager(nil, key, accountIndex, apiKeyIndex, chainId)
+	tx, err := c.GetChangePubKeyTransaction(&types.ChangePubKeyReq{PubKey: key.PubKeyBytes()}, &types.TransactOpts{
+		FromAccountIndex: &accountIndex,
+		ApiKeyIndex:      &apiKeyIndex,
+		Nonce:            &nonce,
+	})
+	if err != nil {
+		return nil, err
+	}
+	txInfo, err := formatTxInfo(tx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := c.GetAuthToken(deadline)
+	if err != nil {
+		return nil, err
+	}
+	recordSign(nil)
+
+	pubKey := key.PubKeyBytes()
+	b := &onboardingBundle{
+		PrivateKey:        hexutil.Encode(key.PrvKeyBytes()),
+		PublicKey:         hexutil.Encode(pubKey[:]),
+		TxInfo:            txInfo,
+		L1Message:         tx.GetL1SignatureBody(),
+		AuthToken:         token,
+		AuthTokenDeadline: deadline,
+	}
+	registerSecret(b.PrivateKey)
+	if b.Fingerprint, err = GetKeyFingerprint(b.PublicKey); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// jsBuildOnboarding expects (accountIndex, apiKeyIndex, chainId, nonce, options?) and returns {privateKey,
+// publicKey, fingerprint, registration: {txType, txInfo, l1Message}, authToken: {token, deadline}, steps}, the
+// artifacts a new user goes through in the order of steps. options is {seed?, authTokenDeadline?}: seed derives
+// the key instead of sampling it, and authTokenDeadline, in seconds, defaults to the profile's token validity.
+func jsBuildOnboarding(this js.Value, args []js.Value) any {
+	if len(args) < 4 {
+		return js.ValueOf(map[string]any{"error": "BuildOnboarding expects at least 4 args: accountIndex, apiKeyIndex, chainId, nonce"})
+	}
+	accountIndex, err := intArg("accountIndex", args[0], txtypes.MinAccountIndex, txtypes.MaxAccountIndex)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	apiKeyIndex, err := intArg("apiKeyIndex", args[1], 0, int64(txtypes.MaxApiKeyIndex))
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	chainId, err := intArg("chainId", args[2], 0, math.MaxUint32)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	nonce, err := intArg("nonce", args[3], 0, math.MaxInt64)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	var opts struct {
+		Seed              string `json:"seed"`
+		AuthTokenDeadline int64  `json:"authTokenDeadline"`
+	}
+	if len(args) > 4 && args[4].Type() == js.TypeObject {
+		if err := decodeStrict("options", args[4], onboardingOptionsSchema, &opts); err != nil {
+			return js.ValueOf(errorResult(err))
+		}
+	}
+	registerSecret(opts.Seed)
+	deadline := client.Now().Add(authTokenTtl())
+	if opts.AuthTokenDeadline != 0 {
+		deadline = time.Unix(opts.AuthTokenDeadline, 0)
+	}
+
+	b, err := BuildOnboarding(opts.Seed, accountIndex, uint8(apiKeyIndex), uint32(chainId), nonce, deadline)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	return js.ValueOf(map[string]any{
+		"privateKey":  b.PrivateKey,
+		"publicKey":   b.PublicKey,
+		"fingerprint": b.Fingerprint,
+		"registration": map[string]any{
+			"txType":    int(txtypes.TxTypeL2ChangePubKey),
+			"txInfo":    b.TxInfo,
+			"l1Message": b.L1Message,
+		},
+		"authToken": map[string]any{
+			"token":    b.AuthToken,
+			"deadline": b.AuthTokenDeadline.Unix(),
+		},
+		"steps": []any{
+			"store privateKey",
+			"sign registration.l1Message with the account's L1 wallet",
+			"set the signature as L1Sig in registration.txInfo and submit it",
+			"wait for the registration to be accepted",
+			"CreateClient with privateKey, then authenticate with authToken.token",
+		},
+		"error": "",
+	})
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// reconcileGrace is how long an order signed by the module is kept while missing from the exchange view, as it
+// may not have been submitted yet.
+const reconcileGrace = 10 * time.Second
+
+// trackedOrder is an order signed by the module which rests on the book once accepted.
+type trackedOrder struct {
+	*txtypes.OrderInfo
+	AccountIndex int64
+	// OrderIndex is assigned by the exchange, it stays 0 until known.
+	OrderIndex int64
+	TxHash     string
+	SignedAt   time.Time
+	// Confirmed is set once the order was seen in the exchange view. Orders only known from the exchange have
+	// no TxHash.
+	Confirmed bool
+}
+
+// matches reports whether index, the index of a cancel or modify tx, designates o.
+func (o *trackedOrder) matches(index int64) bool {
+	if index <= txtypes.MaxClientOrderIndex {
+		return o.ClientOrderIndex != txtypes.NilClientOrderIndex && o.ClientOrderIndex == index
+	}
+	return o.OrderIndex == index
+}
+
+// ownOrders holds the resting orders of each account, by account index. Orders signed by the module are assumed
+// to rest from the moment they are signed until they are canceled by a tx signed by the module, expire, or are
+// missing from the exchange view passed to ReconcileOpenOrders.
+var ownOrders = map[int64][]*trackedOrder{}
+
+// rests reports whether order stays on the book when it does not fill immediately.
+func rests(order *txtypes.OrderInfo) bool {
+	return order.Type == txtypes.LimitOrder && order.TimeInForce != txtypes.ImmediateOrCancel
+}
+
+// trackSignedTx updates the resting orders with a tx the module signed.
+func trackSignedTx(tx txtypes.TxInfo) {
+	if tx == nil || tx.GetTxHash() == "" {
+		return
+	}
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	switch tx := tx.(type) {
+	case *txtypes.L2CreateOrderTxInfo:
+		trackOrderLocked(tx.AccountIndex, tx.OrderInfo, tx.GetTxHash())
+	case *txtypes.L2CreateGroupedOrdersTxInfo:
+		for _, order := range tx.Orders {
+			trackOrderLocked(tx.AccountIndex, order, tx.GetTxHash())
+		}
+	case *txtypes.L2ModifyOrderTxInfo:
+		for _, o := range ownOrders[tx.AccountIndex] {
+			if o.MarketIndex == tx.MarketIndex && o.matches(tx.Index) {
+				o.BaseAmount, o.Price, o.TriggerPrice = tx.BaseAmount, tx.Price, tx.TriggerPrice
+			}
+		}
+	case *txtypes.L2CancelOrderTxInfo:
+		dropOrdersLocked(tx.AccountIndex, func(o *trackedOrder) bool {
+			return o.MarketIndex == tx.MarketIndex && o.matches(tx.Index)
+		})
+	case *txtypes.L2CancelAllOrdersTxInfo:
+		if tx.TimeInForce == txtypes.ImmediateCancelAll {
+			delete(ownOrders, tx.AccountIndex)
+		}
+	}
+}
+
+func trackOrderLocked(accountIndex int64, order *txtypes.OrderInfo, txHash string) {
+	if !rests(order) {
+		return
+	}
+	info := *order
+	ownOrders[accountIndex] = append(ownOrders[accountIndex], &trackedOrder{
+		OrderInfo:    &info,
+		AccountIndex: accountIndex,
+		TxHash:       txHash,
+		SignedAt:     time.Now(),
+	})
+}
+
+func dropOrdersLocked(accountIndex int64, drop func(o *trackedOrder) bool) {
+	kept := ownOrders[accountIndex][:0]
+	for _, o := range ownOrders[accountIndex] {
+		if !drop(o) {
+			kept = append(kept, o)
+		}
+	}
+	if len(kept) == 0 {
+		delete(ownOrders, accountIndex)
+		return
+	}
+	ownOrders[accountIndex] = kept
+}
+
+// restingOrders returns the resting orders of accountIndex on marketIndex, dropping the expired ones first.
+func restingOrders(accountIndex int64, marketIndex uint8) []*txtypes.OrderInfo {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	now := client.Now().UnixMilli()
+	dropOrdersLocked(accountIndex, func(o *trackedOrder) bool {
+		return o.OrderExpiry != txtypes.NilOrderExpiry && o.OrderExpiry <= now
+	})
+
+	var res []*txtypes.OrderInfo
+	for _, o := range ownOrders[accountIndex] {
+		if o.MarketIndex == marketIndex {
+			res = append(res, o.OrderInfo)
+		}
+	}
+	return res
+}
+
+// ReconcileOpenOrders replaces the orders of accountIndex on marketIndex with open, the exchange view of them.
+// Orders signed by the module are matched by order index or client order index and keep their tx hash; the ones
+// missing from open are dropped unless they were signed less than reconcileGrace ago and never confirmed.
+func ReconcileOpenOrders(accountIndex int64, marketIndex uint8, open []*client.OpenOrder) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	var kept, market []*trackedOrder
+	for _, o := range ownOrders[accountIndex] {
+		if o.MarketIndex == marketIndex {
+			market = append(market, o)
+		} else {
+			kept = append(kept, o)
+		}
+	}
+
+	for _, e := range open {
+		var match *trackedOrder
+		for i, o := range market {
+			if o.matches(e.OrderIndex) || (e.ClientOrderIndex != txtypes.NilClientOrderIndex && o.matches(e.ClientOrderIndex)) {
+				match = o
+				market = append(market[:i], market[i+1:]...)
+				break
+			}
+		}
+		if match == nil {
+			match = &trackedOrder{
+				OrderInfo: &txtypes.OrderInfo{
+					MarketIndex:      marketIndex,
+					ClientOrderIndex: e.ClientOrderIndex,
+					IsAsk:            e.IsAsk,
+					Type:             txtypes.LimitOrder,
+					TimeInForce:      txtypes.GoodTillTime,
+				},
+				AccountIndex: accountIndex,
+			}
+		}
+		match.OrderIndex, match.BaseAmount, match.Price, match.OrderExpiry = e.OrderIndex, e.BaseAmount, e.Price, e.OrderExpiry
+		match.Confirmed = true
+		kept = append(kept, match)
+	}
+
+	for _, o := range market {
+		if !o.Confirmed && time.Since(o.SignedAt) < reconcileGrace {
+			kept = append(kept, o)
+		}
+	}
+	if len(kept) == 0 {
+		delete(ownOrders, accountIndex)
+		return
+	}
+	ownOrders[accountIndex] = kept
+}
+
+// FetchOpenOrders reconciles the orders of the client's account on markets with the exchange view. Without
+// markets, every market holding a tracked order is reconciled.
+func FetchOpenOrders(c *client.TxClient, markets []uint8) error {
+	if c.HTTP() == nil {
+		return fmt.Errorf("HTTP client not configured, cannot fetch open orders")
+	}
+	accountIndex := c.GetAccountIndex()
+	if len(markets) == 0 {
+		seen := map[uint8]bool{}
+		stateMu.RLock()
+		for _, o := range ownOrders[accountIndex] {
+			if !seen[o.MarketIndex] {
+				seen[o.MarketIndex] = true
+				markets = append(markets, o.MarketIndex)
+			}
+		}
+		stateMu.RUnlock()
+	}
+	if len(markets) == 0 {
+		return nil
+	}
+
+	auth, err := c.GetAuthToken(c.Now().Add(time.Minute))
+	if err != nil {
+		return err
+	}
+	for _, market := range markets {
+		open, err := c.HTTP().GetOpenOrders(accountIndex, market, auth)
+		if err != nil {
+			return fmt.Errorf("failed to fetch open orders of market %d: %w", market, err)
+		}
+		ReconcileOpenOrders(accountIndex, market, open)
+	}
+	return nil
+}
+
+// openOrdersResult lists the tracked orders of accountIndex, or of every account when nil, not expired at now.
+func openOrdersResult(accountIndex *int64, now time.Time) []any {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	var orders []*trackedOrder
+	for account := range ownOrders {
+		if accountIndex == nil || *accountIndex == account {
+			for _, o := range ownOrders[account] {
+				if o.OrderExpiry == txtypes.NilOrderExpiry || o.OrderExpiry > now.UnixMilli() {
+					orders = append(orders, o)
+				}
+			}
+		}
+	}
+	sort.SliceStable(orders, func(i, j int) bool {
+		if orders[i].AccountIndex != orders[j].AccountIndex {
+			return orders[i].AccountIndex < orders[j].AccountIndex
+		}
+		return orders[i].MarketIndex < orders[j].MarketIndex
+	})
+
+	res := make([]any, 0, len(orders))
+	for _, o := range orders {
+		res = append(res, o.result())
+	}
+	return res
+}
+
+func (o *trackedOrder) result() map[string]any {
+	var signedAt int64
+	if !o.SignedAt.IsZero() {
+		signedAt = o.SignedAt.UnixMilli()
+	}
+	return map[string]any{
+		"accountIndex":     o.AccountIndex,
+		"marketIndex":      o.MarketIndex,
+		"orderIndex":       o.OrderIndex,
+		"clientOrderIndex": o.ClientOrderIndex,
+		"isAsk":            o.IsAsk,
+		"price":            o.Price,
+		"baseAmount":       o.BaseAmount,
+		"orderExpiry":      o.OrderExpiry,
+		"txHash":           o.TxHash,
+		"signedAt":         signedAt,
+		"confirmed":        o.Confirmed,
+	}
+}
+
+var openOrderSchema = objectSchema{
+	"orderIndex":       requiredIntField(0, txtypes.MaxOrderIndex),
+	"clientOrderIndex": intField(0, txtypes.MaxClientOrderIndex),
+	"isAsk":            requiredIntField(0, 1),
+	"price":            requiredIntField(int64(txtypes.MinOrderPrice), int64(txtypes.MaxOrderPrice)),
+	"baseAmount":       requiredIntField(0, txtypes.MaxOrderBaseAmount),
+	"orderExpiry":      intField(0, math.MaxInt64),
+}
+
+// jsGetOpenOrdersCache expects (accountIndex?). Without accountIndex the orders of every account are returned.
+func jsGetOpenOrdersCache(this js.Value, args []js.Value) any {
+	var accountIndex *int64
+	if len(args) > 0 && args[0].Type() == js.TypeNumber {
+		accIdx := int64(args[0].Int())
+		accountIndex = &accIdx
+	}
+	return js.ValueOf(map[string]any{"orders": openOrdersResult(accountIndex, client.Now()), "error": ""})
+}
+
+// jsApplyOpenOrders expects (accountIndex, marketIndex, orders) and reconciles the cache with an exchange view
+// received elsewhere, e.g. an account orders snapshot from the websocket. orders lists {orderIndex,
+// clientOrderIndex?, isAsk, price, baseAmount, orderExpiry?} in protocol units, baseAmount being the remaining
+// base amount.
+func jsApplyOpenOrders(this js.Value, args []js.Value) any {
+	if len(args) < 3 {
+		return js.ValueOf(map[string]any{"error": "ApplyOpenOrders expects 3 args: accountIndex, marketIndex, orders"})
+	}
+	if args[0].Type() != js.TypeNumber || args[1].Type() != js.TypeNumber {
+		return js.ValueOf(map[string]any{"error": "accountIndex and marketIndex should be numbers"})
+	}
+	if !js.Global().Get("Array").Call("isArray", args[2]).Bool() {
+		return js.ValueOf(map[string]any{"error": "orders should be an array"})
+	}
+	accountIndex := int64(args[0].Int())
+	marketIndex := args[1].Int()
+	if marketIndex < 0 || marketIndex > int(txtypes.MaxMarketIndex) {
+		return js.ValueOf(map[string]any{"error": fmt.Sprintf("marketIndex should be an integer between 0 and %d", txtypes.MaxMarketIndex)})
+	}
+
+	open := make([]*client.OpenOrder, 0, args[2].Length())
+	for i := 0; i < args[2].Length(); i++ {
+		o := &client.OpenOrder{MarketIndex: uint8(marketIndex)}
+		if err := decodeStrict(fmt.Sprintf("orders[%d]", i), args[2].Index(i), openOrderSchema, o); err != nil {
+			return js.ValueOf(errorResult(err))
+		}
+		open = append(open, o)
+	}
+	ReconcileOpenOrders(accountIndex, uint8(marketIndex), open)
+	return js.ValueOf(map[string]any{"orders": openOrdersResult(&accountIndex, client.Now()), "error": ""})
+}
+
+// jsFetchOpenOrders expects (marketIndex?, clientIndex?) and returns a Promise. The orders are fetched for the
+// account of the client.
+func jsFetchOpenOrders(this js.Value, args []js.Value) any {
+	c, err := clientFromArgs(args, 1)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	var markets []uint8
+	if len(args) > 0 && args[0].Type() == js.TypeNumber {
+		marketIndex := args[0].Int()
+		if marketIndex < 0 || marketIndex > int(txtypes.MaxMarketIndex) {
+			return js.ValueOf(map[string]any{"error": fmt.Sprintf("marketIndex should be an integer between 0 and %d", txtypes.MaxMarketIndex)})
+		}
+		markets = append(markets, uint8(marketIndex))
+	}
+
+	accountIndex := c.GetAccountIndex()
+	return newPromise(func(clock callClock) map[string]any {
+		if err := FetchOpenOrders(clock.bind(c), markets); err != nil {
+			return map[string]any{"error": wrapErr(err)}
+		}
+		return map[string]any{"orders": openOrdersResult(&accountIndex, clock.Now()), "error": ""}
+	})
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// positions holds, by account index, the last known position of the account in every market as supplied
+// through SetPositions or fetched through FetchPositions. Markets missing from a snapshot hold no position;
+// reduce-only orders of accounts without a snapshot are not checked.
+var positions = map[int64]map[uint8]int64{}
+
+// checkReduceOnly is installed on every client created from JS, see installChecks.
+func checkReduceOnly(tx txtypes.TxInfo) error {
+	var accountIndex int64
+	var orders []*txtypes.OrderInfo
+	switch tx := tx.(type) {
+	case *txtypes.L2CreateOrderTxInfo:
+		accountIndex, orders = tx.AccountIndex, []*txtypes.OrderInfo{tx.OrderInfo}
+	case *txtypes.L2CreateGroupedOrdersTxInfo:
+		accountIndex, orders = tx.AccountIndex, tx.Orders
+	default:
+		return nil
+	}
+
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	markets, ok := positions[accountIndex]
+	if !ok {
+		return nil
+	}
+	for _, order := range orders {
+		if err := types.CheckReduceOnly(markets[order.MarketIndex], order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// installChecks adds the module wide pre-sign checks to a client created from JS.
+func installChecks(c *client.TxClient) *client.TxClient {
+	c.AddTxCheck(checkReduceOnly)
+	c.AddNamedTxCheck(postOnlyCrossCheck, checkPostOnlyCross)
+	c.AddTxCheck(checkSelfTrade)
+	c.AddTxCheck(checkTriggerDirection)
+	c.AddTxCheck(checkMarketRules)
+	c.AddTxCheck(checkPriceBand)
+	c.AddTxCheck(checkConfirmation)
+	c.AddNamedTxCheck(orderIntentCheck, checkOrderIntent)
+	c.OnRejected(recordMarketRejection)
+	return c
+}
+
+var positionSchema = objectSchema{
+	"marketIndex": requiredIntField(0, int64(txtypes.MaxMarketIndex)),
+	"size":        requiredIntField(-txtypes.MaxOrderBaseAmount, txtypes.MaxOrderBaseAmount),
+}
+
+// SetPositions replaces the position snapshot of accountIndex. A nil markets clears it, disabling the checks.
+func SetPositions(accountIndex int64, markets map[uint8]int64) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if markets == nil {
+		delete(positions, accountIndex)
+		return
+	}
+	positions[accountIndex] = markets
+}
+
+// FetchPositions replaces the position snapshot of accountIndex with the positions reported by the exchange.
+func FetchPositions(c *client.TxClient, accountIndex int64) (map[uint8]int64, error) {
+	if c.HTTP() == nil {
+		return nil, fmt.Errorf("HTTP client not configured, cannot fetch positions")
+	}
+	markets, err := c.HTTP().GetPositions(accountIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch positions: %w", err)
+	}
+	SetPositions(accountIndex, markets)
+	return markets, nil
+}
+
+func positionsResult(markets map[uint8]int64) []any {
+	res := make([]any, 0, len(markets))
+	for market := 0; market <= math.MaxUint8; market++ {
+		if size, ok := markets[uint8(market)]; ok {
+			res = append(res, map[string]any{"marketIndex": market, "size": size})
+		}
+	}
+	return res
+}
+
+// jsSetPositions expects (accountIndex, positions). positions lists {marketIndex, size} where size is the base
+// amount held, negative for a short; null clears the snapshot.
+func jsSetPositions(this js.Value, args []js.Value) any {
+	if len(args) < 2 {
+		return js.ValueOf(map[string]any{"error": "SetPositions expects 2 args: accountIndex, positions"})
+	}
+	if args[0].Type() != js.TypeNumber {
+		return js.ValueOf(map[string]any{"error": "accountIndex should be a number"})
+	}
+	accountIndex := int64(args[0].Int())
+
+	if args[1].IsNull() || args[1].IsUndefined() {
+		SetPositions(accountIndex, nil)
+		return js.ValueOf(map[string]any{"positions": []any{}, "error": ""})
+	}
+	if !js.Global().Get("Array").Call("isArray", args[1]).Bool() {
+		return js.ValueOf(map[string]any{"error": "positions should be an array"})
+	}
+
+	markets := map[uint8]int64{}
+	for i := 0; i < args[1].Length(); i++ {
+		var p struct {
+			MarketIndex uint8 `json:"marketIndex"`
+			Size        int64 `json:"size"`
+		}
+		if err := decodeStrict(fmt.Sprintf("positions[%d]", i), args[1].Index(i), positionSchema, &p); err != nil {
+			return js.ValueOf(errorResult(err))
+		}
+		if p.Size != 0 {
+			markets[p.MarketIndex] = p.Size
+		}
+	}
+	SetPositions(accountIndex, markets)
+	return js.ValueOf(map[string]any{"positions": positionsResult(markets), "error": ""})
+}
+
+// jsFetchPositions expects (accountIndex?, clientIndex?) and returns a Promise. accountIndex defaults to the
+// account of the client.
+func jsFetchPositions(this js.Value, args []js.Value) any {
+	c, err := clientFromArgs(args, 1)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	accountIndex := c.GetAccountIndex()
+	if len(args) > 0 && args[0].Type() == js.TypeNumber {
+		accountIndex = int64(args[0].Int())
+	}
+
+	return newPromise(func(callClock) map[string]any {
+		markets, err := FetchPositions(c, accountIndex)
+		if err != nil {
+			return map[string]any{"error": wrapErr(err)}
+		}
+		return map[string]any{"positions": positionsResult(markets), "error": ""}
+	})
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// referencePrices holds the best bid and ask, in price ticks, post-only orders and trigger prices are checked
+// against, by market index. Orders on markets without a reference are not checked against the book.
+var referencePrices = map[uint8][2]uint32{}
+
+// postOnlyCrossCheck names the check installed by installChecks which allowCrossClient removes for one call.
+const postOnlyCrossCheck = "postOnlyCross"
+
+// checkPostOnlyCross is installed on every client created from JS, see installChecks.
+func checkPostOnlyCross(tx txtypes.TxInfo) error {
+	var orders []*txtypes.OrderInfo
+	switch tx := tx.(type) {
+	case *txtypes.L2CreateOrderTxInfo:
+		orders = []*txtypes.OrderInfo{tx.OrderInfo}
+	case *txtypes.L2CreateGroupedOrdersTxInfo:
+		orders = tx.Orders
+	}
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	for _, order := range orders {
+		ref, ok := referencePrices[order.MarketIndex]
+		if !ok {
+			continue
+		}
+		if err := types.CheckPostOnlyCross(order, ref[0], ref[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allowCrossClient reads the allowCross flag of the options object at args[i]. When set, it returns a copy of c
+// skipping the post-only cross check, for the call in progress only.
+func allowCrossClient(c *client.TxClient, args []js.Value, i int) *client.TxClient {
+	if len(args) > i && args[i].Type() == js.TypeObject && args[i].Get("allowCross").Truthy() {
+		return c.WithTxCheck(postOnlyCrossCheck, nil)
+	}
+	return c
+}
+
+// SetReferencePrices sets the top of book orders on marketIndex are checked against. A nil price
+// leaves its side unchecked, two nil prices remove the reference.
+func SetReferencePrices(marketIndex uint8, bestBid, bestAsk uint32) error {
+	if bestBid != txtypes.NilOrderPrice && bestAsk != txtypes.NilOrderPrice && bestBid >= bestAsk {
+		return fmt.Errorf("best bid %d should be below best ask %d", bestBid, bestAsk)
+	}
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if bestBid == txtypes.NilOrderPrice && bestAsk == txtypes.NilOrderPrice {
+		delete(referencePrices, marketIndex)
+		return nil
+	}
+	referencePrices[marketIndex] = [2]uint32{bestBid, bestAsk}
+	return nil
+}
+
+// FetchReferencePrices sets the reference of marketIndex to the top of book reported by the exchange.
+func FetchReferencePrices(c *client.TxClient, marketIndex uint8) (bestBid, bestAsk uint32, err error) {
+	if c.HTTP() == nil {
+		return 0, 0, fmt.Errorf("HTTP client not configured, cannot fetch the top of book")
+	}
+	bestBid, bestAsk, err = c.HTTP().GetTopOfBook(marketIndex)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to fetch the top of book: %w", err)
+	}
+	return bestBid, bestAsk, SetReferencePrices(marketIndex, bestBid, bestAsk)
+}
+
+// jsSetReferencePrices expects (marketIndex, bestBid, bestAsk) in price ticks. Passing 0 for both removes the
+// reference of the market.
+func jsSetReferencePrices(this js.Value, args []js.Value) any {
+	if len(args) < 3 {
+		return js.ValueOf(map[string]any{"error": "SetReferencePrices expects 3 args: marketIndex, bestBid, bestAsk"})
+	}
+
+	var values [3]int64
+	for i, bound := range []int64{int64(txtypes.MaxMarketIndex), math.MaxUint32, math.MaxUint32} {
+		if args[i].Type() != js.TypeNumber || args[i].Int() < 0 || int64(args[i].Int()) > bound {
+			return js.ValueOf(map[string]any{"error": fmt.Sprintf("arg %d should be an integer between 0 and %d", i, bound)})
+		}
+		values[i] = int64(args[i].Int())
+	}
+	if err := SetReferencePrices(uint8(values[0]), uint32(values[1]), uint32(values[2])); err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"error": ""})
+}
+
+// jsFetchReferencePrices expects (marketIndex, clientIndex?) and returns a Promise.
+func jsFetchReferencePrices(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "FetchReferencePrices expects at least 1 arg: marketIndex"})
+	}
+	c, err := clientFromArgs(args, 1)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	marketIndex := uint8(args[0].Int())
+
+	return newPromise(func(callClock) map[string]any {
+		bestBid, bestAsk, err := FetchReferencePrices(c, marketIndex)
+		if err != nil {
+			return map[string]any{"error": wrapErr(err)}
+		}
+		return map[string]any{"bestBid": bestBid, "bestAsk": bestAsk, "error": ""}
+	})
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// defaultPriceFeedMaxAge is how long a price pushed through UpdatePriceFeed is used until ConfigurePriceFeed
+// is called.
+const defaultPriceFeedMaxAge = 10 * time.Second
+
+// feedPrice is the last mark and index price, in price ticks, received for a market.
+type feedPrice struct {
+	mark, index uint32
+	receivedAt  time.Time
+}
+
+// priceFeed holds the prices the host pushes from its market data subscription, by market index. Prices older
+// than maxAge are stale: the checks fed by them are skipped rather than run against an old price.
+var priceFeed = struct {
+	prices          map[uint8]feedPrice
+	maxAge          time.Duration
+	maxDeviationBps uint32
+}{prices: map[uint8]feedPrice{}, maxAge: defaultPriceFeedMaxAge}
+
+var priceFeedOptionsSchema = objectSchema{
+	"maxAgeMs":        intField(1, 3_600_000),
+	"maxDeviationBps": intField(0, 10_000),
+}
+
+var priceFeedUpdateSchema = objectSchema{
+	"marketIndex": requiredIntField(0, int64(txtypes.MaxMarketIndex)),
+	"markPrice":   requiredIntField(0, math.MaxUint32),
+	"indexPrice":  intField(0, math.MaxUint32),
+}
+
+// freshMarkPriceLocked returns the mark price of marketIndex, or the nil price when the feed has none or it is
+// stale.
+func freshMarkPriceLocked(marketIndex uint8, now time.Time) uint32 {
+	p, ok := priceFeed.prices[marketIndex]
+	if !ok || now.Sub(p.receivedAt) > priceFeed.maxAge {
+		return txtypes.NilOrderPrice
+	}
+	return p.mark
+}
+
+// freshMarkPrice is freshMarkPriceLocked for callers not holding stateMu.
+func freshMarkPrice(marketIndex uint8) uint32 {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	return freshMarkPriceLocked(marketIndex, time.Now())
+}
+
+// checkPriceBand is installed on every client created from JS, see installChecks. Orders are checked against
+// the mark price of their market while the feed holds a fresh one.
+func checkPriceBand(tx txtypes.TxInfo) error {
+	var orders []*txtypes.OrderInfo
+	switch tx := tx.(type) {
+	case *txtypes.L2CreateOrderTxInfo:
+		orders = []*txtypes.OrderInfo{tx.OrderInfo}
+	case *txtypes.L2CreateGroupedOrdersTxInfo:
+		orders = tx.Orders
+	}
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	now := time.Now()
+	for _, order := range orders {
+		if err := types.CheckPriceBand(order, freshMarkPriceLocked(order.MarketIndex, now), priceFeed.maxDeviationBps); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdatePriceFeed records the mark and index price of marketIndex. A nil mark price removes the market from
+// the feed.
+func UpdatePriceFeed(marketIndex uint8, markPrice, indexPrice uint32) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if markPrice == txtypes.NilOrderPrice {
+		delete(priceFeed.prices, marketIndex)
+		return
+	}
+	priceFeed.prices[marketIndex] = feedPrice{mark: markPrice, index: indexPrice, receivedAt: time.Now()}
+}
+
+func priceFeedResult() map[string]any {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	markets := make([]int, 0, len(priceFeed.prices))
+	for market := range priceFeed.prices {
+		markets = append(markets, int(market))
+	}
+	sort.Ints(markets)
+
+	now := time.Now()
+	prices := make([]any, 0, len(markets))
+	for _, market := range markets {
+		p := priceFeed.prices[uint8(market)]
+		age := now.Sub(p.receivedAt)
+		prices = append(prices, map[string]any{
+			"marketIndex": market,
+			"markPrice":   p.mark,
+			"indexPrice":  p.index,
+			"ageMs":       age.Milliseconds(),
+			"stale":       age > priceFeed.maxAge,
+		})
+	}
+	return map[string]any{
+		"prices":          prices,
+		"maxAgeMs":        priceFeed.maxAge.Milliseconds(),
+		"maxDeviationBps": priceFeed.maxDeviationBps,
+		"error":           "",
+	}
+}
+
+// jsConfigurePriceFeed expects (options). options is {maxAgeMs?, maxDeviationBps?}: prices older than maxAgeMs
+// are ignored, orders priced more than maxDeviationBps from a fresh mark price are refused. A zero
+// maxDeviationBps, the default, disables the check.
+func jsConfigurePriceFeed(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "ConfigurePriceFeed expects 1 arg: options"})
+	}
+	var opts struct {
+		MaxAgeMs        *int64  `json:"maxAgeMs"`
+		MaxDeviationBps *uint32 `json:"maxDeviationBps"`
+	}
+	if err := decodeStrict("options", args[0], priceFeedOptionsSchema, &opts); err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+
+	stateMu.Lock()
+	if opts.MaxAgeMs != nil {
+		priceFeed.maxAge = time.Duration(*opts.MaxAgeMs) * time.Millisecond
+	}
+	if opts.MaxDeviationBps != nil {
+		priceFeed.maxDeviationBps = *opts.MaxDeviationBps
+	}
+	stateMu.Unlock()
+	return js.ValueOf(priceFeedResult())
+}
+
+// jsUpdatePriceFeed expects (updates), the prices received from the host's market data subscription. updates
+// lists {marketIndex, markPrice, indexPrice?} in price ticks; a markPrice of 0 removes the market from the feed.
+func jsUpdatePriceFeed(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "UpdatePriceFeed expects 1 arg: updates"})
+	}
+	if !js.Global().Get("Array").Call("isArray", args[0]).Bool() {
+		return js.ValueOf(map[string]any{"error": "updates should be an array"})
+	}
+
+	type update struct {
+		MarketIndex uint8  `json:"marketIndex"`
+		MarkPrice   uint32 `json:"markPrice"`
+		IndexPrice  uint32 `json:"indexPrice"`
+	}
+	updates := make([]update, args[0].Length())
+	for i := range updates {
+		if err := decodeStrict(fmt.Sprintf("updates[%d]", i), args[0].Index(i), priceFeedUpdateSchema, &updates[i]); err != nil {
+			return js.ValueOf(errorResult(err))
+		}
+	}
+	for _, u := range updates {
+		UpdatePriceFeed(u.MarketIndex, u.MarkPrice, u.IndexPrice)
+	}
+	return js.ValueOf(map[string]any{"updated": len(updates), "error": ""})
+}
+
+// jsGetPriceFeed expects () and returns the prices held by the feed, with their age.
+func jsGetPriceFeed(this js.Value, args []js.Value) any {
+	return js.ValueOf(priceFeedResult())
+}
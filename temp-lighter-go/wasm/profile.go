@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+)
+
+// profile holds the module wide defaults LoadProfile sets in one call.
+type profile struct {
+	// TxExpirySec is how long txs signed without an explicit expiry stay valid.
+	TxExpirySec int64 `json:"txExpirySec"`
+	// AuthTokenTtlSec is the validity of auth tokens created or minted without a deadline.
+	AuthTokenTtlSec int64 `json:"authTokenTtlSec"`
+	// BatchRetries and BatchBackoffMs are the retry budget of a throttled SubmitBatch chunk.
+	BatchRetries   int   `json:"batchRetries"`
+	BatchBackoffMs int64 `json:"batchBackoffMs"`
+	// TxQueueSize bounds the offline tx queue; 0 disables queuing.
+	TxQueueSize int `json:"txQueueSize"`
+	// MaxOrdersPerMinute, MaxNotionalPerHour and MaxNotionalPerDay are the limits of the clients created
+	// afterwards without their own. Zero disables a limit.
+	MaxOrdersPerMinute int64 `json:"maxOrdersPerMinute"`
+	MaxNotionalPerHour int64 `json:"maxNotionalPerHour"`
+	MaxNotionalPerDay  int64 `json:"maxNotionalPerDay"`
+	// PriceMaxAgeMs and MaxDeviationBps configure the price feed, see ConfigurePriceFeed.
+	PriceMaxAgeMs   int64  `json:"priceMaxAgeMs"`
+	MaxDeviationBps uint32 `json:"maxDeviationBps"`
+	// CancelsFirst makes SubmitBatch sign and send the cancels of a batch before its other txs.
+	CancelsFirst bool `json:"cancelsFirst"`
+}
+
+// profiles are the named profiles LoadProfile accepts. "default" restores the values the module starts with.
+var profiles = map[string]profile{
+	"default": {
+		TxExpirySec:     int64(client.MaxDefaultExpireTime / time.Second),
+		AuthTokenTtlSec: 600,
+		BatchRetries:    defaultBatchRetries,
+		BatchBackoffMs:  defaultBatchBackoff.Milliseconds(),
+		TxQueueSize:     defaultTxQueueSize,
+		PriceMaxAgeMs:   defaultPriceFeedMaxAge.Milliseconds(),
+	},
+	// conservative favors refusing over signing: short validity, few retries and tight limits.
+	"conservative": {
+		TxExpirySec:        120,
+		AuthTokenTtlSec:    300,
+		BatchRetries:       1,
+		BatchBackoffMs:     2_000,
+		TxQueueSize:        20,
+		MaxOrdersPerMinute: 60,
+		PriceMaxAgeMs:      5_000,
+		MaxDeviationBps:    500,
+		CancelsFirst:       true,
+	},
+	// hft keeps quotes short lived and never queues them, as a stale quote must not reach the book later.
+	"hft": {
+		TxExpirySec:     30,
+		AuthTokenTtlSec: 600,
+		BatchRetries:    5,
+		BatchBackoffMs:  200,
+		TxQueueSize:     0,
+		PriceMaxAgeMs:   1_000,
+		MaxDeviationBps: 200,
+		CancelsFirst:    true,
+	},
+	// testnet is permissive and patient with a slower exchange.
+	"testnet": {
+		TxExpirySec:     int64(client.MaxDefaultExpireTime / time.Second),
+		AuthTokenTtlSec: 3_600,
+		BatchRetries:    10,
+		BatchBackoffMs:  500,
+		TxQueueSize:     1_000,
+		PriceMaxAgeMs:   60_000,
+	},
+}
+
+var profileSchema = objectSchema{
+	"txExpirySec":        intField(1, int64(client.MaxDefaultExpireTime/time.Second)),
+	"authTokenTtlSec":    intField(1, 7*24*3600),
+	"batchRetries":       batchOptionsSchema["maxRetries"],
+	"batchBackoffMs":     intField(0, 60_000),
+	"txQueueSize":        intField(0, 10_000),
+	"maxOrdersPerMinute": policySchema["maxOrdersPerMinute"],
+	"maxNotionalPerHour": policySchema["maxNotionalPerHour"],
+	"maxNotionalPerDay":  policySchema["maxNotionalPerDay"],
+	"priceMaxAgeMs":      priceFeedOptionsSchema["maxAgeMs"],
+	"maxDeviationBps":    priceFeedOptionsSchema["maxDeviationBps"],
+	"cancelsFirst":       {Type: "boolean"},
+}
+
+// activeProfile is the profile last loaded, guarded by stateMu.
+var (
+	activeProfileName = "default"
+	activeProfile     = profiles["default"]
+)
+
+// currentProfile returns the profile last loaded.
+func currentProfile() profile {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	return activeProfile
+}
+
+// LoadProfile applies p, loaded under name: the defaults it holds are used from now on and the price feed and
+// tx queue are reconfigured. Clients already created keep their limits.
+func LoadProfile(name string, p profile) error {
+	if err := client.SetDefaultExpireTime(time.Duration(p.TxExpirySec) * time.Second); err != nil {
+		return err
+	}
+
+	stateMu.Lock()
+	activeProfileName, activeProfile = name, p
+	priceFeed.maxAge = time.Duration(p.PriceMaxAgeMs) * time.Millisecond
+	priceFeed.maxDeviationBps = p.MaxDeviationBps
+	stateMu.Unlock()
+
+	txQueue.mu.Lock()
+	txQueue.maxSize = p.TxQueueSize
+	if len(txQueue.txs) > txQueue.maxSize {
+		txQueue.txs = txQueue.txs[:txQueue.maxSize]
+	}
+	txQueue.mu.Unlock()
+	return nil
+}
+
+func profileResult() map[string]any {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	p := activeProfile
+	return map[string]any{
+		"name": activeProfileName,
+		"profile": map[string]any{
+			"txExpirySec":        p.TxExpirySec,
+			"authTokenTtlSec":    p.AuthTokenTtlSec,
+			"batchRetries":       p.BatchRetries,
+			"batchBackoffMs":     p.BatchBackoffMs,
+			"txQueueSize":        p.TxQueueSize,
+			"maxOrdersPerMinute": p.MaxOrdersPerMinute,
+			"maxNotionalPerHour": p.MaxNotionalPerHour,
+			"maxNotionalPerDay":  p.MaxNotionalPerDay,
+			"priceMaxAgeMs":      p.PriceMaxAgeMs,
+			"maxDeviationBps":    p.MaxDeviationBps,
+			"cancelsFirst":       p.CancelsFirst,
+		},
+		"error": "",
+	}
+}
+
+// jsLoadProfile expects (name, overrides?). name is "default", "conservative", "hft" or "testnet"; overrides
+// replaces any field of the profile, e.g. {txExpirySec: 60}. Returns the profile now in effect.
+func jsLoadProfile(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "LoadProfile expects at least 1 arg: name"})
+	}
+	p, ok := profiles[args[0].String()]
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for name := range profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return js.ValueOf(map[string]any{"error": fmt.Sprintf("unknown profile %q, expected one of %s", args[0].String(), strings.Join(names, ", "))})
+	}
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		if err := decodeStrict("overrides", args[1], profileSchema, &p); err != nil {
+			return js.ValueOf(errorResult(err))
+		}
+	}
+
+	if err := LoadProfile(args[0].String(), p); err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(profileResult())
+}
+
+// jsGetProfile expects () and returns the name and values of the profile in effect.
+func jsGetProfile(this js.Value, args []js.Value) any {
+	return js.ValueOf(profileResult())
+}
+
+// authTokenTtl is the validity of the auth tokens created without a deadline.
+func authTokenTtl() time.Duration {
+	return time.Duration(currentProfile().AuthTokenTtlSec) * time.Second
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall/js"
+)
+
+const redacted = "[REDACTED]"
+
+// minSecretLength keeps short passphrases from redacting every occurrence of common substrings.
+const minSecretLength = 8
+
+// RedactionMode selects what the redaction filter removes from errors and warnings.
+type RedactionMode int
+
+const (
+	// RedactKnown removes the secrets the module has seen: private keys, seeds and passphrases.
+	RedactKnown RedactionMode = iota
+	// RedactStrict additionally removes every hex string of 32 bytes or more, which covers keys the module has
+	// not seen as well as signatures and hashes.
+	RedactStrict
+)
+
+var longHex = regexp.MustCompile(`(?i)(0x)?[0-9a-f]{64,}`)
+
+// redactor holds fingerprints of secrets rather than the secrets themselves, so that it is not one more copy of
+// the key material in memory. Outputs are scanned window by window for every registered secret length.
+type redactor struct {
+	mu   sync.RWMutex
+	mode RedactionMode
+	// hex holds secrets that are hex strings, lowercased and without 0x prefix, matched case-insensitively.
+	hex map[int]map[[32]byte]struct{}
+	raw map[int]map[[32]byte]struct{}
+}
+
+var redaction = &redactor{
+	hex: map[int]map[[32]byte]struct{}{},
+	raw: map[int]map[[32]byte]struct{}{},
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// hexSecret returns s lowercased and without 0x prefix when it is a hex secret, matched case-insensitively.
+func hexSecret(s string) (string, bool) {
+	if h := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X"); isHex(h) && len(h) >= minSecretLength {
+		return strings.ToLower(h), true
+	}
+	return s, false
+}
+
+// registerSecret makes the filter remove s, and its hex variants, from every output.
+func registerSecret(s string) {
+	set := redaction.raw
+	if h, ok := hexSecret(s); ok {
+		s, set = h, redaction.hex
+	}
+	if len(s) < minSecretLength {
+		return
+	}
+
+	redaction.mu.Lock()
+	defer redaction.mu.Unlock()
+	if set[len(s)] == nil {
+		set[len(s)] = map[[32]byte]struct{}{}
+	}
+	set[len(s)][sha256.Sum256([]byte(s))] = struct{}{}
+}
+
+// scan replaces the secrets of s. With tag, each is replaced by secretTag instead of redacted, keeping its 0x
+// prefix, so that the secret can be put back by whoever holds it.
+func (r *redactor) scan(s string, secrets map[int]map[[32]byte]struct{}, fold, tag bool) string {
+	for n, set := range secrets {
+		for i := 0; i+n <= len(s); i++ {
+			window := s[i : i+n]
+			if fold {
+				window = strings.ToLower(window)
+			}
+			sum := sha256.Sum256([]byte(window))
+			if _, ok := set[sum]; !ok {
+				continue
+			}
+			start, repl := i, redacted
+			if tag {
+				repl = secretTag(sum)
+			} else if fold && i >= 2 && (s[i-2:i] == "0x" || s[i-2:i] == "0X") {
+				start -= 2
+			}
+			s = s[:start] + repl + s[i+n:]
+			i = start + len(repl) - 1
+		}
+	}
+	return s
+}
+
+// secretTag names the secret fingerprinted sum without revealing it.
+func secretTag(sum [32]byte) string {
+	return fmt.Sprintf("[REDACTED:%x]", sum[:6])
+}
+
+// redactTagged removes the known secrets from s like redact, but replaces each by its secretTag.
+func redactTagged(s string) string {
+	redaction.mu.RLock()
+	defer redaction.mu.RUnlock()
+	s = redaction.scan(s, redaction.hex, true, true)
+	return redaction.scan(s, redaction.raw, false, true)
+}
+
+// redact removes key material from s. Every string leaving the module as an error or a log record goes through
+// it.
+func redact(s string) string {
+	redaction.mu.RLock()
+	defer redaction.mu.RUnlock()
+
+	s = redaction.scan(s, redaction.hex, true, false)
+	s = redaction.scan(s, redaction.raw, false, false)
+	if redaction.mode == RedactStrict {
+		s = longHex.ReplaceAllString(s, redacted)
+	}
+	return s
+}
+
+func ParseRedactionMode(s string) (RedactionMode, error) {
+	switch s {
+	case "known":
+		return RedactKnown, nil
+	case "strict":
+		return RedactStrict, nil
+	}
+	return 0, fmt.Errorf("unknown redaction mode %q, expected known or strict", s)
+}
+
+func (m RedactionMode) String() string {
+	if m == RedactStrict {
+		return "strict"
+	}
+	return "known"
+}
+
+// jsSetRedaction expects (mode). Redaction cannot be turned off, "known" is the default.
+func jsSetRedaction(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "SetRedaction expects 1 arg: mode"})
+	}
+
+	mode, err := ParseRedactionMode(args[0].String())
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	redaction.mu.Lock()
+	redaction.mode = mode
+	redaction.mu.Unlock()
+	return js.ValueOf(map[string]any{"mode": mode.String(), "error": ""})
+}
+
+// jsRedact expects (text). It runs text through the filter applied to errors and warnings, so hosts can filter
+// their own logs and check that nothing sensitive gets through.
+func jsRedact(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "Redact expects 1 arg: text"})
+	}
+	return js.ValueOf(map[string]any{"text": redact(args[0].String()), "error": ""})
+}
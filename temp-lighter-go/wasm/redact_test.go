@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"syscall/js"
+	"testing"
+	"time"
+)
+
+const (
+	testSeed       = "correct horse battery staple"
+	testPassphrase = "hunter2 but longer"
+)
+
+// secretVariants returns the ways secret may show up in an output: as is, and for hex keys without their 0x
+// prefix and uppercased.
+func secretVariants(secret string) []string {
+	variants := []string{secret}
+	if h, ok := hexSecret(secret); ok {
+		variants = append(variants, h, strings.ToUpper(h), "0x"+strings.ToUpper(h))
+	}
+	return variants
+}
+
+func checkRedacted(t *testing.T, output, where string, secrets ...string) {
+	t.Helper()
+	for _, secret := range secrets {
+		for _, variant := range secretVariants(secret) {
+			if strings.Contains(strings.ToLower(output), strings.ToLower(variant)) {
+				t.Errorf("%s leaks a secret: %s", where, output)
+			}
+		}
+	}
+}
+
+// TestRedaction registers a key, a seed and a passphrase the way the exports do, and checks that none of them
+// gets through the errors, warnings, log entries and recordings of the module.
+func TestRedaction(t *testing.T) {
+	privateKey, _, errStr := GenerateAPIKey(testSeed)
+	if errStr != "" {
+		t.Fatal(errStr)
+	}
+	res := js.ValueOf(jsCreateClient(js.Undefined(), []js.Value{js.ValueOf(privateKey), js.ValueOf(5), js.ValueOf(2), js.ValueOf(300)}))
+	if errStr := res.Get("error").String(); errStr != "" {
+		t.Fatal(errStr)
+	}
+	defer setDefaultClient(nil)
+	if err := LockClient(testPassphrase); err != nil {
+		t.Fatal(err)
+	}
+	secrets := []string{privateKey, testSeed, testPassphrase}
+
+	leaky := fmt.Sprintf("key %s, upper %s, bare %s, seed %q, passphrase %s", privateKey, strings.ToUpper(privateKey),
+		strings.TrimPrefix(privateKey, "0x"), testSeed, testPassphrase)
+
+	checkRedacted(t, wrapErr(fmt.Errorf("signing failed: %s", leaky)), "wrapErr", secrets...)
+	checkRedacted(t, redactTagged(leaky), "redactTagged", secrets...)
+
+	var entries []string
+	logged := js.FuncOf(func(this js.Value, args []js.Value) any {
+		entries = append(entries, js.Global().Get("JSON").Call("stringify", args[0]).String())
+		return nil
+	})
+	defer logged.Release()
+	logger = logged.Value
+	warn(leaky)
+	logEvent("info", "test", leaky, map[string]any{"detail": leaky, "count": 1})
+	logger = js.Undefined()
+	if len(entries) != 2 {
+		t.Fatalf("%d log entries, expected 2", len(entries))
+	}
+	for _, entry := range entries {
+		checkRedacted(t, entry, "log entry", secrets...)
+	}
+
+	// Without a logger, warnings go to the console.
+	console := js.Global().Get("console")
+	consoleWarn := console.Get("warn")
+	console.Set("warn", logged)
+	warn(leaky)
+	console.Set("warn", consoleWarn)
+	if len(entries) != 3 {
+		t.Fatalf("the warning did not reach the console")
+	}
+	checkRedacted(t, entries[2], "console warning", secrets...)
+
+	StartRecording(js.Undefined())
+	args := []js.Value{js.ValueOf(privateKey), js.ValueOf(5), js.ValueOf(2), js.ValueOf(300), js.ValueOf(testPassphrase)}
+	recordCall("CreateClient", time.Now(), args, map[string]any{"error": leaky})
+	log, calls, _ := StopRecording()
+	if calls != 1 {
+		t.Fatalf("%d calls recorded, expected 1", calls)
+	}
+	checkRedacted(t, log, "recording", secrets...)
+
+	var lines []string
+	sink := js.FuncOf(func(this js.Value, args []js.Value) any {
+		lines = append(lines, args[0].String())
+		return nil
+	})
+	defer sink.Release()
+	StartRecording(sink.Value)
+	recordCall("CreateClient", time.Now(), args, map[string]any{"error": leaky})
+	StopRecording()
+	if len(lines) != 2 {
+		t.Fatalf("%d lines reached the recording sink, expected 2", len(lines))
+	}
+	checkRedacted(t, strings.Join(lines, "\n"), "recording sink", secrets...)
+}
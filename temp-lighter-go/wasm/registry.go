@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/signer"
+)
+
+// defaultClientIndex addresses txClient, the client created by CreateClient.
+const defaultClientIndex = 0
+
+// clients holds every client registered besides txClient, by clientIndex.
+var (
+	clients         = map[int]*client.TxClient{}
+	nextClientIndex = defaultClientIndex + 1
+)
+
+func registerClient(c *client.TxClient) int {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	return registerClientLocked(c)
+}
+
+func registerClientLocked(c *client.TxClient) int {
+	clientIndex := nextClientIndex
+	nextClientIndex++
+	clients[clientIndex] = c
+	return clientIndex
+}
+
+// unregisterClient drops the client at clientIndex and its policy.
+func unregisterClient(clientIndex int) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	unregisterClientLocked(clientIndex)
+}
+
+func unregisterClientLocked(clientIndex int) {
+	delete(clients, clientIndex)
+	delete(clientPolicies, clientIndex)
+}
+
+// setDefaultClient replaces txClient, the client at defaultClientIndex.
+func setDefaultClient(c *client.TxClient) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	txClient = c
+}
+
+// replaceClient replaces the client at clientIndex by next unless it is no longer old, i.e. another call replaced
+// or dropped it meanwhile, in which case it reports false.
+func replaceClient(clientIndex int, old, next *client.TxClient) bool {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if clientIndex == defaultClientIndex {
+		if txClient != old {
+			return false
+		}
+		txClient = next
+		return true
+	}
+	if clients[clientIndex] != old {
+		return false
+	}
+	clients[clientIndex] = next
+	return true
+}
+
+// swapKeyManagerLocked switches txClient and every registered client holding the key of km, but not locking it
+// already, to next. The caller holds stateMu.
+func swapKeyManagerLocked(km signer.KeyManager, next *signer.LockableKeyManager) {
+	holds := func(c *client.TxClient) bool {
+		_, locking := c.GetKeyManager().(*signer.LockableKeyManager)
+		return !locking && c.GetKeyManager().PubKeyBytes() == km.PubKeyBytes()
+	}
+	if txClient != nil && holds(txClient) {
+		txClient = txClient.WithKeyManager(next)
+	}
+	for clientIndex, c := range clients {
+		if holds(c) {
+			clients[clientIndex] = c.WithKeyManager(next)
+		}
+	}
+}
+
+func getClient(clientIndex int) (*client.TxClient, error) {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	if clientIndex == defaultClientIndex {
+		if txClient == nil {
+			return nil, fmt.Errorf("client not initialized")
+		}
+		return txClient, nil
+	}
+
+	c, ok := clients[clientIndex]
+	if !ok {
+		return nil, fmt.Errorf("client %d not found", clientIndex)
+	}
+	return c, nil
+}
+
+// registeredClients returns every client, txClient included once created, by ascending clientIndex.
+func registeredClients() (indices []int, all []*client.TxClient) {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	if txClient != nil {
+		indices, all = append(indices, defaultClientIndex), append(all, txClient)
+	}
+	for clientIndex := range clients {
+		indices = append(indices, clientIndex)
+	}
+	sort.Ints(indices)
+	for _, clientIndex := range indices[len(all):] {
+		all = append(all, clients[clientIndex])
+	}
+	return indices, all
+}
+
+// clientFromArgs resolves the optional clientIndex argument at position i, defaulting to txClient.
+func clientFromArgs(args []js.Value, i int) (*client.TxClient, error) {
+	if len(args) > i && args[i].Type() == js.TypeNumber {
+		return getClient(args[i].Int())
+	}
+	return getClient(defaultClientIndex)
+}
+
+// CloneClient registers a client reusing the key of the client at clientIndex, targeting accountIndex instead.
+// Session clients are not cloned, as a clone would keep signing with the session key once RevokeSessionKey
+// dropped the session.
+func CloneClient(clientIndex int, accountIndex int64) (int, error) {
+	c, err := getClient(clientIndex)
+	if err != nil {
+		return 0, err
+	}
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if sessionClients[clientIndex] {
+		return 0, fmt.Errorf("client %d is a session key, create another session for account %d instead", clientIndex, accountIndex)
+	}
+	return registerClientLocked(c.WithAccountIndex(accountIndex)), nil
+}
+
+// jsCloneClient expects (clientIndex, accountIndex).
+func jsCloneClient(this js.Value, args []js.Value) any {
+	if len(args) < 2 {
+		return js.ValueOf(map[string]any{"error": "CloneClient expects 2 args: clientIndex, accountIndex"})
+	}
+
+	clientIndex, err := CloneClient(args[0].Int(), int64(args[1].Int()))
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"clientIndex": clientIndex, "error": ""})
+}
+
+// jsGetAccountIndex expects (clientIndex?).
+func jsGetAccountIndex(this js.Value, args []js.Value) any {
+	c, err := clientFromArgs(args, 0)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"accountIndex": c.GetAccountIndex(), "error": ""})
+}
+
+// jsGetApiKeyIndex expects (clientIndex?).
+func jsGetApiKeyIndex(this js.Value, args []js.Value) any {
+	c, err := clientFromArgs(args, 0)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"apiKeyIndex": int(c.GetApiKeyIndex()), "error": ""})
+}
+
+// jsGetChainId expects (clientIndex?).
+func jsGetChainId(this js.Value, args []js.Value) any {
+	c, err := clientFromArgs(args, 0)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"chainId": c.GetChainId(), "error": ""})
+}
@@ -0,0 +1,554 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+const (
+	// replayFormat heads every recorded log, so that a player refuses logs it cannot read.
+	replayFormat = "lighter-signer-replay/1"
+	// maxRecordedCalls bounds the calls a recording without sink keeps in memory.
+	maxRecordedCalls = 10_000
+	// replayCallTimeout bounds the wait for a replayed call returning a Promise.
+	replayCallTimeout = time.Minute
+)
+
+// recordedCall is one line of a recorded log: the args of a call to an export and its result, as JSON with the
+// known secrets replaced by their secretTag. Now is the exchange time of the call, in milliseconds, which the
+// player freezes the clock at. Async is set when the export returned a Promise, whose settled value is Result.
+type recordedCall struct {
+	Seq    int64           `json:"seq"`
+	Export string          `json:"export"`
+	Now    int64           `json:"now"`
+	Args   json.RawMessage `json:"args"`
+	Result json.RawMessage `json:"result"`
+	Async  bool            `json:"async,omitempty"`
+}
+
+type replayHeader struct {
+	Format    string `json:"format"`
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	StartedAt int64  `json:"startedAt"`
+}
+
+// recorder is the recording started by StartRecording. It is not guarded by stateMu, as it is written by the
+// export wrapper of every call.
+var recorder struct {
+	mu      sync.Mutex
+	on      bool
+	header  replayHeader
+	seq     int64
+	calls   []recordedCall
+	dropped int
+	// sink, when set, receives every line as it is recorded instead of calls.
+	sink js.Value
+}
+
+// unrecordedExports are the exports of the recorder and the player themselves.
+var unrecordedExports = map[string]bool{"StartRecording": true, "StopRecording": true, "ReplaySession": true}
+
+// networkExports are the exports reaching the exchange, which a replay never calls: replaying a production log
+// must not fetch nonces, submit txs, cancel orders or flatten positions again.
+var networkExports = map[string]bool{
+	"SubmitTx":             true,
+	"SubmitBatch":          true,
+	"FlushTxQueue":         true,
+	"KillSwitch":           true,
+	"FetchNextNonce":       true,
+	"CheckNonceGap":        true,
+	"RepairNonceGap":       true,
+	"SyncClock":            true,
+	"CheckExchangeStatus":  true,
+	"CheckEndpoints":       true,
+	"FetchMarketRules":     true,
+	"FetchOpenOrders":      true,
+	"FetchPositions":       true,
+	"FetchReferencePrices": true,
+	"Warmup":               true,
+}
+
+// replayOffline is set while ReplaySession runs. fetchTransport then refuses every request, whichever client
+// makes it, and the clients created by replayed calls get no HTTP client.
+var replayOffline atomic.Bool
+
+var errReplayOffline = fmt.Errorf("the network is disabled while a session is replayed")
+
+func recording() bool {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	return recorder.on
+}
+
+// StartRecording starts recording every call to an export, replacing a previous recording. Lines go to sink
+// when it is a function, the header first, and are kept until StopRecording otherwise.
+func StartRecording(sink js.Value) {
+	info := GetVersion()
+	recorder.mu.Lock()
+	recorder.on, recorder.seq, recorder.calls, recorder.dropped = true, 0, nil, 0
+	recorder.header = replayHeader{Format: replayFormat, Version: info.Version, Commit: info.Commit, StartedAt: time.Now().UnixMilli()}
+	recorder.sink = js.Undefined()
+	if sink.Type() == js.TypeFunction {
+		recorder.sink = sink
+	}
+	header := recorder.header
+	recorder.mu.Unlock()
+
+	if sink.Type() == js.TypeFunction {
+		writeRecordedLine(sink, header)
+	}
+}
+
+// StopRecording ends the recording and returns the log of the calls kept, one JSON value per line, and the
+// number of calls dropped once maxRecordedCalls were kept. The log is empty when it went to a sink. The calls
+// whose Promise is still pending are lost.
+func StopRecording() (log string, calls, dropped int) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if !recorder.on {
+		return "", 0, 0
+	}
+	recorder.on = false
+	if recorder.sink.Type() == js.TypeFunction {
+		recorder.sink = js.Undefined()
+		return "", 0, recorder.dropped
+	}
+	sort.Slice(recorder.calls, func(i, j int) bool { return recorder.calls[i].Seq < recorder.calls[j].Seq })
+
+	var b strings.Builder
+	lines := []any{recorder.header}
+	for _, c := range recorder.calls {
+		lines = append(lines, c)
+	}
+	for _, line := range lines {
+		data, _ := json.Marshal(line)
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	calls, dropped = len(recorder.calls), recorder.dropped
+	recorder.calls, recorder.sink = nil, js.Undefined()
+	return b.String(), calls, dropped
+}
+
+func writeRecordedLine(sink js.Value, line any) {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			warn(fmt.Sprintf("recording sink failed: %v", r))
+		}
+	}()
+	sink.Invoke(string(data))
+}
+
+// recordingJSON returns v as JSON with the known secrets tagged. Functions become null, as JSON.stringify
+// drops them; values it refuses, e.g. BigInts, are recorded as null.
+func recordingJSON(v js.Value) (raw json.RawMessage) {
+	defer func() {
+		if r := recover(); r != nil {
+			raw = json.RawMessage("null")
+		}
+	}()
+	s := js.Global().Get("JSON").Call("stringify", v)
+	if s.Type() != js.TypeString {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(redactTagged(s.String()))
+}
+
+func isPromise(v js.Value) bool {
+	return v.Type() == js.TypeObject && v.Get("then").Type() == js.TypeFunction
+}
+
+// recordCall records a call to the export name, once it returned res, while a recording is on. The args are
+// only serialized afterwards, so that the secrets the call registered, e.g. the key of CreateClient, are
+// tagged.
+func recordCall(name string, now time.Time, args []js.Value, res any) {
+	recorder.mu.Lock()
+	if !recorder.on || unrecordedExports[name] {
+		recorder.mu.Unlock()
+		return
+	}
+	recorder.seq++
+	call := recordedCall{Seq: recorder.seq, Export: name, Now: now.UnixMilli()}
+	recorder.mu.Unlock()
+
+	list := make([]any, len(args))
+	for i, a := range args {
+		list[i] = a
+	}
+	call.Args = recordingJSON(js.ValueOf(list))
+
+	v := js.ValueOf(res)
+	if !isPromise(v) {
+		call.Result = recordingJSON(v)
+		storeRecordedCall(call)
+		return
+	}
+	call.Async = true
+	var onFulfilled, onRejected js.Func
+	settle := func(result js.Value) {
+		call.Result = recordingJSON(result)
+		storeRecordedCall(call)
+		onFulfilled.Release()
+		onRejected.Release()
+	}
+	onFulfilled = js.FuncOf(func(this js.Value, args []js.Value) any {
+		settle(args[0])
+		return nil
+	})
+	onRejected = js.FuncOf(func(this js.Value, args []js.Value) any {
+		settle(js.ValueOf(map[string]any{"rejected": args[0].Call("toString").String()}))
+		return nil
+	})
+	v.Call("then", onFulfilled, onRejected)
+}
+
+func storeRecordedCall(call recordedCall) {
+	recorder.mu.Lock()
+	if !recorder.on {
+		recorder.mu.Unlock()
+		return
+	}
+	sink := recorder.sink
+	if sink.Type() != js.TypeFunction {
+		if len(recorder.calls) < maxRecordedCalls {
+			recorder.calls = append(recorder.calls, call)
+		} else {
+			recorder.dropped++
+		}
+	}
+	recorder.mu.Unlock()
+
+	if sink.Type() == js.TypeFunction {
+		writeRecordedLine(sink, call)
+	}
+}
+
+// replayMismatch is a replayed call whose result differs from the recorded one, or that could not be replayed.
+type replayMismatch struct {
+	Seq      int64
+	Export   string
+	Expected json.RawMessage
+	Actual   json.RawMessage
+	Err      string
+}
+
+var secretTagPattern = regexp.MustCompile(`\[REDACTED:[0-9a-f]{12}\]`)
+
+// parseReplayLog reads a log written by a recording.
+func parseReplayLog(log string) (replayHeader, []recordedCall, error) {
+	var header replayHeader
+	var calls []recordedCall
+	for i, line := range strings.Split(log, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if header.Format == "" {
+			if err := json.Unmarshal([]byte(line), &header); err != nil || header.Format != replayFormat {
+				return header, nil, fmt.Errorf("line %d: not a %s header", i+1, replayFormat)
+			}
+			continue
+		}
+		var c recordedCall
+		if err := json.Unmarshal([]byte(line), &c); err != nil {
+			return header, nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		calls = append(calls, c)
+	}
+	if header.Format == "" {
+		return header, nil, fmt.Errorf("empty log")
+	}
+	sort.SliceStable(calls, func(i, j int) bool { return calls[i].Seq < calls[j].Seq })
+	return header, calls, nil
+}
+
+// ReplaySession calls again every export recorded in log, in order, with the clock frozen at the time of the
+// original call, and returns the calls whose result differs. secrets are the keys, seeds and passphrases the
+// recording tagged; they are put back into the args. The network is disabled meanwhile: the networkExports are
+// not called, reported as mismatches, and no request leaves the instance. Calls relying on randomness, e.g.
+// GenerateAPIKey without seed, are expected to differ. As the clock is frozen for the whole instance, replay on an
+// instance doing nothing else. It must not be called from the event loop goroutine.
+func ReplaySession(log string, secrets []string) (header replayHeader, replayed int, mismatches []replayMismatch, err error) {
+	header, calls, err := parseReplayLog(log)
+	if err != nil {
+		return header, 0, nil, err
+	}
+	byTag := map[string]string{}
+	for _, s := range secrets {
+		registerSecret(s)
+		n, _ := hexSecret(s)
+		byTag[secretTag(sha256.Sum256([]byte(n)))] = n
+	}
+	defer client.FreezeClock(time.Time{})
+	replayOffline.Store(true)
+	defer replayOffline.Store(false)
+
+	for _, c := range calls {
+		replayed++
+		actual, err := replayCall(c, byTag)
+		if err != nil {
+			mismatches = append(mismatches, replayMismatch{Seq: c.Seq, Export: c.Export, Expected: c.Result, Err: err.Error()})
+			continue
+		}
+		same, recordedInvalid, replayedInvalid := compareReplayed(c.Result, actual, replayKeys())
+		switch {
+		case replayedInvalid > 0:
+			mismatches = append(mismatches, replayMismatch{Seq: c.Seq, Export: c.Export, Expected: c.Result, Actual: actual,
+				Err: fmt.Sprintf("%d replayed signatures do not verify against the recomputed tx hash", replayedInvalid)})
+		case recordedInvalid > 0:
+			mismatches = append(mismatches, replayMismatch{Seq: c.Seq, Export: c.Export, Expected: c.Result, Actual: actual,
+				Err: fmt.Sprintf("%d recorded signatures do not verify against the recomputed tx hash", recordedInvalid)})
+		case !same:
+			mismatches = append(mismatches, replayMismatch{Seq: c.Seq, Export: c.Export, Expected: c.Result, Actual: actual})
+		}
+	}
+	return header, replayed, mismatches, nil
+}
+
+// replayCall calls the export of c with its args, its secrets restored from byTag, and returns the result as
+// recordCall would have recorded it.
+func replayCall(c recordedCall, byTag map[string]string) (json.RawMessage, error) {
+	if networkExports[c.Export] {
+		return nil, fmt.Errorf("%s was not replayed, it reaches the exchange", c.Export)
+	}
+	fn := exported(c.Export)
+	if fn.Type() != js.TypeFunction {
+		return nil, fmt.Errorf("%s is not exported by this build", c.Export)
+	}
+	var missing string
+	args := secretTagPattern.ReplaceAllStringFunc(string(c.Args), func(tag string) string {
+		if s, ok := byTag[tag]; ok {
+			return s
+		}
+		missing = tag
+		return tag
+	})
+	if missing != "" {
+		return nil, fmt.Errorf("the secret tagged %s was not supplied", missing)
+	}
+	list := js.Global().Get("JSON").Call("parse", args)
+	if list.Type() != js.TypeObject || !js.Global().Get("Array").Call("isArray", list).Bool() {
+		return nil, fmt.Errorf("args are not an array")
+	}
+	argv := make([]any, list.Length())
+	for i := range argv {
+		argv[i] = list.Index(i)
+	}
+
+	client.FreezeClock(time.UnixMilli(c.Now))
+	res := fn.Invoke(argv...)
+	if isPromise(res) {
+		ctx, cancel := context.WithTimeout(context.Background(), replayCallTimeout)
+		defer cancel()
+		v, rejected, err := awaitPromise(ctx, res)
+		if err != nil {
+			return nil, err
+		}
+		if res = v; rejected {
+			res = js.ValueOf(map[string]any{"rejected": v.Call("toString").String()})
+		}
+	}
+	return recordingJSON(res), nil
+}
+
+// replayKey is a key the signatures of a replay may be made with.
+type replayKey struct {
+	chainId uint32
+	pubKey  [40]byte
+}
+
+// replayKeys returns the keys of the registered clients, which sign the replayed calls.
+func replayKeys() []replayKey {
+	_, all := registeredClients()
+	keys := make([]replayKey, 0, len(all))
+	for _, c := range all {
+		keys = append(keys, replayKey{chainId: c.GetChainId(), pubKey: c.GetKeyManager().PubKeyBytes()})
+	}
+	return keys
+}
+
+// signedTxTypes are the tx types a signed txInfo, which does not tell its type, is decoded as.
+var signedTxTypes = []uint8{
+	txtypes.TxTypeL2ChangePubKey, txtypes.TxTypeL2CreateSubAccount, txtypes.TxTypeL2CreatePublicPool,
+	txtypes.TxTypeL2UpdatePublicPool, txtypes.TxTypeL2Transfer, txtypes.TxTypeL2Withdraw, txtypes.TxTypeL2CreateOrder,
+	txtypes.TxTypeL2CreateGroupedOrders, txtypes.TxTypeL2CancelOrder, txtypes.TxTypeL2ModifyOrder,
+	txtypes.TxTypeL2CancelAllOrders, txtypes.TxTypeL2MintShares, txtypes.TxTypeL2BurnShares,
+	txtypes.TxTypeL2UpdateLeverage, txtypes.TxTypeL2UpdateMargin,
+}
+
+// verifyTxInfo reports whether raw, a signed txInfo, carries a signature made with one of keys over the hash
+// recomputed from its fields. A ChangePubKey may also be signed with the key it registers. ok is false when raw
+// is not a signed txInfo.
+func verifyTxInfo(raw []byte, keys []replayKey) (valid, ok bool) {
+	for _, txType := range signedTxTypes {
+		tx, err := types.DecodeSignedTx(txType, string(raw))
+		if err != nil {
+			continue
+		}
+		ok = true
+		candidates := keys
+		if cpk, isCPK := tx.(*txtypes.L2ChangePubKeyTxInfo); isCPK && len(cpk.PubKey) == 40 {
+			var pubKey [40]byte
+			copy(pubKey[:], cpk.PubKey)
+			for _, k := range keys {
+				candidates = append(candidates[:len(candidates):len(candidates)], replayKey{chainId: k.chainId, pubKey: pubKey})
+			}
+		}
+		for _, k := range candidates {
+			if types.VerifyTxSignature(tx, k.chainId, k.pubKey) == nil {
+				return true, true
+			}
+		}
+	}
+	return false, ok
+}
+
+func decodeJSONNumbers(data []byte) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	var v any
+	err := dec.Decode(&v)
+	return v, err
+}
+
+// compareReplayed compares the recorded and replayed results of a call regardless of the order of object keys,
+// which JSON.stringify keeps from the maps results are built from. Schnorr signatures are randomized, so that
+// signatures, also within the txInfo strings, are compared by validity instead: each one of a signed tx is
+// verified with keys against the hash recomputed from the tx fields, which have to be identical. The invalid
+// counts are the signatures of each result failing verification.
+func compareReplayed(recorded, replayed json.RawMessage, keys []replayKey) (same bool, recordedInvalid, replayedInvalid int) {
+	x, errX := decodeJSONNumbers(recorded)
+	y, errY := decodeJSONNumbers(replayed)
+	if errX != nil || errY != nil {
+		return string(recorded) == string(replayed), 0, 0
+	}
+	x = verifySignatures(x, keys, &recordedInvalid)
+	y = verifySignatures(y, keys, &replayedInvalid)
+	return reflect.DeepEqual(x, y), recordedInvalid, replayedInvalid
+}
+
+func isSignatureKey(k string) bool {
+	return strings.EqualFold(k, "sig") || strings.EqualFold(k, "signature")
+}
+
+// verifySignatures replaces the signatures within v, a decoded JSON value, by their verdict: "<valid signature>"
+// or "<invalid signature>" for signed txs, see verifyTxInfo, counting the invalid ones, and "<signature>" for the
+// others, e.g. of auth tokens, which cannot be recomputed. Strings holding JSON objects such as txInfo are decoded.
+func verifySignatures(v any, keys []replayKey, invalid *int) any {
+	switch v := v.(type) {
+	case map[string]any:
+		verdict := "<signature>"
+		for k, field := range v {
+			if _, isString := field.(string); isString && isSignatureKey(k) {
+				raw, _ := json.Marshal(v)
+				if valid, ok := verifyTxInfo(raw, keys); ok && valid {
+					verdict = "<valid signature>"
+				} else if ok {
+					verdict = "<invalid signature>"
+					*invalid++
+				}
+				break
+			}
+		}
+		for k, field := range v {
+			if _, isString := field.(string); isString && isSignatureKey(k) {
+				v[k] = verdict
+			} else {
+				v[k] = verifySignatures(field, keys, invalid)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = verifySignatures(item, keys, invalid)
+		}
+	case string:
+		if strings.HasPrefix(v, "{") {
+			if obj, err := decodeJSONNumbers([]byte(v)); err == nil {
+				if _, isObject := obj.(map[string]any); isObject {
+					return verifySignatures(obj, keys, invalid)
+				}
+			}
+		}
+	}
+	return v
+}
+
+// jsStartRecording expects (sink?) and returns {format}. Every later call to an export is recorded with its
+// args and result, the known secrets replaced by tags. sink, a function, receives each line of the log as a
+// string to append to a file; without it, StopRecording returns the log.
+func jsStartRecording(this js.Value, args []js.Value) any {
+	sink := js.Undefined()
+	if len(args) > 0 && !args[0].IsUndefined() && !args[0].IsNull() {
+		if args[0].Type() != js.TypeFunction {
+			return js.ValueOf(map[string]any{"error": "sink should be a function"})
+		}
+		sink = args[0]
+	}
+	StartRecording(sink)
+	return js.ValueOf(map[string]any{"format": replayFormat, "error": ""})
+}
+
+// jsStopRecording expects () and returns {log, calls, dropped}. log is empty when the recording went to a sink.
+func jsStopRecording(this js.Value, args []js.Value) any {
+	log, calls, dropped := StopRecording()
+	return js.ValueOf(map[string]any{"log": log, "calls": calls, "dropped": dropped, "error": ""})
+}
+
+// jsReplaySession expects (log, secrets?) and returns a Promise resolving to {recordedVersion, replayed,
+// mismatches}. secrets lists the keys, seeds and passphrases used during the recording. mismatches holds
+// {seq, export, expected, actual, error} for every call whose result differs from the recorded one, or that could
+// not be replayed.
+func jsReplaySession(this js.Value, args []js.Value) any {
+	if len(args) < 1 || args[0].Type() != js.TypeString {
+		return js.ValueOf(map[string]any{"error": "ReplaySession expects at least 1 arg: log"})
+	}
+	var secrets []string
+	if len(args) > 1 && !args[1].IsUndefined() && !args[1].IsNull() {
+		if !js.Global().Get("Array").Call("isArray", args[1]).Bool() {
+			return js.ValueOf(map[string]any{"error": "secrets should be an array of strings"})
+		}
+		for i := 0; i < args[1].Length(); i++ {
+			if args[1].Index(i).Type() != js.TypeString {
+				return js.ValueOf(map[string]any{"error": fmt.Sprintf("secrets[%d] should be a string", i)})
+			}
+			secrets = append(secrets, args[1].Index(i).String())
+		}
+	}
+	log := args[0].String()
+
+	return newPromise(func(callClock) map[string]any {
+		header, replayed, mismatches, err := ReplaySession(log, secrets)
+		if err != nil {
+			return map[string]any{"error": wrapErr(err)}
+		}
+		list := make([]any, len(mismatches))
+		for i, m := range mismatches {
+			list[i] = map[string]any{
+				"seq":      m.Seq,
+				"export":   m.Export,
+				"expected": string(m.Expected),
+				"actual":   string(m.Actual),
+				"error":    redact(m.Err),
+			}
+		}
+		return map[string]any{"recordedVersion": header.Version, "replayed": replayed, "mismatches": list, "error": ""}
+	})
+}
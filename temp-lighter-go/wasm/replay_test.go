@@ -0,0 +1,66 @@
+package main
+
+import (
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// TestRecordedCallClock records a call and checks that, once it returned, a tx signed on its clock, as its Promise
+// would, expires relative to the recorded instant while the clock moved on.
+func TestRecordedCallClock(t *testing.T) {
+	privateKey, _, errStr := GenerateAPIKey("")
+	if errStr != "" {
+		t.Fatal(errStr)
+	}
+	res := js.ValueOf(jsCreateClient(js.Undefined(), []js.Value{js.ValueOf(privateKey), js.ValueOf(5), js.ValueOf(2), js.ValueOf(300)}))
+	if errStr := res.Get("error").String(); errStr != "" {
+		t.Fatal(errStr)
+	}
+	defer setDefaultClient(nil)
+
+	StartRecording(js.Undefined())
+	var clock callClock
+	callExport("SignMarketableOrder", func(this js.Value, args []js.Value) any {
+		clock = clockOfCall()
+		return map[string]any{"error": ""}
+	}, js.Undefined(), nil)
+	log, calls, _ := StopRecording()
+	_, recorded, err := parseReplayLog(log)
+	if err != nil || calls != 1 {
+		t.Fatalf("%d calls recorded: %v", calls, err)
+	}
+	if !time.Time(clockOfCall()).IsZero() {
+		t.Error("the clock of the call outlived it")
+	}
+
+	skew := client.GetClockSkew()
+	client.SetClockSkew(client.ClockSkew{Offset: skew.Offset + time.Hour})
+	defer client.SetClockSkew(skew)
+	order := &marketableOrder{MarketIndex: 1, BaseAmount: 1000, MaxSlippageBps: 50, Nonce: 7, BestBid: 100000, BestAsk: 100100}
+	txInfo, _, _, err := SignMarketableOrder(order, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := types.DecodeSignedTx(txtypes.TxTypeL2CreateOrder, txInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.UnixMilli(recorded[0].Now).Add(client.DefaultExpireTime()).UnixMilli()
+	if expiredAt, _ := types.ExpiredAtOf(tx); expiredAt != want {
+		t.Errorf("the tx expires at %d, expected %d from the recorded instant", expiredAt, want)
+	}
+
+	txInfo, _, _, err = SignMarketableOrder(order, callClock{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, _ = types.DecodeSignedTx(txtypes.TxTypeL2CreateOrder, txInfo)
+	if expiredAt, _ := types.ExpiredAtOf(tx); expiredAt < want+time.Hour.Milliseconds() {
+		t.Errorf("the tx of an unrecorded call expires at %d, before the skewed clock", expiredAt)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// roundingModes picks how ToOrderUnits rounds decimal prices and sizes, by field and side. The defaults never
+// make an order worse than typed: buys round their price down, sells up, and sizes round down.
+var roundingModes = map[string]types.RoundingMode{
+	"priceBuy":  types.RoundDown,
+	"priceSell": types.RoundUp,
+	"sizeBuy":   types.RoundDown,
+	"sizeSell":  types.RoundDown,
+}
+
+var roundingModesSchema = objectSchema{
+	"priceBuy":  {Type: "string", MaxLength: 16},
+	"priceSell": {Type: "string", MaxLength: 16},
+	"sizeBuy":   {Type: "string", MaxLength: 16},
+	"sizeSell":  {Type: "string", MaxLength: 16},
+}
+
+// readRoundingModes returns modes updated with the ones set in v, a roundingModesSchema object.
+func readRoundingModes(name string, v js.Value, modes map[string]types.RoundingMode) (map[string]types.RoundingMode, error) {
+	var set map[string]string
+	if err := decodeStrict(name, v, roundingModesSchema, &set); err != nil {
+		return nil, err
+	}
+	res := make(map[string]types.RoundingMode, len(modes))
+	for k, m := range modes {
+		res[k] = m
+	}
+	for k, s := range set {
+		m, err := types.ParseRoundingMode(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", name, k, err)
+		}
+		res[k] = m
+	}
+	return res, nil
+}
+
+func roundingModesResult(modes map[string]types.RoundingMode) map[string]any {
+	return map[string]any{
+		"priceBuy":  modes["priceBuy"].String(),
+		"priceSell": modes["priceSell"].String(),
+		"sizeBuy":   modes["sizeBuy"].String(),
+		"sizeSell":  modes["sizeSell"].String(),
+		"error":     "",
+	}
+}
+
+// ToOrderUnits converts a decimal price and size of an order on a market with known decimals into price ticks
+// and base amount, rounded to the market's steps following modes. An empty price or size is left at 0.
+func ToOrderUnits(rules *types.MarketRules, isAsk uint8, price, size string, modes map[string]types.RoundingMode) (uint32, int64, error) {
+	side := "Buy"
+	if isAsk == 1 {
+		side = "Sell"
+	}
+
+	var ticks uint32
+	if price != "" {
+		p, err := types.RoundDecimalUnits(price, rules.PriceDecimals, int64(rules.PriceStep), int64(txtypes.MaxOrderPrice), modes["price"+side])
+		if err != nil {
+			return 0, 0, fmt.Errorf("price: %w", err)
+		}
+		if p < int64(txtypes.MinOrderPrice) {
+			return 0, 0, fmt.Errorf("price %q rounds to %d ticks", price, p)
+		}
+		ticks = uint32(p)
+	}
+
+	var baseAmount int64
+	if size != "" {
+		b, err := types.RoundDecimalUnits(size, rules.SizeDecimals, rules.BaseStep, txtypes.MaxOrderBaseAmount, modes["size"+side])
+		if err != nil {
+			return 0, 0, fmt.Errorf("size: %w", err)
+		}
+		if b < 0 {
+			return 0, 0, fmt.Errorf("size %q is negative", size)
+		}
+		baseAmount = b
+	}
+	return ticks, baseAmount, nil
+}
+
+// decimalArg reads a decimal passed from JS as a string or an integer number; null and undefined give "".
+// Fractional numbers are refused, as their float value may already differ from the decimal the caller typed.
+func decimalArg(name string, v js.Value) (string, error) {
+	switch v.Type() {
+	case js.TypeString:
+		return v.String(), nil
+	case js.TypeNumber:
+		if !js.Global().Get("Number").Call("isSafeInteger", v).Bool() {
+			return "", fmt.Errorf("%s %s should be passed as a decimal string", name, js.Global().Get("String").Invoke(v).String())
+		}
+		return strconv.Itoa(v.Int()), nil
+	case js.TypeNull, js.TypeUndefined:
+		return "", nil
+	}
+	return "", fmt.Errorf("%s should be a decimal string or an integer, got %s", name, v.Type())
+}
+
+// jsSetRoundingModes expects (modes). modes is {priceBuy?, priceSell?, sizeBuy?, sizeSell?}, each one of
+// "exact", "down", "up" or "nearest"; the fields left out keep their mode.
+func jsSetRoundingModes(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "SetRoundingModes expects 1 arg: modes"})
+	}
+
+	modes, err := readRoundingModes("modes", args[0], roundingModes)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	roundingModes = modes
+	return js.ValueOf(roundingModesResult(modes))
+}
+
+// jsToOrderUnits expects (marketIndex, isAsk, price, size, modes?). price and size are decimal strings, or null
+// to skip them; modes overrides the rounding set through SetRoundingModes for this call. The market's decimals
+// and steps come from SetMarketRules or FetchMarketRules.
+func jsToOrderUnits(this js.Value, args []js.Value) any {
+	if len(args) < 4 {
+		return js.ValueOf(map[string]any{"error": "ToOrderUnits expects at least 4 args: marketIndex, isAsk, price, size"})
+	}
+	if args[0].Type() != js.TypeNumber || args[0].Int() < 0 || args[0].Int() > int(txtypes.MaxMarketIndex) {
+		return js.ValueOf(map[string]any{"error": fmt.Sprintf("marketIndex should be an integer between 0 and %d", txtypes.MaxMarketIndex)})
+	}
+	if args[1].Type() != js.TypeNumber || (args[1].Int() != 0 && args[1].Int() != 1) {
+		return js.ValueOf(map[string]any{"error": "isAsk should be 0 or 1"})
+	}
+	marketIndex, isAsk := uint8(args[0].Int()), uint8(args[1].Int())
+
+	stateMu.RLock()
+	rules, ok := marketRules[marketIndex]
+	stateMu.RUnlock()
+	if !ok || !rules.HasDecimals {
+		return js.ValueOf(map[string]any{"error": fmt.Sprintf("decimals of market %d are unknown, call FetchMarketRules or SetMarketRules first", marketIndex)})
+	}
+	price, err := decimalArg("price", args[2])
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	size, err := decimalArg("size", args[3])
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	modes := roundingModes
+	if len(args) > 4 && args[4].Type() == js.TypeObject {
+		if modes, err = readRoundingModes("modes", args[4], roundingModes); err != nil {
+			return js.ValueOf(errorResult(err))
+		}
+	}
+
+	ticks, baseAmount, err := ToOrderUnits(rules, isAsk, price, size, modes)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"price": ticks, "baseAmount": baseAmount, "error": ""})
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"strconv"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+const (
+	roundTripIterations = 200
+
+	// maxJSInt is the largest integer a JS number holds exactly, the generated values stay below it.
+	maxJSInt = 1<<53 - 1
+)
+
+// roundTripCase is one generated request: the args of a signing export, the same request sent through the string
+// export of the same name, and the fields, named as in the PascalCase txInfo, the signed tx must hold.
+type roundTripCase struct {
+	export       string
+	sign         func(this js.Value, args []js.Value) any
+	txType       uint8
+	args         []any
+	stringExport func() (txInfo, err string)
+	fields       map[string]any
+}
+
+func itoa(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
+
+func randRange(r *rand.Rand, min, max int64) int64 {
+	return min + r.Int63n(max-min+1)
+}
+
+func randMemo(r *rand.Rand) (string, [32]byte) {
+	var memo [32]byte
+	b := make([]byte, r.Intn(len(memo)+1))
+	for i := range b {
+		b[i] = byte(randRange(r, ' ', '~'))
+	}
+	copy(memo[:], b)
+	return string(b), memo
+}
+
+// roundTripGenerators build random valid requests for the signing exports. Every arg that ends up in the tx is
+// listed in fields, so a dropped or altered arg is reported.
+var roundTripGenerators = []func(r *rand.Rand, clientIndex int, accountIndex int64) roundTripCase{
+	func(r *rand.Rand, clientIndex int, accountIndex int64) roundTripCase {
+		market := randRange(r, 0, int64(txtypes.MaxMarketIndex))
+		clientOrderIndex := randRange(r, txtypes.MinClientOrderIndex, txtypes.MaxClientOrderIndex)
+		baseAmount := randRange(r, txtypes.MinOrderBaseAmount, txtypes.MaxOrderBaseAmount)
+		price := randRange(r, int64(txtypes.MinOrderPrice), int64(txtypes.MaxOrderPrice))
+		isAsk, reduceOnly := r.Int63n(2), r.Int63n(2)
+		timeInForce := []int64{txtypes.ImmediateOrCancel, txtypes.GoodTillTime, txtypes.PostOnly}[r.Intn(3)]
+		orderExpiry := txtypes.NilOrderExpiry
+		if timeInForce != txtypes.ImmediateOrCancel {
+			orderExpiry = time.Now().UnixMilli() + randRange(r, txtypes.MinOrderExpiryPeriod, txtypes.MaxOrderExpiryPeriod)
+		}
+		nonce := randRange(r, txtypes.MinNonce, maxJSInt)
+		return roundTripCase{
+			export: "SignCreateOrder",
+			sign:   jsSignCreateOrder,
+			txType: txtypes.TxTypeL2CreateOrder,
+			args:   []any{market, clientOrderIndex, baseAmount, price, isAsk, txtypes.LimitOrder, timeInForce, reduceOnly, 0, orderExpiry, nonce, clientIndex},
+			stringExport: func() (string, string) {
+				return SignCreateOrder(itoa(int64(clientIndex)), "", itoa(market), itoa(clientOrderIndex), itoa(baseAmount), itoa(price),
+					itoa(isAsk), itoa(txtypes.LimitOrder), itoa(timeInForce), itoa(reduceOnly), "0", itoa(orderExpiry), itoa(nonce))
+			},
+			fields: map[string]any{
+				"AccountIndex":     accountIndex,
+				"MarketIndex":      market,
+				"ClientOrderIndex": clientOrderIndex,
+				"BaseAmount":       baseAmount,
+				"Price":            price,
+				"IsAsk":            isAsk,
+				"Type":             txtypes.LimitOrder,
+				"TimeInForce":      timeInForce,
+				"ReduceOnly":       reduceOnly,
+				"TriggerPrice":     txtypes.NilOrderTriggerPrice,
+				"OrderExpiry":      orderExpiry,
+				"Nonce":            nonce,
+			},
+		}
+	},
+	func(r *rand.Rand, clientIndex int, accountIndex int64) roundTripCase {
+		market := randRange(r, 0, int64(txtypes.MaxMarketIndex))
+		orderIndex := randRange(r, 0, maxJSInt)
+		nonce := randRange(r, txtypes.MinNonce, maxJSInt)
+		return roundTripCase{
+			export: "SignCancelOrder",
+			sign:   jsSignCancelOrder,
+			txType: txtypes.TxTypeL2CancelOrder,
+			args:   []any{market, orderIndex, nonce, clientIndex},
+			stringExport: func() (string, string) {
+				return SignCancelOrder(itoa(int64(clientIndex)), "", itoa(market), itoa(orderIndex), itoa(nonce))
+			},
+			fields: map[string]any{
+				"AccountIndex": accountIndex,
+				"MarketIndex":  market,
+				"Index":        orderIndex,
+				"Nonce":        nonce,
+			},
+		}
+	},
+	func(r *rand.Rand, clientIndex int, accountIndex int64) roundTripCase {
+		timeInForce := []int64{txtypes.ImmediateCancelAll, txtypes.ScheduledCancelAll, txtypes.AbortScheduledCancelAll}[r.Intn(3)]
+		cancelAt := int64(0)
+		if timeInForce == txtypes.ScheduledCancelAll {
+			cancelAt = time.Now().UnixMilli() + randRange(r, txtypes.MinOrderCancelAllPeriod, txtypes.MaxOrderCancelAllPeriod)
+		}
+		nonce := randRange(r, txtypes.MinNonce, maxJSInt)
+		return roundTripCase{
+			export: "SignCancelAllOrders",
+			sign:   jsSignCancelAllOrders,
+			txType: txtypes.TxTypeL2CancelAllOrders,
+			args:   []any{timeInForce, cancelAt, nonce, clientIndex},
+			stringExport: func() (string, string) {
+				return SignCancelAllOrders(itoa(int64(clientIndex)), "", itoa(timeInForce), itoa(cancelAt), itoa(nonce))
+			},
+			fields: map[string]any{
+				"AccountIndex": accountIndex,
+				"TimeInForce":  timeInForce,
+				"Time":         cancelAt,
+				"Nonce":        nonce,
+			},
+		}
+	},
+	func(r *rand.Rand, clientIndex int, accountIndex int64) roundTripCase {
+		toAccount := randRange(r, txtypes.MinAccountIndex+1, txtypes.MaxAccountIndex)
+		amount := randRange(r, txtypes.MinTransferAmount, maxJSInt)
+		fee := randRange(r, 0, maxJSInt)
+		memoStr, memo := randMemo(r)
+		nonce := randRange(r, txtypes.MinNonce, maxJSInt)
+		return roundTripCase{
+			export: "SignTransfer",
+			sign:   jsSignTransfer,
+			txType: txtypes.TxTypeL2Transfer,
+			args:   []any{toAccount, amount, fee, memoStr, nonce, clientIndex},
+			stringExport: func() (string, string) {
+				return SignTransfer(itoa(int64(clientIndex)), itoa(toAccount), itoa(amount), itoa(fee), memoStr, itoa(nonce))
+			},
+			fields: map[string]any{
+				"FromAccountIndex": accountIndex,
+				"ToAccountIndex":   toAccount,
+				"USDCAmount":       amount,
+				"Fee":              fee,
+				"Memo":             memo,
+				"Nonce":            nonce,
+			},
+		}
+	},
+	func(r *rand.Rand, clientIndex int, accountIndex int64) roundTripCase {
+		market := randRange(r, 0, int64(txtypes.MaxMarketIndex))
+		fraction := randRange(r, 1, txtypes.MarginFractionTick)
+		marginMode := []int64{txtypes.CrossMargin, txtypes.IsolatedMargin}[r.Intn(2)]
+		nonce := randRange(r, txtypes.MinNonce, maxJSInt)
+		return roundTripCase{
+			export: "SignUpdateLeverage",
+			sign:   jsSignUpdateLeverage,
+			txType: txtypes.TxTypeL2UpdateLeverage,
+			args:   []any{market, fraction, marginMode, nonce, clientIndex},
+			stringExport: func() (string, string) {
+				return SignUpdateLeverage(itoa(int64(clientIndex)), itoa(market), itoa(fraction), itoa(marginMode), itoa(nonce))
+			},
+			fields: map[string]any{
+				"AccountIndex":          accountIndex,
+				"MarketIndex":           market,
+				"InitialMarginFraction": fraction,
+				"MarginMode":            marginMode,
+				"Nonce":                 nonce,
+			},
+		}
+	},
+}
+
+// checkRoundTrip decodes txInfo as a signed tx of c.txType and reports the fields of c it does not hold
+// unchanged.
+func checkRoundTrip(t *testing.T, c roundTripCase, surface, txInfo string) {
+	t.Helper()
+	tx, err := types.DecodeSignedTx(c.txType, txInfo)
+	if err != nil {
+		t.Fatalf("%s (%s): the signed txInfo does not decode: %v", c.export, surface, err)
+	}
+	data, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]json.RawMessage
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	for field, want := range c.fields {
+		wantJSON, err := json.Marshal(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := decoded[field]
+		var gotJSON bytes.Buffer
+		if ok {
+			if err := json.Compact(&gotJSON, got); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if !ok || !bytes.Equal(gotJSON.Bytes(), wantJSON) {
+			t.Errorf("%s (%s): %s sent as %s, signed as %s", c.export, surface, field, wantJSON, gotJSON.String())
+		}
+	}
+}
+
+// TestSignRoundTrip signs random valid requests through every signing export, on both surfaces, and checks that
+// decoding the signed txInfo gives back every field of the request.
+func TestSignRoundTrip(t *testing.T) {
+	seed := time.Now().UnixNano()
+	t.Logf("seed %d", seed)
+	r := rand.New(rand.NewSource(seed))
+
+	km, err := generateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountIndex := randRange(r, txtypes.MinAccountIndex+1, txtypes.MaxAccountIndex)
+	clientIndex := registerClient(client.NewTxClientWithKeyManager(nil, km, accountIndex, uint8(r.Intn(int(txtypes.MaxApiKeyIndex)+1)), benchmarkChainId))
+	defer unregisterClient(clientIndex)
+
+	for i := 0; i < roundTripIterations; i++ {
+		for _, generate := range roundTripGenerators {
+			c := generate(r, clientIndex, accountIndex)
+			args := make([]js.Value, len(c.args))
+			for k, a := range c.args {
+				args[k] = js.ValueOf(a)
+			}
+			res := js.ValueOf(c.sign(js.Undefined(), args))
+			if errStr := res.Get("error").String(); errStr != "" {
+				t.Fatalf("%s rejected a valid request: %s", c.export, errStr)
+			}
+			checkRoundTrip(t, c, "js export", res.Get("txInfo").String())
+
+			txInfo, errStr := c.stringExport()
+			if errStr != "" {
+				t.Fatalf("string export %s rejected a valid request: %s", c.export, errStr)
+			}
+			checkRoundTrip(t, c, "string export", txInfo)
+			if t.Failed() {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+)
+
+// initOptionsGlobal names the global a loader sets before running the module to choose where its exports go. It
+// is read and removed as the module starts, which go.run does synchronously, so that loaders instantiating
+// several modules set it before each run.
+const initOptionsGlobal = "lighterSignerInit"
+
+// exportTarget receives every export besides the global object: the namespace of the init options, or an object
+// of its own. exportGlobals is cleared by init options {globals: false}, leaving the global object untouched.
+var (
+	exportTarget  = js.Undefined()
+	exportGlobals = true
+	onReady       = js.Undefined()
+)
+
+// readInitOptions applies the init options {namespace?, globals?, onReady?} a loader left in initOptionsGlobal.
+// Without them every export is a global, as wasm_exec.js hosts expect. Loaders of ES module runtimes, e.g. Deno
+// and Bun, pass globals false and receive the exports from onReady instead.
+func readInitOptions() error {
+	exportTarget = js.Global().Get("Object").New()
+	opts := js.Global().Get(initOptionsGlobal)
+	if opts.Type() != js.TypeObject {
+		return nil
+	}
+	js.Global().Delete(initOptionsGlobal)
+
+	if ns := opts.Get("namespace"); ns.Type() == js.TypeObject {
+		exportTarget = ns
+	} else if !ns.IsUndefined() && !ns.IsNull() {
+		return fmt.Errorf("%s.namespace should be an object", initOptionsGlobal)
+	}
+	switch g := opts.Get("globals"); g.Type() {
+	case js.TypeBoolean:
+		exportGlobals = g.Bool()
+	case js.TypeUndefined, js.TypeNull:
+	default:
+		return fmt.Errorf("%s.globals should be a boolean", initOptionsGlobal)
+	}
+	switch f := opts.Get("onReady"); f.Type() {
+	case js.TypeFunction:
+		onReady = f
+	case js.TypeUndefined, js.TypeNull:
+	default:
+		return fmt.Errorf("%s.onReady should be a function", initOptionsGlobal)
+	}
+	return nil
+}
+
+// signalReady passes the exports to the onReady callback of the init options, once all of them are registered.
+func signalReady() {
+	if onReady.Type() != js.TypeFunction {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			warn(fmt.Sprintf("onReady failed: %v", r))
+		}
+	}()
+	onReady.Invoke(exportTarget)
+	onReady = js.Undefined()
+}
+
+// exported returns the export name, wherever the exports were registered, or undefined.
+func exported(name string) js.Value {
+	if f, ok := exports[name]; ok {
+		return f.Value
+	}
+	return js.Undefined()
+}
+
+// hostRuntime names the JS environment the module runs in. Deno and Bun both expose a Node compatible process
+// global, so they are told apart first.
+func hostRuntime() string {
+	global := js.Global()
+	switch {
+	case global.Get("Deno").Truthy():
+		return "deno"
+	case global.Get("Bun").Truthy():
+		return "bun"
+	case global.Get("process").Truthy() && global.Get("process").Get("versions").Get("node").Truthy():
+		return "node"
+	case global.Get("WorkerGlobalScope").Truthy():
+		return "worker"
+	case global.Get("window").Truthy():
+		return "browser"
+	}
+	return "unknown"
+}
+
+// hostRuntimeVersion returns the version of the runtime named by hostRuntime, when it reports one.
+func hostRuntimeVersion(runtime string) string {
+	global := js.Global()
+	var v js.Value
+	switch runtime {
+	case "deno":
+		v = global.Get("Deno").Get("version").Get("deno")
+	case "bun":
+		v = global.Get("Bun").Get("version")
+	case "node":
+		v = global.Get("process").Get("versions").Get("node")
+	case "worker", "browser":
+		if nav := global.Get("navigator"); nav.Truthy() {
+			v = nav.Get("userAgent")
+		}
+	}
+	if v.Type() != js.TypeString {
+		return ""
+	}
+	return v.String()
+}
+
+// jsGetRuntime expects () and returns {runtime, version, globals}. runtime is deno, bun, node, worker, browser or
+// unknown; globals tells whether the exports were registered on the global object, see the lighterSignerInit
+// options.
+func jsGetRuntime(this js.Value, args []js.Value) any {
+	runtime := hostRuntime()
+	return js.ValueOf(map[string]any{
+		"runtime": runtime,
+		"version": hostRuntimeVersion(runtime),
+		"globals": exportGlobals,
+		"error":   "",
+	})
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+const (
+	// defaultBatchRetries is how many times a throttled chunk is sent again before SubmitBatch gives up, unless
+	// a profile sets another budget.
+	defaultBatchRetries = 3
+	// defaultBatchBackoff is the first wait after a throttled chunk when the exchange gives no Retry-After; it
+	// doubles on every retry.
+	defaultBatchBackoff = time.Second
+)
+
+var batchOptionsSchema = objectSchema{
+	"chunkSize":     intField(1, types.MaxBatchTxs),
+	"minIntervalMs": intField(0, 60_000),
+	"maxRetries":    intField(0, 10),
+	"atomic":        {Type: "boolean"},
+	"cancelsFirst":  {Type: "boolean"},
+}
+
+// batchOptions tune how SubmitBatch splits and paces a batch.
+type batchOptions struct {
+	ChunkSize     int   `json:"chunkSize"`
+	MinIntervalMs int64 `json:"minIntervalMs"`
+	MaxRetries    *int  `json:"maxRetries"`
+	// Atomic signs every request before the first chunk is sent, and sends nothing when one fails to sign.
+	Atomic bool `json:"atomic"`
+	// CancelsFirst overrides the cancelsFirst setting of the profile for the batch.
+	CancelsFirst *bool `json:"cancelsFirst"`
+	onChunk      js.Value
+}
+
+// signedChunk is a chunk of a batch, signed and ordered for submission, or the error that stopped its signing.
+type signedChunk struct {
+	index, start int
+	txs          []txtypes.TxInfo
+	order        []int
+	err          error
+}
+
+// signChunks signs the n requests of a batch chunkSize at a time, request i with sign(i), and sends every chunk
+// on out, ordered as sendTxBatch expects it. out holds one chunk, so a chunk is signed while the previous one is
+// in flight but signing never runs further ahead. Signing stops at the first error, which is sent as the last
+// chunk, or when done is closed.
+func signChunks(n, chunkSize int, sign func(i int) (txtypes.TxInfo, error), out chan<- signedChunk, done <-chan struct{}) {
+	defer close(out)
+	for start, index := 0, 0; start < n; start, index = start+chunkSize, index+1 {
+		chunk := signedChunk{index: index, start: start}
+		for i := start; i < min(start+chunkSize, n); i++ {
+			tx, err := sign(i)
+			if err != nil {
+				chunk.err = fmt.Errorf("requests[%d]: %w", i, err)
+				break
+			}
+			chunk.txs = append(chunk.txs, tx)
+		}
+		if chunk.err == nil {
+			chunk.order, chunk.err = types.OrderTxBatch(chunk.txs)
+		}
+
+		select {
+		case out <- chunk:
+		case <-done:
+			return
+		}
+		if chunk.err != nil {
+			return
+		}
+	}
+}
+
+// sendChunk submits chunk, retrying while the exchange throttles it, and returns the tx hashes in request order.
+func sendChunk(c *client.TxClient, chunk signedChunk, maxRetries int, backoff time.Duration) ([]string, error) {
+	ordered := make([]txtypes.TxInfo, len(chunk.txs))
+	for k, i := range chunk.order {
+		ordered[k] = chunk.txs[i]
+	}
+
+	for retry := 0; ; retry++ {
+		hashes, err := c.HTTP().SendTxBatch(ordered)
+		var rateErr *client.RateLimitError
+		if errors.As(err, &rateErr) && retry < maxRetries {
+			wait := rateErr.RetryAfter
+			if wait == 0 {
+				wait = backoff
+				backoff *= 2
+			}
+			logEvent("warn", "batch.throttled", fmt.Sprintf("chunk %d throttled, retrying in %s", chunk.index, wait), map[string]any{"chunk": chunk.index, "retry": retry + 1})
+			time.Sleep(wait)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(hashes) != len(ordered) {
+			return nil, fmt.Errorf("exchange returned %d tx hashes for %d txs", len(hashes), len(ordered))
+		}
+
+		res := make([]string, len(hashes))
+		for k, i := range chunk.order {
+			res[i] = hashes[k]
+		}
+		return res, nil
+	}
+}
+
+// SubmitBatch signs reqs with c and submits them in chunks of at most opts.ChunkSize txs, at most one chunk
+// every opts.MinIntervalMs. Chunk N+1 is signed while chunk N is in flight, unless opts.Atomic is set.
+// opts.onChunk, when set, receives the result of every chunk as it completes. Submission stops at the first
+// failed chunk, as the nonces of the following ones could no longer be used. items reports every request, in
+// request order. When cancels go first, see the profile, the cancel requests are moved to the first chunks and
+// the nonces of the batch are handed out again in that order.
+func SubmitBatch(c *client.TxClient, reqs []batchRequest, opts batchOptions) (chunks []any, items []batchItem, err error) {
+	cancelsFirst := currentProfile().CancelsFirst
+	if opts.CancelsFirst != nil {
+		cancelsFirst = *opts.CancelsFirst
+	}
+	if !cancelsFirst {
+		return submitBatch(c, reqs, opts)
+	}
+	reqs, order := prioritizeCancels(reqs)
+	chunks, items, err = submitBatch(c, reqs, opts)
+	return chunks, restoreRequestOrder(items, order), err
+}
+
+func submitBatch(c *client.TxClient, reqs []batchRequest, opts batchOptions) (chunks []any, items []batchItem, err error) {
+	p := currentProfile()
+	maxRetries, backoff := p.BatchRetries, time.Duration(p.BatchBackoffMs)*time.Millisecond
+	if opts.MaxRetries != nil {
+		maxRetries = *opts.MaxRetries
+	}
+	interval := time.Duration(opts.MinIntervalMs) * time.Millisecond
+
+	items = newBatchItems(reqs)
+	sign := func(i int) (txtypes.TxInfo, error) {
+		tx, err := signTxParams(c, reqs[i].txType, reqs[i].params)
+		if err == nil {
+			_, err = formatTxInfo(tx)
+		}
+		return tx, err
+	}
+	if opts.Atomic {
+		signBatchItems(c, reqs, items, true)
+		for _, item := range items {
+			if item.err != nil {
+				return nil, items, fmt.Errorf("requests[%d]: %w", item.index, item.err)
+			}
+		}
+		sign = func(i int) (txtypes.TxInfo, error) { return items[i].tx, nil }
+	}
+
+	signed := make(chan signedChunk, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go signChunks(len(reqs), opts.ChunkSize, sign, signed, done)
+
+	var lastSent time.Time
+	for chunk := range signed {
+		for i, tx := range chunk.txs {
+			items[chunk.start+i].tx, items[chunk.start+i].status = tx, batchSigned
+		}
+		var hashes []string
+		if chunk.err == nil {
+			if wait := interval - time.Since(lastSent); !lastSent.IsZero() && wait > 0 {
+				time.Sleep(wait)
+			}
+			lastSent = time.Now()
+			hashes, chunk.err = sendChunk(c, chunk, maxRetries, backoff)
+			for i, tx := range chunk.txs {
+				item := &items[chunk.start+i]
+				item.txHash = tx.GetTxHash()
+				if chunk.err == nil {
+					item.txHash, item.status = hashes[i], batchSubmitted
+				} else {
+					item.status, item.err = batchFailed, chunk.err
+				}
+				emitSubmission(tx, item.txHash, chunk.err)
+			}
+		} else if failed := chunk.start + len(chunk.txs); failed < min(chunk.start+opts.ChunkSize, len(items)) {
+			// The request at failed could not be signed
+			items[failed].status, items[failed].err = batchFailed, errors.Unwrap(chunk.err)
+		} else {
+			for i := range chunk.txs {
+				items[chunk.start+i].status, items[chunk.start+i].err = batchFailed, chunk.err
+			}
+		}
+
+		txHashes := make([]any, 0, len(hashes))
+		for _, h := range hashes {
+			txHashes = append(txHashes, h)
+		}
+		res := map[string]any{
+			"index":    chunk.index,
+			"start":    chunk.start,
+			"count":    min(opts.ChunkSize, len(reqs)-chunk.start),
+			"txHashes": txHashes,
+			"error":    wrapErr(chunk.err),
+		}
+		chunks = append(chunks, res)
+		if opts.onChunk.Type() == js.TypeFunction {
+			opts.onChunk.Invoke(js.ValueOf(res))
+		}
+		if chunk.err != nil {
+			return chunks, items, fmt.Errorf("chunk %d: %w", chunk.index, chunk.err)
+		}
+	}
+	return chunks, items, nil
+}
+
+// jsSubmitBatch expects (requests, options?, clientIndex?) and returns a Promise. requests lists {txType,
+// params} as accepted by PrepareTx, in nonce order. options is {chunkSize?, minIntervalMs?, maxRetries?, atomic?,
+// cancelsFirst?, onChunk?}: chunkSize defaults to the exchange batch limit, minIntervalMs spaces the chunks,
+// maxRetries bounds the retries of a throttled chunk, atomic sends nothing unless every request could be signed,
+// cancelsFirst overrides the profile's and onChunk receives {index, start, count, txHashes, error} for every
+// chunk, start counting in submission order. The result holds the chunks, the number of
+// txs submitted, the status of the batch and one item per request, see SignBatch.
+func jsSubmitBatch(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "SubmitBatch expects at least 1 arg: requests"})
+	}
+	c, err := clientFromArgs(args, 2)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	if c.HTTP() == nil {
+		return js.ValueOf(map[string]any{"error": "HTTP client not configured"})
+	}
+	if err := checkNotHalted(); err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	if !js.Global().Get("Array").Call("isArray", args[0]).Bool() || args[0].Length() == 0 {
+		return js.ValueOf(map[string]any{"error": "requests should be a non-empty array"})
+	}
+
+	reqs, err := readBatchRequests(args[0])
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+
+	opts := batchOptions{ChunkSize: types.MaxBatchTxs}
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		onChunk := args[1].Get("onChunk")
+		if onChunk.Type() != js.TypeUndefined && onChunk.Type() != js.TypeFunction {
+			return js.ValueOf(map[string]any{"error": "onChunk should be a function"})
+		}
+		// JSON.stringify leaves the callback out of what decodeStrict checks.
+		if err := decodeStrict("options", args[1], batchOptionsSchema, &opts); err != nil {
+			return js.ValueOf(errorResult(err))
+		}
+		opts.onChunk = onChunk
+	}
+
+	return newPromise(func(clock callClock) map[string]any {
+		chunks, items, err := SubmitBatch(clock.bind(c), reqs, opts)
+		if chunks == nil {
+			chunks = []any{}
+		}
+		return map[string]any{
+			"chunks":    chunks,
+			"submitted": countBatchItems(items, batchSubmitted),
+			"status":    batchStatus(items, batchSubmitted),
+			"items":     batchItemsResult(items),
+			"error":     wrapErr(err),
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/types"
+)
+
+// fieldSchema describes one key of an object accepted from JS.
+type fieldSchema struct {
+	// Type is one of "integer", "string", "boolean" or "array". Array items must be integers.
+	Type     string
+	Required bool
+	// Min and Max bound integers and array items, MaxLength bounds strings in bytes.
+	Min, Max  int64
+	MaxLength int
+}
+
+// objectSchema maps the keys of an object to their schema. Keys it does not list are rejected.
+type objectSchema map[string]fieldSchema
+
+func intField(min, max int64) fieldSchema {
+	return fieldSchema{Type: "integer", Min: min, Max: max}
+}
+
+func requiredIntField(min, max int64) fieldSchema {
+	return fieldSchema{Type: "integer", Required: true, Min: min, Max: max}
+}
+
+// SchemaError lists every way an object violates its schema.
+type SchemaError struct {
+	Name       string
+	Violations []string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Name, strings.Join(e.Violations, "; "))
+}
+
+// validate checks data, a JSON object, against s and returns all violations sorted by key.
+func (s objectSchema) validate(data []byte) []string {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var obj map[string]interface{}
+	if err := dec.Decode(&obj); err != nil || obj == nil {
+		return []string{"expected a JSON object"}
+	}
+
+	var violations []string
+	for key, value := range obj {
+		field, ok := s[key]
+		if !ok {
+			violations = append(violations, fmt.Sprintf("%s: unknown field", key))
+			continue
+		}
+		violations = append(violations, field.check(key, value)...)
+	}
+	for key, field := range s {
+		if _, ok := obj[key]; field.Required && !ok {
+			violations = append(violations, fmt.Sprintf("%s: required", key))
+		}
+	}
+
+	sort.Strings(violations)
+	return violations
+}
+
+func (f fieldSchema) check(key string, value interface{}) []string {
+	switch f.Type {
+	case "integer":
+		if msg := f.checkInt(value); msg != "" {
+			return []string{fmt.Sprintf("%s: %s", key, msg)}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected a string", key)}
+		}
+		if f.MaxLength > 0 && len(s) > f.MaxLength {
+			return []string{fmt.Sprintf("%s: longer than %d bytes", key, f.MaxLength)}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{fmt.Sprintf("%s: expected a boolean", key)}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an array", key)}
+		}
+		var violations []string
+		for i, item := range items {
+			if msg := f.checkInt(item); msg != "" {
+				violations = append(violations, fmt.Sprintf("%s[%d]: %s", key, i, msg))
+			}
+		}
+		return violations
+	}
+	return nil
+}
+
+func (f fieldSchema) checkInt(value interface{}) string {
+	n, ok := value.(json.Number)
+	if !ok {
+		return "expected an integer"
+	}
+	i, err := strconv.ParseInt(n.String(), 10, 64)
+	if err != nil {
+		return fmt.Sprintf("expected an integer, got %s", n)
+	}
+	if i < f.Min || i > f.Max {
+		return fmt.Sprintf("%d out of range [%d, %d]", i, f.Min, f.Max)
+	}
+	return ""
+}
+
+// decodeStrict validates v, a plain JS object, against s and decodes it into out. All violations are
+// reported at once in a *SchemaError.
+func decodeStrict(name string, v js.Value, s objectSchema, out interface{}) error {
+	data := []byte(js.Global().Get("JSON").Call("stringify", v).String())
+	if violations := s.validate(data); len(violations) > 0 {
+		return &SchemaError{Name: name, Violations: violations}
+	}
+	return json.Unmarshal(data, out)
+}
+
+// errorResult is the JS result for err, listing the schema or market rule violations separately when there are
+// any and adding the code of errors callers are expected to handle. Market rule violations come with the nearest
+// valid baseAmount and price as suggested.
+func errorResult(err error) map[string]any {
+	res := map[string]any{"error": wrapErr(err)}
+	if code := errorCode(err); code != "" {
+		res["code"] = code
+	}
+	if schemaErr, ok := err.(*SchemaError); ok {
+		violations := make([]any, 0, len(schemaErr.Violations))
+		for _, v := range schemaErr.Violations {
+			violations = append(violations, redact(v))
+		}
+		res["violations"] = violations
+	}
+	var sizeErr *types.OrderSizeError
+	if errors.As(err, &sizeErr) {
+		violations := make([]any, 0, len(sizeErr.Violations))
+		for _, v := range sizeErr.Violations {
+			violations = append(violations, v)
+		}
+		res["violations"] = violations
+		res["suggested"] = map[string]any{"baseAmount": sizeErr.SuggestedBaseAmount, "price": sizeErr.SuggestedPrice}
+	}
+	return res
+}
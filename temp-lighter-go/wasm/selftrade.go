@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// SelfTradeMode selects what happens to an order which would match a resting order of the same account.
+type SelfTradeMode int
+
+const (
+	SelfTradeAllow SelfTradeMode = iota
+	SelfTradeWarn
+	SelfTradeRefuse
+)
+
+var selfTradeMode = SelfTradeAllow
+
+func ParseSelfTradeMode(s string) (SelfTradeMode, error) {
+	switch s {
+	case "off":
+		return SelfTradeAllow, nil
+	case "warn":
+		return SelfTradeWarn, nil
+	case "refuse":
+		return SelfTradeRefuse, nil
+	}
+	return 0, fmt.Errorf("unknown self-trade prevention mode %q, expected off, warn or refuse", s)
+}
+
+func (m SelfTradeMode) String() string {
+	switch m {
+	case SelfTradeWarn:
+		return "warn"
+	case SelfTradeRefuse:
+		return "refuse"
+	}
+	return "off"
+}
+
+// checkSelfTrade is installed on every client created from JS, see installChecks. Only the resting orders the
+// module signed itself are known to it.
+func checkSelfTrade(tx txtypes.TxInfo) error {
+	if selfTradeMode == SelfTradeAllow {
+		return nil
+	}
+
+	var accountIndex int64
+	var orders []*txtypes.OrderInfo
+	switch tx := tx.(type) {
+	case *txtypes.L2CreateOrderTxInfo:
+		accountIndex, orders = tx.AccountIndex, []*txtypes.OrderInfo{tx.OrderInfo}
+	case *txtypes.L2CreateGroupedOrdersTxInfo:
+		accountIndex, orders = tx.AccountIndex, tx.Orders
+	}
+	for _, order := range orders {
+		err := types.CheckSelfTrade(order, restingOrders(accountIndex, order.MarketIndex))
+		if err == nil {
+			continue
+		}
+		if selfTradeMode == SelfTradeRefuse {
+			return err
+		}
+		warn(err.Error())
+	}
+	return nil
+}
+
+// jsSetSelfTradePrevention expects ("off" | "warn" | "refuse"). "off" is the default.
+func jsSetSelfTradePrevention(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "SetSelfTradePrevention expects 1 arg: mode"})
+	}
+
+	mode, err := ParseSelfTradeMode(args[0].String())
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	selfTradeMode = mode
+	return js.ValueOf(map[string]any{"mode": mode.String(), "error": ""})
+}
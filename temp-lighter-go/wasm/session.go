@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/policy"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// sessionClients marks which registered clients are session keys, so they can be revoked as such.
+var sessionClients = map[int]bool{}
+
+// clientPolicies holds the policy each client was created with, by clientIndex, for GetPolicyUsage. Clients
+// derived from one share its policy, and so its limits, but are not listed.
+var clientPolicies = map[int]*policy.Policy{}
+
+// setClientPolicy records p as the policy of clientIndex; nil clears it. p resolves its address book restriction
+// against the module's address book.
+func setClientPolicy(clientIndex int, p *policy.Policy) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if p == nil {
+		delete(clientPolicies, clientIndex)
+		return
+	}
+	p.InAddressBook = inAddressBook
+	clientPolicies[clientIndex] = p
+}
+
+// SessionKey is the result of CreateSessionKey.
+type SessionKey struct {
+	ClientIndex int
+	TxInfo      string
+	L1Message   string
+	PrivateKey  string
+	PublicKey   string
+}
+
+// CreateSessionKey generates a key for apiKeyIndex of the loaded client's account and signs, with the new key,
+// the ChangePubKey tx registering it, as RotateAPIKey does. The tx still needs the L1 signature of L1Message.
+// The session key is registered as a new client restricted by p: every transaction it signs is checked against
+// p before signing.
+func CreateSessionKey(apiKeyIndex uint8, p *policy.Policy, nonce int64) (*SessionKey, error) {
+	primary, err := getClient(defaultClientIndex)
+	if err != nil {
+		return nil, err
+	}
+	if apiKeyIndex == primary.GetApiKeyIndex() {
+		return nil, fmt.Errorf("session key must use a different api key index than the primary key")
+	}
+
+	sessionKey, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+	fromAcc := primary.GetAccountIndex()
+	ops := &types.TransactOpts{
+		FromAccountIndex: &fromAcc,
+		ApiKeyIndex:      &apiKeyIndex,
+		Nonce:            &nonce,
+	}
+
+	tx, err := primary.WithKeyManager(sessionKey).GetChangePubKeyTransaction(&types.ChangePubKeyReq{PubKey: sessionKey.PubKeyBytes()}, ops)
+	if err != nil {
+		return nil, err
+	}
+	txInfo, err := formatTxInfo(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	session := primary.WithKeyManager(sessionKey)
+	session.SwitchAPIKey(apiKeyIndex)
+	session.SetTxCheck(p.Check)
+	clientIndex := registerClient(session)
+	stateMu.Lock()
+	sessionClients[clientIndex] = true
+	stateMu.Unlock()
+	setClientPolicy(clientIndex, p)
+
+	pubKey := sessionKey.PubKeyBytes()
+	s := &SessionKey{
+		ClientIndex: clientIndex,
+		TxInfo:      txInfo,
+		L1Message:   tx.GetL1SignatureBody(),
+		PrivateKey:  hexutil.Encode(sessionKey.PrvKeyBytes()),
+		PublicKey:   hexutil.Encode(pubKey[:]),
+	}
+	registerSecret(s.PrivateKey)
+	return s, nil
+}
+
+// RevokeSessionKey drops a session client, and the txs prepared with it, so nothing can be signed with it
+// anymore. The key stays registered on the exchange until it is replaced there.
+func RevokeSessionKey(clientIndex int) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if !sessionClients[clientIndex] {
+		return fmt.Errorf("client %d is not a session key", clientIndex)
+	}
+	delete(sessionClients, clientIndex)
+	unregisterClientLocked(clientIndex)
+	for txId, pending := range pendingTxs {
+		if pending.clientIndex == clientIndex {
+			delete(pendingTxs, txId)
+		}
+	}
+	return nil
+}
+
+var policySchema = objectSchema{
+	"notBefore":          intField(0, math.MaxInt64),
+	"notAfter":           intField(0, math.MaxInt64),
+	"markets":            {Type: "array", Min: 0, Max: math.MaxUint8},
+	"maxOrderNotional":   intField(0, math.MaxInt64),
+	"allowTransfers":     {Type: "boolean"},
+	"allowWithdrawals":   {Type: "boolean"},
+	"destinations":       {Type: "array", Min: txtypes.MinAccountIndex, Max: txtypes.MaxAccountIndex},
+	"addressBookOnly":    {Type: "boolean"},
+	"maxOrdersPerMinute": intField(0, math.MaxInt64),
+	"maxNotionalPerHour": intField(0, math.MaxInt64),
+	"maxNotionalPerDay":  intField(0, math.MaxInt64),
+}
+
+var clientOptionsSchema = objectSchema{
+	"destinations":       policySchema["destinations"],
+	"addressBookOnly":    policySchema["addressBookOnly"],
+	"maxOrdersPerMinute": policySchema["maxOrdersPerMinute"],
+	"maxNotionalPerHour": policySchema["maxNotionalPerHour"],
+	"maxNotionalPerDay":  policySchema["maxNotionalPerDay"],
+}
+
+// readClientOptions decodes the options a client is created with into the policy enforcing them, or nil when
+// they restrict nothing. They can only be given at creation: the policy is installed on the client and on every
+// client derived from it, and no export can change or remove it, so a compromised page cannot widen them.
+func readClientOptions(name string, v js.Value) (*policy.Policy, error) {
+	defaults := currentProfile()
+	p := &policy.Policy{
+		AllowTransfers:     true,
+		AllowWithdrawals:   true,
+		AllowAccountTxs:    true,
+		MaxOrdersPerMinute: defaults.MaxOrdersPerMinute,
+		MaxNotionalPerHour: defaults.MaxNotionalPerHour,
+		MaxNotionalPerDay:  defaults.MaxNotionalPerDay,
+	}
+	if v.Type() == js.TypeObject {
+		if err := decodeStrict(name, v, clientOptionsSchema, p); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.Destinations) == 0 && !p.AddressBookOnly && p.MaxOrdersPerMinute == 0 && p.MaxNotionalPerHour == 0 && p.MaxNotionalPerDay == 0 {
+		return nil, nil
+	}
+	return p, nil
+}
+
+// parsePolicy decodes a policy passed from JS as a plain object, rejecting unknown fields so that a misspelled
+// restriction cannot silently be ignored.
+func parsePolicy(v js.Value) (*policy.Policy, error) {
+	p := &policy.Policy{}
+	if v.Type() != js.TypeObject {
+		return p, nil
+	}
+	if err := decodeStrict("policy", v, policySchema, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// jsCreateSessionKey expects (apiKeyIndex, policy, nonce) and returns {clientIndex, txInfo, l1Message, privateKey,
+// publicKey}, l1Message being the message to sign with the account's L1 key for the ChangePubKey tx.
+func jsCreateSessionKey(this js.Value, args []js.Value) any {
+	if len(args) < 3 {
+		return js.ValueOf(map[string]any{"error": "CreateSessionKey expects 3 args: apiKeyIndex, policy, nonce"})
+	}
+
+	p, err := parsePolicy(args[1])
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+
+	s, err := CreateSessionKey(uint8(args[0].Int()), p, int64(args[2].Int()))
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{
+		"clientIndex": s.ClientIndex,
+		"txInfo":      s.TxInfo,
+		"l1Message":   s.L1Message,
+		"privateKey":  s.PrivateKey,
+		"publicKey":   s.PublicKey,
+		"error":       "",
+	})
+}
+
+// bigResult converts n for JS, saturating at math.MaxInt64.
+func bigResult(n *big.Int) int64 {
+	if !n.IsInt64() {
+		return math.MaxInt64
+	}
+	return n.Int64()
+}
+
+// remaining returns the headroom left under limit, or -1 when limit is 0 and so disabled.
+func remaining(limit int64, used *big.Int) int64 {
+	if limit == 0 {
+		return -1
+	}
+	left := new(big.Int).Sub(big.NewInt(limit), used)
+	if left.Sign() < 0 {
+		return 0
+	}
+	return left.Int64()
+}
+
+// jsGetPolicyUsage expects (clientIndex?) and reports the rolling limits of the policy the client was created
+// with: the orders and notional signed within each window, the limit and the headroom left, -1 meaning
+// unlimited.
+func jsGetPolicyUsage(this js.Value, args []js.Value) any {
+	clientIndex := defaultClientIndex
+	if len(args) > 0 && args[0].Type() == js.TypeNumber {
+		clientIndex = args[0].Int()
+	}
+	stateMu.RLock()
+	p, ok := clientPolicies[clientIndex]
+	stateMu.RUnlock()
+	if !ok {
+		return js.ValueOf(map[string]any{"error": fmt.Sprintf("client %d has no policy", clientIndex)})
+	}
+
+	u := p.Usage(time.Now())
+	return js.ValueOf(map[string]any{
+		"ordersLastMinute":         u.OrdersLastMinute,
+		"maxOrdersPerMinute":       u.MaxOrdersPerMinute,
+		"remainingOrdersPerMinute": remaining(u.MaxOrdersPerMinute, big.NewInt(u.OrdersLastMinute)),
+		"notionalLastHour":         bigResult(u.NotionalLastHour),
+		"maxNotionalPerHour":       u.MaxNotionalPerHour,
+		"remainingNotionalPerHour": remaining(u.MaxNotionalPerHour, u.NotionalLastHour),
+		"notionalLastDay":          bigResult(u.NotionalLastDay),
+		"maxNotionalPerDay":        u.MaxNotionalPerDay,
+		"remainingNotionalPerDay":  remaining(u.MaxNotionalPerDay, u.NotionalLastDay),
+		"error":                    "",
+	})
+}
+
+func jsRevokeSessionKey(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "RevokeSessionKey expects 1 arg: clientIndex"})
+	}
+	return js.ValueOf(map[string]any{"error": wrapErr(RevokeSessionKey(args[0].Int()))})
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"syscall/js"
+	"testing"
+
+	"github.com/elliottech/lighter-go/policy"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+func TestCreateSessionKey(t *testing.T) {
+	privateKey, _, errStr := GenerateAPIKey("")
+	if errStr != "" {
+		t.Fatal(errStr)
+	}
+	res := js.ValueOf(jsCreateClient(js.Undefined(), []js.Value{js.ValueOf(privateKey), js.ValueOf(5), js.ValueOf(2), js.ValueOf(300)}))
+	if errStr := res.Get("error").String(); errStr != "" {
+		t.Fatal(errStr)
+	}
+	defer setDefaultClient(nil)
+
+	s, err := CreateSessionKey(4, &policy.Policy{}, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer setClientPolicy(s.ClientIndex, nil)
+	defer RevokeSessionKey(s.ClientIndex)
+
+	sessionKey, err := parsePublicKey(s.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := types.DecodeSignedTx(txtypes.TxTypeL2ChangePubKey, s.TxInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changePubKey := tx.(*txtypes.L2ChangePubKeyTxInfo)
+	if changePubKey.AccountIndex != 5 || changePubKey.ApiKeyIndex != 4 || !bytes.Equal(changePubKey.PubKey, sessionKey[:]) {
+		t.Errorf("the ChangePubKey tx registers %x for account %d, api key %d", changePubKey.PubKey, changePubKey.AccountIndex, changePubKey.ApiKeyIndex)
+	}
+	if err := types.VerifyTxSignature(tx, 300, sessionKey); err != nil {
+		t.Errorf("the ChangePubKey tx is not signed by the session key: %v", err)
+	}
+	if want := changePubKey.GetL1SignatureBody(); s.L1Message == "" || s.L1Message != want {
+		t.Errorf("l1Message is %q, expected %q", s.L1Message, want)
+	}
+
+	session, err := getClient(s.ClientIndex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.GetKeyManager().PubKeyBytes() != sessionKey || session.GetApiKeyIndex() != 4 {
+		t.Errorf("the session client does not sign with the session key for api key 4")
+	}
+}
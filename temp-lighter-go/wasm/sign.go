@@ -0,0 +1,448 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// The client and signing exports exist on two surfaces: the js.Global bindings below, and the //export
+// functions of main.go taking strings. The string exports convert their params with stringArg and call the
+// bindings, so both surfaces build their requests in one place and fail with the same errors.
+
+// intArg reads an integer arg, refusing values outside [min, max] rather than truncating them to the width of
+// the tx field. A string is refused too, with the reason when it does not even follow the decimal grammar of
+// types.ParseDecimal.
+func intArg(name string, v js.Value, min, max int64) (int64, error) {
+	if v.Type() == js.TypeString {
+		if _, err := types.ParseDecimal(v.String()); err != nil {
+			return 0, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if v.Type() != js.TypeNumber || !js.Global().Get("Number").Call("isSafeInteger", v).Bool() {
+		return 0, fmt.Errorf("%s should be an integer, got %s", name, js.Global().Get("String").Invoke(v).String())
+	}
+	n := int64(v.Int())
+	if n < min || n > max {
+		return 0, fmt.Errorf("%s should be an integer between %d and %d, got %d", name, min, max, n)
+	}
+	return n, nil
+}
+
+// stringArg converts a param of the string exports into the value a JS caller would pass: an empty string is
+// an omitted arg, an integer is a number, anything else, e.g. a decimal amount or an expiry preset, is a string.
+// Integers are read with the grammar of types.ParseDecimal, so "+5" stays a string its export refuses.
+func stringArg(s string) js.Value {
+	if s == "" {
+		return js.Undefined()
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && !strings.HasPrefix(s, "+") {
+		return js.ValueOf(n)
+	}
+	return js.ValueOf(s)
+}
+
+// callStringExport calls the binding fn with args and returns the string result key of its result along with
+// its error.
+func callStringExport(fn func(this js.Value, args []js.Value) any, key string, args ...js.Value) (value, err string) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = wrapErr(fmt.Errorf("%v", r))
+		}
+	}()
+
+	res := js.ValueOf(fn(js.Undefined(), args))
+	if err = res.Get("error").String(); err != "" {
+		return "", err
+	}
+	switch v := res.Get(key); v.Type() {
+	case js.TypeUndefined:
+		return "", ""
+	case js.TypeString:
+		return v.String(), ""
+	default:
+		return js.Global().Get("String").Invoke(v).String(), ""
+	}
+}
+
+// signTxReq signs req, the request of one of the signing exports, with the key of c and ops, see transactOpts.
+func signTxReq(c *client.TxClient, ops *types.TransactOpts, req any) (string, error) {
+	var tx txtypes.TxInfo
+	var err error
+	switch req := req.(type) {
+	case *types.CreateOrderTxReq:
+		tx, err = c.GetCreateOrderTransaction(req, ops)
+	case *types.CancelOrderTxReq:
+		tx, err = c.GetCancelOrderTransaction(req, ops)
+	case *types.CancelAllOrdersTxReq:
+		tx, err = c.GetCancelAllOrdersTransaction(req, ops)
+	case *types.TransferTxReq:
+		tx, err = c.GetTransferTransaction(req, ops)
+	case *types.UpdateLeverageTxReq:
+		tx, err = c.GetUpdateLeverageTransaction(req, ops)
+	default:
+		return "", fmt.Errorf("unsupported tx request %T", req)
+	}
+	if err != nil {
+		return "", err
+	}
+	return formatTxInfo(tx)
+}
+
+// jsCreateClient expects (apiKey, accountIndex, apiKeyIndex, chainId, baseUrl?, options?).
+func jsCreateClient(this js.Value, args []js.Value) any {
+	if len(args) < 4 {
+		return js.ValueOf(map[string]any{"error": "CreateClient expects at least 4 args: apiKey, accountIndex, apiKeyIndex, chainId, baseUrl?, options?"})
+	}
+	if args[0].Type() != js.TypeString {
+		return js.ValueOf(map[string]any{"error": "apiKey should be a string"})
+	}
+
+	apiKey := args[0].String()
+	registerSecret(apiKey)
+	accIdx, err := intArg("accountIndex", args[1], txtypes.MinAccountIndex, txtypes.MaxAccountIndex)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	apiKeyIdx, err := intArg("apiKeyIndex", args[2], 0, int64(txtypes.MaxApiKeyIndex))
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	chainId, err := intArg("chainId", args[3], 0, math.MaxUint32)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+
+	// Optional base URL, or base URLs by priority, enabling the HTTP client; signing never requires it
+	httpClient, err := httpClientFromArgs(args, 4)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+
+	// Optional restrictions, only settable here: {destinations?} lists the accounts transfers may go to,
+	// {addressBookOnly?} only lets them go to the accounts of the address book,
+	// {maxOrdersPerMinute?, maxNotionalPerHour?, maxNotionalPerDay?} are rolling limits, defaulting to the profile's.
+	options := js.Undefined()
+	if len(args) > 5 {
+		options = args[5]
+	}
+	restrictions, err := readClientOptions("options", options)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+
+	tx, err := client.NewTxClient(httpClient, apiKey, accIdx, uint8(apiKeyIdx), uint32(chainId))
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	tx = installChecks(tx)
+	if restrictions != nil {
+		tx.AddTxCheck(restrictions.Check)
+	}
+	setDefaultClient(tx)
+	setClientPolicy(defaultClientIndex, restrictions)
+	return js.ValueOf(map[string]any{"clientIndex": defaultClientIndex, "error": ""})
+}
+
+// createOrderArgs reads the args of SignCreateOrder, whose signature SimulateCreateOrder shares, after their count
+// was checked.
+func createOrderArgs(args []js.Value) (c *client.TxClient, ops *types.TransactOpts, req *types.CreateOrderTxReq, err error) {
+	c, err = clientFromArgs(args, 11)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	marketIndex, err := intArg("marketIndex", args[0], 0, int64(txtypes.MaxMarketIndex))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	clientOrderIndex, err := intArg("clientOrderIndex", args[1], txtypes.NilClientOrderIndex, txtypes.MaxClientOrderIndex)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	baseAmount, err := amountArg("baseAmount", args[2], marketDecimals(uint8(marketIndex), false), txtypes.MaxOrderBaseAmount)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	price, err := amountArg("price", args[3], marketDecimals(uint8(marketIndex), true), int64(txtypes.MaxOrderPrice))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	isAsk, err := intArg("isAsk", args[4], 0, 1)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	orderType, err := intArg("orderType", args[5], 0, math.MaxUint8)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	timeInForce, err := intArg("timeInForce", args[6], 0, math.MaxUint8)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	reduceOnly, err := intArg("reduceOnly", args[7], 0, 1)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	triggerPrice, err := amountArg("triggerPrice", args[8], marketDecimals(uint8(marketIndex), true), int64(txtypes.MaxOrderPrice))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	orderExpiry, err := parseOrderExpiry(args[9])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	nonce, err := intArg("nonce", args[10], txtypes.MinNonce, math.MaxInt64)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	fromAcc, err := accountFromArgs(c, args, 12)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ops, err = orderOptsFromArgs(c, fromAcc, nonce, args, 13)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	req = &types.CreateOrderTxReq{
+		MarketIndex:      uint8(marketIndex),
+		ClientOrderIndex: clientOrderIndex,
+		BaseAmount:       baseAmount,
+		Price:            uint32(price),
+		IsAsk:            uint8(isAsk),
+		Type:             uint8(orderType),
+		TimeInForce:      uint8(timeInForce),
+		ReduceOnly:       uint8(reduceOnly),
+		TriggerPrice:     uint32(triggerPrice),
+		OrderExpiry:      orderExpiry,
+	}
+	return c, ops, req, nil
+}
+
+// jsSignCreateOrder expects (marketIndex, clientOrderIndex, baseAmount, price, isAsk, orderType, timeInForce,
+// reduceOnly, triggerPrice, orderExpiry, nonce, clientIndex?, accountIndex?, options?). options is {allowCross?,
+// intentHash?}, intentHash being the hash HashOrderIntent returned for the order the user approved, required
+// once RequireOrderIntent was called, along with the TransactOpts overrides {accountIndex?, apiKeyIndex?, nonce?,
+// expiredAt?} every signing export accepts: each one set wins over the positional arg, which wins over the
+// client's default.
+func jsSignCreateOrder(this js.Value, args []js.Value) any {
+	if len(args) < 11 {
+		return js.ValueOf(map[string]any{"error": "SignCreateOrder expects at least 11 args: marketIndex, clientOrderIndex, baseAmount, price, isAsk, orderType, timeInForce, reduceOnly, triggerPrice, orderExpiry, nonce"})
+	}
+	c, ops, req, err := createOrderArgs(args)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+
+	c = allowCrossClient(c, args, 13)
+	if c, err = intentClient(c, args, 13); err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	txInfo, err := signTxReq(c, ops, req)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	return js.ValueOf(map[string]any{"txInfo": txInfo, "error": ""})
+}
+
+// jsSignCancelOrder expects (marketIndex, orderIndex, nonce, clientIndex?, accountIndex?, options?), options
+// being the TransactOpts overrides, see SignCreateOrder.
+func jsSignCancelOrder(this js.Value, args []js.Value) any {
+	if len(args) < 3 {
+		return js.ValueOf(map[string]any{"error": "SignCancelOrder expects at least 3 args: marketIndex, orderIndex, nonce"})
+	}
+	c, err := clientFromArgs(args, 3)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+
+	marketIndex, err := intArg("marketIndex", args[0], 0, int64(txtypes.MaxMarketIndex))
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	orderIndex, err := intArg("orderIndex", args[1], 0, txtypes.MaxOrderIndex)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	nonce, err := intArg("nonce", args[2], txtypes.MinNonce, math.MaxInt64)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	fromAcc, err := accountFromArgs(c, args, 4)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+
+	ops, err := txOptsFromArgs(c, fromAcc, nonce, args, 5)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+
+	req := &types.CancelOrderTxReq{
+		MarketIndex: uint8(marketIndex),
+		Index:       orderIndex,
+	}
+	txInfo, err := signTxReq(c, ops, req)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	return js.ValueOf(map[string]any{"txInfo": txInfo, "error": ""})
+}
+
+// jsSignCancelAllOrders expects (timeInForce, time, nonce, clientIndex?, accountIndex?, options?), options being
+// the TransactOpts overrides, see SignCreateOrder.
+func jsSignCancelAllOrders(this js.Value, args []js.Value) any {
+	if len(args) < 3 {
+		return js.ValueOf(map[string]any{"error": "SignCancelAllOrders expects at least 3 args: timeInForce, time, nonce"})
+	}
+	c, err := clientFromArgs(args, 3)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+
+	timeInForce, err := intArg("timeInForce", args[0], 0, math.MaxUint8)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	timeVal, err := parseTimeParam("time", args[1], time.Millisecond, txtypes.NilOrderExpiry)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	nonce, err := intArg("nonce", args[2], txtypes.MinNonce, math.MaxInt64)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	fromAcc, err := accountFromArgs(c, args, 4)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+
+	ops, err := txOptsFromArgs(c, fromAcc, nonce, args, 5)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+
+	req := &types.CancelAllOrdersTxReq{
+		TimeInForce: uint8(timeInForce),
+		Time:        timeVal,
+	}
+	txInfo, err := signTxReq(c, ops, req)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	return js.ValueOf(map[string]any{"txInfo": txInfo, "error": ""})
+}
+
+// jsSignTransfer expects (toAccountIndex, usdcAmount, fee, memo, nonce, clientIndex?, options?). toAccountIndex may
+// be the label of an address book entry, memo {template, vars} to render a memo template. options are the
+// TransactOpts overrides, see SignCreateOrder.
+func jsSignTransfer(this js.Value, args []js.Value) any {
+	if len(args) < 5 {
+		return js.ValueOf(map[string]any{"error": "SignTransfer expects at least 5 args: toAccountIndex, usdcAmount, fee, memo, nonce"})
+	}
+	c, err := clientFromArgs(args, 5)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+
+	toAccount, err := destinationArg("toAccountIndex", args[0])
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	usdcAmount, err := amountArg("usdcAmount", args[1], usdcDecimals, txtypes.MaxTransferAmount)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	fee, err := amountArg("fee", args[2], usdcDecimals, txtypes.MaxTransferAmount)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	memo, err := memoArg("memo", args[3])
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	nonce, err := intArg("nonce", args[4], txtypes.MinNonce, math.MaxInt64)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	ops, err := txOptsFromArgs(c, c.GetAccountIndex(), nonce, args, 6)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+
+	req := &types.TransferTxReq{
+		ToAccountIndex: toAccount,
+		USDCAmount:     usdcAmount,
+		Fee:            fee,
+		Memo:           memo,
+	}
+	txInfo, err := signTxReq(c, ops, req)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	return js.ValueOf(map[string]any{"txInfo": txInfo, "error": ""})
+}
+
+// jsSignUpdateLeverage expects (marketIndex, initialMarginFraction, marginMode, nonce, clientIndex?, options?),
+// options being the TransactOpts overrides, see SignCreateOrder.
+func jsSignUpdateLeverage(this js.Value, args []js.Value) any {
+	if len(args) < 4 {
+		return js.ValueOf(map[string]any{"error": "SignUpdateLeverage expects at least 4 args: marketIndex, initialMarginFraction, marginMode, nonce"})
+	}
+	c, err := clientFromArgs(args, 4)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+
+	marketIndex, err := intArg("marketIndex", args[0], 0, int64(txtypes.MaxMarketIndex))
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	fraction, err := intArg("initialMarginFraction", args[1], 0, math.MaxUint16)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	marginMode, err := intArg("marginMode", args[2], 0, math.MaxUint8)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	nonce, err := intArg("nonce", args[3], txtypes.MinNonce, math.MaxInt64)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	ops, err := txOptsFromArgs(c, c.GetAccountIndex(), nonce, args, 5)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+
+	req := &types.UpdateLeverageTxReq{
+		MarketIndex:           uint8(marketIndex),
+		InitialMarginFraction: uint16(fraction),
+		MarginMode:            uint8(marginMode),
+	}
+	txInfo, err := signTxReq(c, ops, req)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	return js.ValueOf(map[string]any{"txInfo": txInfo, "error": ""})
+}
+
+// jsCheckClient expects (clientIndex?).
+func jsCheckClient(this js.Value, args []js.Value) any {
+	c, err := clientFromArgs(args, 0)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	if c.GetKeyManager() == nil {
+		return js.ValueOf(map[string]any{"error": "client key manager is nil"})
+	}
+	return js.ValueOf(map[string]any{"error": ""})
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// maxSignedOrders bounds the signed order index; the oldest entries are dropped first.
+const maxSignedOrders = 10_000
+
+// signedOrder is a create or modify order tx signed in this session, for an order with a client order index.
+type signedOrder struct {
+	AccountIndex     int64
+	MarketIndex      uint8
+	ClientOrderIndex int64
+	TxType           uint8
+	Nonce            int64
+	TxHash           string
+	TxInfo           string
+	SignedAt         time.Time
+}
+
+type signedOrderKey struct {
+	accountIndex     int64
+	clientOrderIndex int64
+}
+
+// signedOrders indexes the signed orders by account and client order index, so that fills and order updates,
+// which carry the client order index, can be matched to the exact payload signed. signedOrderLog keeps the
+// entries in signing order to drop the oldest ones.
+var (
+	signedOrders   = map[signedOrderKey][]*signedOrder{}
+	signedOrderLog []*signedOrder
+)
+
+// indexSignedOrder records tx, encoded as txInfo, when it creates or modifies an order by client order index.
+// Grouped orders carry no client order index and are not recorded.
+func indexSignedOrder(tx txtypes.TxInfo, txInfo string) {
+	var o *signedOrder
+	switch tx := tx.(type) {
+	case *txtypes.L2CreateOrderTxInfo:
+		if tx.ClientOrderIndex == txtypes.NilClientOrderIndex {
+			return
+		}
+		o = &signedOrder{AccountIndex: tx.AccountIndex, MarketIndex: tx.MarketIndex, ClientOrderIndex: tx.ClientOrderIndex, Nonce: tx.Nonce}
+	case *txtypes.L2ModifyOrderTxInfo:
+		if tx.Index > txtypes.MaxClientOrderIndex {
+			return
+		}
+		o = &signedOrder{AccountIndex: tx.AccountIndex, MarketIndex: tx.MarketIndex, ClientOrderIndex: tx.Index, Nonce: tx.Nonce}
+	default:
+		return
+	}
+	o.TxType, o.TxHash, o.TxInfo, o.SignedAt = tx.GetTxType(), tx.GetTxHash(), txInfo, time.Now()
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	key := signedOrderKey{o.AccountIndex, o.ClientOrderIndex}
+	signedOrders[key] = append(signedOrders[key], o)
+	signedOrderLog = append(signedOrderLog, o)
+	if len(signedOrderLog) > maxSignedOrders {
+		dropSignedOrderLocked(signedOrderLog[0])
+		signedOrderLog = signedOrderLog[1:]
+	}
+}
+
+// dropSignedOrderLocked removes o from signedOrders, but not from signedOrderLog.
+func dropSignedOrderLocked(o *signedOrder) {
+	key := signedOrderKey{o.AccountIndex, o.ClientOrderIndex}
+	entries := signedOrders[key]
+	for i, e := range entries {
+		if e == o {
+			entries = append(entries[:i:i], entries[i+1:]...)
+			break
+		}
+	}
+	if len(entries) == 0 {
+		delete(signedOrders, key)
+		return
+	}
+	signedOrders[key] = entries
+}
+
+// dropAccountSignedOrdersLocked removes every entry of accountIndex from the index.
+func dropAccountSignedOrdersLocked(accountIndex int64) {
+	kept := signedOrderLog[:0]
+	for _, o := range signedOrderLog {
+		if o.AccountIndex == accountIndex {
+			dropSignedOrderLocked(o)
+			continue
+		}
+		kept = append(kept, o)
+	}
+	signedOrderLog = kept
+}
+
+// LookupSignedOrder returns the txs signed in this session for clientOrderIndex of accountIndex, oldest first.
+func LookupSignedOrder(accountIndex, clientOrderIndex int64) []signedOrder {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	entries := signedOrders[signedOrderKey{accountIndex, clientOrderIndex}]
+	res := make([]signedOrder, len(entries))
+	for i, o := range entries {
+		res[i] = *o
+	}
+	return res
+}
+
+// jsLookupSignedOrder expects (clientOrderIndex, accountIndex?, clientIndex?). accountIndex defaults to the
+// account of the client. Returns {orders} listing {txType, nonce, txHash, signedAt, txInfo, ...} for every
+// create or modify tx signed in this session for the order, oldest first.
+func jsLookupSignedOrder(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "LookupSignedOrder expects at least 1 arg: clientOrderIndex"})
+	}
+	if args[0].Type() != js.TypeNumber || args[0].Float() < float64(txtypes.MinClientOrderIndex) || args[0].Float() > float64(txtypes.MaxClientOrderIndex) {
+		return js.ValueOf(map[string]any{"error": fmt.Sprintf("clientOrderIndex should be an integer between %d and %d", txtypes.MinClientOrderIndex, txtypes.MaxClientOrderIndex)})
+	}
+	c, err := clientFromArgs(args, 2)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	accountIndex := c.GetAccountIndex()
+	if len(args) > 1 && args[1].Type() == js.TypeNumber {
+		accountIndex = int64(args[1].Int())
+	}
+
+	entries := LookupSignedOrder(accountIndex, int64(args[0].Float()))
+	orders := make([]any, 0, len(entries))
+	for _, o := range entries {
+		txInfo := o.TxInfo
+		if casing := currentCasing(); casing != txtypes.PascalCase {
+			if txInfo, err = txtypes.ConvertJSONKeys([]byte(o.TxInfo), casing); err != nil {
+				return js.ValueOf(map[string]any{"error": wrapErr(err)})
+			}
+		}
+		orders = append(orders, map[string]any{
+			"accountIndex":     o.AccountIndex,
+			"marketIndex":      o.MarketIndex,
+			"clientOrderIndex": o.ClientOrderIndex,
+			"txType":           o.TxType,
+			"nonce":            o.Nonce,
+			"txHash":           o.TxHash,
+			"signedAt":         o.SignedAt.UnixMilli(),
+			"txInfo":           txInfo,
+		})
+	}
+	return js.ValueOf(map[string]any{"orders": orders, "error": ""})
+}
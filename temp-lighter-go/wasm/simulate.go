@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/policy"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// SimulateCreateOrder builds the order req of c with ops and runs every check signing it would, without
+// signing it, counting it against the rolling limits or asking for confirmation. The warnings name the checks
+// that could not run for want of state, e.g. market rules or a mark price.
+func SimulateCreateOrder(c *client.TxClient, ops *types.TransactOpts, req *types.CreateOrderTxReq) (*txtypes.L2CreateOrderTxInfo, []byte, []string, error) {
+	ops, err := c.FullFillDefaultOps(ops)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	tx := types.ConvertCreateOrderTx(req, ops)
+	msgHash, err := c.SimulateTx(tx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return tx, msgHash, simulationWarnings(tx), nil
+}
+
+// simulationWarnings lists the checks the module skipped for order tx as it lacks the state they need.
+func simulationWarnings(tx *txtypes.L2CreateOrderTxInfo) []string {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+
+	warnings := []string{}
+	if _, ok := marketRules[tx.MarketIndex]; !ok {
+		warnings = append(warnings, fmt.Sprintf("no rules loaded for market %d, the size and price steps were not checked", tx.MarketIndex))
+	}
+	if tx.ReduceOnly == 1 {
+		if _, ok := positions[tx.AccountIndex]; !ok {
+			warnings = append(warnings, fmt.Sprintf("no positions known for account %d, reduce-only was not checked", tx.AccountIndex))
+		}
+	}
+	if tx.Price != txtypes.NilOrderPrice && priceFeed.maxDeviationBps > 0 && freshMarkPriceLocked(tx.MarketIndex, time.Now()) == txtypes.NilOrderPrice {
+		warnings = append(warnings, fmt.Sprintf("no fresh mark price for market %d, the price band was not checked", tx.MarketIndex))
+	}
+	return warnings
+}
+
+// jsSimulateCreateOrder expects (marketIndex, clientOrderIndex, baseAmount, price, isAsk, orderType, timeInForce,
+// reduceOnly, triggerPrice, orderExpiry, nonce, clientIndex?, accountIndex?, options?), the args of
+// SignCreateOrder, and returns {txInfo, msgHash, notional, warnings}: the unsigned tx SignCreateOrder would
+// sign, the hash it would sign and the order notional in protocol units. A call SignCreateOrder would refuse
+// returns its error.
+func jsSimulateCreateOrder(this js.Value, args []js.Value) any {
+	if len(args) < 11 {
+		return js.ValueOf(map[string]any{"error": "SimulateCreateOrder expects at least 11 args: marketIndex, clientOrderIndex, baseAmount, price, isAsk, orderType, timeInForce, reduceOnly, triggerPrice, orderExpiry, nonce"})
+	}
+	c, ops, req, err := createOrderArgs(args)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+
+	c = allowCrossClient(c, args, 13)
+	if c, err = intentClient(c, args, 13); err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	tx, msgHash, warnings, err := SimulateCreateOrder(c, ops, req)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	txInfo, err := tx.GetTxInfo()
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	txInfo, err = casedTxInfo(txInfo)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+
+	list := make([]any, len(warnings))
+	for i, w := range warnings {
+		list[i] = w
+	}
+	return js.ValueOf(map[string]any{
+		"txInfo":   txInfo,
+		"msgHash":  hexutil.Encode(msgHash),
+		"notional": bigResult(policy.Notional(tx.BaseAmount, tx.Price)),
+		"warnings": list,
+		"error":    "",
+	})
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/policy"
+	"github.com/elliottech/lighter-go/types"
+)
+
+// snapshotVersion is bumped whenever snapshot changes incompatibly.
+const snapshotVersion = 1
+
+// snapshot is the non-secret state of the module, as serialized by ExportSession. Keys are not part of it;
+// they are restored separately, e.g. by unlocking an encrypted key, before ImportSession is called.
+type snapshot struct {
+	Version    int   `json:"version"`
+	ExportedAt int64 `json:"exportedAt"`
+
+	// The identity of the loaded client, which the snapshot can only be imported into.
+	AccountIndex int64  `json:"accountIndex"`
+	ApiKeyIndex  uint8  `json:"apiKeyIndex"`
+	ChainId      uint32 `json:"chainId"`
+
+	Restrictions  *policy.Policy               `json:"restrictions,omitempty"`
+	Positions     map[int64]map[uint8]int64    `json:"positions"`
+	MarketRules   map[uint8]*types.MarketRules `json:"marketRules"`
+	RoundingModes map[string]string            `json:"roundingModes"`
+	SessionEnd    int64                        `json:"sessionEnd"`
+	OpenOrders    map[int64][]*trackedOrder    `json:"openOrders"`
+	AddressBook   map[string]int64             `json:"addressBook,omitempty"`
+}
+
+// ExportSession serializes the state of the module tied to the loaded client.
+func ExportSession() (string, error) {
+	c, err := getClient(defaultClientIndex)
+	if err != nil {
+		return "", err
+	}
+
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	s := &snapshot{
+		Version:       snapshotVersion,
+		ExportedAt:    time.Now().UnixMilli(),
+		AccountIndex:  c.GetAccountIndex(),
+		ApiKeyIndex:   c.GetApiKeyIndex(),
+		ChainId:       c.GetChainId(),
+		Restrictions:  clientPolicies[defaultClientIndex],
+		Positions:     positions,
+		MarketRules:   marketRules,
+		RoundingModes: map[string]string{},
+		OpenOrders:    ownOrders,
+		AddressBook:   addressBook,
+	}
+	for k, m := range roundingModes {
+		s.RoundingModes[k] = m.String()
+	}
+	if !sessionEnd.IsZero() {
+		s.SessionEnd = sessionEnd.UnixMilli()
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ImportSession restores a state serialized by ExportSession for the same client. Restrictions are only
+// restored when the loaded client was created without any, as they may be tightened but never replaced.
+func ImportSession(data string) (restrictionsRestored bool, err error) {
+	c, err := getClient(defaultClientIndex)
+	if err != nil {
+		return false, err
+	}
+
+	s := &snapshot{}
+	dec := json.NewDecoder(bytes.NewReader([]byte(data)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(s); err != nil {
+		return false, fmt.Errorf("invalid session: %w", err)
+	}
+	if s.Version != snapshotVersion {
+		return false, fmt.Errorf("unsupported session version %d, expected %d", s.Version, snapshotVersion)
+	}
+	if s.AccountIndex != c.GetAccountIndex() || s.ApiKeyIndex != c.GetApiKeyIndex() || s.ChainId != c.GetChainId() {
+		return false, fmt.Errorf("session was exported for account %d, api key %d on chain %d", s.AccountIndex, s.ApiKeyIndex, s.ChainId)
+	}
+
+	modes := map[string]types.RoundingMode{}
+	for k := range roundingModes {
+		m, err := types.ParseRoundingMode(s.RoundingModes[k])
+		if err != nil {
+			return false, fmt.Errorf("invalid session rounding mode %s: %w", k, err)
+		}
+		modes[k] = m
+	}
+	for marketIndex, rules := range s.MarketRules {
+		if rules == nil || rules.MarketIndex != marketIndex || rules.BaseStep < 1 || rules.PriceStep < 1 ||
+			rules.SizeDecimals > maxDecimals || rules.PriceDecimals > maxDecimals {
+			return false, fmt.Errorf("invalid session rules for market %d", marketIndex)
+		}
+	}
+	for accountIndex, orders := range s.OpenOrders {
+		for _, o := range orders {
+			if o == nil || o.OrderInfo == nil || o.AccountIndex != accountIndex {
+				return false, fmt.Errorf("invalid session open order of account %d", accountIndex)
+			}
+		}
+	}
+
+	if err := checkAddressBook(s.AddressBook); err != nil {
+		return false, fmt.Errorf("invalid session: %w", err)
+	}
+
+	stateMu.Lock()
+	positions = s.Positions
+	if positions == nil {
+		positions = map[int64]map[uint8]int64{}
+	}
+	marketRules = s.MarketRules
+	if marketRules == nil {
+		marketRules = map[uint8]*types.MarketRules{}
+	}
+	ownOrders = s.OpenOrders
+	if ownOrders == nil {
+		ownOrders = map[int64][]*trackedOrder{}
+	}
+	addressBook = s.AddressBook
+	if addressBook == nil {
+		addressBook = map[string]int64{}
+	}
+	restore := s.Restrictions != nil && clientPolicies[defaultClientIndex] == nil
+	stateMu.Unlock()
+	roundingModes = modes
+	sessionEnd = time.Time{}
+	if s.SessionEnd != 0 {
+		sessionEnd = time.UnixMilli(s.SessionEnd)
+	}
+
+	if restore {
+		// The restrictions are those the loaded client was created with, see readClientOptions.
+		s.Restrictions.AllowAccountTxs = true
+		restricted := c.WithKeyManager(c.GetKeyManager())
+		restricted.AddTxCheck(s.Restrictions.Check)
+		if !replaceClient(defaultClientIndex, c, restricted) {
+			return false, fmt.Errorf("client was replaced while the session was imported")
+		}
+		setClientPolicy(defaultClientIndex, s.Restrictions)
+		restrictionsRestored = true
+	}
+	return restrictionsRestored, nil
+}
+
+func jsExportSession(this js.Value, args []js.Value) any {
+	session, err := ExportSession()
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"session": session, "error": ""})
+}
+
+// jsImportSession expects (session), a string returned by ExportSession, once the same client was created again.
+func jsImportSession(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "ImportSession expects 1 arg: session"})
+	}
+
+	restored, err := ImportSession(args[0].String())
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"restrictionsRestored": restored, "error": ""})
+}
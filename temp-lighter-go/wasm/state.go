@@ -0,0 +1,11 @@
+package main
+
+import "sync"
+
+// stateMu guards the state shared by every client: positions, referencePrices, priceFeed, marketRules,
+// ownOrders, signedOrders, clientPolicies, addressBook, memoTemplates, nonceRejections, exchangeHalt,
+// expiryWatch, activeProfile, confirmation, responseCasing, pendingTxs, pendingRotation and the client
+// registry: txClient, clients and sessionClients. Promise bodies run on their own goroutines
+// and interleave with the handlers at every network round trip, so each accessor holds it for its own access
+// only, and never while calling into JS, which may call back into the module. Helpers named *Locked expect the caller to hold it.
+var stateMu sync.RWMutex
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// strategyOrder is a resting order recognized as placed by a strategy.
+type strategyOrder struct {
+	*trackedOrder
+	Slot uint32
+}
+
+// StrategyOrders returns the resting orders of accountIndex whose client order index is derived from strategyID
+// and one of its first slots slots, by market and slot. Only the orders known to the module are considered, see
+// FetchOpenOrders.
+func StrategyOrders(accountIndex int64, strategyID string, slots uint32) ([]strategyOrder, error) {
+	bySlot := map[uint8]map[int64]uint32{}
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+
+	var res []strategyOrder
+	for _, o := range ownOrders[accountIndex] {
+		if o.ClientOrderIndex == txtypes.NilClientOrderIndex {
+			continue
+		}
+		if bySlot[o.MarketIndex] == nil {
+			m, err := types.StrategySlots(strategyID, o.MarketIndex, slots)
+			if err != nil {
+				return nil, err
+			}
+			bySlot[o.MarketIndex] = m
+		}
+		if slot, ok := bySlot[o.MarketIndex][o.ClientOrderIndex]; ok {
+			res = append(res, strategyOrder{o, slot})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].MarketIndex != res[j].MarketIndex {
+			return res[i].MarketIndex < res[j].MarketIndex
+		}
+		return res[i].Slot < res[j].Slot
+	})
+	return res, nil
+}
+
+// jsDeriveClientOrderIndex expects (strategyId, marketIndex, slot) and returns {clientOrderIndex}, the same for
+// the same arguments across restarts.
+func jsDeriveClientOrderIndex(this js.Value, args []js.Value) any {
+	if len(args) < 3 {
+		return js.ValueOf(map[string]any{"error": "DeriveClientOrderIndex expects 3 args: strategyId, marketIndex, slot"})
+	}
+	if args[0].Type() != js.TypeString {
+		return js.ValueOf(map[string]any{"error": "strategyId should be a string"})
+	}
+	marketIndex, err := intArg("marketIndex", args[1], 0, int64(txtypes.MaxMarketIndex))
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	slot, err := intArg("slot", args[2], 0, types.MaxStrategySlots-1)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+
+	index, err := types.DeriveClientOrderIndex(args[0].String(), uint8(marketIndex), uint32(slot))
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"clientOrderIndex": index, "error": ""})
+}
+
+// jsAdoptStrategyOrders expects (strategyId, slots, clientIndex?) and returns {orders}, the resting orders of the
+// client's account placed by the strategy with one of its first slots slots, each with its slot. A restarted
+// process fetches the open orders first, then adopts these instead of orphaning them.
+func jsAdoptStrategyOrders(this js.Value, args []js.Value) any {
+	if len(args) < 2 {
+		return js.ValueOf(map[string]any{"error": "AdoptStrategyOrders expects at least 2 args: strategyId, slots"})
+	}
+	c, err := clientFromArgs(args, 2)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	if args[0].Type() != js.TypeString {
+		return js.ValueOf(map[string]any{"error": "strategyId should be a string"})
+	}
+	slots, err := intArg("slots", args[1], 1, types.MaxStrategySlots)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+
+	orders, err := StrategyOrders(c.GetAccountIndex(), args[0].String(), uint32(slots))
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	res := make([]any, 0, len(orders))
+	for _, o := range orders {
+		res = append(res, map[string]any{
+			"marketIndex":      o.MarketIndex,
+			"slot":             o.Slot,
+			"clientOrderIndex": o.ClientOrderIndex,
+			"orderIndex":       o.OrderIndex,
+			"isAsk":            o.IsAsk,
+			"price":            o.Price,
+			"baseAmount":       o.BaseAmount,
+			"confirmed":        o.Confirmed,
+		})
+	}
+	if len(res) > 0 {
+		logEvent("info", "strategy.adopted", fmt.Sprintf("adopted %d orders of strategy %q", len(res), args[0].String()), map[string]any{"accountIndex": c.GetAccountIndex()})
+	}
+	return js.ValueOf(map[string]any{"orders": res, "error": ""})
+}
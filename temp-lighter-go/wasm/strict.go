@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"syscall/js"
+)
+
+// errMockPathDisabled is returned by the demo and diagnostic paths while strict mode is on: seeded key
+// generation and the exports signing with throwaway clients.
+var errMockPathDisabled = errors.New("mock path disabled in strict mode")
+
+// strictMode is set from startup in builds tagged strict, or by EnableStrictMode. It is never cleared.
+var strictMode atomic.Bool
+
+func init() {
+	if strictBuild {
+		strictMode.Store(true)
+	}
+}
+
+// checkNotMock refuses to run the demo or diagnostic path named path while strict mode is on.
+func checkNotMock(path string) error {
+	if strictMode.Load() {
+		return fmt.Errorf("%w: %s", errMockPathDisabled, path)
+	}
+	return nil
+}
+
+// jsEnableStrictMode expects () and returns {strict, strictBuild}. From then on, the demo and diagnostic paths
+// fail with code MOCK_PATH_DISABLED; there is no way back short of reloading the module.
+func jsEnableStrictMode(this js.Value, args []js.Value) any {
+	strictMode.Store(true)
+	logEvent("info", "strict.enabled", "strict mode enabled", nil)
+	return js.ValueOf(map[string]any{"strict": true, "strictBuild": strictBuild, "error": ""})
+}
+
+// jsAssertStrictMode expects () and returns {strict, strictBuild}, with an error unless strict mode is on, so
+// that hosts handling real funds can refuse to start otherwise.
+func jsAssertStrictMode(this js.Value, args []js.Value) any {
+	res := map[string]any{"strict": strictMode.Load(), "strictBuild": strictBuild, "error": ""}
+	if !strictMode.Load() {
+		res["error"] = "strict mode is off: build with -tags strict or call EnableStrictMode"
+	}
+	return js.ValueOf(res)
+}
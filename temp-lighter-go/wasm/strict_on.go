@@ -0,0 +1,6 @@
+//go:build strict
+
+package main
+
+// strictBuild is set for builds tagged strict, which start in strict mode.
+const strictBuild = true
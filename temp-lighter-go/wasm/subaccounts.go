@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+)
+
+// accountFromArgs resolves the optional accountIndex argument at position i, letting a master client sign on
+// behalf of its sub-accounts. It defaults to the client's own account.
+func accountFromArgs(c *client.TxClient, args []js.Value, i int) (int64, error) {
+	if len(args) <= i || args[i].Type() != js.TypeNumber {
+		return c.GetAccountIndex(), nil
+	}
+
+	accountIndex := int64(args[i].Int())
+	if !c.ControlsAccount(accountIndex) {
+		return 0, fmt.Errorf("account %d is not a sub-account of %d", accountIndex, c.GetAccountIndex())
+	}
+	return accountIndex, nil
+}
+
+// SetSubAccounts replaces the sub-accounts the client at clientIndex may sign for. The list is the caller's: the
+// exchange is not asked whether the accounts are sub-accounts of the client's.
+func SetSubAccounts(clientIndex int, accountIndexes []int64) error {
+	c, err := getClient(clientIndex)
+	if err != nil {
+		return err
+	}
+	if !replaceClient(clientIndex, c, c.WithSubAccounts(accountIndexes)) {
+		return fmt.Errorf("client %d was replaced while its sub-accounts were set", clientIndex)
+	}
+	return nil
+}
+
+// SignSubAccountTransfer signs, with the master client's key, a transfer between two accounts the client
+// controls: its own account and the sub-accounts registered with SetSubAccounts. Those are trusted as given, a
+// transfer to an account which is not a sub-account is signed all the same and refused by the exchange at best. o,
+// when set, overrides the TransactOpts, see transactOpts; its accountIndex replaces fromAccountIndex.
+func SignSubAccountTransfer(clientIndex int, fromAccountIndex, toAccountIndex, usdcAmount, fee int64, memo [32]byte, nonce int64, o *txOverrides) (string, error) {
+	c, err := getClient(clientIndex)
+	if err != nil {
+		return "", err
+	}
+	ops, err := transactOpts(c, fromAccountIndex, nonce, o)
+	if err != nil {
+		return "", err
+	}
+	fromAccountIndex = *ops.FromAccountIndex
+	if fromAccountIndex == toAccountIndex {
+		return "", fmt.Errorf("from and to accounts should differ")
+	}
+	if !c.ControlsAccount(fromAccountIndex) {
+		return "", fmt.Errorf("account %d is not a sub-account of %d", fromAccountIndex, c.GetAccountIndex())
+	}
+	if !c.ControlsAccount(toAccountIndex) {
+		return "", fmt.Errorf("account %d is not a sub-account of %d", toAccountIndex, c.GetAccountIndex())
+	}
+
+	req := &types.TransferTxReq{
+		ToAccountIndex: toAccountIndex,
+		USDCAmount:     usdcAmount,
+		Fee:            fee,
+		Memo:           memo,
+	}
+	txInfoObj, err := c.GetTransferTransaction(req, ops)
+	if err != nil {
+		return "", err
+	}
+	return formatTxInfo(txInfoObj)
+}
+
+// jsSetSubAccounts expects (accountIndexes, clientIndex?). The accounts are not checked against the exchange, see
+// SetSubAccounts.
+func jsSetSubAccounts(this js.Value, args []js.Value) any {
+	if len(args) < 1 || args[0].Type() != js.TypeObject {
+		return js.ValueOf(map[string]any{"error": "SetSubAccounts expects 1 arg: accountIndexes"})
+	}
+	clientIndex := defaultClientIndex
+	if len(args) > 1 && args[1].Type() == js.TypeNumber {
+		clientIndex = args[1].Int()
+	}
+
+	accountIndexes := make([]int64, 0, args[0].Length())
+	for i := 0; i < args[0].Length(); i++ {
+		accountIndexes = append(accountIndexes, int64(args[0].Index(i).Int()))
+	}
+	if err := SetSubAccounts(clientIndex, accountIndexes); err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"error": ""})
+}
+
+// jsSignSubAccountTransfer expects (fromAccountIndex, toAccountIndex, usdcAmount, fee, memo, nonce, clientIndex?,
+// options?). memo is free text or {template, vars}, see SignTransfer; options are the TransactOpts overrides, see
+// SignCreateOrder.
+func jsSignSubAccountTransfer(this js.Value, args []js.Value) any {
+	if len(args) < 6 {
+		return js.ValueOf(map[string]any{"error": "SignSubAccountTransfer expects 6 args"})
+	}
+
+	clientIndex := defaultClientIndex
+	if len(args) > 6 && args[6].Type() == js.TypeNumber {
+		clientIndex = args[6].Int()
+	}
+
+	memo, err := memoArg("memo", args[4])
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	o, err := txOverridesFromArgs(args, 7)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	txInfo, err := SignSubAccountTransfer(clientIndex, int64(args[0].Int()), int64(args[1].Int()), int64(args[2].Int()), int64(args[3].Int()), memo, int64(args[5].Int()), o)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"txInfo": txInfo, "error": ""})
+}
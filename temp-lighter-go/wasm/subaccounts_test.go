@@ -0,0 +1,49 @@
+package main
+
+import (
+	"syscall/js"
+	"testing"
+)
+
+func TestSetSubAccounts(t *testing.T) {
+	privateKey, _, errStr := GenerateAPIKey("")
+	if errStr != "" {
+		t.Fatal(errStr)
+	}
+	res := js.ValueOf(jsCreateClient(js.Undefined(), []js.Value{js.ValueOf(privateKey), js.ValueOf(5), js.ValueOf(2), js.ValueOf(300)}))
+	if errStr := res.Get("error").String(); errStr != "" {
+		t.Fatal(errStr)
+	}
+	defer setDefaultClient(nil)
+	before, _ := getClient(defaultClientIndex)
+
+	res = js.ValueOf(jsSetSubAccounts(js.Undefined(), []js.Value{js.ValueOf([]any{6, 7})}))
+	if errStr := res.Get("error").String(); errStr != "" {
+		t.Fatal(errStr)
+	}
+	after, _ := getClient(defaultClientIndex)
+	if after == before {
+		t.Fatal("the client was changed in place")
+	}
+	if before.ControlsAccount(6) {
+		t.Error("the client in use before SetSubAccounts controls account 6")
+	}
+	if !after.ControlsAccount(6) || !after.ControlsAccount(7) {
+		t.Error("the client does not control the sub-accounts set")
+	}
+	if _, err := SignSubAccountTransfer(defaultClientIndex, 6, 7, 1000000, 0, [32]byte{}, 1, nil); err != nil {
+		t.Error(err)
+	}
+	if _, err := SignSubAccountTransfer(defaultClientIndex, 6, 8, 1000000, 0, [32]byte{}, 1, nil); err == nil {
+		t.Error("a transfer to an account which is not a sub-account was signed")
+	}
+
+	cloneIndex, err := CloneClient(defaultClientIndex, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unregisterClient(cloneIndex)
+	if err := SetSubAccounts(cloneIndex, []int64{10}); err == nil {
+		t.Error("the sub-accounts of a dropped client were set")
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// secondsMillisBoundary separates bare numbers that look like unix seconds from ones that look like unix
+// milliseconds: 1e11 seconds is in the year 5138, 1e11 milliseconds in 1973.
+const secondsMillisBoundary = 100_000_000_000
+
+// numericTimeUnit, when set through SetTimeUnit, is how every bare number passed as a time parameter is read.
+// When it is zero, bare numbers are only accepted if they look like the parameter's own unit.
+var numericTimeUnit time.Duration
+
+// normalizeTimes, set through SetTimeNormalization, makes implausible timestamps be converted between seconds,
+// milliseconds and microseconds with a warning instead of being rejected.
+var normalizeTimes bool
+
+// parseTimeParam reads the time parameter name passed from JS and returns it in unit, counted from the epoch.
+// v may be an ISO-8601 timestamp ("2025-01-02T15:04:05Z"), a duration from now ("15m", "24h", "7d"), a number
+// with a unit suffix ("1735830245s", "1735830245000ms") or a bare number. Bare numbers equal to one of
+// sentinels, e.g. 0 for no expiry, are returned unchanged. Every other value has to fall in the plausible range
+// of txtypes.CheckTimestamp.
+func parseTimeParam(name string, v js.Value, unit time.Duration, sentinels ...int64) (int64, error) {
+	var ms int64
+	switch v.Type() {
+	case js.TypeNumber:
+		n := int64(v.Float())
+		for _, s := range sentinels {
+			if n == s {
+				return n, nil
+			}
+		}
+		var err error
+		if ms, err = bareTimeToMillis(name, n, unit); err != nil {
+			return 0, err
+		}
+	case js.TypeString:
+		t, err := parseTimeString(v.String(), client.Now())
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		ms = t.UnixMilli()
+	default:
+		return 0, fmt.Errorf("invalid %s: expected a number or a string, got %s", name, v.Type())
+	}
+
+	ms, err := checkTimestamp(name, ms)
+	if err != nil {
+		return 0, err
+	}
+	return convertTimeUnit(ms, time.Millisecond, unit), nil
+}
+
+func bareTimeToMillis(name string, n int64, unit time.Duration) (int64, error) {
+	if numericTimeUnit != 0 {
+		return convertTimeUnit(n, numericTimeUnit, time.Millisecond), nil
+	}
+	looksLikeMillis := n >= secondsMillisBoundary
+	if looksLikeMillis != (unit == time.Millisecond) && !normalizeTimes {
+		suffix := "s"
+		if looksLikeMillis {
+			suffix = "ms"
+		}
+		return 0, fmt.Errorf("ambiguous %s %d: it is read in %s, pass a string with an explicit unit such as \"%d%s\" or an ISO-8601 timestamp, or call SetTimeUnit", name, n, unitName(unit), n, suffix)
+	}
+	return convertTimeUnit(n, unit, time.Millisecond), nil
+}
+
+// convertTimeUnit converts n from one unit to another without going through nanoseconds, which would overflow
+// for large timestamps.
+func convertTimeUnit(n int64, from, to time.Duration) int64 {
+	if from >= to {
+		return n * int64(from/to)
+	}
+	return n / int64(to/from)
+}
+
+// checkTimestamp rejects ms when it is implausible or, with normalizeTimes, converts it and warns.
+func checkTimestamp(name string, ms int64) (int64, error) {
+	if !normalizeTimes {
+		return ms, txtypes.CheckTimestamp(name, ms)
+	}
+	normalized, ok, err := txtypes.NormalizeTimestamp(name, ms)
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		warn(fmt.Sprintf("%s %d normalized to %d milliseconds", name, ms, normalized))
+	}
+	return normalized, nil
+}
+
+// warn reports a recoverable problem with the caller's input to the logger, or on the host console.
+func warn(msg string) {
+	logEvent("warn", "warning", msg, nil)
+}
+
+// parseTimeString parses an ISO-8601 timestamp, a duration relative to now or a number suffixed by s or ms.
+// Suffixed numbers too small to be a timestamp after 1973, like "30s", are read as durations.
+func parseTimeString(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	if n, ok := strings.CutSuffix(s, "ms"); ok {
+		if ms, err := strconv.ParseInt(n, 10, 64); err == nil && ms >= secondsMillisBoundary {
+			return time.UnixMilli(ms), nil
+		}
+	}
+	if n, ok := strings.CutSuffix(s, "s"); ok {
+		if sec, err := strconv.ParseInt(n, 10, 64); err == nil && sec >= secondsMillisBoundary/1000 {
+			return time.Unix(sec, 0), nil
+		}
+	}
+	if n, ok := strings.CutSuffix(s, "d"); ok {
+		if days, err := strconv.ParseInt(n, 10, 64); err == nil {
+			return now.Add(time.Duration(days) * 24 * time.Hour), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(d), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is neither an ISO-8601 timestamp, a duration nor a number with an s or ms suffix", s)
+}
+
+func unitName(unit time.Duration) string {
+	if unit == time.Second {
+		return "seconds"
+	}
+	return "milliseconds"
+}
+
+// jsSetTimeUnit expects ("s" | "ms" | ""). An empty unit restores the default of inferring it per parameter.
+func jsSetTimeUnit(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "SetTimeUnit expects 1 arg: unit"})
+	}
+
+	switch args[0].String() {
+	case "s":
+		numericTimeUnit = time.Second
+	case "ms":
+		numericTimeUnit = time.Millisecond
+	case "":
+		numericTimeUnit = 0
+	default:
+		return js.ValueOf(map[string]any{"error": wrapErr(fmt.Errorf("unknown time unit: %s", args[0].String()))})
+	}
+	return js.ValueOf(map[string]any{"error": ""})
+}
+
+// jsSetTimeNormalization expects (enabled). When enabled, timestamps off by a factor of 1000 are converted with a
+// warning instead of being rejected.
+func jsSetTimeNormalization(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "SetTimeNormalization expects 1 arg: enabled"})
+	}
+	normalizeTimes = args[0].Truthy()
+	return js.ValueOf(map[string]any{"error": ""})
+}
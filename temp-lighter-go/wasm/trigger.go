@@ -0,0 +1,27 @@
+package main
+
+import (
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// checkTriggerDirection is installed on every client created from JS, see installChecks. Trigger prices are
+// checked against the reference prices of their market when one is set, against the order's own price always.
+func checkTriggerDirection(tx txtypes.TxInfo) error {
+	var orders []*txtypes.OrderInfo
+	switch tx := tx.(type) {
+	case *txtypes.L2CreateOrderTxInfo:
+		orders = []*txtypes.OrderInfo{tx.OrderInfo}
+	case *txtypes.L2CreateGroupedOrdersTxInfo:
+		orders = tx.Orders
+	}
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	for _, order := range orders {
+		ref := referencePrices[order.MarketIndex]
+		if err := types.CheckTriggerDirection(order, ref[0], ref[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
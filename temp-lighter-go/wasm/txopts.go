@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// txOptsSchema is the per-call override object of the signing exports. Every field is optional.
+var txOptsSchema = objectSchema{
+	"accountIndex": intField(txtypes.MinAccountIndex, txtypes.MaxAccountIndex),
+	"apiKeyIndex":  intField(0, int64(txtypes.MaxApiKeyIndex)),
+	"nonce":        intField(txtypes.MinNonce, math.MaxInt64),
+	"expiredAt":    intField(0, txtypes.MaxTimestamp),
+}
+
+// orderOptsSchema is txOptsSchema along with allowCross and intentHash, see allowCrossClient and intentClient.
+var orderOptsSchema = func() objectSchema {
+	s := objectSchema{"allowCross": {Type: "boolean"}, "intentHash": {Type: "string"}}
+	for k, f := range txOptsSchema {
+		s[k] = f
+	}
+	return s
+}()
+
+// txOverrides are the fields of a per-call override object.
+type txOverrides struct {
+	AccountIndex *int64 `json:"accountIndex"`
+	ApiKeyIndex  *uint8 `json:"apiKeyIndex"`
+	Nonce        *int64 `json:"nonce"`
+	ExpiredAt    *int64 `json:"expiredAt"`
+}
+
+// transactOpts returns the TransactOpts a signing export signs with c. Each field is taken, in order of
+// precedence, from the override object o, from the positional args of the export, here fromAcc and nonce, or
+// from the client: its api key index, and an expiry client.DefaultExpireTime from now. An account other than
+// the client's has to be one of its sub-accounts, wherever it comes from.
+func transactOpts(c *client.TxClient, fromAcc, nonce int64, o *txOverrides) (*types.TransactOpts, error) {
+	apiIdx := c.GetApiKeyIndex()
+	ops := &types.TransactOpts{FromAccountIndex: &fromAcc, ApiKeyIndex: &apiIdx, Nonce: &nonce}
+	if o == nil {
+		return ops, nil
+	}
+	if o.AccountIndex != nil {
+		if !c.ControlsAccount(*o.AccountIndex) {
+			return nil, fmt.Errorf("account %d is not a sub-account of %d", *o.AccountIndex, c.GetAccountIndex())
+		}
+		ops.FromAccountIndex = o.AccountIndex
+	}
+	if o.ApiKeyIndex != nil {
+		ops.ApiKeyIndex = o.ApiKeyIndex
+	}
+	if o.Nonce != nil {
+		ops.Nonce = o.Nonce
+	}
+	if o.ExpiredAt != nil {
+		ops.ExpiredAt = *o.ExpiredAt
+	}
+	return ops, nil
+}
+
+func readTxOverrides(args []js.Value, i int, s objectSchema) (*txOverrides, error) {
+	if len(args) <= i || args[i].Type() != js.TypeObject {
+		return nil, nil
+	}
+	o := &txOverrides{}
+	if err := decodeStrict("options", args[i], s, o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// txOverridesFromArgs reads the optional override object at position i, for the exports resolving their
+// TransactOpts themselves.
+func txOverridesFromArgs(args []js.Value, i int) (*txOverrides, error) {
+	return readTxOverrides(args, i, txOptsSchema)
+}
+
+// txOptsFromArgs resolves the TransactOpts of a signing export, see transactOpts, reading the optional override
+// object {accountIndex?, apiKeyIndex?, nonce?, expiredAt?} at position i.
+func txOptsFromArgs(c *client.TxClient, fromAcc, nonce int64, args []js.Value, i int) (*types.TransactOpts, error) {
+	o, err := readTxOverrides(args, i, txOptsSchema)
+	if err != nil {
+		return nil, err
+	}
+	return transactOpts(c, fromAcc, nonce, o)
+}
+
+// orderOptsFromArgs is txOptsFromArgs for the order exports, whose options also hold allowCross.
+func orderOptsFromArgs(c *client.TxClient, fromAcc, nonce int64, args []js.Value, i int) (*types.TransactOpts, error) {
+	o, err := readTxOverrides(args, i, orderOptsSchema)
+	if err != nil {
+		return nil, err
+	}
+	return transactOpts(c, fromAcc, nonce, o)
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+)
+
+// defaultTxQueueSize bounds the offline queue until ConfigureTxQueue is called.
+const defaultTxQueueSize = 100
+
+// queuedTx is a signed tx waiting for connectivity to be submitted through http, the HTTP client of the client
+// submitting it.
+type queuedTx struct {
+	http      *client.HTTPClient
+	tx        txtypes.TxInfo
+	txHash    string
+	queuedAt  time.Time
+	expiresAt time.Time
+}
+
+// txQueue holds the signed txs SubmitTx could not send because the network was down, oldest first, so that
+// the nonces of each sender stay in order.
+var txQueue = struct {
+	mu        sync.Mutex
+	txs       []*queuedTx
+	maxSize   int
+	onEvent   js.Value
+	listening bool
+}{maxSize: defaultTxQueueSize}
+
+// emitTxQueueEvent passes {event, txHash, txType, ...fields} to the callback set through ConfigureTxQueue.
+func emitTxQueueEvent(event string, q *queuedTx, fields map[string]any) {
+	entry := map[string]any{"event": event, "txHash": q.txHash, "txType": q.tx.GetTxType()}
+	for k, v := range fields {
+		entry[k] = v
+	}
+	logEvent("info", "txQueue."+event, fmt.Sprintf("tx %s %s", q.txHash, event), entry)
+
+	txQueue.mu.Lock()
+	onEvent := txQueue.onEvent
+	txQueue.mu.Unlock()
+	if onEvent.Type() == js.TypeFunction {
+		onEvent.Invoke(js.ValueOf(entry))
+	}
+}
+
+// enqueueTx queues tx, signed by c, until it expires.
+func enqueueTx(c *client.TxClient, tx txtypes.TxInfo, txHash string) error {
+	expiredAt, err := types.ExpiredAtOf(tx)
+	if err != nil {
+		return err
+	}
+	q := &queuedTx{http: c.HTTP(), tx: tx, txHash: txHash, queuedAt: time.Now(), expiresAt: time.UnixMilli(expiredAt)}
+	if !c.Now().Before(q.expiresAt) {
+		return fmt.Errorf("tx expired at %s, not queuing it", q.expiresAt.UTC().Format(time.RFC3339))
+	}
+
+	txQueue.mu.Lock()
+	if len(txQueue.txs) >= txQueue.maxSize {
+		txQueue.mu.Unlock()
+		return fmt.Errorf("tx queue is full (%d txs)", txQueue.maxSize)
+	}
+	txQueue.txs = append(txQueue.txs, q)
+	txQueue.mu.Unlock()
+
+	emitTxQueueEvent("queued", q, map[string]any{"expiresAt": q.expiresAt.UnixMilli()})
+	listenOnline()
+	return nil
+}
+
+// FlushTxQueue submits the queued txs, oldest first. The txs expired by clock are dropped, as are the ones the
+// exchange rejects; flushing stops at the first network error or while the exchange is halted, leaving the remaining txs
+// queued.
+func FlushTxQueue(clock callClock) (flushed, expired, rejected, remaining int) {
+	for {
+		txQueue.mu.Lock()
+		if len(txQueue.txs) == 0 {
+			txQueue.mu.Unlock()
+			return flushed, expired, rejected, 0
+		}
+		q, queued := txQueue.txs[0], len(txQueue.txs)
+		txQueue.mu.Unlock()
+		if checkNotHalted() != nil {
+			return flushed, expired, rejected, queued
+		}
+
+		var netErr *client.NetworkError
+		if !clock.Now().Before(q.expiresAt) {
+			expired++
+			emitTxQueueEvent("expired", q, nil)
+		} else if q.http == nil {
+			rejected++
+			emitTxQueueEvent("rejected", q, map[string]any{"error": "HTTP client not configured"})
+		} else if _, err := q.http.SendRawTx(q.tx); errors.As(err, &netErr) || client.IsHaltError(err) {
+			noteExchangeStatus(err)
+			txQueue.mu.Lock()
+			remaining = len(txQueue.txs)
+			txQueue.mu.Unlock()
+			return flushed, expired, rejected, remaining
+		} else {
+			if err != nil {
+				rejected++
+				emitTxQueueEvent("rejected", q, map[string]any{"error": wrapErr(err)})
+			} else {
+				flushed++
+				emitTxQueueEvent("flushed", q, nil)
+			}
+			emitSubmission(q.tx, q.txHash, err)
+		}
+
+		txQueue.mu.Lock()
+		if len(txQueue.txs) > 0 && txQueue.txs[0] == q {
+			txQueue.txs = txQueue.txs[1:]
+		}
+		txQueue.mu.Unlock()
+	}
+}
+
+// listenOnline flushes the queue whenever the host reports that connectivity came back, in runtimes having an
+// "online" event.
+func listenOnline() {
+	txQueue.mu.Lock()
+	defer txQueue.mu.Unlock()
+	if txQueue.listening || js.Global().Get("addEventListener").Type() != js.TypeFunction {
+		return
+	}
+	txQueue.listening = true
+	js.Global().Call("addEventListener", "online", js.FuncOf(func(this js.Value, args []js.Value) any {
+		done := trackTask(taskAsync, "online")
+		go func() {
+			defer done()
+			FlushTxQueue(callClock{})
+		}()
+		return nil
+	}))
+}
+
+// jsSubmitTx expects (txType, txInfo, options?, clientIndex?) and returns a Promise. txInfo is a signed tx; it is
+// sent through the client's HTTP client. options is {queueOnFailure?}: when set and the network is down or the
+// exchange halted, the tx is queued until it expires, to be sent by FlushTxQueue, when the host goes back online
+// or when the exchange resumes. Otherwise submissions fail fast with code EXCHANGE_HALTED during a halt.
+func jsSubmitTx(this js.Value, args []js.Value) any {
+	if len(args) < 2 {
+		return js.ValueOf(map[string]any{"error": "SubmitTx expects at least 2 args: txType, txInfo"})
+	}
+	c, err := clientFromArgs(args, 3)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	if c.HTTP() == nil {
+		return js.ValueOf(map[string]any{"error": "HTTP client not configured"})
+	}
+	if args[0].Type() != js.TypeNumber || args[0].Int() < 0 || args[0].Int() > 255 {
+		return js.ValueOf(map[string]any{"error": "txType should be an integer between 0 and 255"})
+	}
+	tx, err := types.DecodeSignedTx(uint8(args[0].Int()), args[1].String())
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	msgHash, err := tx.Hash(c.GetChainId())
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	txHash := ethCommon.Bytes2Hex(msgHash)
+	queueOnFailure := len(args) > 2 && args[2].Type() == js.TypeObject && args[2].Get("queueOnFailure").Truthy()
+	if err := checkNotHalted(); err != nil && !queueOnFailure {
+		return js.ValueOf(errorResult(err))
+	}
+
+	return newPromise(func(clock callClock) map[string]any {
+		err := checkNotHalted()
+		if err == nil {
+			var res string
+			res, err = c.HTTP().SendRawTx(tx)
+			emitSubmission(tx, txHash, err)
+			if err == nil {
+				return map[string]any{"txHash": res, "queued": false, "error": ""}
+			}
+			if client.IsHaltError(err) {
+				err = fmt.Errorf("%w: %v", errExchangeHalted, err)
+			}
+		}
+		var netErr *client.NetworkError
+		if !queueOnFailure || !errors.As(err, &netErr) && !errors.Is(err, errExchangeHalted) {
+			return errorResult(err)
+		}
+		if qErr := enqueueTx(clock.bind(c), tx, txHash); qErr != nil {
+			return map[string]any{"error": wrapErr(fmt.Errorf("%w; %v", err, qErr))}
+		}
+		return map[string]any{"txHash": txHash, "queued": true, "error": ""}
+	})
+}
+
+// jsFlushTxQueue returns a Promise sending the queued txs, see FlushTxQueue.
+func jsFlushTxQueue(this js.Value, args []js.Value) any {
+	return newPromise(func(clock callClock) map[string]any {
+		flushed, expired, rejected, remaining := FlushTxQueue(clock)
+		return map[string]any{
+			"flushed":   flushed,
+			"expired":   expired,
+			"rejected":  rejected,
+			"remaining": remaining,
+			"error":     "",
+		}
+	})
+}
+
+// jsConfigureTxQueue expects (maxSize, onEvent?). onEvent receives {event, txHash, txType, ...} for every tx
+// "queued", "flushed", "expired" or "rejected". Shrinking the queue below its length drops its newest txs.
+func jsConfigureTxQueue(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "ConfigureTxQueue expects at least 1 arg: maxSize"})
+	}
+	if args[0].Type() != js.TypeNumber || args[0].Int() < 0 || args[0].Int() > 10_000 {
+		return js.ValueOf(map[string]any{"error": "maxSize should be an integer between 0 and 10000"})
+	}
+
+	txQueue.mu.Lock()
+	defer txQueue.mu.Unlock()
+	txQueue.maxSize = args[0].Int()
+	if len(txQueue.txs) > txQueue.maxSize {
+		txQueue.txs = txQueue.txs[:txQueue.maxSize]
+	}
+	txQueue.onEvent = js.Undefined()
+	if len(args) > 1 && args[1].Type() == js.TypeFunction {
+		txQueue.onEvent = args[1]
+	}
+	return js.ValueOf(map[string]any{"maxSize": txQueue.maxSize, "length": len(txQueue.txs), "error": ""})
+}
+
+// jsGetTxQueue lists the queued txs, oldest first.
+func jsGetTxQueue(this js.Value, args []js.Value) any {
+	txQueue.mu.Lock()
+	defer txQueue.mu.Unlock()
+
+	txs := make([]any, 0, len(txQueue.txs))
+	for _, q := range txQueue.txs {
+		txs = append(txs, map[string]any{
+			"txHash":    q.txHash,
+			"txType":    q.tx.GetTxType(),
+			"queuedAt":  q.queuedAt.UnixMilli(),
+			"expiresAt": q.expiresAt.UnixMilli(),
+		})
+	}
+	return js.ValueOf(map[string]any{"txs": txs, "maxSize": txQueue.maxSize, "error": ""})
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"runtime"
+	"runtime/debug"
+	"syscall/js"
+)
+
+// Set at build time through -ldflags "-X main.version=... -X main.commit=... -X main.buildTime=...", see
+// scripts/build-wasm.js. commit and buildTime fall back to the VCS info stamped by the go tool.
+var (
+	version   = "dev"
+	commit    = ""
+	buildTime = ""
+)
+
+// VersionInfo identifies the exact signer build.
+type VersionInfo struct {
+	Version          string
+	LighterGoVersion string
+	Commit           string
+	Modified         bool
+	BuildTime        string
+	GoVersion        string
+	Target           string
+	Dependencies     map[string]string
+}
+
+func GetVersion() VersionInfo {
+	info := VersionInfo{
+		Version:      version,
+		Commit:       commit,
+		BuildTime:    buildTime,
+		GoVersion:    runtime.Version(),
+		Target:       runtime.GOOS + "/" + runtime.GOARCH,
+		Dependencies: map[string]string{},
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.LighterGoVersion = bi.Main.Version
+	for _, dep := range bi.Deps {
+		info.Dependencies[dep.Path] = dep.Version
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = s.Value
+			}
+		case "vcs.time":
+			if info.BuildTime == "" {
+				info.BuildTime = s.Value
+			}
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
+
+func jsGetVersion(this js.Value, args []js.Value) any {
+	info := GetVersion()
+	deps := map[string]any{}
+	for path, v := range info.Dependencies {
+		deps[path] = v
+	}
+	return js.ValueOf(map[string]any{
+		"version":          info.Version,
+		"lighterGoVersion": info.LighterGoVersion,
+		"commit":           info.Commit,
+		"modified":         info.Modified,
+		"buildTime":        info.BuildTime,
+		"goVersion":        info.GoVersion,
+		"target":           info.Target,
+		"dependencies":     deps,
+		"error":            "",
+	})
+}
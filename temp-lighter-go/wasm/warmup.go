@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/client"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+var warmupOptionsSchema = objectSchema{
+	"nonce":   {Type: "boolean"},
+	"markets": {Type: "array", Min: 0, Max: int64(txtypes.MaxMarketIndex)},
+}
+
+// warmupOptions select what Warmup prefetches with the client.
+type warmupOptions struct {
+	Nonce   bool    `json:"nonce"`
+	Markets []uint8 `json:"markets"`
+}
+
+// warmSigning generates a throwaway key and signs, hashes and encodes a create order and a cancel with it, so
+// that the one-off initialization of the crypto primitives and encoders is not paid by the first real order.
+// Neither the loaded clients nor the statistics are touched.
+func warmSigning() error {
+	km, err := generateKey()
+	if err != nil {
+		return err
+	}
+	c := client.NewTxClientWithKeyManager(nil, km, 1, 0, benchmarkChainId)
+	fromAcc, apiIdx, nonce := c.GetAccountIndex(), c.GetApiKeyIndex(), int64(0)
+	ops := &types.TransactOpts{FromAccountIndex: &fromAcc, ApiKeyIndex: &apiIdx, Nonce: &nonce}
+
+	order, err := c.GetCreateOrderTransaction(&types.CreateOrderTxReq{
+		MarketIndex: 1,
+		BaseAmount:  1000,
+		Price:       100000,
+		Type:        txtypes.LimitOrder,
+		TimeInForce: txtypes.GoodTillTime,
+		OrderExpiry: time.Now().Add(time.Hour).UnixMilli(),
+	}, ops)
+	if err != nil {
+		return err
+	}
+	cancel, err := c.GetCancelOrderTransaction(&types.CancelOrderTxReq{MarketIndex: 1, Index: 1}, ops)
+	if err != nil {
+		return err
+	}
+	for _, tx := range []txtypes.TxInfo{order, cancel} {
+		if _, err := tx.GetTxInfo(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Warmup pays the first-use costs of signing ahead of the first real order and, with c, prefetches what opts
+// selects: the next nonce of the client's key, returned as it is not cached, and the rules of markets, loaded
+// as by FetchMarketRules, listed in loaded. The prefetches that failed are reported in errs.
+func Warmup(c *client.TxClient, opts warmupOptions) (signTime time.Duration, nonce int64, loaded []uint8, errs []string) {
+	start := time.Now()
+	if err := warmSigning(); err != nil {
+		errs = append(errs, "sign: "+err.Error())
+	}
+	signTime, nonce = time.Since(start), -1
+
+	if opts.Nonce {
+		n, err := c.HTTP().GetNextNonce(c.GetAccountIndex(), c.GetApiKeyIndex())
+		if err != nil {
+			errs = append(errs, "nonce: "+err.Error())
+		} else {
+			nonce = n
+		}
+	}
+	for _, marketIndex := range opts.Markets {
+		if _, err := FetchMarketRules(c, marketIndex); err != nil {
+			errs = append(errs, fmt.Sprintf("market %d: %v", marketIndex, err))
+			continue
+		}
+		loaded = append(loaded, marketIndex)
+	}
+	return signTime, nonce, loaded, errs
+}
+
+// jsWarmup expects (options?, clientIndex?) and returns a Promise resolving to {elapsedMs, signMs, nonce,
+// markets}. options is {nonce?, markets?}: nonce fetches the next nonce of the client's key, -1 otherwise, and
+// markets lists the markets whose rules are loaded; the result lists the ones that were. The client is only needed to prefetch. error names every
+// prefetch that failed; the others still took effect.
+func jsWarmup(this js.Value, args []js.Value) any {
+	var opts warmupOptions
+	if len(args) > 0 && args[0].Type() == js.TypeObject {
+		if err := decodeStrict("options", args[0], warmupOptionsSchema, &opts); err != nil {
+			return js.ValueOf(errorResult(err))
+		}
+	}
+	var c *client.TxClient
+	if opts.Nonce || len(opts.Markets) > 0 {
+		var err error
+		if c, err = clientFromArgs(args, 1); err != nil {
+			return js.ValueOf(map[string]any{"error": wrapErr(err)})
+		}
+		if c.HTTP() == nil {
+			return js.ValueOf(map[string]any{"error": "HTTP client not configured, cannot prefetch"})
+		}
+	}
+
+	return newPromise(func(callClock) map[string]any {
+		start := time.Now()
+		signTime, nonce, loaded, errs := Warmup(c, opts)
+		markets := make([]any, len(loaded))
+		for i, marketIndex := range loaded {
+			markets[i] = marketIndex
+		}
+		return map[string]any{
+			"elapsedMs": time.Since(start).Milliseconds(),
+			"signMs":    signTime.Milliseconds(),
+			"nonce":     nonce,
+			"markets":   markets,
+			"error":     strings.Join(errs, "; "),
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// defaultWithdrawalFeeValidity is how long a fee given to SetWithdrawalFee is trusted when no validity is set.
+const defaultWithdrawalFeeValidity = 5 * time.Minute
+
+// withdrawalFeeQuote is the fee SignWithdraw checks the caller's fee against.
+type withdrawalFeeQuote struct {
+	Fee          int64
+	ToleranceBps uint32
+	ExpiresAt    time.Time
+}
+
+var withdrawalFee *withdrawalFeeQuote
+
+var withdrawalFeeOptionsSchema = objectSchema{
+	"toleranceBps":    intField(0, 10_000),
+	"validForSeconds": intField(1, 24*60*60),
+}
+
+// CheckWithdrawalFee checks fee against the quote set through SetWithdrawalFee, which must not have expired.
+func CheckWithdrawalFee(fee int64, now time.Time) error {
+	if withdrawalFee == nil {
+		return fmt.Errorf("no withdrawal fee quote, call SetWithdrawalFee first")
+	}
+	if !now.Before(withdrawalFee.ExpiresAt) {
+		return fmt.Errorf("withdrawal fee quote expired at %s, call SetWithdrawalFee again", withdrawalFee.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	return types.CheckWithdrawalFee(fee, withdrawalFee.Fee, withdrawalFee.ToleranceBps)
+}
+
+func withdrawalFeeResult(q *withdrawalFeeQuote) map[string]any {
+	return map[string]any{
+		"fee":          q.Fee,
+		"toleranceBps": q.ToleranceBps,
+		"expiresAt":    q.ExpiresAt.UnixMilli(),
+		"error":        "",
+	}
+}
+
+// jsSetWithdrawalFee expects (fee, options?). fee is the withdrawal fee currently charged by the exchange, in USDC
+// units or as a decimal string; null clears it. options is {toleranceBps?, validForSeconds?}: the fees accepted
+// by SignWithdraw may differ from it by toleranceBps, 0 by default, for validForSeconds, 300 by default.
+func jsSetWithdrawalFee(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return js.ValueOf(map[string]any{"error": "SetWithdrawalFee expects at least 1 arg: fee"})
+	}
+	if args[0].IsNull() || args[0].IsUndefined() {
+		withdrawalFee = nil
+		return js.ValueOf(map[string]any{"error": ""})
+	}
+
+	fee, err := amountArg("fee", args[0], usdcDecimals, int64(txtypes.MaxWithdrawalAmount))
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	var opts struct {
+		ToleranceBps    uint32 `json:"toleranceBps"`
+		ValidForSeconds int64  `json:"validForSeconds"`
+	}
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		if err := decodeStrict("options", args[1], withdrawalFeeOptionsSchema, &opts); err != nil {
+			return js.ValueOf(errorResult(err))
+		}
+	}
+	validFor := defaultWithdrawalFeeValidity
+	if opts.ValidForSeconds > 0 {
+		validFor = time.Duration(opts.ValidForSeconds) * time.Second
+	}
+
+	withdrawalFee = &withdrawalFeeQuote{Fee: fee, ToleranceBps: opts.ToleranceBps, ExpiresAt: time.Now().Add(validFor)}
+	return js.ValueOf(withdrawalFeeResult(withdrawalFee))
+}
+
+// jsSignWithdraw expects (usdcAmount, fee, nonce, clientIndex?, options?), options being the TransactOpts
+// overrides, see SignCreateOrder. fee is the withdrawal fee the caller expects to
+// pay; it is not part of the tx, but signing is refused unless it matches the quote set through SetWithdrawalFee,
+// so that no withdrawal is prepared against a stale fee.
+func jsSignWithdraw(this js.Value, args []js.Value) any {
+	if len(args) < 3 {
+		return js.ValueOf(map[string]any{"error": "SignWithdraw expects at least 3 args: usdcAmount, fee, nonce"})
+	}
+	c, err := clientFromArgs(args, 3)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+
+	usdcAmount, err := amountArg("usdcAmount", args[0], usdcDecimals, int64(txtypes.MaxWithdrawalAmount))
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	fee, err := amountArg("fee", args[1], usdcDecimals, int64(txtypes.MaxWithdrawalAmount))
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	if err := CheckWithdrawalFee(fee, time.Now()); err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	nonce := int64(args[2].Int())
+
+	ops, err := txOptsFromArgs(c, c.GetAccountIndex(), nonce, args, 4)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	txInfoObj, err := c.GetWithdrawTransaction(&types.WithdrawTxReq{USDCAmount: uint64(usdcAmount)}, ops)
+	if err != nil {
+		return js.ValueOf(errorResult(err))
+	}
+	txInfo, err := formatTxInfo(txInfoObj)
+	if err != nil {
+		return js.ValueOf(map[string]any{"error": wrapErr(err)})
+	}
+	return js.ValueOf(map[string]any{"txInfo": txInfo, "error": ""})
+}
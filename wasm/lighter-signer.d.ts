@@ -0,0 +1,1113 @@
+// Code generated by gendts from temp-lighter-go/wasm. DO NOT EDIT.
+
+export {};
+
+declare global {
+  /** Returned by every function on failure. violations lists each schema violation of an object argument. */
+  interface LighterErrorResult {
+    error: string;
+    code?: string;
+    violations?: string[];
+    suggested?: { baseAmount: number; price: number };
+  }
+
+  interface CreateClientResult {
+    clientIndex: number;
+    error: string;
+  }
+
+  /** expects (apiKey, accountIndex, apiKeyIndex, chainId, baseUrl?, options?). */
+  function CreateClient(apiKey: string, accountIndex: number, apiKeyIndex: number, chainId: number, baseUrl?: string | string[], options?: unknown): CreateClientResult | LighterErrorResult;
+
+  interface GenerateAPIKeyResult {
+    privateKey: string;
+    publicKey: string;
+    error: string;
+  }
+
+  function GenerateAPIKey(seed?: string): GenerateAPIKeyResult | LighterErrorResult;
+
+  interface SignCreateOrderResult {
+    txInfo: string;
+    error: string;
+  }
+
+  /** expects (marketIndex, clientOrderIndex, baseAmount, price, isAsk, orderType, timeInForce, reduceOnly, triggerPrice, orderExpiry, nonce, clientIndex?, accountIndex?, options?). options is {allowCross?, intentHash?}, intentHash being the hash HashOrderIntent returned for the order the user approved, required once RequireOrderIntent was called, along with the TransactOpts overrides {accountIndex?, apiKeyIndex?, nonce?, expiredAt?} every signing export accepts: each one set wins over the positional arg, which wins over the client's default. */
+  function SignCreateOrder(marketIndex: number, clientOrderIndex: number, baseAmount: number | string, price: number | string, isAsk: number, orderType: number, timeInForce: number, reduceOnly: number, triggerPrice: number | string, orderExpiry: number | string, nonce: number, clientIndex?: number, accountIndex?: number, options?: { allowCross?: boolean; intentHash?: string; accountIndex?: number; apiKeyIndex?: number; nonce?: number; expiredAt?: number }): SignCreateOrderResult | LighterErrorResult;
+
+  interface SignCancelOrderResult {
+    txInfo: string;
+    error: string;
+  }
+
+  /** expects (marketIndex, orderIndex, nonce, clientIndex?, accountIndex?, options?), options being the TransactOpts overrides, see SignCreateOrder. */
+  function SignCancelOrder(marketIndex: number, orderIndex: number, nonce: number, clientIndex?: number, accountIndex?: number, options?: { accountIndex?: number; apiKeyIndex?: number; nonce?: number; expiredAt?: number }): SignCancelOrderResult | LighterErrorResult;
+
+  interface SignCancelAllOrdersResult {
+    txInfo: string;
+    error: string;
+  }
+
+  /** expects (timeInForce, time, nonce, clientIndex?, accountIndex?, options?), options being the TransactOpts overrides, see SignCreateOrder. */
+  function SignCancelAllOrders(timeInForce: number, time: number | string, nonce: number, clientIndex?: number, accountIndex?: number, options?: { accountIndex?: number; apiKeyIndex?: number; nonce?: number; expiredAt?: number }): SignCancelAllOrdersResult | LighterErrorResult;
+
+  interface SignTransferResult {
+    txInfo: string;
+    error: string;
+  }
+
+  /** expects (toAccountIndex, usdcAmount, fee, memo, nonce, clientIndex?, options?). toAccountIndex may be the label of an address book entry, memo {template, vars} to render a memo template. options are the TransactOpts overrides, see SignCreateOrder. */
+  function SignTransfer(toAccountIndex: number | string, usdcAmount: number | string, fee: number | string, memo: string | { template: string; vars?: Record<string, string | number> }, nonce: number, clientIndex?: number, options?: { accountIndex?: number; apiKeyIndex?: number; nonce?: number; expiredAt?: number }): SignTransferResult | LighterErrorResult;
+
+  interface SignUpdateLeverageResult {
+    txInfo: string;
+    error: string;
+  }
+
+  /** expects (marketIndex, initialMarginFraction, marginMode, nonce, clientIndex?, options?), options being the TransactOpts overrides, see SignCreateOrder. */
+  function SignUpdateLeverage(marketIndex: number, initialMarginFraction: number, marginMode: number, nonce: number, clientIndex?: number, options?: { accountIndex?: number; apiKeyIndex?: number; nonce?: number; expiredAt?: number }): SignUpdateLeverageResult | LighterErrorResult;
+
+  interface CreateAuthTokenResult {
+    authToken: string;
+    error: string;
+  }
+
+  /** expects (deadline?, options?). options may bind the token to an origin and a session id; without them the legacy token format is produced. */
+  function CreateAuthToken(deadline?: number | string, options?: { origin?: string; sessionId?: string }): CreateAuthTokenResult | LighterErrorResult;
+
+  interface DecodeAuthTokenResult {
+    accountIndex: number;
+    apiKeyIndex: number;
+    deadline: number;
+    expired: boolean;
+    expiresIn: number;
+    origin: string;
+    sessionId: string;
+    version: number;
+    error: string;
+  }
+
+  /** expects (token). The token is decoded but its signature is not checked. */
+  function DecodeAuthToken(token: string): DecodeAuthTokenResult | LighterErrorResult;
+
+  interface VerifyAuthTokenResult {
+    accountIndex: number;
+    apiKeyIndex: number;
+    deadline: number;
+    expired: boolean;
+    expiresIn: number;
+    origin: string;
+    reason?: string;
+    sessionId: string;
+    valid?: boolean;
+    version: number;
+    error: string;
+  }
+
+  /** expects (token, publicKey, options?). The token must be signed by publicKey, unexpired and bound to exactly the origin and session id of options. */
+  function VerifyAuthToken(token: string, publicKey: string, options?: { origin?: string; sessionId?: string }): VerifyAuthTokenResult | LighterErrorResult;
+
+  /** expects (clientIndex?). */
+  function CheckClient(clientIndex?: number): LighterErrorResult;
+
+  interface RotateAPIKeyResult {
+    l1Message: string;
+    privateKey: string;
+    publicKey: string;
+    rotated: boolean;
+    txInfo: string;
+    error: string;
+  }
+
+  /** expects (nonce, confirm?) and returns {txInfo, l1Message, privateKey, publicKey, rotated}. When confirm is a function it is called synchronously with the rotation result and the rotation is committed if it returns a truthy value. */
+  function RotateAPIKey(nonce: number, confirm?: (...args: any[]) => any): RotateAPIKeyResult | LighterErrorResult;
+
+  function CommitAPIKeyRotation(): LighterErrorResult;
+
+  interface ExportPublicKeyResult {
+    fingerprint: string;
+    publicKey: string;
+    error: string;
+  }
+
+  function ExportPublicKey(privateKey?: string): ExportPublicKeyResult | LighterErrorResult;
+
+  interface GetKeyFingerprintResult {
+    fingerprint: string;
+    error: string;
+  }
+
+  function GetKeyFingerprint(publicKey: string): GetKeyFingerprintResult | LighterErrorResult;
+
+  interface ValidateAPIKeyPairResult {
+    derivedFingerprint: string;
+    derivedPublicKey: string;
+    expectedPublicKey: string;
+    match: boolean;
+    mismatches: string[];
+    registeredPublicKey: string;
+    error: string;
+  }
+
+  /** expects (privateKey, publicKey, accountIndex?, apiKeyIndex?) and returns a Promise, as checking the registered key needs a network round trip. publicKey may be empty when only the registered key should be checked. */
+  function ValidateAPIKeyPair(privateKey: string, publicKey: string, accountIndex?: number, apiKeyIndex?: number): Promise<ValidateAPIKeyPairResult | LighterErrorResult>;
+
+  interface LockClientResult {
+    locked: boolean;
+    error: string;
+  }
+
+  function LockClient(passphrase?: string): LockClientResult | LighterErrorResult;
+
+  interface UnlockClientResult {
+    locked: boolean;
+    error: string;
+  }
+
+  /** expects (passphrase, autoLockSeconds?). autoLockSeconds defaults to 15 minutes, 0 disables it. */
+  function UnlockClient(passphrase: string, autoLockSeconds?: number): UnlockClientResult | LighterErrorResult;
+
+  interface CreateSessionKeyResult {
+    clientIndex: number;
+    l1Message: string;
+    privateKey: string;
+    publicKey: string;
+    txInfo: string;
+    error: string;
+  }
+
+  /** expects (apiKeyIndex, policy, nonce) and returns {clientIndex, txInfo, l1Message, privateKey, publicKey}, l1Message being the message to sign with the account's L1 key for the ChangePubKey tx. */
+  function CreateSessionKey(apiKeyIndex: number, policy: object, nonce: number): CreateSessionKeyResult | LighterErrorResult;
+
+  function RevokeSessionKey(clientIndex: number): LighterErrorResult;
+
+  interface CreateExternalSignerClientResult {
+    clientIndex: number;
+    error: string;
+  }
+
+  /** expects (publicKey, accountIndex, apiKeyIndex, chainId, signCallback, baseUrl?, options?), options being the same as for CreateClient. */
+  function CreateExternalSignerClient(publicKey: string, accountIndex: number, apiKeyIndex: number, chainId: number, signCallback: (...args: any[]) => any, baseUrl?: string | string[], options?: unknown): CreateExternalSignerClientResult | LighterErrorResult;
+
+  interface PrepareTxResult {
+    hash: string;
+    payload: string;
+    requiredApprovals: number;
+    txId: string;
+    txType: number;
+    error: string;
+  }
+
+  /** expects (txType, params, options?) where params is an object or a JSON string and options may hold clientIndex, approvers, threshold and allowCross. threshold defaults to the number of approvers. */
+  function PrepareTx(txType: string, params: unknown, options?: object): PrepareTxResult | LighterErrorResult;
+
+  interface FinalizeTxResult {
+    approvals: number;
+    finalized: boolean;
+    requiredApprovals: number;
+    txInfo: string;
+    error: string;
+  }
+
+  /** expects (txId, signatures) where signatures is a hex string or an array of them. */
+  function FinalizeTx(txId: string, signatures: string | unknown[]): FinalizeTxResult | LighterErrorResult;
+
+  interface SignApprovalResult {
+    signature: string;
+    error: string;
+  }
+
+  /** expects (privateKey, txId). */
+  function SignApproval(privateKey: string, txId: string): SignApprovalResult | LighterErrorResult;
+
+  /** expects (accountIndexes, clientIndex?). The accounts are not checked against the exchange, see SetSubAccounts. */
+  function SetSubAccounts(accountIndexes: object | unknown[], clientIndex?: number): LighterErrorResult;
+
+  interface SignSubAccountTransferResult {
+    txInfo: string;
+    error: string;
+  }
+
+  /** expects (fromAccountIndex, toAccountIndex, usdcAmount, fee, memo, nonce, clientIndex?, options?). memo is free text or {template, vars}, see SignTransfer; options are the TransactOpts overrides, see SignCreateOrder. */
+  function SignSubAccountTransfer(fromAccountIndex: number, toAccountIndex: number, usdcAmount: number, fee: number, memo: string | { template: string; vars?: Record<string, string | number> }, nonce: number, clientIndex?: number, options?: { accountIndex?: number; apiKeyIndex?: number; nonce?: number; expiredAt?: number }): SignSubAccountTransferResult | LighterErrorResult;
+
+  interface CloneClientResult {
+    clientIndex: number;
+    error: string;
+  }
+
+  /** expects (clientIndex, accountIndex). */
+  function CloneClient(clientIndex: number, accountIndex: number): CloneClientResult | LighterErrorResult;
+
+  interface GetAccountIndexResult {
+    accountIndex: number;
+    error: string;
+  }
+
+  /** expects (clientIndex?). */
+  function GetAccountIndex(clientIndex?: number): GetAccountIndexResult | LighterErrorResult;
+
+  interface GetApiKeyIndexResult {
+    apiKeyIndex: number;
+    error: string;
+  }
+
+  /** expects (clientIndex?). */
+  function GetApiKeyIndex(clientIndex?: number): GetApiKeyIndexResult | LighterErrorResult;
+
+  interface GetChainIdResult {
+    chainId: number;
+    error: string;
+  }
+
+  /** expects (clientIndex?). */
+  function GetChainId(clientIndex?: number): GetChainIdResult | LighterErrorResult;
+
+  interface CanonicalizeTxInfoResult {
+    txInfo: string;
+    error: string;
+  }
+
+  /** expects (json) and returns it in the canonical form every txInfo is emitted in, so payloads produced elsewhere can be compared byte for byte. */
+  function CanonicalizeTxInfo(json: string): CanonicalizeTxInfoResult | LighterErrorResult;
+
+  interface SetResponseCasingResult {
+    casing: string;
+    error: string;
+  }
+
+  /** expects ("pascal" | "camel" | "snake"). */
+  function SetResponseCasing(casing: "pascal" | "camel" | "snake"): SetResponseCasingResult | LighterErrorResult;
+
+  /** expects ("s" | "ms" | ""). An empty unit restores the default of inferring it per parameter. */
+  function SetTimeUnit(unit: "s" | "ms" | ""): LighterErrorResult;
+
+  /** expects (enabled). When enabled, timestamps off by a factor of 1000 are converted with a warning instead of being rejected. */
+  function SetTimeNormalization(enabled: boolean): LighterErrorResult;
+
+  interface GetVersionResult {
+    buildTime: string;
+    commit: string;
+    dependencies: Record<string, unknown>;
+    goVersion: string;
+    lighterGoVersion: string;
+    modified: boolean;
+    target: string;
+    version: string;
+    error: string;
+  }
+
+  function GetVersion(): GetVersionResult | LighterErrorResult;
+
+  interface GetCapabilitiesResult {
+    apiVersions: string[];
+    capabilitiesVersion: number;
+    features: Record<string, unknown>;
+    supportedFeatures: string[];
+    txTypes: { exports: string[]; name: string; txType: number }[];
+    version: string;
+    error: string;
+  }
+
+  function GetCapabilities(): GetCapabilitiesResult | LighterErrorResult;
+
+  function Shutdown(): LighterErrorResult;
+
+  interface PingResult {
+    goroutines: number;
+    healthy: boolean;
+    lastHeartbeatAgeMs: number;
+    lastSignAt: number;
+    oldestTaskAgeMs: number;
+    pendingTasks: number;
+    signCount: number;
+    uptimeMs: number;
+    error: string;
+  }
+
+  function Ping(): PingResult | LighterErrorResult;
+
+  interface GetMemoryStatsResult {
+    frees: number;
+    goroutines: number;
+    heapAlloc: number;
+    heapInUse: number;
+    heapObjects: number;
+    heapSys: number;
+    lastGCAt: number;
+    lastGCPauseNs: number;
+    mallocs: number;
+    numGC: number;
+    sys: number;
+    totalAlloc: number;
+    totalGCPauseNs: number;
+    error: string;
+  }
+
+  /** expects (forceGC?). When forceGC is true a collection runs first, so that heapInUse reflects live memory only. */
+  function GetMemoryStats(forceGC?: boolean): GetMemoryStatsResult | LighterErrorResult;
+
+  interface GetModuleHashResult {
+    algorithm: string;
+    components: Record<string, unknown>;
+    hash: string;
+    error: string;
+  }
+
+  function GetModuleHash(): GetModuleHashResult | LighterErrorResult;
+
+  interface SetRedactionResult {
+    mode: string;
+    error: string;
+  }
+
+  /** expects (mode). Redaction cannot be turned off, "known" is the default. */
+  function SetRedaction(mode: string): SetRedactionResult | LighterErrorResult;
+
+  interface RedactResult {
+    text: string;
+    error: string;
+  }
+
+  /** expects (text). It runs text through the filter applied to errors and warnings, so hosts can filter their own logs and check that nothing sensitive gets through. */
+  function Redact(text: string): RedactResult | LighterErrorResult;
+
+  interface RunBenchmarkResult {
+    allocsPerOp: number;
+    bytesPerOp: number;
+    elapsedMs: number;
+    nsPerOp: number;
+    numGC: number;
+    opsPerSec: number;
+    orders: number;
+    runtime: string;
+    version: string;
+    error: string;
+  }
+
+  /** expects (orders?). It blocks the event loop while signing, so it should not be run while the page or process has other work to do. */
+  function RunBenchmark(orders?: number): RunBenchmarkResult | LighterErrorResult;
+
+  interface SignMarketableOrderResult {
+    price: number;
+    referencePrice: number;
+    txInfo: string;
+    error: string;
+  }
+
+  /** expects (order) and returns a Promise, as the top of book may have to be fetched. order holds marketIndex, baseAmount, isAsk, maxSlippageBps and nonce, and optionally clientOrderIndex, reduceOnly, bestBid, bestAsk and clientIndex, along with the TransactOpts overrides accountIndex, apiKeyIndex and expiredAt, see SignCreateOrder. Prices are in ticks. */
+  function SignMarketableOrder(order: object): Promise<SignMarketableOrderResult | LighterErrorResult>;
+
+  interface SetPositionsResult {
+    positions: unknown[];
+    error: string;
+  }
+
+  /** expects (accountIndex, positions). positions lists {marketIndex, size} where size is the base amount held, negative for a short; null clears the snapshot. */
+  function SetPositions(accountIndex: number, positions: object | unknown[]): SetPositionsResult | LighterErrorResult;
+
+  interface FetchPositionsResult {
+    positions: unknown[];
+    error: string;
+  }
+
+  /** expects (accountIndex?, clientIndex?) and returns a Promise. accountIndex defaults to the account of the client. */
+  function FetchPositions(accountIndex?: number, clientIndex?: number): Promise<FetchPositionsResult | LighterErrorResult>;
+
+  /** expects (marketIndex, bestBid, bestAsk) in price ticks. Passing 0 for both removes the reference of the market. */
+  function SetReferencePrices(marketIndex: unknown, bestBid: unknown, bestAsk: unknown): LighterErrorResult;
+
+  interface FetchReferencePricesResult {
+    bestAsk: number;
+    bestBid: number;
+    error: string;
+  }
+
+  /** expects (marketIndex, clientIndex?) and returns a Promise. */
+  function FetchReferencePrices(marketIndex: number, clientIndex?: number): Promise<FetchReferencePricesResult | LighterErrorResult>;
+
+  interface SetSelfTradePreventionResult {
+    mode: string;
+    error: string;
+  }
+
+  /** expects ("off" | "warn" | "refuse"). "off" is the default. */
+  function SetSelfTradePrevention(mode: "off" | "warn" | "refuse"): SetSelfTradePreventionResult | LighterErrorResult;
+
+  interface GetOpenOrdersCacheResult {
+    orders: unknown[];
+    error: string;
+  }
+
+  /** expects (accountIndex?). Without accountIndex the orders of every account are returned. */
+  function GetOpenOrdersCache(accountIndex?: number): GetOpenOrdersCacheResult | LighterErrorResult;
+
+  interface ApplyOpenOrdersResult {
+    orders: unknown[];
+    error: string;
+  }
+
+  /** expects (accountIndex, marketIndex, orders) and reconciles the cache with an exchange view received elsewhere, e.g. an account orders snapshot from the websocket. orders lists {orderIndex, clientOrderIndex?, isAsk, price, baseAmount, orderExpiry?} in protocol units, baseAmount being the remaining base amount. */
+  function ApplyOpenOrders(accountIndex: number, marketIndex: number, orders: object | unknown[]): ApplyOpenOrdersResult | LighterErrorResult;
+
+  interface FetchOpenOrdersResult {
+    orders: unknown[];
+    error: string;
+  }
+
+  /** expects (marketIndex?, clientIndex?) and returns a Promise. The orders are fetched for the account of the client. */
+  function FetchOpenOrders(marketIndex?: number, clientIndex?: number): Promise<FetchOpenOrdersResult | LighterErrorResult>;
+
+  interface BuildSendTxBatchBodyResult {
+    body: string;
+    contentType: string;
+    order: unknown[];
+    path: string;
+    error: string;
+  }
+
+  /** expects (txs). txs lists {txType, txInfo} as returned by the signing exports, in any response casing. The result is POSTed as is to path with the given content type. */
+  function BuildSendTxBatchBody(txs: object | unknown[]): BuildSendTxBatchBodyResult | LighterErrorResult;
+
+  interface SetSessionEndResult {
+    sessionEnd: number;
+    error: string;
+  }
+
+  /** expects (sessionEnd), any time parameter accepted by the signing exports. 0 unsets it. */
+  function SetSessionEnd(sessionEnd: number | string): SetSessionEndResult | LighterErrorResult;
+
+  interface ResolveOrderExpiryResult {
+    orderExpiry: number;
+    error: string;
+  }
+
+  /** expects (preset) and returns the orderExpiry an order signed now would get from it. */
+  function ResolveOrderExpiry(preset: string): ResolveOrderExpiryResult | LighterErrorResult;
+
+  interface SetMarketRulesResult {
+    baseStep: number;
+    hasDecimals: boolean;
+    marketIndex: number;
+    minBaseAmount: number;
+    minQuoteAmount: number;
+    priceDecimals: number;
+    priceStep: number;
+    sizeDecimals: number;
+    error: string;
+  }
+
+  /** expects (marketIndex, rules). rules is {minBaseAmount?, minQuoteAmount?, baseStep?, priceStep?, sizeDecimals?, priceDecimals?} in protocol units, minQuoteAmount being counted in base amount times price ticks; the decimals go together. null removes the rules of the market. */
+  function SetMarketRules(marketIndex: number, rules: object): SetMarketRulesResult | LighterErrorResult;
+
+  interface FetchMarketRulesResult {
+    baseStep: number;
+    hasDecimals: boolean;
+    marketIndex: number;
+    minBaseAmount: number;
+    minQuoteAmount: number;
+    priceDecimals: number;
+    priceStep: number;
+    sizeDecimals: number;
+    error: string;
+  }
+
+  /** expects (marketIndex, clientIndex?) and returns a Promise. */
+  function FetchMarketRules(marketIndex: number, clientIndex?: number): Promise<FetchMarketRulesResult | LighterErrorResult>;
+
+  interface SetRoundingModesResult {
+    priceBuy: string;
+    priceSell: string;
+    sizeBuy: string;
+    sizeSell: string;
+    error: string;
+  }
+
+  /** expects (modes). modes is {priceBuy?, priceSell?, sizeBuy?, sizeSell?}, each one of "exact", "down", "up" or "nearest"; the fields left out keep their mode. */
+  function SetRoundingModes(modes: object): SetRoundingModesResult | LighterErrorResult;
+
+  interface ToOrderUnitsResult {
+    baseAmount: number;
+    price: number;
+    error: string;
+  }
+
+  /** expects (marketIndex, isAsk, price, size, modes?). price and size are decimal strings, or null to skip them; modes overrides the rounding set through SetRoundingModes for this call. The market's decimals and steps come from SetMarketRules or FetchMarketRules. */
+  function ToOrderUnits(marketIndex: number, isAsk: number, price: null | number | string, size: null | number | string, modes?: object): ToOrderUnitsResult | LighterErrorResult;
+
+  interface SetWithdrawalFeeResult {
+    expiresAt: number;
+    fee: number;
+    toleranceBps: number;
+    error: string;
+  }
+
+  /** expects (fee, options?). fee is the withdrawal fee currently charged by the exchange, in USDC units or as a decimal string; null clears it. options is {toleranceBps?, validForSeconds?}: the fees accepted by SignWithdraw may differ from it by toleranceBps, 0 by default, for validForSeconds, 300 by default. */
+  function SetWithdrawalFee(fee: number | string, options?: object): SetWithdrawalFeeResult | LighterErrorResult;
+
+  interface SignWithdrawResult {
+    txInfo: string;
+    error: string;
+  }
+
+  /** expects (usdcAmount, fee, nonce, clientIndex?, options?), options being the TransactOpts overrides, see SignCreateOrder. fee is the withdrawal fee the caller expects to pay; it is not part of the tx, but signing is refused unless it matches the quote set through SetWithdrawalFee, so that no withdrawal is prepared against a stale fee. */
+  function SignWithdraw(usdcAmount: number | string, fee: number | string, nonce: number, clientIndex?: number, options?: { accountIndex?: number; apiKeyIndex?: number; nonce?: number; expiredAt?: number }): SignWithdrawResult | LighterErrorResult;
+
+  interface GetPolicyUsageResult {
+    maxNotionalPerDay: number;
+    maxNotionalPerHour: number;
+    maxOrdersPerMinute: number;
+    notionalLastDay: number;
+    notionalLastHour: number;
+    ordersLastMinute: number;
+    remainingNotionalPerDay: number;
+    remainingNotionalPerHour: number;
+    remainingOrdersPerMinute: number;
+    error: string;
+  }
+
+  /** expects (clientIndex?) and reports the rolling limits of the policy the client was created with: the orders and notional signed within each window, the limit and the headroom left, -1 meaning unlimited. */
+  function GetPolicyUsage(clientIndex?: number): GetPolicyUsageResult | LighterErrorResult;
+
+  interface SetConfirmationHookResult {
+    leverageAbove: number;
+    transferAbove: number;
+    withdrawalAbove: number;
+    error: string;
+  }
+
+  /** expects (callback, thresholds?). callback is called synchronously with a description of every transfer, withdrawal and leverage change above its threshold before it is signed, and the tx is only signed if it returns a truthy value; null removes it. thresholds is {transferAbove?, withdrawalAbove?, leverageAbove?}, amounts in USDC units and leverage as a whole multiple, all 0 by default. */
+  function SetConfirmationHook(callback: (...args: any[]) => any, thresholds?: object): SetConfirmationHookResult | LighterErrorResult;
+
+  interface MintAuthTokenResult {
+    authToken: string;
+    clientIndex: number;
+    deadline: number;
+    expired: boolean;
+    expiresIn: number;
+    label: string;
+    error: string;
+  }
+
+  /** expects (label, ttlSeconds?, options?, clientIndex?). The token is valid for ttlSeconds, the authTokenTtlSec of the profile by default, and bound to the origin and session id of options when given. Minting under an existing label replaces its token. */
+  function MintAuthToken(label: string, ttlSeconds?: number, options?: { origin?: string; sessionId?: string }, clientIndex?: number): MintAuthTokenResult | LighterErrorResult;
+
+  interface RefreshAuthTokenResult {
+    authToken: string;
+    clientIndex: number;
+    deadline: number;
+    expired: boolean;
+    expiresIn: number;
+    label: string;
+    error: string;
+  }
+
+  /** expects (label). */
+  function RefreshAuthToken(label: string): RefreshAuthTokenResult | LighterErrorResult;
+
+  /** expects (label). The module forgets the token; the exchange keeps accepting it until its deadline, which is why short validities are advised. */
+  function RevokeAuthToken(label: string): LighterErrorResult;
+
+  interface GetAuthTokensResult {
+    tokens: Record<string, unknown>[];
+    error: string;
+  }
+
+  /** lists the labeled auth tokens, sorted by label. */
+  function GetAuthTokens(): GetAuthTokensResult | LighterErrorResult;
+
+  /** expects (callback). callback receives every log entry as {level, event, message, time, ...}; null restores the default of warning on the console. */
+  function SetLogger(callback: (...args: any[]) => any): LighterErrorResult;
+
+  interface GetEndpointsResult {
+    endpoints: { active: boolean; downUntil: number; failures: number; healthy: boolean; url: string }[];
+    error: string;
+  }
+
+  /** expects (clientIndex?) and lists the API base URLs of the client by priority. */
+  function GetEndpoints(clientIndex?: number): GetEndpointsResult | LighterErrorResult;
+
+  interface CheckEndpointsResult {
+    endpoints: { active: boolean; downUntil: number; failures: number; healthy: boolean; url: string }[];
+    error: string;
+  }
+
+  /** expects (clientIndex?) and returns a Promise. Every API base URL of the client is probed, so that failing ones are skipped and recovered ones used again without waiting for traffic. */
+  function CheckEndpoints(clientIndex?: number): Promise<CheckEndpointsResult | LighterErrorResult>;
+
+  interface SubmitTxResult {
+    queued: boolean;
+    txHash: string;
+    error: string;
+  }
+
+  /** expects (txType, txInfo, options?, clientIndex?) and returns a Promise. txInfo is a signed tx; it is sent through the client's HTTP client. options is {queueOnFailure?}: when set and the network is down or the exchange halted, the tx is queued until it expires, to be sent by FlushTxQueue, when the host goes back online or when the exchange resumes. Otherwise submissions fail fast with code EXCHANGE_HALTED during a halt. */
+  function SubmitTx(txType: number, txInfo: string, options?: object, clientIndex?: number): Promise<SubmitTxResult | LighterErrorResult>;
+
+  interface FlushTxQueueResult {
+    expired: number;
+    flushed: number;
+    rejected: number;
+    remaining: number;
+    error: string;
+  }
+
+  /** returns a Promise sending the queued txs, see FlushTxQueue. */
+  function FlushTxQueue(): Promise<FlushTxQueueResult | LighterErrorResult>;
+
+  interface ConfigureTxQueueResult {
+    length: number;
+    maxSize: number;
+    error: string;
+  }
+
+  /** expects (maxSize, onEvent?). onEvent receives {event, txHash, txType, ...} for every tx "queued", "flushed", "expired" or "rejected". Shrinking the queue below its length drops its newest txs. */
+  function ConfigureTxQueue(maxSize: number, onEvent?: (...args: any[]) => any): ConfigureTxQueueResult | LighterErrorResult;
+
+  interface GetTxQueueResult {
+    maxSize: number;
+    txs: { expiresAt: number; queuedAt: number; txHash: string; txType: number }[];
+    error: string;
+  }
+
+  /** lists the queued txs, oldest first. */
+  function GetTxQueue(): GetTxQueueResult | LighterErrorResult;
+
+  interface ExportSessionResult {
+    session: string;
+    error: string;
+  }
+
+  function ExportSession(): ExportSessionResult | LighterErrorResult;
+
+  interface ImportSessionResult {
+    restrictionsRestored: boolean;
+    error: string;
+  }
+
+  /** expects (session), a string returned by ExportSession, once the same client was created again. */
+  function ImportSession(session: string): ImportSessionResult | LighterErrorResult;
+
+  interface GetPendingOperationsResult {
+    queued: { ageMs: number; expiresAt: number; queuedAt: number; txHash: string; txType: number }[];
+    tasks: { ageMs: number; id: number; kind: string; name: string; startedAt: number }[];
+    error: string;
+  }
+
+  /** lists the background tasks still running, async exports and refreshers, and the txs queued by SubmitTx, so that callers can drain them before Shutdown or spot a stuck one. */
+  function GetPendingOperations(): GetPendingOperationsResult | LighterErrorResult;
+
+  interface SubmitBatchResult {
+    chunks: unknown[];
+    items: unknown[];
+    status: string;
+    submitted: number;
+    error: string;
+  }
+
+  /** expects (requests, options?, clientIndex?) and returns a Promise. requests lists {txType, params} as accepted by PrepareTx, in nonce order. options is {chunkSize?, minIntervalMs?, maxRetries?, atomic?, cancelsFirst?, onChunk?}: chunkSize defaults to the exchange batch limit, minIntervalMs spaces the chunks, maxRetries bounds the retries of a throttled chunk, atomic sends nothing unless every request could be signed, cancelsFirst overrides the profile's and onChunk receives {index, start, count, txHashes, error} for every chunk, start counting in submission order. The result holds the chunks, the number of txs submitted, the status of the batch and one item per request, see SignBatch. */
+  function SubmitBatch(requests: unknown[], options?: object, clientIndex?: number): Promise<SubmitBatchResult | LighterErrorResult>;
+
+  interface ConfigurePriceFeedResult {
+    maxAgeMs: number;
+    maxDeviationBps: number;
+    prices: { ageMs: number; indexPrice: number; markPrice: number; marketIndex: number; stale: boolean }[];
+    error: string;
+  }
+
+  /** expects (options). options is {maxAgeMs?, maxDeviationBps?}: prices older than maxAgeMs are ignored, orders priced more than maxDeviationBps from a fresh mark price are refused. A zero maxDeviationBps, the default, disables the check. */
+  function ConfigurePriceFeed(options: object): ConfigurePriceFeedResult | LighterErrorResult;
+
+  interface UpdatePriceFeedResult {
+    updated: number;
+    error: string;
+  }
+
+  /** expects (updates), the prices received from the host's market data subscription. updates lists {marketIndex, markPrice, indexPrice?} in price ticks; a markPrice of 0 removes the market from the feed. */
+  function UpdatePriceFeed(updates: object | unknown[]): UpdatePriceFeedResult | LighterErrorResult;
+
+  interface GetPriceFeedResult {
+    maxAgeMs: number;
+    maxDeviationBps: number;
+    prices: { ageMs: number; indexPrice: number; markPrice: number; marketIndex: number; stale: boolean }[];
+    error: string;
+  }
+
+  /** expects () and returns the prices held by the feed, with their age. */
+  function GetPriceFeed(): GetPriceFeedResult | LighterErrorResult;
+
+  interface LookupSignedOrderResult {
+    orders: { accountIndex: number; clientOrderIndex: number; marketIndex: number; nonce: number; signedAt: number; txHash: string; txInfo: string; txType: number }[];
+    error: string;
+  }
+
+  /** expects (clientOrderIndex, accountIndex?, clientIndex?). accountIndex defaults to the account of the client. Returns {orders} listing {txType, nonce, txHash, signedAt, txInfo, ...} for every create or modify tx signed in this session for the order, oldest first. */
+  function LookupSignedOrder(clientOrderIndex: number, accountIndex?: number, clientIndex?: number): LookupSignedOrderResult | LighterErrorResult;
+
+  interface AmendOrderResult {
+    nextNonce: number;
+    path: string;
+    txInfos: unknown[];
+    txTypes: unknown[];
+    error: string;
+  }
+
+  /** expects (amend). amend holds marketIndex, clientOrderIndex and nonce, and optionally baseAmount, price, triggerPrice, isAsk, timeInForce, reduceOnly, orderExpiry, mode ("auto", "modify" or "replace"), clientIndex and intentHash, the hash HashAmendIntent returned for the change the user approved, required once RequireOrderIntent was called. Returns {path, txInfos, txTypes, nextNonce}: path is "modify" or "cancelReplace" and txInfos lists the txs to submit in order. */
+  function AmendOrder(amend: object): AmendOrderResult | LighterErrorResult;
+
+  interface SyncClockResult {
+    offsetMs: number;
+    roundTripMs: number;
+    syncedAt: number;
+    error: string;
+  }
+
+  /** expects (clientIndex?) and returns a Promise. It measures the offset of the exchange clock through the client's HTTP client and applies it to every deadline and expiry the module computes. */
+  function SyncClock(clientIndex?: number): Promise<SyncClockResult | LighterErrorResult>;
+
+  interface SetClockSkewResult {
+    offsetMs: number;
+    roundTripMs: number;
+    syncedAt: number;
+    error: string;
+  }
+
+  /** expects (offsetMs), the offset of the exchange clock from the local one as measured by the host. 0 stops correcting the local time. */
+  function SetClockSkew(offsetMs: number): SetClockSkewResult | LighterErrorResult;
+
+  interface GetClockSkewResult {
+    offsetMs: number;
+    roundTripMs: number;
+    syncedAt: number;
+    error: string;
+  }
+
+  /** expects () and returns {offsetMs, roundTripMs, syncedAt}: offsetMs is added to the local time to get the exchange time, syncedAt is 0 until the clock was synced. */
+  function GetClockSkew(): GetClockSkewResult | LighterErrorResult;
+
+  interface LoadProfileResult {
+    name: string;
+    profile: Record<string, unknown>;
+    error: string;
+  }
+
+  /** expects (name, overrides?). name is "default", "conservative", "hft" or "testnet"; overrides replaces any field of the profile, e.g. {txExpirySec: 60}. Returns the profile now in effect. */
+  function LoadProfile(name: string, overrides?: object): LoadProfileResult | LighterErrorResult;
+
+  interface GetProfileResult {
+    name: string;
+    profile: Record<string, unknown>;
+    error: string;
+  }
+
+  /** expects () and returns the name and values of the profile in effect. */
+  function GetProfile(): GetProfileResult | LighterErrorResult;
+
+  interface SubscribeResult {
+    subscriptionId: number;
+    error: string;
+  }
+
+  /** expects (event, callback) and returns {subscriptionId}. callback receives {event, time, ...} every time event happens; see eventNames for the events and what they carry. */
+  function Subscribe(event: string, callback: (...args: any[]) => any): SubscribeResult | LighterErrorResult;
+
+  interface UnsubscribeResult {
+    removed: boolean;
+    error: string;
+  }
+
+  /** expects (subscriptionId) and returns {removed}, false when the subscription did not exist. */
+  function Unsubscribe(subscriptionId: number): UnsubscribeResult | LighterErrorResult;
+
+  interface FetchNextNonceResult {
+    nonce: number;
+    error: string;
+  }
+
+  /** expects (accountIndex?, apiKeyIndex?, clientIndex?) and returns a Promise resolving to {nonce}, the next nonce the exchange expects from the key. accountIndex and apiKeyIndex default to the client's. Subscribers of "nonceResync" are notified. */
+  function FetchNextNonce(accountIndex?: number, apiKeyIndex?: number, clientIndex?: number): Promise<FetchNextNonceResult | LighterErrorResult>;
+
+  interface DeriveClientOrderIndexResult {
+    clientOrderIndex: number;
+    error: string;
+  }
+
+  /** expects (strategyId, marketIndex, slot) and returns {clientOrderIndex}, the same for the same arguments across restarts. */
+  function DeriveClientOrderIndex(strategyId: string, marketIndex: number, slot: number): DeriveClientOrderIndexResult | LighterErrorResult;
+
+  interface AdoptStrategyOrdersResult {
+    orders: { baseAmount: number; clientOrderIndex: number; confirmed: boolean; isAsk: number; marketIndex: number; orderIndex: number; price: number; slot: number }[];
+    error: string;
+  }
+
+  /** expects (strategyId, slots, clientIndex?) and returns {orders}, the resting orders of the client's account placed by the strategy with one of its first slots slots, each with its slot. A restarted process fetches the open orders first, then adopts these instead of orphaning them. */
+  function AdoptStrategyOrders(strategyId: string, slots: number, clientIndex?: number): AdoptStrategyOrdersResult | LighterErrorResult;
+
+  interface SetAddressBookEntryResult {
+    entries: unknown[];
+    error: string;
+  }
+
+  /** expects (label, accountIndex) and returns {entries}. Transfers accept label in place of the account index. */
+  function SetAddressBookEntry(label: string, accountIndex: number): SetAddressBookEntryResult | LighterErrorResult;
+
+  interface RemoveAddressBookEntryResult {
+    removed: boolean;
+    error: string;
+  }
+
+  /** expects (label) and returns {removed}, false when nothing was saved under label. */
+  function RemoveAddressBookEntry(label: string): RemoveAddressBookEntryResult | LighterErrorResult;
+
+  interface GetAddressBookResult {
+    entries: unknown[];
+    error: string;
+  }
+
+  /** expects () and returns {entries}, the saved {label, accountIndex} sorted by label. */
+  function GetAddressBook(): GetAddressBookResult | LighterErrorResult;
+
+  interface SimulateCreateOrderResult {
+    msgHash: string;
+    notional: number;
+    txInfo: string;
+    warnings: unknown[];
+    error: string;
+  }
+
+  /** expects (marketIndex, clientOrderIndex, baseAmount, price, isAsk, orderType, timeInForce, reduceOnly, triggerPrice, orderExpiry, nonce, clientIndex?, accountIndex?, options?), the args of SignCreateOrder, and returns {txInfo, msgHash, notional, warnings}: the unsigned tx SignCreateOrder would sign, the hash it would sign and the order notional in protocol units. A call SignCreateOrder would refuse returns its error. */
+  function SimulateCreateOrder(marketIndex: number, clientOrderIndex: number, baseAmount: number | string, price: number | string, isAsk: number, orderType: number, timeInForce: number, reduceOnly: number, triggerPrice: number | string, orderExpiry: number | string, nonce: number, clientIndex?: number, accountIndex?: number, options?: { allowCross?: boolean; intentHash?: string; accountIndex?: number; apiKeyIndex?: number; nonce?: number; expiredAt?: number }): SimulateCreateOrderResult | LighterErrorResult;
+
+  interface SignBatchResult {
+    items: unknown[];
+    signed: number;
+    status: string;
+    error: string;
+  }
+
+  /** expects (requests, options?, clientIndex?). requests lists {txType, params} as accepted by PrepareTx, options is {atomic?}. Requests are signed independently unless atomic is set, which keeps none of them when one fails. The result is {status, signed, items}: status is "ok", "partial" or "failed" and items holds {index, txType, nonce, status, txHash, txInfo?, error} for every request, in request order, status being "signed", "failed", "skipped" or "rolledBack". */
+  function SignBatch(requests: unknown[], options?: object, clientIndex?: number): SignBatchResult | LighterErrorResult;
+
+  interface CheckNonceGapResult {
+    expected: number;
+    gap: boolean;
+    lowestQueued: number;
+    lowestRejected: number;
+    queued: number;
+    stale: boolean;
+    error: string;
+  }
+
+  /** expects (clientIndex?) and returns a Promise resolving to {expected, lowestRejected, lowestQueued, queued, gap, stale} for the api key of the client, see RepairNonceGap. */
+  function CheckNonceGap(clientIndex?: number): Promise<CheckNonceGapResult | LighterErrorResult>;
+
+  interface RepairNonceGapResult {
+    expected?: number;
+    nextNonce?: number;
+    resigned: { nonce: number; oldTxHash: string; txHash: string }[];
+    error: string;
+  }
+
+  /** expects (options?, clientIndex?) and returns a Promise resolving to {expected, nextNonce, resigned}. options is {resign?}: when set, the queued txs of the client's api key are signed again with the nonces following the one the exchange expects, and resigned lists {oldTxHash, txHash, nonce} for each. */
+  function RepairNonceGap(options?: object, clientIndex?: number): Promise<RepairNonceGapResult | LighterErrorResult>;
+
+  interface CheckExchangeStatusResult {
+    halted: boolean;
+    reason: string;
+    since: number;
+    status?: number;
+    error: string;
+  }
+
+  /** expects (clientIndex?) and returns a Promise resolving to {halted, reason, since, status}: the status the exchange reports, and whether it is halted since the millisecond timestamp since. Submissions fail fast with code EXCHANGE_HALTED while it is, unless queued, see SubmitTx. */
+  function CheckExchangeStatus(clientIndex?: number): Promise<CheckExchangeStatusResult | LighterErrorResult>;
+
+  interface GetExchangeStatusResult {
+    halted: boolean;
+    reason: string;
+    since: number;
+    error: string;
+  }
+
+  /** expects () and returns {halted, reason, since} as last seen, see CheckExchangeStatus. */
+  function GetExchangeStatus(): GetExchangeStatusResult | LighterErrorResult;
+
+  interface SetExchangeHaltedResult {
+    halted: boolean;
+    reason: string;
+    since: number;
+    error: string;
+  }
+
+  /** expects (halted, reason?) and returns {halted, reason, since}, for hosts learning of halts from elsewhere, e.g. a status feed. Clearing the halt flushes the txs queued meanwhile. */
+  function SetExchangeHalted(halted: boolean, reason?: string): SetExchangeHaltedResult | LighterErrorResult;
+
+  interface GetMarketStatsResult {
+    markets: Record<string, unknown>[];
+    error: string;
+  }
+
+  /** expects (marketIndex?) and returns {markets}, the signing statistics of every market seen, or of marketIndex only, sorted by market: {marketIndex, ordersSigned, averageBaseAmount, modified, canceled, rejected, rejectRate, rejectedByCode}. rejected counts the order, modify and cancel txs the pre-sign checks refused, by error code, "OTHER" when the error has none; rejectRate is their share of the attempts. */
+  function GetMarketStats(marketIndex?: number): GetMarketStatsResult | LighterErrorResult;
+
+  interface WarmupResult {
+    elapsedMs: number;
+    markets: unknown[];
+    nonce: number;
+    signMs: number;
+    error: string;
+  }
+
+  /** expects (options?, clientIndex?) and returns a Promise resolving to {elapsedMs, signMs, nonce, markets}. options is {nonce?, markets?}: nonce fetches the next nonce of the client's key, -1 otherwise, and markets lists the markets whose rules are loaded; the result lists the ones that were. The client is only needed to prefetch. error names every prefetch that failed; the others still took effect. */
+  function Warmup(options?: object, clientIndex?: number): Promise<WarmupResult | LighterErrorResult>;
+
+  interface EnableStrictModeResult {
+    strict: boolean;
+    strictBuild: boolean;
+    error: string;
+  }
+
+  /** expects () and returns {strict, strictBuild}. From then on, the demo and diagnostic paths fail with code MOCK_PATH_DISABLED; there is no way back short of reloading the module. */
+  function EnableStrictMode(): EnableStrictModeResult | LighterErrorResult;
+
+  interface AssertStrictModeResult {
+    strict: boolean;
+    strictBuild: boolean;
+    error: string;
+  }
+
+  /** expects () and returns {strict, strictBuild}, with an error unless strict mode is on, so that hosts handling real funds can refuse to start otherwise. */
+  function AssertStrictMode(): AssertStrictModeResult | LighterErrorResult;
+
+  interface ConfigureHTTPResult {
+    customFetch: boolean;
+    headers: unknown[];
+    proxy: boolean;
+    error: string;
+  }
+
+  /** expects (options, clientIndex?) and returns {headers, proxy, customFetch}: the names of the headers now sent with every request of the client, and whether its requests go through a proxy or dispatcher and a custom fetch. options is {headers?, proxyUrl?, tls?, dispatcher?, fetch?}: headers are sent with every request, e.g. the credentials of a gateway; proxyUrl and tls, e.g. {ca, rejectUnauthorized}, configure an undici dispatcher under Node; dispatcher is passed to fetch as is, and fetch replaces the global one. Each call replaces the previous configuration; {} restores the defaults. */
+  function ConfigureHTTP(options: object, clientIndex?: number): ConfigureHTTPResult | LighterErrorResult;
+
+  interface BuildOnboardingResult {
+    authToken: Record<string, unknown>;
+    fingerprint: string;
+    privateKey: string;
+    publicKey: string;
+    registration: Record<string, unknown>;
+    steps: unknown[];
+    error: string;
+  }
+
+  /** expects (accountIndex, apiKeyIndex, chainId, nonce, options?) and returns {privateKey, publicKey, fingerprint, registration: {txType, txInfo, l1Message}, authToken: {token, deadline}, steps}, the artifacts a new user goes through in the order of steps. options is {seed?, authTokenDeadline?}: seed derives the key instead of sampling it, and authTokenDeadline, in seconds, defaults to the profile's token validity. */
+  function BuildOnboarding(accountIndex: number, apiKeyIndex: number, chainId: number, nonce: number, options?: object): BuildOnboardingResult | LighterErrorResult;
+
+  interface GetExpiringOrdersResult {
+    orders: Record<string, unknown>[];
+    error: string;
+  }
+
+  /** expects (withinSeconds, accountIndex?) and returns {orders}, the resting orders, of accountIndex or of every account, expiring within withinSeconds, soonest first, each with expiresInMs. */
+  function GetExpiringOrders(withinSeconds: number, accountIndex?: number): GetExpiringOrdersResult | LighterErrorResult;
+
+  interface WatchOrderExpiryResult {
+    leadSeconds: number;
+    error: string;
+  }
+
+  /** expects (leadSeconds) and returns {leadSeconds}. Subscribers of "orderExpiring" are then notified once for every resting order leadSeconds before its expiry, so that it can be refreshed in time; 0 stops watching. */
+  function WatchOrderExpiry(leadSeconds: number): WatchOrderExpiryResult | LighterErrorResult;
+
+  interface KillSwitchResult {
+    accounts: unknown[];
+    failed: number;
+    error: string;
+  }
+
+  /** expects (options?) and returns a Promise resolving to {accounts, failed}. options is {flatten?, maxSlippageBps?, nonces?}, see KillSwitch; maxSlippageBps defaults to 500. accounts holds one entry per account, by clientIndex of the client it used, {clientIndex, accountIndex, cancelAll, flatten, error}, where cancelAll and each flatten order are {txInfo, txHash, submitted, error} and flatten orders also have their marketIndex. failed counts the accounts with an error on any of them. */
+  function KillSwitch(options?: object): Promise<KillSwitchResult | LighterErrorResult>;
+
+  interface StartRecordingResult {
+    format: string;
+    error: string;
+  }
+
+  /** expects (sink?) and returns {format}. Every later call to an export is recorded with its args and result, the known secrets replaced by tags. sink, a function, receives each line of the log as a string to append to a file; without it, StopRecording returns the log. */
+  function StartRecording(sink?: (...args: any[]) => any): StartRecordingResult | LighterErrorResult;
+
+  interface StopRecordingResult {
+    calls: number;
+    dropped: number;
+    log: string;
+    error: string;
+  }
+
+  /** expects () and returns {log, calls, dropped}. log is empty when the recording went to a sink. */
+  function StopRecording(): StopRecordingResult | LighterErrorResult;
+
+  interface ReplaySessionResult {
+    mismatches: unknown[];
+    recordedVersion: string;
+    replayed: number;
+    error: string;
+  }
+
+  /** expects (log, secrets?) and returns a Promise resolving to {recordedVersion, replayed, mismatches}. secrets lists the keys, seeds and passphrases used during the recording. mismatches holds {seq, export, expected, actual, error} for every call whose result differs from the recorded one, or that could not be replayed. */
+  function ReplaySession(log: string, secrets?: unknown[]): Promise<ReplaySessionResult | LighterErrorResult>;
+
+  interface SetMemoTemplateResult {
+    vars: unknown[] | string[];
+    error: string;
+  }
+
+  /** expects (name, template) and returns {vars}, the placeholders of template. Transfers then accept {template: name, vars} as memo, e.g. template "inv:{invoice};st:{strategy}" with {invoice: 1042, strategy: "mm"}. A null template removes it. */
+  function SetMemoTemplate(name: string, template: string): SetMemoTemplateResult | LighterErrorResult;
+
+  interface DecodeMemoResult {
+    hex: string;
+    template: string;
+    text: string;
+    vars: unknown;
+    error: string;
+  }
+
+  /** expects (memo, template?) and returns {text, hex, template, vars}. memo is the Memo of a transfer txInfo, an array of bytes, or its hex. vars holds the values of the placeholders of the memo template saved under template or, without it, of the first template the memo follows, whose name is returned; template is empty and vars null when it follows none. */
+  function DecodeMemo(memo: number[] | string, template?: string): DecodeMemoResult | LighterErrorResult;
+
+  interface HashOrderIntentResult {
+    accountIndex: number;
+    hash: string;
+    orderExpiry: number;
+    error: string;
+  }
+
+  /** expects the args of SignCreateOrder and returns {hash, accountIndex, orderExpiry}. hash covers the fields of the order a person approves, see types.OrderIntentHash; a UI shows it in its confirmation dialog and passes it back as options.intentHash to SignCreateOrder, which refuses to sign any other order. orderExpiry is the resolved expiry, to sign with when the order used a preset, as the preset resolves again at sign time. */
+  function HashOrderIntent(marketIndex: number, clientOrderIndex: number, baseAmount: number | string, price: number | string, isAsk: number, orderType: number, timeInForce: number, reduceOnly: number, triggerPrice: number | string, orderExpiry: number | string, nonce: number, clientIndex?: number, accountIndex?: number, options?: { allowCross?: boolean; intentHash?: string; accountIndex?: number; apiKeyIndex?: number; nonce?: number; expiredAt?: number }): HashOrderIntentResult | LighterErrorResult;
+
+  interface GetRuntimeResult {
+    globals: boolean;
+    runtime: string;
+    version: string;
+    error: string;
+  }
+
+  /** expects () and returns {runtime, version, globals}. runtime is deno, bun, node, worker, browser or unknown; globals tells whether the exports were registered on the global object, see the lighterSignerInit options. */
+  function GetRuntime(): GetRuntimeResult | LighterErrorResult;
+
+  interface RequireOrderIntentResult {
+    required: boolean;
+    error: string;
+  }
+
+  /** expects () and returns {required}. From then on, SignCreateOrder only signs orders whose options.intentHash matches them, AmendOrder only signs changes whose amend.intentHash matches them, see HashAmendIntent, and the other ways of signing orders and modifies fail with code ORDER_INTENT_REQUIRED; there is no way back short of reloading the module. */
+  function RequireOrderIntent(): RequireOrderIntentResult | LighterErrorResult;
+
+  interface HashAmendIntentResult {
+    hash: string;
+    path: string;
+    error: string;
+  }
+
+  /** expects the args of AmendOrder and returns {hash, path}. hash covers what the amend changes, see AmendIntentHash; a UI shows it in its confirmation dialog and passes it back as amend.intentHash to AmendOrder, which refuses to sign any other change. */
+  function HashAmendIntent(amend: object): HashAmendIntentResult | LighterErrorResult;
+}
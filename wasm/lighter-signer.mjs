@@ -0,0 +1,68 @@
+// ES module entry point of the signer for Deno, Bun, Node and browsers. It needs no shim: wasm_exec.js only
+// relies on globals every one of them provides, and the wasm is read with the host's own API.
+//
+//   import { init } from './lighter-signer.mjs';
+//   const signer = await init();
+//   signer.CreateClient(privateKey, accountIndex, apiKeyIndex, chainId);
+//
+// The exports are returned instead of being set on the global object, so several instances may be loaded side
+// by side. Pass { globals: true } to also register them as globals, as the wasm_exec.js loaders do.
+
+import './wasm_exec.js';
+
+const defaultWasmURL = new URL('./lighter-signer.wasm', import.meta.url);
+
+// readWasm returns the bytes of source: bytes as is, a Response, or a URL or path resolved against this module.
+async function readWasm(source) {
+  if (source instanceof ArrayBuffer || ArrayBuffer.isView(source)) {
+    return source;
+  }
+  if (typeof Response !== 'undefined' && source instanceof Response) {
+    return source.arrayBuffer();
+  }
+  const url = source === undefined ? defaultWasmURL : new URL(source, import.meta.url);
+  if (url.protocol === 'file:') {
+    if (globalThis.Deno) {
+      return globalThis.Deno.readFile(url);
+    }
+    if (globalThis.Bun) {
+      return globalThis.Bun.file(url).arrayBuffer();
+    }
+    const { readFile } = await import('node:fs/promises');
+    return readFile(url);
+  }
+  const res = await fetch(url);
+  if (!res.ok) {
+    throw new Error(`failed to fetch ${url}: ${res.status} ${res.statusText}`);
+  }
+  return res.arrayBuffer();
+}
+
+/**
+ * Instantiates the signer and resolves to the object holding its exports once they are registered.
+ * @param {string | URL | Response | BufferSource} [source] the wasm, lighter-signer.wasm next to this module by default
+ * @param {{ globals?: boolean, namespace?: object }} [options] globals also registers the exports as globals;
+ *   namespace is the object to register them on
+ */
+export async function init(source, options = {}) {
+  const bytes = await readWasm(source);
+  const go = new globalThis.Go();
+  const { instance } = await WebAssembly.instantiate(bytes, go.importObject);
+
+  return new Promise((resolve, reject) => {
+    // The module reads the options as it starts, within go.run, so no other instance can pick them up.
+    globalThis.lighterSignerInit = {
+      globals: options.globals ?? false,
+      namespace: options.namespace,
+      onReady: resolve,
+    };
+    go.run(instance).catch(reject);
+    if (globalThis.lighterSignerInit) {
+      // A build predating the init options registered its exports as globals.
+      delete globalThis.lighterSignerInit;
+      resolve(globalThis);
+    }
+  });
+}
+
+export default init;